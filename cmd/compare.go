@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/scanner"
+	"github.com/corpeningc/dua/internal/treefile"
+	"github.com/corpeningc/dua/ui"
+)
+
+// runCompare implements `dua compare left right`, showing two directories
+// side by side. Either side can be a JSON export instead, to compare against
+// an earlier state of a directory.
+func runCompare(args []string) error {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dua compare [flags] left right")
+		fmt.Fprintln(flags.Output(), "Each side is a directory to scan or a tree saved with --export-json.")
+		flags.PrintDefaults()
+	}
+
+	var configPath string
+	flags.StringVar(&configPath, "config", "", "Config file (default: dua/config.json in the user config directory)")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return ExitCode(2)
+	}
+
+	if configPath == "" {
+		configPath, _ = config.DefaultPath()
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("could not load config '%s': %w", configPath, err)
+	}
+
+	// Scan both sides at once, they're often on different disks
+	type side struct {
+		doc  treefile.Document
+		live bool
+		err  error
+	}
+	results := make([]chan side, 2)
+	for i, path := range flags.Args() {
+		results[i] = make(chan side, 1)
+		go func() {
+			doc, live, err := loadCompareSide(path)
+			results[i] <- side{doc, live, err}
+		}()
+	}
+	left, right := <-results[0], <-results[1]
+	if left.err != nil {
+		return left.err
+	}
+	if right.err != nil {
+		return right.err
+	}
+
+	program := tea.NewProgram(ui.NewCompare(left.doc, right.doc, left.live && right.live, cfg), tea.WithAltScreen())
+	final, err := program.Run()
+	if err != nil {
+		return err
+	}
+	// Printed after the alternate screen is gone, so it can be copied
+	if command := final.(ui.Compare).RsyncCommand(); command != "" {
+		fmt.Println(command)
+	}
+	return nil
+}
+
+// loadCompareSide loads path if it's an export and scans it if it's a
+// directory, which it reports as live.
+func loadCompareSide(path string) (treefile.Document, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return treefile.Document{}, false, err
+	}
+	if !info.IsDir() {
+		doc, err := treefile.Load(path)
+		return doc, false, err
+	}
+	root, err := scanner.NormalizeRoot(path)
+	if err != nil {
+		return treefile.Document{}, false, err
+	}
+	fmt.Fprintf(os.Stderr, "Scanning %s...\n", root)
+	doc, err := treefile.Scan(root)
+	return doc, true, err
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/paths"
+	"github.com/corpeningc/dua/internal/scandb"
+	"github.com/corpeningc/dua/internal/scanner"
+	"github.com/corpeningc/dua/internal/schedule"
+	"github.com/corpeningc/dua/internal/treefile"
+)
+
+// daemonRoot is a configured root with its parsed schedule.
+type daemonRoot struct {
+	path     string
+	schedule schedule.Schedule
+	next     time.Time
+}
+
+// runDaemon implements `dua daemon`, which scans the roots listed in the
+// config on their schedules, records each scan as a snapshot, prunes old
+// ones and caches the latest tree so `dua --cached` opens instantly.
+func runDaemon(args []string) error {
+	flags := flag.NewFlagSet("daemon", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dua daemon [flags]")
+		flags.PrintDefaults()
+	}
+
+	var configPath, dbPath string
+	var once bool
+	var pprofAddr string
+	flags.StringVar(&configPath, "config", "", "Config file (default: dua/config.json in the user config directory)")
+	flags.StringVar(&dbPath, "db", "", "Snapshot database (default: daemon.db in the config, or snapshots.db in the state directory)")
+	flags.BoolVar(&once, "once", false, "Scan every root now and exit, ignoring the schedules")
+	flags.StringVar(&pprofAddr, "pprof", "", "Serve profiling data on this address, e.g. localhost:6060")
+	flags.Parse(args)
+
+	if pprofAddr != "" {
+		if err := servePprof(pprofAddr); err != nil {
+			return err
+		}
+	}
+
+	if configPath == "" {
+		configPath, _ = config.DefaultPath()
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("could not load config '%s': %w", configPath, err)
+	}
+	if len(cfg.Daemon.Roots) == 0 {
+		return fmt.Errorf("no roots to scan, add them under \"daemon\" in %s", configPath)
+	}
+
+	var roots []*daemonRoot
+	now := time.Now()
+	for _, configured := range cfg.Daemon.Roots {
+		path, err := scanner.NormalizeRoot(configured.Path)
+		if err != nil {
+			return err
+		}
+		sched, err := schedule.Parse(configured.Schedule)
+		if err != nil {
+			return fmt.Errorf("%s: %w", configured.Path, err)
+		}
+		roots = append(roots, &daemonRoot{path: path, schedule: sched, next: sched.Next(now)})
+	}
+
+	if dbPath == "" {
+		dbPath = cfg.Daemon.DB
+	}
+	if dbPath == "" {
+		if dbPath, err = paths.StateFile("snapshots.db"); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+			return err
+		}
+	}
+	db, err := scandb.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	limits := scanner.NewRateLimits(cfg.ScanRateLimits)
+
+	if once {
+		for _, root := range roots {
+			daemonScan(db, root.path, cfg.Daemon.KeepDays, limits)
+		}
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("dua daemon: %d roots, snapshots in %s", len(roots), dbPath)
+	for {
+		due := roots[0]
+		for _, root := range roots[1:] {
+			if !root.next.IsZero() && (due.next.IsZero() || root.next.Before(due.next)) {
+				due = root
+			}
+		}
+		if due.next.IsZero() {
+			return errors.New("no schedule ever fires")
+		}
+		log.Printf("next scan of %s at %s", due.path, due.next.Format(time.DateTime))
+
+		timer := time.NewTimer(time.Until(due.next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Printf("dua daemon: stopping")
+			return nil
+		case <-timer.C:
+		}
+
+		daemonScan(db, due.path, cfg.Daemon.KeepDays, limits)
+		due.next = due.schedule.Next(time.Now())
+	}
+}
+
+// daemonScan scans root at the rate limits allow, records and caches it and
+// prunes snapshots older than keepDays. Failures are logged so one bad root
+// doesn't stop the rest.
+func daemonScan(db *scandb.DB, root string, keepDays int, limits *scanner.RateLimits) {
+	start := time.Now()
+	streamer := scanner.NewStreamingScanner()
+	streamer.SetRateLimit(limits.For(root))
+	doc, err := treefile.ScanWith(streamer, root)
+	if err != nil {
+		log.Printf("scan of %s failed: %v", root, err)
+		return
+	}
+
+	snapshot, err := db.RecordTree(doc)
+	if err != nil {
+		log.Printf("recording %s failed: %v", root, err)
+		return
+	}
+	if err := treefile.SaveCache(doc); err != nil {
+		log.Printf("caching %s failed: %v", root, err)
+	}
+	log.Printf("scanned %s in %s: %d files, %s (snapshot %d)",
+		root, time.Since(start).Round(time.Millisecond), snapshot.Files, humanize.Bytes(snapshot.Bytes), snapshot.ID)
+
+	if keepDays > 0 {
+		pruned, err := db.Prune(root, time.Now().AddDate(0, 0, -keepDays))
+		if err != nil {
+			log.Printf("pruning %s failed: %v", root, err)
+		} else if pruned > 0 {
+			log.Printf("pruned %d snapshots of %s older than %d days", pruned, root, keepDays)
+		}
+	}
+}
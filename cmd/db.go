@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/scandb"
+	"github.com/corpeningc/dua/internal/treefile"
+)
+
+// runRecordSnapshot scans root into a new snapshot in the SQLite database at
+// dbPath, creating the database if it doesn't exist. With an aggregateDepth,
+// only the entries down to that depth are recorded.
+func runRecordSnapshot(root, dbPath string, aggregateDepth int) error {
+	db, err := scandb.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var snapshot scandb.Snapshot
+	if aggregateDepth > 0 {
+		var doc treefile.Document
+		if doc, err = treefile.Scan(root); err != nil {
+			return err
+		}
+		doc.Tree.Aggregate(aggregateDepth)
+		snapshot, err = db.RecordTree(doc)
+	} else {
+		snapshot, err = db.Record(root)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Recorded snapshot %d of %s in %s: %d files, %d dirs, %s\n",
+		snapshot.ID, root, dbPath, snapshot.Files, snapshot.Dirs, humanize.Bytes(snapshot.Bytes))
+	return nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/openfiles"
+)
+
+// runDeletedOpenReport lists deleted files that processes still hold open,
+// largest first. Their space stays in use, counted by df but invisible to
+// du, until every holder closes them.
+func runDeletedOpenReport() error {
+	files, err := openfiles.Scan()
+	if err != nil {
+		return err
+	}
+
+	var deleted []openfiles.File
+	var total int64
+	for _, file := range files {
+		if file.Deleted && file.Size > 0 {
+			deleted = append(deleted, file)
+			total += file.Size
+		}
+	}
+	if len(deleted) == 0 {
+		fmt.Println("No deleted files are held open")
+		return nil
+	}
+
+	sort.Slice(deleted, func(i, j int) bool {
+		return deleted[i].Size > deleted[j].Size
+	})
+	fmt.Println("Deleted files still held open:")
+	for _, file := range deleted {
+		var holders []string
+		for _, process := range file.Holders {
+			holders = append(holders, process.String())
+		}
+		fmt.Printf("  %10s  %s\n  %10s  held by %s\n", humanize.Bytes(file.Size), file.Path, "", strings.Join(holders, ", "))
+	}
+
+	fmt.Printf("\n%d files take %s\n", len(deleted), humanize.Bytes(total))
+	fmt.Println("Restart or signal the processes to have them close the files, or truncate one in place with")
+	fmt.Printf("  : > %s\n", deleted[0].Descriptors[0])
+	return nil
+}
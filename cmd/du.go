@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/kiosk"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// runDu implements `dua du [flags] [path...]`, a stand-in for the common
+// `du -sh --max-depth=N` that uses the parallel scanner and lists
+// directories smallest first, so the totals end up at the bottom.
+func runDu(args []string, policy *kiosk.Policy) error {
+	flags := flag.NewFlagSet("du", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dua du [flags] [path...]")
+		flags.PrintDefaults()
+	}
+
+	var maxDepth int
+	var summarize bool
+	var bytes bool
+	flags.IntVar(&maxDepth, "max-depth", -1, "Only list directories this many levels below each path (-1 for all)")
+	flags.IntVar(&maxDepth, "d", -1, "Shorthand for -max-depth")
+	flags.BoolVar(&summarize, "s", false, "Only show a total for each path, like -max-depth 0")
+	flags.BoolVar(&bytes, "b", false, "Print sizes in bytes instead of human-readable units")
+	flags.Parse(args)
+
+	if summarize {
+		maxDepth = 0
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	format := humanize.Bytes
+	if bytes {
+		format = func(n int64) string { return fmt.Sprint(n) }
+	}
+
+	failed := false
+	for _, path := range paths {
+		if err := duPath(path, maxDepth, format, policy); err != nil {
+			fmt.Fprintf(os.Stderr, "dua du: %v\n", err)
+			failed = true
+		}
+	}
+	if failed {
+		return ExitCode(1)
+	}
+	return nil
+}
+
+// duPath scans path and prints the apparent size of it and its directories
+// down to maxDepth, if policy allows scanning it.
+func duPath(path string, maxDepth int, format func(int64) string, policy *kiosk.Policy) error {
+	root, err := scanner.NormalizeRoot(path)
+	if err != nil {
+		return err
+	}
+	if !policy.CanScan(root) {
+		return fmt.Errorf("%s: kiosk mode only allows scanning %s", root, strings.Join(policy.Roots, ", "))
+	}
+
+	totals := make(map[string]int64)
+	streamer := scanner.NewStreamingScanner()
+	updates, errs := streamer.StartStreaming(root)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for err := range errs {
+			fmt.Fprintf(os.Stderr, "dua du: %v\n", err)
+		}
+	}()
+
+	for update := range updates {
+		if update.IsComplete {
+			break
+		}
+		if update.DirInfo == nil {
+			continue
+		}
+
+		// Each update carries one directory's own files, which count towards
+		// it and every directory above it
+		own := update.DirInfo.Size
+		for dir := update.DirInfo.Path; ; dir = filepath.Dir(dir) {
+			totals[dir] += own
+			if dir == root || filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	streamer.Stop()
+	<-done
+
+	var dirs []string
+	for dir := range totals {
+		if maxDepth < 0 || depthBelow(root, dir) <= maxDepth {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if totals[dirs[i]] != totals[dirs[j]] {
+			return totals[dirs[i]] < totals[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	// Show paths the way they were given, like du does
+	for _, dir := range dirs {
+		rel, _ := filepath.Rel(root, dir)
+		fmt.Printf("%s\t%s\n", format(totals[dir]), filepath.Join(path, rel))
+	}
+	return nil
+}
+
+func depthBelow(root, dir string) int {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
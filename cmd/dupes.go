@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/dupes"
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/ui"
+)
+
+// runDupDirs prints the duplicated directory trees under root along with the
+// space that removing the extra copies would free.
+func runDupDirs(root string) error {
+	fmt.Printf("Hashing %s for duplicate directories...\n", root)
+
+	groups, err := dupes.FindDuplicateDirs(root)
+	if err != nil {
+		return err
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No duplicate directories found")
+		return nil
+	}
+
+	var total int64
+	for _, group := range groups {
+		fmt.Printf("\n%s each, %s reclaimable:\n", humanize.Bytes(group.Size), humanize.Bytes(group.Savings()))
+		for _, path := range group.Paths {
+			fmt.Printf("  %s\n", path)
+		}
+		total += group.Savings()
+	}
+
+	fmt.Printf("\n%d duplicate groups, %s reclaimable\n", len(groups), humanize.Bytes(total))
+	return nil
+}
+
+// runSimilarMedia hashes the images under root and opens the review pane on
+// the groups of near-duplicates.
+func runSimilarMedia(root string, cfg config.Config) error {
+	fmt.Printf("Hashing images in %s...\n", root)
+
+	groups, err := dupes.FindSimilarImages(root, dupes.DefaultSimilarity)
+	if err != nil {
+		return err
+	}
+
+	program := tea.NewProgram(ui.NewMediaReview(groups, cfg), tea.WithAltScreen())
+	_, err = program.Run()
+	return err
+}
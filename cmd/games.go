@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/corpeningc/dua/internal/games"
+	"github.com/corpeningc/dua/internal/humanize"
+)
+
+// gamesStaleMonths is how long since a game was last played before the
+// summary counts it as a candidate for uninstalling.
+const gamesStaleMonths = 6
+
+// runGamesReport lists the Steam and Epic libraries on this machine, plus root
+// if it's a Steam library they don't know of, with each game's size and when
+// it was last played, largest first.
+func runGamesReport(root string) error {
+	libraries := games.Find(root)
+	if len(libraries) == 0 {
+		fmt.Println("No Steam or Epic games found")
+		return nil
+	}
+
+	sort.Slice(libraries, func(i, j int) bool {
+		return libraries[i].Size > libraries[j].Size
+	})
+	stale := time.Now().AddDate(0, -gamesStaleMonths, 0)
+	var count, staleCount int
+	var total, staleSize int64
+	for i, library := range libraries {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s library %s, %s:\n", library.Launcher, library.Path, humanize.Bytes(library.Size))
+
+		sort.Slice(library.Games, func(i, j int) bool {
+			return library.Games[i].Size > library.Games[j].Size
+		})
+		for _, game := range library.Games {
+			played := "not recorded"
+			if !game.LastPlayed.IsZero() {
+				played = "played " + game.LastPlayed.Format(time.DateOnly)
+			} else if game.Launcher == games.Steam {
+				played = "never played"
+			}
+			fmt.Printf("  %10s  %-18s  %s\n  %10s  %-18s  %s\n", humanize.Bytes(game.Size), played, game.Name, "", "", game.Path)
+
+			count++
+			total += game.Size
+			if game.Launcher == games.Steam && game.LastPlayed.Before(stale) {
+				staleCount++
+				staleSize += game.Size
+			}
+		}
+	}
+
+	fmt.Printf("\n%d games, %s\n", count, humanize.Bytes(total))
+	if staleCount > 0 {
+		fmt.Printf("%d not played in %d months take %s\n", staleCount, gamesStaleMonths, humanize.Bytes(staleSize))
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/kiosk"
+	"github.com/corpeningc/dua/internal/rpc"
+	"github.com/corpeningc/dua/internal/rpc/duapb"
+	"github.com/corpeningc/dua/internal/scanner"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// runGRPC implements `dua grpc`, which serves the ScanService defined in
+// proto/dua/v1/scan.proto so other programs can start scans and stream
+// their results.
+func runGRPC(args []string) error {
+	flags := flag.NewFlagSet("grpc", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dua grpc [flags]")
+		flags.PrintDefaults()
+	}
+
+	var listen, pprofAddr, configPath, certFile, keyFile, clientCAFile, tokenFile string
+	var roots []string
+	flags.StringVar(&listen, "listen", "localhost:7070", "Address to serve gRPC on, only this machine's unless -token-file or -tls-client-ca is given")
+	flags.StringVar(&configPath, "config", "", "Config file (default: dua/config.json in the user config directory)")
+	flags.StringVar(&pprofAddr, "pprof", "", "Also serve profiling data on this address, e.g. localhost:6060")
+	flags.StringVar(&certFile, "tls-cert", "", "Serve TLS with this certificate, along with -tls-key")
+	flags.StringVar(&keyFile, "tls-key", "", "Private key of the -tls-cert certificate")
+	flags.StringVar(&clientCAFile, "tls-client-ca", "", "Require clients to present a certificate signed by a CA in this file")
+	flags.StringVar(&tokenFile, "token-file", "", "File holding a token clients must send as \"authorization: Bearer TOKEN\"")
+	flags.Func("root", "Directory clients may scan, with everything below it; repeat for more (default: any)", func(root string) error {
+		resolved, err := scanner.NormalizeRoot(root)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, resolved)
+		return nil
+	})
+	flags.Parse(args)
+
+	if err := checkGRPCAccess(listen, certFile, keyFile, clientCAFile, tokenFile); err != nil {
+		return err
+	}
+	var options []grpc.ServerOption
+	if certFile != "" {
+		tlsConfig, err := grpcTLSConfig(certFile, keyFile, clientCAFile)
+		if err != nil {
+			return err
+		}
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	if tokenFile != "" {
+		token, err := readToken(tokenFile)
+		if err != nil {
+			return err
+		}
+		options = append(options, rpc.RequireToken(token)...)
+	}
+	var policy *kiosk.Policy
+	if len(roots) > 0 {
+		policy = &kiosk.Policy{Roots: roots}
+	}
+
+	if pprofAddr != "" {
+		if err := servePprof(pprofAddr); err != nil {
+			return err
+		}
+	}
+
+	if configPath == "" {
+		configPath, _ = config.DefaultPath()
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("could not load config '%s': %w", configPath, err)
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	scans := rpc.NewServer(scanner.NewRateLimits(cfg.ScanRateLimits), policy)
+	server := grpc.NewServer(options...)
+	duapb.RegisterScanServiceServer(server, scans)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		log.Printf("dua grpc: stopping")
+		scans.Stop()
+		server.Stop()
+	}()
+
+	log.Printf("dua grpc: serving on %s", listener.Addr())
+	return server.Serve(listener)
+}
+
+// checkGRPCAccess checks the flags dua grpc is given for serving listen.
+// Anyone who can connect can read the machine's directory listings, so
+// serving other machines takes clients proving who they are, with a token
+// or a client certificate; TLS on its own only encrypts the connection.
+func checkGRPCAccess(listen, certFile, keyFile, clientCAFile, tokenFile string) error {
+	if (certFile == "") != (keyFile == "") {
+		return errors.New("-tls-cert and -tls-key go together")
+	}
+	if clientCAFile != "" && certFile == "" {
+		return errors.New("-tls-client-ca needs -tls-cert and -tls-key")
+	}
+	if tokenFile == "" && clientCAFile == "" && !loopback(listen) {
+		return fmt.Errorf("refusing to serve %s to other machines without -token-file or -tls-client-ca", listen)
+	}
+	return nil
+}
+
+// grpcTLSConfig serves the certificate in certFile with the key in keyFile,
+// and if clientCAFile is set, requires clients to present a certificate
+// signed by a CA in it.
+func grpcTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if clientCAFile != "" {
+		data, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%s holds no PEM certificates", clientCAFile)
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// readToken returns the token in file, without surrounding whitespace.
+func readToken(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%s holds no token", file)
+	}
+	return token, nil
+}
+
+// loopback reports whether addr only listens on this machine. Host names
+// other than localhost may resolve anywhere, so they don't count.
+func loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
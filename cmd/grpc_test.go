@@ -0,0 +1,49 @@
+package cmd
+
+import "testing"
+
+func TestLoopback(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"localhost:7070", true},
+		{"127.0.0.1:7070", true},
+		{"127.1.2.3:7070", true},
+		{"[::1]:7070", true},
+		{":7070", false},
+		{"0.0.0.0:7070", false},
+		{"[::]:7070", false},
+		{"192.168.1.10:7070", false},
+		{"example.com:7070", false},
+		{"localhost", false},
+	}
+	for _, test := range tests {
+		if got := loopback(test.addr); got != test.want {
+			t.Errorf("loopback(%q) = %v, want %v", test.addr, got, test.want)
+		}
+	}
+}
+
+func TestCheckGRPCAccess(t *testing.T) {
+	tests := []struct {
+		listen, cert, key, clientCA, token string
+		ok                                 bool
+	}{
+		{"localhost:7070", "", "", "", "", true},
+		{":7070", "", "", "", "", false},
+		{":7070", "cert.pem", "key.pem", "", "", false},
+		{":7070", "", "", "", "token", true},
+		{":7070", "cert.pem", "key.pem", "", "token", true},
+		{":7070", "cert.pem", "key.pem", "ca.pem", "", true},
+		{":7070", "", "", "ca.pem", "", false},
+		{"localhost:7070", "cert.pem", "", "", "", false},
+	}
+	for _, test := range tests {
+		err := checkGRPCAccess(test.listen, test.cert, test.key, test.clientCA, test.token)
+		if (err == nil) != test.ok {
+			t.Errorf("checkGRPCAccess(%q, %q, %q, %q, %q) = %v, want ok %v",
+				test.listen, test.cert, test.key, test.clientCA, test.token, err, test.ok)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/corpeningc/dua/internal/fsusage"
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/scandb"
+)
+
+// historyTopDirs is how many of the fastest growing directories are listed.
+const historyTopDirs = 10
+
+// runHistory prints the snapshots of root recorded in the database at
+// dbPath, how fast it grows, and when the filesystem holding it would fill up
+// at that rate.
+func runHistory(root, dbPath string) error {
+	db, err := scandb.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	snapshots, err := db.Snapshots(root)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Printf("No snapshots of %s in %s yet, record one with --db\n", root, dbPath)
+		return nil
+	}
+
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	fmt.Printf("History of %s: %d snapshots from %s to %s\n",
+		root, len(snapshots), first.Taken.Format(time.DateOnly), last.Taken.Format(time.DateOnly))
+	for _, s := range snapshots {
+		fmt.Printf("  %s  %10s  %d files\n", s.Taken.Format("2006-01-02 15:04"), humanize.Bytes(s.Bytes), s.Files)
+	}
+
+	perDay, ok := scandb.GrowthPerDay(snapshots)
+	if !ok {
+		fmt.Println("\nRecord more snapshots over time to see growth.")
+		return nil
+	}
+	fmt.Printf("\nGrowth: %s/day (%s/month)\n", signedBytes(perDay), signedBytes(perDay*30))
+
+	if usage, err := fsusage.Stat(root); err == nil {
+		fmt.Printf("Filesystem: %s available of %s", humanize.Bytes(int64(usage.Available)), humanize.Bytes(int64(usage.Total)))
+		if perDay > 0 {
+			days := float64(usage.Available) / perDay
+			full := time.Now().Add(time.Duration(days * 24 * float64(time.Hour)))
+			if days < 365*100 {
+				fmt.Printf(", full in about %.0f days at this rate (around %s)", math.Ceil(days), full.Format(time.DateOnly))
+			}
+		}
+		fmt.Println()
+	}
+
+	growth, err := db.ChildGrowth(root, first.ID, last.ID)
+	if err != nil {
+		return err
+	}
+	days := last.Taken.Sub(first.Taken).Hours() / 24
+	if days <= 0 || len(growth) == 0 {
+		return nil
+	}
+	sort.Slice(growth, func(i, j int) bool {
+		return growth[i].After-growth[i].Before > growth[j].After-growth[j].Before
+	})
+
+	fmt.Printf("\nFastest growing directories since %s:\n", first.Taken.Format(time.DateOnly))
+	for _, g := range growth[:min(len(growth), historyTopDirs)] {
+		if g.After <= g.Before {
+			break
+		}
+		fmt.Printf("  %12s/day  %s (%s)\n", signedBytes(float64(g.After-g.Before)/days), g.Path, humanize.Bytes(g.After))
+	}
+	return nil
+}
+
+// signedBytes formats a rate of change with its sign.
+func signedBytes(n float64) string {
+	if n < 0 {
+		return "-" + humanize.Bytes(int64(-n))
+	}
+	return "+" + humanize.Bytes(int64(n))
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/kube"
+	"github.com/corpeningc/dua/internal/treefile"
+)
+
+// kubeNearlyFull is how full a claim has to be to be called out.
+const kubeNearlyFull = 0.9
+
+// runKube implements `dua k8s`, which lists how full the persistent volume
+// claims of a cluster are, or scans the given claims (or all of them) into
+// one tree to browse with -load.
+func runKube(args []string) error {
+	flags := flag.NewFlagSet("k8s", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dua k8s [flags] [namespace/claim...]")
+		flags.PrintDefaults()
+	}
+
+	var kubeContext, kubeconfig, namespace, output string
+	var all bool
+	flags.StringVar(&kubeContext, "context", "", "kubeconfig context to use (default: the current context)")
+	flags.StringVar(&kubeconfig, "kubeconfig", "", "kubeconfig file (default: kubectl's)")
+	flags.StringVar(&namespace, "n", "", "Only include claims in this namespace")
+	flags.StringVar(&output, "o", "pvcs.json", "File to write scanned claims to, for -load")
+	flags.BoolVar(&all, "all", false, "Scan every mounted claim")
+
+	// Allow flags after the claims, like dua merge
+	var names []string
+	for {
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			break
+		}
+		names = append(names, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if err := kube.Available(); err != nil {
+		return err
+	}
+	client := kube.Client{}
+	if kubeContext != "" {
+		client.Flags = append(client.Flags, "--context", kubeContext)
+	}
+	if kubeconfig != "" {
+		client.Flags = append(client.Flags, "--kubeconfig", kubeconfig)
+	}
+
+	if len(names) == 0 {
+		claims, err := client.Claims(namespace)
+		if err != nil {
+			return err
+		}
+		if !all {
+			printClaims(claims)
+			return nil
+		}
+		for _, claim := range claims {
+			names = append(names, claim.Namespace+"/"+claim.Name)
+		}
+	}
+	return scanClaims(client, names, namespace, output)
+}
+
+// printClaims lists claims with how full they are, fullest first.
+func printClaims(claims []kube.Claim) {
+	if len(claims) == 0 {
+		fmt.Println("No mounted persistent volume claims found.")
+		return
+	}
+
+	fmt.Printf("%d mounted persistent volume claims, fullest first:\n", len(claims))
+	for _, claim := range claims {
+		line := fmt.Sprintf("  %5.1f%%  %10s of %-10s  %s/%s", claim.Full()*100,
+			humanize.Bytes(claim.Used), humanize.Bytes(claim.Capacity), claim.Namespace, claim.Name)
+		if claim.Full() >= kubeNearlyFull {
+			line += fmt.Sprintf("  (nearly full, %s left)", humanize.Bytes(claim.Available))
+		}
+		fmt.Println(line)
+	}
+	fmt.Println("\nScan claims with `dua k8s namespace/claim...` or `dua k8s -all` to see what's in them.")
+}
+
+// scanClaims scans each claim through a pod that mounts it and saves them as
+// one tree with a directory per namespace. Claims that can't be scanned are
+// reported and skipped.
+func scanClaims(client kube.Client, names []string, namespace, output string) error {
+	var docs []treefile.Document
+	for _, name := range names {
+		ns, claim, ok := strings.Cut(name, "/")
+		if !ok {
+			if namespace == "" {
+				return fmt.Errorf("%q is not namespace/claim, or pass the namespace with -n", name)
+			}
+			ns, claim = namespace, name
+		}
+
+		mount, err := client.FindMount(ns, claim)
+		if err == nil {
+			var doc treefile.Document
+			if doc, err = client.Scan(ns, claim, mount); err == nil {
+				fmt.Printf("Scanned %s/%s through pod %s\n", ns, claim, mount.Pod)
+				docs = append(docs, doc)
+				continue
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Could not scan %s/%s: %v\n", ns, claim, err)
+	}
+	if len(docs) == 0 {
+		return errors.New("no claims scanned")
+	}
+
+	merged, err := treefile.Merge(docs)
+	if err != nil {
+		return err
+	}
+	if err := treefile.Save(output, merged); err != nil {
+		return err
+	}
+	fmt.Printf("Saved %s from %d of %d claims to %s, browse it with: dua --load %s\n",
+		humanize.Bytes(merged.Tree.Size), len(docs), len(names), output, output)
+	return nil
+}
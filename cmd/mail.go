@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/mailstore"
+)
+
+// mailTopAttachments is how many of the largest attachments are listed.
+const mailTopAttachments = 20
+
+// runMailReport lists the Maildir folders and mbox files under root with
+// their message counts, largest first, and the largest attachments in them.
+func runMailReport(root string) error {
+	stores, err := mailstore.Find(root)
+	if err != nil {
+		return err
+	}
+	if len(stores) == 0 {
+		fmt.Printf("No Maildir folders or mbox files under %s\n", root)
+		return nil
+	}
+
+	var attachments []mailstore.Attachment
+	for i := range stores {
+		err := mailstore.Read(&stores[i], func(a mailstore.Attachment) {
+			attachments = append(attachments, a)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", stores[i].Path, err)
+		}
+	}
+
+	sort.Slice(stores, func(i, j int) bool {
+		return stores[i].Size > stores[j].Size
+	})
+	var messages int
+	var total int64
+	fmt.Printf("Mail stores under %s:\n", root)
+	for _, store := range stores {
+		fmt.Printf("  %-7s %8d messages  %10s  %s\n", store.Format, store.Messages, humanize.Bytes(store.Size), store.Path)
+		messages += store.Messages
+		total += store.Size
+	}
+	fmt.Printf("\n%d stores, %d messages, %s\n", len(stores), messages, humanize.Bytes(total))
+
+	if len(attachments) == 0 {
+		return nil
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].Size > attachments[j].Size
+	})
+
+	var attached int64
+	for _, a := range attachments {
+		attached += a.Size
+	}
+	fmt.Printf("\n%d attachments take %s, the largest:\n", len(attachments), humanize.Bytes(attached))
+	for _, a := range attachments[:min(len(attachments), mailTopAttachments)] {
+		date := "no date"
+		if !a.Date.IsZero() {
+			date = a.Date.Format(time.DateOnly)
+		}
+		where := a.Path
+		if a.Index > 0 {
+			where = fmt.Sprintf("%s, message %d", a.Path, a.Index)
+		}
+		fmt.Printf("  %10s  %s in %q (%s)\n  %10s  %s\n", humanize.Bytes(a.Size), a.Name, a.Subject, date, "", where)
+	}
+	return nil
+}
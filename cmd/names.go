@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/corpeningc/dua/internal/names"
+)
+
+// nameFix renames one entry to a safe name.
+type nameFix struct {
+	dir   string
+	from  string
+	to    string
+	depth int
+}
+
+// runNameReport lists names under root that break on other systems. With fix
+// set it lists what the offending entries would be renamed to, and once
+// answers confirms, renames them, deepest first so renaming a directory
+// never invalidates the paths still to be fixed inside it.
+func runNameReport(root string, fix bool, answers io.Reader) error {
+	var fixes []nameFix
+	found := 0
+
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading directory %s: %v\n", dir, err)
+			return
+		}
+
+		var siblings []string
+		for _, entry := range entries {
+			siblings = append(siblings, entry.Name())
+		}
+		// Names claimed so far, folded so fixes can't create new collisions
+		taken := make(map[string]bool)
+		for _, name := range siblings {
+			taken[strings.ToLower(name)] = true
+		}
+		claim := func(from, to string) {
+			base := to
+			for n := 2; taken[strings.ToLower(to)]; n++ {
+				to = names.Disambiguate(base, n)
+			}
+			taken[strings.ToLower(to)] = true
+			fixes = append(fixes, nameFix{dir: dir, from: from, to: to, depth: depth})
+		}
+
+		renamed := make(map[string]bool)
+		for _, name := range siblings {
+			problems := names.Check(name)
+			if len(problems) == 0 {
+				continue
+			}
+			found++
+			printNameProblem(filepath.Join(dir, name), problems)
+			if fixed := names.Fix(name); fixed != name {
+				claim(name, fixed)
+				renamed[name] = true
+			}
+		}
+
+		for _, group := range names.Collisions(siblings) {
+			found++
+			for _, name := range group {
+				printNameProblem(filepath.Join(dir, name), []names.Problem{names.CaseCollision})
+			}
+			// Keep the first name, move the rest out of the way
+			for i, name := range group[1:] {
+				if !renamed[name] {
+					claim(name, names.Disambiguate(name, i+2))
+				}
+			}
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				walk(filepath.Join(dir, entry.Name()), depth+1)
+			}
+		}
+	}
+	walk(root, 0)
+
+	fmt.Printf("\n%d problem names\n", found)
+	if !fix || len(fixes) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(fixes, func(i, j int) bool {
+		return fixes[i].depth > fixes[j].depth
+	})
+
+	fmt.Println()
+	for _, f := range fixes {
+		fmt.Printf("%s -> %s\n", strconv.Quote(filepath.Join(f.dir, f.from)), strconv.Quote(finalPath(root, filepath.Join(f.dir, f.to), fixes)))
+	}
+	fmt.Printf("\nRename %d entries? [y/N] ", len(fixes))
+	answer, _ := bufio.NewReader(answers).ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		fmt.Println("Nothing renamed")
+		return nil
+	}
+
+	fmt.Println()
+	failed := 0
+	for _, f := range fixes {
+		from, to := filepath.Join(f.dir, f.from), filepath.Join(f.dir, f.to)
+		if err := os.Rename(from, to); err != nil {
+			fmt.Fprintf(os.Stderr, "Error renaming %s: %v\n", strconv.Quote(from), err)
+			failed++
+			continue
+		}
+		fmt.Printf("renamed %s -> %s\n", strconv.Quote(from), strconv.Quote(f.to))
+	}
+	fmt.Printf("\n%d renamed, %d failed\n", len(fixes)-failed, failed)
+	return nil
+}
+
+// finalPath is where path ends up once the directories above it below root
+// are renamed by fixes too.
+func finalPath(root, path string, fixes []nameFix) string {
+	dir := filepath.Dir(path)
+	if dir == root || dir == path {
+		return path
+	}
+	dir = finalPath(root, dir, fixes)
+	for _, f := range fixes {
+		if filepath.Join(f.dir, f.from) == filepath.Dir(path) {
+			dir = filepath.Join(filepath.Dir(dir), f.to)
+		}
+	}
+	return filepath.Join(dir, filepath.Base(path))
+}
+
+// printNameProblem quotes the path so stray spaces and control characters
+// are visible.
+func printNameProblem(path string, problems []names.Problem) {
+	labels := make([]string, len(problems))
+	for i, problem := range problems {
+		labels[i] = string(problem)
+	}
+	fmt.Printf("%-40s %s\n", strings.Join(labels, ", "), strconv.Quote(path))
+}
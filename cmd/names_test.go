@@ -0,0 +1,48 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFixNamesConfirms renames only once the listing is confirmed, moving
+// reserved names inside a directory that's renamed too.
+func TestFixNamesConfirms(t *testing.T) {
+	for _, answer := range []string{"", "n\n", "y\n"} {
+		root := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(root, "a:b", "con.tar.gz"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := runNameReport(root, true, strings.NewReader(answer)); err != nil {
+			t.Fatal(err)
+		}
+		want, gone := filepath.Join(root, "a_b", "con_.tar.gz"), filepath.Join(root, "a:b", "con.tar.gz")
+		if answer != "y\n" {
+			want, gone = gone, want
+		}
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("answering %q: %v", answer, err)
+		}
+		if _, err := os.Stat(gone); err == nil {
+			t.Errorf("answering %q left %s", answer, gone)
+		}
+	}
+}
+
+func TestFinalPath(t *testing.T) {
+	fixes := []nameFix{
+		{dir: "/r/a:b", from: "con", to: "con_"},
+		{dir: "/r", from: "a:b", to: "a_b"},
+	}
+	if got := finalPath("/r", "/r/a:b/con_", fixes); got != "/r/a_b/con_" {
+		t.Errorf("finalPath = %s, want /r/a_b/con_", got)
+	}
+	if got := finalPath("/r", "/r/a_b", fixes); got != "/r/a_b" {
+		t.Errorf("finalPath = %s, want /r/a_b", got)
+	}
+}
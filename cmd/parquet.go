@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/scanner"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/zstd"
+)
+
+// parquetBatch is how many rows are buffered before they're handed to the
+// writer.
+const parquetBatch = 4096
+
+// parquetRow is one file in a Parquet export. Components are the path's
+// segments below the scanned root, so files can be grouped by directory at
+// any depth. With aggregation, a row can also be a directory holding Files
+// files, with the newest modification time among them.
+type parquetRow struct {
+	Path       string    `parquet:"path"`
+	Components []string  `parquet:"components,list"`
+	Depth      int32     `parquet:"depth"`
+	Name       string    `parquet:"name"`
+	Extension  string    `parquet:"extension"`
+	Size       int64     `parquet:"size"`
+	ModTime    time.Time `parquet:"mtime,timestamp(millisecond)"`
+	Owner      string    `parquet:"owner"`
+	IsDir      bool      `parquet:"is_dir"`
+	Files      int64     `parquet:"files"`
+}
+
+// runParquetExport writes a row per regular file under root to a Parquet
+// file for analysis in tools like DuckDB or pandas. With an aggregateDepth,
+// files deeper than that are rolled up into a row for their directory at
+// that depth instead.
+func runParquetExport(root, file string, aggregateDepth int) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	writer := parquet.NewGenericWriter[parquetRow](out, parquet.Compression(&zstd.Codec{}))
+
+	var files int
+	var total int64
+	rows := make([]parquetRow, 0, parquetBatch)
+	// Rolled up directories, written once the walk is done
+	var rollups []*parquetRow
+	rollupOf := make(map[string]*parquetRow)
+	flush := func() error {
+		_, err := writer.Write(rows)
+		rows = rows[:0]
+		return err
+	}
+
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		components := strings.Split(filepath.ToSlash(rel), "/")
+		files++
+		total += info.Size()
+
+		if aggregateDepth > 0 && len(components) > aggregateDepth {
+			components = components[:aggregateDepth]
+			dir := filepath.Join(root, filepath.FromSlash(strings.Join(components, "/")))
+			rollup := rollupOf[dir]
+			if rollup == nil {
+				rollup = &parquetRow{
+					Path:       dir,
+					Components: components,
+					Depth:      int32(aggregateDepth),
+					Name:       components[len(components)-1],
+					IsDir:      true,
+				}
+				rollupOf[dir] = rollup
+				rollups = append(rollups, rollup)
+			}
+			rollup.Size += info.Size()
+			rollup.Files++
+			if info.ModTime().After(rollup.ModTime) {
+				rollup.ModTime = info.ModTime()
+			}
+			return nil
+		}
+
+		rows = append(rows, parquetRow{
+			Path:       path,
+			Components: components,
+			Depth:      int32(len(components)),
+			Name:       entry.Name(),
+			Extension:  strings.ToLower(strings.TrimPrefix(filepath.Ext(entry.Name()), ".")),
+			Size:       info.Size(),
+			ModTime:    info.ModTime(),
+			Owner:      scanner.Owner(info),
+			Files:      1,
+		})
+
+		if len(rows) == parquetBatch {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, rollup := range rollups {
+		rows = append(rows, *rollup)
+		if len(rows) == parquetBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d files (%s) to %s\n", files, humanize.Bytes(total), file)
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// windowsMaxPath is the classic Windows path limit that breaks copies of deep
+// trees onto Windows machines and older archive tools.
+const windowsMaxPath = 260
+
+// maxNameBytes is the per-name limit of most Unix filesystems.
+const maxNameBytes = 255
+
+type pathEntry struct {
+	path  string
+	depth int
+	name  int // Name length in characters
+}
+
+// runPathReport lists the count deepest paths and longest names under root,
+// along with how many exceed the limits that trip up Windows, rsync and tar.
+func runPathReport(root string, count int) error {
+	var entries []pathEntry
+	var overMaxPath, overNameLimit int
+	hasChildren := make(map[string]bool)
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if path == root {
+			return nil
+		}
+
+		hasChildren[filepath.Dir(path)] = true
+		rel, _ := filepath.Rel(root, path)
+		entries = append(entries, pathEntry{
+			path:  path,
+			depth: strings.Count(rel, string(filepath.Separator)) + 1,
+			name:  utf8.RuneCountInString(entry.Name()),
+		})
+		if utf8.RuneCountInString(path) >= windowsMaxPath {
+			overMaxPath++
+		}
+		if len(entry.Name()) > maxNameBytes {
+			overNameLimit++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Only leaves count for depth, otherwise one deep chain fills the list
+	// with its own parents
+	var leaves []pathEntry
+	for _, entry := range entries {
+		if !hasChildren[entry.path] {
+			leaves = append(leaves, entry)
+		}
+	}
+	sort.Slice(leaves, func(i, j int) bool {
+		if leaves[i].depth != leaves[j].depth {
+			return leaves[i].depth > leaves[j].depth
+		}
+		return len(leaves[i].path) > len(leaves[j].path)
+	})
+	fmt.Println("Deepest paths:")
+	for _, entry := range leaves[:min(count, len(leaves))] {
+		fmt.Printf("  depth %3d  %4d chars  %s\n", entry.depth, utf8.RuneCountInString(entry.path), entry.path)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].name != entries[j].name {
+			return entries[i].name > entries[j].name
+		}
+		return entries[i].path < entries[j].path
+	})
+	fmt.Println("\nLongest names:")
+	for _, entry := range entries[:min(count, len(entries))] {
+		fmt.Printf("  %4d chars  %s\n", entry.name, entry.path)
+	}
+
+	fmt.Printf("\n%d paths of %d characters or more (Windows MAX_PATH)\n", overMaxPath, windowsMaxPath)
+	fmt.Printf("%d names longer than %d bytes\n", overNameLimit, maxNameBytes)
+	return nil
+}
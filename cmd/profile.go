@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+)
+
+// startProfiles starts a CPU profile written to cpuFile, if set. The returned
+// function stops it and writes a heap profile to memFile, if set, and must be
+// called before exiting.
+func startProfiles(cpuFile, memFile string) (func(), error) {
+	var cpu *os.File
+	if cpuFile != "" {
+		var err error
+		if cpu, err = os.Create(cpuFile); err != nil {
+			return nil, err
+		}
+		if err := runtimepprof.StartCPUProfile(cpu); err != nil {
+			cpu.Close()
+			return nil, err
+		}
+	}
+
+	return func() {
+		if cpu != nil {
+			runtimepprof.StopCPUProfile()
+			cpu.Close()
+			cpu = nil
+		}
+		if memFile != "" {
+			if err := writeHeapProfile(memFile); err != nil {
+				log.Printf("Could not write heap profile: %v", err)
+			}
+			memFile = ""
+		}
+	}, nil
+}
+
+func writeHeapProfile(file string) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	// Bring the in-use figures up to date, allocation totals cover the run
+	runtime.GC()
+	if err := runtimepprof.WriteHeapProfile(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// servePprof serves the net/http/pprof handlers on addr in the background,
+// for profiling a long-running server. They go on their own mux, so nothing
+// else is exposed, and only on loopback addresses, since profiles reveal
+// paths and command lines and the handlers have no authentication.
+func servePprof(addr string) error {
+	if !loopback(addr) {
+		return fmt.Errorf("refusing to serve profiling data on %s, which other machines can reach; use e.g. localhost:6060", addr)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	go func() {
+		log.Printf("pprof: serving on http://%s/debug/pprof/", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("pprof: %v", err)
+		}
+	}()
+	return nil
+}
@@ -5,12 +5,72 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/cost"
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/kiosk"
+	"github.com/corpeningc/dua/internal/notes"
+	"github.com/corpeningc/dua/internal/owners"
+	"github.com/corpeningc/dua/internal/scanner"
+	"github.com/corpeningc/dua/internal/sessionlock"
+	"github.com/corpeningc/dua/internal/treefile"
 	"github.com/corpeningc/dua/ui"
 )
 
+// verifySample is how many directories besides the root --verify walks again.
+const verifySample = 20
+
+// kioskRefused are the subcommands kiosk mode doesn't run: services and
+// cluster scans reach beyond the allowed roots, and compare can sync.
+var kioskRefused = map[string]bool{"compare": true, "daemon": true, "grpc": true, "install-service": true, "k8s": true}
+
+// ExitCode ends dua with that status once Execute has cleaned up, whatever
+// went wrong having been printed already.
+type ExitCode int
+
+func (c ExitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(c))
+}
+
 func Execute() error {
+	// An admin's restrictions apply to everything, see the kiosk package
+	policy, err := kiosk.Load()
+	if err != nil {
+		return err
+	}
+
+	if len(os.Args) > 1 {
+		if policy != nil && kioskRefused[os.Args[1]] {
+			return fmt.Errorf("dua %s is disabled in kiosk mode, see %s", os.Args[1], kiosk.File)
+		}
+		switch os.Args[1] {
+		case "du":
+			return runDu(os.Args[2:], policy)
+		case "shell-init":
+			return runShellInit(os.Args[2:])
+		case "merge":
+			return runMerge(os.Args[2:])
+		case "compare":
+			return runCompare(os.Args[2:])
+		case "daemon":
+			return runDaemon(os.Args[2:])
+		case "grpc":
+			return runGRPC(os.Args[2:])
+		case "install-service":
+			return runInstallService(os.Args[2:])
+		case "k8s":
+			return runKube(os.Args[2:])
+		case "verify":
+			return runVerify(os.Args[2:])
+		case "version":
+			return runVersion(os.Args[2:])
+		}
+	}
+
 	// Set up debug logging
 	logFile, err := os.Create("/tmp/dua-debug.log")
 	if err == nil {
@@ -21,27 +81,379 @@ func Execute() error {
 
 	// Define command line flags
 	var path string
+	var configPath string
+	var profile string
+	var tutorial bool
+	var dupDirs bool
+	var similarMedia bool
+	var unusedMonths int
+	var unusedMinSize string
+	var selectPrint bool
+	var selectNul bool
+	var printOnExit bool
+	var chooseDir bool
+	var chooseFile bool
+	var inline bool
+	var inlineLines int
+	var selectFrom string
+	var markFrom string
+	var deepPaths int
+	var nameProblems bool
+	var fixNames bool
+	var dbPath string
+	var exportParquet string
+	var history bool
+	var exportJSON string
+	var signKey string
+	var load string
+	var cached bool
+	var resume bool
+	var verify bool
+	var quick int
+	var precount bool
+	var aggregateDepth int
+	var ownersFile string
+	var mailReport bool
+	var gamesReport bool
+	var deletedOpen bool
+	var cpuProfile string
+	var memProfile string
 
 	flag.StringVar(&path, "path", ".", "Directory path to analyze")
+	flag.StringVar(&configPath, "config", "", "Config file (default: dua/config.json in the user config directory)")
+	flag.StringVar(&profile, "profile", "", "Apply a named profile from the config file")
+	flag.BoolVar(&tutorial, "tutorial", false, "Show the introductory tutorial")
+	flag.BoolVar(&dupDirs, "dup-dirs", false, "Report duplicated directory trees and exit")
+	flag.BoolVar(&similarMedia, "similar-media", false, "Review groups of near-duplicate images")
+	flag.BoolVar(&selectPrint, "select-print", false, "Print the selected or marked paths to stdout on exit")
+	flag.BoolVar(&selectNul, "0", false, "End the paths -select-print prints with NUL, for xargs -0 and rsync --from0")
+	flag.BoolVar(&printOnExit, "print-on-exit", false, "Print the directory under the cursor to stdout on exit")
+	flag.BoolVar(&chooseDir, "choose-dir", false, "Pick a directory: enter prints it to stdout, esc exits with status 130")
+	flag.BoolVar(&chooseFile, "choose-file", false, "Pick a file: enter prints it to stdout, esc exits with status 130")
+	flag.BoolVar(&inline, "inline", false, "Draw in the terminal's scrollback instead of full screen")
+	flag.IntVar(&inlineLines, "inline-lines", 15, "Number of lines to use with -inline")
+	flag.StringVar(&selectFrom, "select-from", "", "Select the paths listed in this file, one per line (- for stdin)")
+	flag.StringVar(&markFrom, "mark-from", "", "Mark the paths listed in this file for deletion (- for stdin)")
+	flag.IntVar(&deepPaths, "deep-paths", 0, "Report this many of the deepest paths and longest names and exit")
+	flag.BoolVar(&nameProblems, "name-problems", false, "Report file names that break on other systems and exit")
+	flag.BoolVar(&fixNames, "fix-names", false, "Like -name-problems, and rename the offending entries once confirmed")
+	flag.StringVar(&dbPath, "db", "", "Record the scan as a new snapshot in this SQLite database and exit")
+	flag.BoolVar(&history, "history", false, "With -db, show the recorded snapshots, growth and when the disk fills up, and exit")
+	flag.StringVar(&exportParquet, "export-parquet", "", "Write a row per file to this Parquet file and exit")
+	flag.StringVar(&exportJSON, "export-json", "", "Save the scanned tree as JSON, for dua merge or -load, and exit")
+	flag.StringVar(&signKey, "sign-key", "", "With -export-json, sign the export with this minisign secret key, writing FILE.sig for dua verify")
+	flag.IntVar(&aggregateDepth, "aggregate-depth", 0, "With -export-json, -export-parquet or -db, roll everything deeper up into the directories this many levels below the root")
+	flag.StringVar(&load, "load", "", "Browse a tree saved with -export-json or dua merge instead of scanning")
+	flag.BoolVar(&cached, "cached", false, "Open the tree dua daemon last cached for the path instead of scanning")
+	flag.BoolVar(&resume, "resume", false, "Continue an interrupted scan of the path where it left off instead of starting over")
+	flag.StringVar(&ownersFile, "owners", "", "CODEOWNERS-style rules file attributing paths to owners")
+	flag.BoolVar(&mailReport, "mail", false, "Report Maildir folders and mbox files with their message counts and largest attachments, and exit")
+	flag.BoolVar(&gamesReport, "games", false, "Report Steam and Epic games with their sizes and when they were last played, and exit")
+	flag.BoolVar(&deletedOpen, "deleted-open", false, "Report deleted files still held open by processes, which take space du can't see, and exit")
+	flag.IntVar(&unusedMonths, "unused-months", 0, "Report large files not read in this many months and exit")
+	flag.StringVar(&unusedMinSize, "unused-min-size", "100M", "Smallest file to include in the -unused-months report")
+	flag.BoolVar(&verify, "verify", false, "Once the scan finishes, walk the root and a sample of directories again and report any differences")
+	flag.IntVar(&quick, "quick", 0, "Stop scanning after this many seconds and show what was reached, with unscanned branches marked and estimated")
+	flag.BoolVar(&precount, "precount", false, "Count the directories and files alongside the scan to show its progress")
+	flag.StringVar(&cpuProfile, "cpuprofile", "", "Write a CPU profile of the run to this file, for go tool pprof")
+	flag.StringVar(&memProfile, "memprofile", "", "Write a heap profile to this file on exit, for go tool pprof")
 	flag.Parse()
 
+	stopProfiles, err := startProfiles(cpuProfile, memProfile)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return ExitCode(1)
+	}
+	defer stopProfiles()
+
+	if configPath == "" {
+		configPath, _ = config.DefaultPath()
+	}
+
+	cfg := config.Default()
+	if configPath != "" {
+		if cfg, err = config.Load(configPath); err != nil {
+			fmt.Printf("Error: Could not load config '%s': %v\n", configPath, err)
+			return ExitCode(1)
+		}
+	}
+
+	if profile != "" {
+		if cfg, err = cfg.WithProfile(profile); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return ExitCode(1)
+		}
+	}
+
 	// Path validation
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		fmt.Printf("Error: Path '%s' does not exist\n", path)
-		os.Exit(1)
+		return ExitCode(1)
+	}
+
+	// Resolve relative paths, trailing slashes and symlinks up front so every
+	// scanned path hangs off the same root
+	root, err := scanner.NormalizeRoot(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return ExitCode(1)
+	}
+
+	if !policy.CanScan(root) {
+		return fmt.Errorf("kiosk mode only allows scanning %s", strings.Join(policy.Roots, ", "))
+	}
+	if (fixNames || similarMedia) && !policy.CanChange(root) {
+		// Both change files under the root
+		return fmt.Errorf("kiosk mode only allows -fix-names and -similar-media below %s", strings.Join(policy.Writable, ", "))
+	}
+
+	if aggregateDepth > 0 && exportJSON == "" && exportParquet == "" && (dbPath == "" || history) {
+		return fmt.Errorf("-aggregate-depth needs -export-json, -export-parquet or -db")
+	}
+
+	if signKey != "" && exportJSON == "" {
+		return fmt.Errorf("-sign-key needs -export-json")
+	}
+
+	if dbPath != "" {
+		if history {
+			return runHistory(root, dbPath)
+		}
+		return runRecordSnapshot(root, dbPath, aggregateDepth)
+	}
+
+	if exportParquet != "" {
+		return runParquetExport(root, exportParquet, aggregateDepth)
+	}
+
+	if exportJSON != "" {
+		return runJSONExport(root, exportJSON, aggregateDepth, signKey)
+	}
+
+	if dupDirs {
+		return runDupDirs(root)
+	}
+
+	if similarMedia {
+		return runSimilarMedia(root, cfg)
+	}
+
+	if deepPaths > 0 {
+		return runPathReport(root, deepPaths)
+	}
+
+	if nameProblems || fixNames {
+		return runNameReport(root, fixNames, os.Stdin)
+	}
+
+	if mailReport {
+		return runMailReport(root)
+	}
+
+	if gamesReport {
+		return runGamesReport(root)
+	}
+
+	if deletedOpen {
+		return runDeletedOpenReport()
+	}
+
+	if unusedMonths > 0 {
+		minSize, err := humanize.ParseBytes(unusedMinSize)
+		if err != nil {
+			return err
+		}
+		return runUnusedReport(root, unusedMonths, minSize)
+	}
+
+	// When printing paths, stdout carries only those so it can be piped or
+	// captured by the shell while the interface draws on stderr
+	display := os.Stdout
+	if selectPrint || printOnExit || chooseDir || chooseFile {
+		display = os.Stderr
 	}
 
 	var model ui.Model
 
-	fmt.Printf("Starting DUA for: %s\n", path)
-	model = ui.NewStreamingModel(path)
+	if load != "" {
+		doc, err := treefile.Load(load)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return ExitCode(1)
+		}
+		fmt.Fprintf(display, "Loading DUA tree from: %s\n", load)
+		model = ui.NewLoadedModel(doc.DirInfo(), load, cfg)
+	} else if cached {
+		file, err := treefile.CachePath(root)
+		if err == nil {
+			var doc treefile.Document
+			if doc, err = treefile.Load(file); err == nil {
+				model = ui.NewCachedModel(doc.DirInfo(), doc.Scanned, cfg)
+			}
+		}
+		if err != nil {
+			fmt.Printf("Error: no cached tree for %s, is dua daemon scanning it? (%v)\n", root, err)
+			return ExitCode(1)
+		}
+	} else {
+		checkpoint, _ := scanner.CheckpointPath(root)
+		if resume {
+			tree, pending, err := scanner.LoadCheckpoint(checkpoint, root)
+			if err != nil {
+				fmt.Printf("Error: no interrupted scan of %s to resume (%v)\n", root, err)
+				return ExitCode(1)
+			}
+			fmt.Fprintf(display, "Resuming DUA for: %s (%d directories left)\n", root, len(pending))
+			model = ui.NewResumedModel(tree, pending, cfg)
+		} else {
+			fmt.Fprintf(display, "Starting DUA for: %s\n", root)
+			model = ui.NewStreamingModel(root, cfg)
+		}
+		if checkpoint != "" {
+			model.SetCheckpoint(checkpoint)
+		}
+		if verify {
+			model.SetVerify(verifySample)
+		}
+		if quick > 0 {
+			model.SetQuick(time.Duration(quick) * time.Second)
+		}
+		if precount || cfg.Precount {
+			model.SetPrecount()
+		}
+		if history, err := scanner.LastScanPath(root); err == nil {
+			model.SetScanHistory(history)
+		}
+	}
+	// A loaded export can't be changed, so it doesn't need guarding
+	if load == "" {
+		lock, err := sessionlock.Acquire(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not write session lock: %v\n", err)
+		} else {
+			defer lock.Release()
+			model.SetSessionLock(lock)
+		}
+	}
+	// The tutorial needs more room than an inline pane, so it's only shown
+	// there on request
+	if tutorial || (!inline && !cfg.DisableTutorial && !ui.TutorialCompleted()) {
+		model.StartTutorial()
+	}
+	notesFile := cfg.NotesDB
+	if notesFile == "" {
+		notesFile, _ = notes.DefaultPath()
+	}
+	if notesFile != "" {
+		loaded, err := notes.Load(notesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not load notes: %v\n", err)
+		}
+		model.SetNotes(notesFile, loaded)
+	}
+	rate, err := cost.NewRate(cfg.CostPerGBMonth, cfg.StorageClass, cfg.Currency)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return ExitCode(1)
+	}
+	model.SetCost(rate, cfg.ShowCost)
+	model.SetKiosk(policy)
+
+	if ownersFile == "" {
+		ownersFile = cfg.OwnersFile
+	}
+	if ownersFile != "" {
+		rules, err := owners.Load(ownersFile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return ExitCode(1)
+		}
+		model.SetOwners(rules)
+	}
+	if chooseDir {
+		model.SetChooseMode(ui.ChooseDir)
+	} else if chooseFile {
+		model.SetChooseMode(ui.ChooseFile)
+	}
 
-	program := tea.NewProgram(model, tea.WithAltScreen())
+	// Focus tells whether a long scan or deletion needs a notification
+	options := []tea.ProgramOption{tea.WithOutput(display), tea.WithReportFocus()}
+	if inline {
+		// Leave room for a couple of rows besides the header and footer
+		model.SetInline(max(inlineLines, 6))
+	} else {
+		options = append(options, tea.WithAltScreen())
+	}
+	for _, list := range []struct {
+		file string
+		mark bool
+	}{{selectFrom, false}, {markFrom, true}} {
+		if list.file == "" {
+			continue
+		}
+		paths, err := readPathList(list.file)
+		if err != nil {
+			return fmt.Errorf("could not read path list: %w", err)
+		}
+		model.ImportSelection(paths, list.mark)
+		if list.file == "-" {
+			// stdin held the list, so keys have to come from the terminal
+			options = append(options, tea.WithInputTTY())
+		}
+	}
 
-	if _, err := program.Run(); err != nil {
+	program := tea.NewProgram(model, options...)
+
+	finalModel, err := program.Run()
+	if err != nil {
 		fmt.Printf("Error running TUI: %v\n", err)
-		os.Exit(1)
+		return ExitCode(1)
+	}
+
+	if chooseDir || chooseFile {
+		m, _ := finalModel.(ui.Model)
+		m.StopScan()
+		path, ok := m.Chosen()
+		if !ok {
+			// Like a shell interrupted by Ctrl-C, so scripts can tell
+			// cancelling apart from failing
+			return ExitCode(130)
+		}
+		fmt.Println(path)
+		return nil
+	}
+
+	if m, ok := finalModel.(ui.Model); ok {
+		m.StopScan()
+		fmt.Fprint(display, m.SessionSummary())
+		if selectPrint {
+			end := "\n"
+			if selectNul {
+				end = "\x00"
+			}
+			for _, path := range m.SelectedPaths() {
+				fmt.Print(path + end)
+			}
+		}
+		if printOnExit {
+			fmt.Println(m.CursorDir())
+		}
 	}
 
 	return nil
 }
+
+// readPathList reads a path list from file, or from stdin if file is "-".
+func readPathList(file string) ([]string, error) {
+	if file == "-" {
+		return ui.ReadPathList(os.Stdin)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ui.ReadPathList(f)
+}
@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/paths"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// systemdUnit runs dua with the filesystem read-only apart from the
+// directories it keeps snapshots and cached trees in. A system service keeps
+// those under /var/lib/dua and /var/cache/dua.
+var systemdUnit = template.Must(template.New("systemd").Funcs(template.FuncMap{"quote": systemdQuote}).Parse(`[Unit]
+Description={{.Description}}
+Documentation=https://github.com/corpeningc/dua
+After=local-fs.target{{if .Network}} network.target{{end}}
+
+[Service]
+Type=simple
+ExecStart={{range $i, $arg := .Args}}{{if $i}} {{end}}{{quote $arg}}{{end}}
+Restart=on-failure
+RestartSec=30s
+Nice=10
+IOSchedulingClass=idle
+{{- if .System}}
+Environment=XDG_STATE_HOME=/var/lib XDG_CACHE_HOME=/var/cache
+StateDirectory=dua
+CacheDirectory=dua
+# Root can read everything but do nothing else
+CapabilityBoundingSet=CAP_DAC_READ_SEARCH
+{{- end}}
+
+NoNewPrivileges=yes
+ProtectSystem=strict
+ProtectHome=read-only
+{{- range .Writable}}
+ReadWritePaths=-{{quote .}}
+{{- end}}
+PrivateTmp=yes
+PrivateDevices=yes
+ProtectKernelTunables=yes
+ProtectKernelModules=yes
+ProtectKernelLogs=yes
+ProtectControlGroups=yes
+RestrictSUIDSGID=yes
+RestrictRealtime=yes
+LockPersonality=yes
+RestrictAddressFamilies=AF_UNIX{{if .Network}} AF_INET AF_INET6{{end}}
+
+[Install]
+WantedBy={{if .System}}multi-user.target{{else}}default.target{{end}}
+`))
+
+// launchdPlist has no sandboxing equivalent to systemd's, so it only keeps
+// the service out of the way of interactive work.
+var launchdPlist = template.Must(template.New("launchd").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+{{- range .Args}}
+		<string>{{xml .}}</string>
+{{- end}}
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>ProcessType</key>
+	<string>Background</string>
+	<key>LowPriorityIO</key>
+	<true/>
+	<key>Nice</key>
+	<integer>10</integer>
+	<key>StandardOutPath</key>
+	<string>{{xml .Log}}</string>
+	<key>StandardErrorPath</key>
+	<string>{{xml .Log}}</string>
+</dict>
+</plist>
+`))
+
+// service is what the templates are filled in with.
+type service struct {
+	Name        string
+	Label       string
+	Description string
+	Args        []string
+	System      bool
+	Network     bool
+	Writable    []string
+	Log         string
+}
+
+// runInstallService implements `dua install-service`, which writes a systemd
+// unit or launchd plist that keeps `dua daemon` or `dua grpc` running.
+func runInstallService(args []string) error {
+	flags := flag.NewFlagSet("install-service", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dua install-service [flags]")
+		flags.PrintDefaults()
+	}
+
+	defaultFormat := "systemd"
+	if runtime.GOOS == "darwin" {
+		defaultFormat = "launchd"
+	}
+
+	var mode, format, configPath, listen, certFile, keyFile, clientCAFile, tokenFile string
+	var roots []string
+	var system, print bool
+	flags.StringVar(&mode, "mode", "daemon", "What the service runs: daemon or grpc")
+	flags.StringVar(&format, "format", defaultFormat, "Service manager: systemd or launchd")
+	flags.StringVar(&configPath, "config", "", "Config file for the service (default: dua/config.json in the user config directory)")
+	flags.StringVar(&listen, "listen", "localhost:7070", "Address for the grpc mode to serve on")
+	flags.StringVar(&certFile, "tls-cert", "", "Certificate for the grpc mode to serve TLS with, along with -tls-key")
+	flags.StringVar(&keyFile, "tls-key", "", "Private key of the -tls-cert certificate")
+	flags.StringVar(&clientCAFile, "tls-client-ca", "", "CA file the grpc mode checks client certificates against")
+	flags.StringVar(&tokenFile, "token-file", "", "File holding the token grpc mode clients must send")
+	flags.Func("root", "Directory grpc mode clients may scan; repeat for more (default: any)", func(root string) error {
+		resolved, err := scanner.NormalizeRoot(root)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, resolved)
+		return nil
+	})
+	flags.BoolVar(&system, "system", false, "Install a system-wide service run as root instead of one for the current user")
+	flags.BoolVar(&print, "print", false, "Print the service file instead of installing it")
+	flags.Parse(args)
+
+	if mode != "grpc" {
+		var grpcOnly []string
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "listen", "tls-cert", "tls-key", "tls-client-ca", "token-file", "root":
+				grpcOnly = append(grpcOnly, "-"+f.Name)
+			}
+		})
+		if len(grpcOnly) > 0 {
+			return fmt.Errorf("-mode grpc is needed for %s", strings.Join(grpcOnly, ", "))
+		}
+	}
+	// The service doesn't run in this directory, so it's given absolute paths
+	if err := absolute(&configPath, &certFile, &keyFile, &clientCAFile, &tokenFile); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+
+	svc := service{Name: "dua-" + mode, Label: "io.github.corpeningc.dua." + mode, System: system}
+	switch mode {
+	case "daemon":
+		if configPath == "" {
+			if configPath, err = config.DefaultPath(); err != nil {
+				return err
+			}
+		}
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return fmt.Errorf("could not load config '%s': %w", configPath, err)
+		}
+		if len(cfg.Daemon.Roots) == 0 {
+			fmt.Fprintf(os.Stderr, "Warning: no roots to scan yet, add them under \"daemon\" in %s\n", configPath)
+		}
+		svc.Description = "dua scheduled disk usage scans"
+		svc.Args = []string{exe, "daemon", "-config", configPath}
+		if cfg.Daemon.DB != "" {
+			db, err := filepath.Abs(cfg.Daemon.DB)
+			if err != nil {
+				return err
+			}
+			svc.Writable = append(svc.Writable, filepath.Dir(db))
+			svc.Args = append(svc.Args, "-db", db)
+		}
+	case "grpc":
+		// Caught now rather than when the service keeps failing to start
+		if err := checkGRPCAccess(listen, certFile, keyFile, clientCAFile, tokenFile); err != nil {
+			return err
+		}
+		svc.Description = "dua gRPC scan server"
+		svc.Args = []string{exe, "grpc", "-listen", listen}
+		if configPath != "" {
+			svc.Args = append(svc.Args, "-config", configPath)
+		}
+		if certFile != "" {
+			if _, err := grpcTLSConfig(certFile, keyFile, clientCAFile); err != nil {
+				return err
+			}
+			svc.Args = append(svc.Args, "-tls-cert", certFile, "-tls-key", keyFile)
+			if clientCAFile != "" {
+				svc.Args = append(svc.Args, "-tls-client-ca", clientCAFile)
+			}
+		}
+		if tokenFile != "" {
+			if _, err := readToken(tokenFile); err != nil {
+				return err
+			}
+			svc.Args = append(svc.Args, "-token-file", tokenFile)
+		}
+		for _, root := range roots {
+			svc.Args = append(svc.Args, "-root", root)
+		}
+		svc.Network = true
+	default:
+		return fmt.Errorf("unknown mode %q, expected daemon or grpc", mode)
+	}
+
+	var tmpl *template.Template
+	var file, start string
+	switch format {
+	case "systemd":
+		tmpl = systemdUnit
+		file, start, err = systemdLocation(&svc)
+	case "launchd":
+		tmpl = launchdPlist
+		file, start, err = launchdLocation(&svc)
+	default:
+		return fmt.Errorf("unknown format %q, expected systemd or launchd", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, svc); err != nil {
+		return err
+	}
+	if print {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+
+	// Sandboxed services can't create the directories they write to
+	for _, dir := range svc.Writable {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, out.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Installed %s\nStart it with:\n  %s\n", file, start)
+	return nil
+}
+
+// absolute makes each of files that's set absolute.
+func absolute(files ...*string) error {
+	for _, file := range files {
+		if *file == "" {
+			continue
+		}
+		abs, err := filepath.Abs(*file)
+		if err != nil {
+			return err
+		}
+		*file = abs
+	}
+	return nil
+}
+
+// systemdLocation returns where the unit for svc goes and the command that
+// starts it. User services get the state and cache directories made
+// writable, system services have systemd create theirs.
+func systemdLocation(svc *service) (string, string, error) {
+	unit := svc.Name + ".service"
+	if svc.System {
+		return filepath.Join("/etc/systemd/system", unit), "systemctl daemon-reload && systemctl enable --now " + unit, nil
+	}
+
+	for _, dir := range []func() (string, error){paths.StateDir, paths.CacheDir} {
+		path, err := dir()
+		if err != nil {
+			return "", "", err
+		}
+		svc.Writable = append(svc.Writable, path)
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", "", err
+	}
+	return filepath.Join(configDir, "systemd", "user", unit), "systemctl --user daemon-reload && systemctl --user enable --now " + unit, nil
+}
+
+// launchdLocation returns where the plist for svc goes and the command that
+// loads it, and points its output at a log file.
+func launchdLocation(svc *service) (string, string, error) {
+	plist := svc.Label + ".plist"
+	if svc.System {
+		svc.Log = filepath.Join("/Library/Logs", svc.Name+".log")
+		file := filepath.Join("/Library/LaunchDaemons", plist)
+		return file, "sudo launchctl bootstrap system " + file, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	svc.Log = filepath.Join(home, "Library", "Logs", svc.Name+".log")
+	file := filepath.Join(home, "Library", "LaunchAgents", plist)
+	return file, "launchctl bootstrap gui/$(id -u) " + file, nil
+}
+
+// systemdQuote quotes a word for a unit file if it needs it.
+func systemdQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$%;") {
+		return s
+	}
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", "$$", "%", "%%").Replace(s)
+	return `"` + s + `"`
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// shellScripts define duacd, which runs dua and changes to the directory
+// under the cursor when it exits. Extra arguments are passed on to dua.
+var shellScripts = map[string]string{
+	"bash": posixShellScript,
+	"zsh": posixShellScript + `
+# Widget for binding duacd to a key, e.g. bindkey '^G' _duacd_widget
+_duacd_widget() {
+  duacd </dev/tty
+  zle reset-prompt
+}
+zle -N _duacd_widget
+`,
+	"fish": `function duacd --description 'Browse with dua and cd to the directory left under the cursor'
+    set -l dir (command dua --print-on-exit $argv)
+    and test -n "$dir"
+    and cd -- $dir
+end
+`,
+}
+
+const posixShellScript = `duacd() {
+  local dir
+  dir="$(command dua --print-on-exit "$@")" && [ -n "$dir" ] && cd -- "$dir"
+}
+`
+
+// runShellInit implements `dua shell-init SHELL`, printing functions meant to
+// be evaluated from the shell's startup file.
+func runShellInit(args []string) error {
+	shells := slices.Sorted(maps.Keys(shellScripts))
+
+	if len(args) != 1 {
+		return fmt.Errorf("usage: dua shell-init %s", strings.Join(shells, "|"))
+	}
+
+	script, ok := shellScripts[args[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q, expected one of %s", args[0], strings.Join(shells, ", "))
+	}
+	fmt.Print(script)
+	return nil
+}
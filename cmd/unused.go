@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// unusedFile is a large file that hasn't been read recently.
+type unusedFile struct {
+	path       string
+	size       int64
+	accessTime time.Time
+}
+
+// runUnusedReport lists files of at least minSize under root that haven't
+// been read in the given number of months. Files unread for twice as long
+// are flagged for deletion, the rest for archiving.
+func runUnusedReport(root string, months int, minSize int64) error {
+	if !scanner.AccessTimesTracked(root) {
+		return fmt.Errorf("%s is mounted with noatime, access times aren't recorded", root)
+	}
+
+	now := time.Now()
+	cutoff := now.AddDate(0, -months, 0)
+	deleteCutoff := now.AddDate(0, -2*months, 0)
+
+	var files []unusedFile
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.Size() < minSize {
+			return nil
+		}
+		accessTime, ok := scanner.AccessTime(info)
+		if !ok || !accessTime.Before(cutoff) {
+			return nil
+		}
+
+		files = append(files, unusedFile{path: path, size: info.Size(), accessTime: accessTime})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		fmt.Printf("No files of %s or more unread in %d months\n", humanize.Bytes(minSize), months)
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].size > files[j].size
+	})
+
+	var total int64
+	for _, file := range files {
+		candidate := "archive"
+		if file.accessTime.Before(deleteCutoff) {
+			candidate = "delete"
+		}
+		fmt.Printf("%-8s %10s  last read %s  %s\n",
+			candidate, humanize.Bytes(file.size), file.accessTime.Format("2006-01-02"), file.path)
+		total += file.size
+	}
+
+	fmt.Printf("\n%d files, %s unread in %d months\n", len(files), humanize.Bytes(total), months)
+	return nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/corpeningc/dua/internal/minisign"
+)
+
+// signPasswordEnv holds the password of an encrypted secret key, for
+// signing without a terminal to ask on.
+const signPasswordEnv = "DUA_SIGN_PASSWORD"
+
+// signExport signs file with the minisign secret key at keyFile, writing the
+// signature to file.sig. The trusted comment names the host and root, so
+// they can't be changed along with the export.
+func signExport(file, keyFile, host, root string) error {
+	keyData, err := os.ReadFile(keyFile)
+	if err != nil {
+		return err
+	}
+	key, err := minisign.ParsePrivateKey(keyData, signPassword)
+	if err != nil {
+		return fmt.Errorf("%s: %w", keyFile, err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	comment := fmt.Sprintf("timestamp:%d\tfile:%s\thashed\thost:%s\troot:%s", time.Now().Unix(), filepath.Base(file), host, root)
+	return os.WriteFile(file+".sig", key.Sign(data, comment), 0o644)
+}
+
+// signPassword asks for the secret key's password on the terminal, unless
+// it's in the environment.
+func signPassword() ([]byte, error) {
+	if password, ok := os.LookupEnv(signPasswordEnv); ok {
+		return []byte(password), nil
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return nil, fmt.Errorf("the secret key is encrypted, set %s to its password", signPasswordEnv)
+	}
+	fmt.Fprint(os.Stderr, "Password for the secret key: ")
+	password, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	return password, err
+}
+
+// runVerify implements `dua verify -p key.pub report.json.sig`, checking
+// that an export signed with --sign-key hasn't changed since.
+func runVerify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dua verify [flags] export.json.sig")
+		fmt.Fprintln(flags.Output(), "Checks the export beside the signature, or the one given with -m.")
+		flags.PrintDefaults()
+	}
+
+	var keyFile, keyText, message string
+	flags.StringVar(&keyFile, "p", "", "Public key file, as written by minisign -G")
+	flags.StringVar(&keyText, "P", "", "Public key, the second line of its file")
+	flags.StringVar(&message, "m", "", "Signed file (default: the signature's name without .sig)")
+	flags.Parse(args)
+	if flags.NArg() != 1 || (keyFile == "") == (keyText == "") {
+		flags.Usage()
+		return ExitCode(2)
+	}
+	sigFile := flags.Arg(0)
+	if message == "" {
+		message = strings.TrimSuffix(strings.TrimSuffix(sigFile, ".sig"), ".minisig")
+		if message == sigFile {
+			return errors.New("can't tell the signed file from the signature's name, give it with -m")
+		}
+	}
+
+	keyData := []byte(keyText)
+	if keyFile != "" {
+		var err error
+		if keyData, err = os.ReadFile(keyFile); err != nil {
+			return err
+		}
+	}
+	key, err := minisign.ParsePublicKey(keyData)
+	if err != nil {
+		return err
+	}
+	sigData, err := os.ReadFile(sigFile)
+	if err != nil {
+		return err
+	}
+	sig, err := minisign.ParseSignature(sigData)
+	if err != nil {
+		return fmt.Errorf("%s: %w", sigFile, err)
+	}
+	data, err := os.ReadFile(message)
+	if err != nil {
+		return err
+	}
+	if err := key.Verify(data, sig); err != nil {
+		return fmt.Errorf("%s: %w", message, err)
+	}
+
+	fmt.Printf("Signature and comment signature verified for %s with key %s\n", message, key.ID)
+	fmt.Printf("Trusted comment: %s\n", sig.TrustedComment)
+	return nil
+}
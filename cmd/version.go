@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Release builds set these with -ldflags, e.g.
+// -X github.com/corpeningc/dua/cmd.version=v1.4.0
+// -X github.com/corpeningc/dua/cmd.buildDate=2024-05-01T12:00:00Z
+var (
+	version   string
+	buildDate string
+)
+
+// buildInfo is what the binary knows about how it was built, for checking
+// which build is deployed where.
+type buildInfo struct {
+	Version    string   `json:"version"`
+	Revision   string   `json:"revision,omitempty"`
+	CommitTime string   `json:"commit_time,omitempty"`
+	Modified   bool     `json:"modified"` // Built from a tree with uncommitted changes
+	BuildDate  string   `json:"build_date,omitempty"`
+	GoVersion  string   `json:"go_version"`
+	GOOS       string   `json:"goos"`
+	GOARCH     string   `json:"goarch"`
+	ArchLevel  string   `json:"arch_level,omitempty"` // GOAMD64, GOARM and the like
+	CGO        bool     `json:"cgo"`
+	Tags       []string `json:"tags"`
+}
+
+// readBuildInfo gathers the build metadata the Go toolchain embeds, along
+// with what a release build set.
+func readBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   version,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		Tags:      []string{},
+	}
+	embedded, ok := debug.ReadBuildInfo()
+	if !ok {
+		if info.Version == "" {
+			info.Version = "unknown"
+		}
+		return info
+	}
+	if info.Version == "" {
+		// Set by go install of a tagged version, "(devel)" for local builds
+		info.Version = embedded.Main.Version
+	}
+	for _, setting := range embedded.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.CommitTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		case "CGO_ENABLED":
+			info.CGO = setting.Value == "1"
+		case "-tags":
+			info.Tags = strings.Split(setting.Value, ",")
+		case "GOAMD64", "GOARM", "GOARM64", "GO386", "GOMIPS", "GOMIPS64", "GOPPC64", "GORISCV64":
+			info.ArchLevel = setting.Key + "=" + setting.Value
+		}
+	}
+	return info
+}
+
+// runVersion implements `dua version`, printing the version on one line,
+// everything known about the build with --build-info, or all of it as JSON
+// for inventory tools with --json.
+func runVersion(args []string) error {
+	flags := flag.NewFlagSet("version", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dua version [flags]")
+		flags.PrintDefaults()
+	}
+
+	var full, asJSON bool
+	flags.BoolVar(&full, "build-info", false, "Show the revision, build date, platform and build settings")
+	flags.BoolVar(&asJSON, "json", false, "Print the build information as JSON")
+	flags.Parse(args)
+
+	info := readBuildInfo()
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+
+	revision := info.Revision
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if info.Modified {
+		revision += "-dirty"
+	}
+	if !full {
+		line := "dua " + info.Version
+		if revision != "" {
+			line += " (" + revision + ")"
+		}
+		fmt.Printf("%s %s/%s\n", line, info.GOOS, info.GOARCH)
+		return nil
+	}
+
+	row := func(label, value string) {
+		if value != "" {
+			fmt.Printf("%-12s %s\n", label+":", value)
+		}
+	}
+	row("Version", info.Version)
+	row("Revision", revision)
+	row("Committed", info.CommitTime)
+	row("Built", info.BuildDate)
+	row("Go", info.GoVersion)
+	row("Platform", info.GOOS+"/"+info.GOARCH)
+	row("Arch level", info.ArchLevel)
+	row("cgo", fmt.Sprint(info.CGO))
+	row("Tags", strings.Join(info.Tags, ","))
+	return nil
+}
@@ -0,0 +1,140 @@
+// Package backuprepo recognizes restic, Borg and Time Machine repositories,
+// whose chunk directories say nothing about what they hold, and asks the
+// backup tools how much data they store and how well it deduplicates.
+package backuprepo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Kind is a type of backup repository.
+type Kind int
+
+const (
+	None Kind = iota
+	Restic
+	Borg
+	TimeMachine
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Restic:
+		return "restic"
+	case Borg:
+		return "Borg"
+	case TimeMachine:
+		return "Time Machine"
+	}
+	return ""
+}
+
+// Detect tells what kind of repository a directory is from its name and the
+// names of its files and subdirectories, without reading anything.
+func Detect(name string, hasFile, hasDir func(name string) bool) Kind {
+	switch {
+	case name == "Backups.backupdb" || strings.HasSuffix(name, ".backupbundle"):
+		return TimeMachine
+	case strings.HasSuffix(name, ".sparsebundle") && hasFile("com.apple.TimeMachine.MachineID.plist"):
+		return TimeMachine
+	case !hasDir("data") || !hasFile("config"):
+		// Both restic and Borg keep their chunks in data/ next to a config
+		return None
+	case hasDir("index") && hasDir("keys") && hasDir("snapshots"):
+		return Restic
+	case hasFile("README"):
+		return Borg
+	}
+	return None
+}
+
+// Stats is how much a repository holds.
+type Stats struct {
+	// Logical is the total size of every backup as if restored separately.
+	Logical int64
+	// Stored is what the deduplicated, compressed data takes up.
+	Stored int64
+	// Snapshots is the number of backups, if the tool reports it.
+	Snapshots int
+}
+
+// Ratio is how many times smaller the stored data is than the backups.
+func (s Stats) Ratio() float64 {
+	if s.Stored <= 0 {
+		return 0
+	}
+	return float64(s.Logical) / float64(s.Stored)
+}
+
+// ErrNoStats is returned for repositories whose tools don't report their
+// deduplicated size.
+var ErrNoStats = errors.New("no deduplication stats available")
+
+// Inspect asks the repository's tool for its stats. Encrypted repositories
+// need their password in the tool's usual environment variables, such as
+// RESTIC_PASSWORD or BORG_PASSPHRASE, since there's nowhere to prompt.
+func Inspect(path string, kind Kind) (Stats, error) {
+	switch kind {
+	case Restic:
+		return inspectRestic(path)
+	case Borg:
+		return inspectBorg(path)
+	}
+	return Stats{}, ErrNoStats
+}
+
+func inspectRestic(path string) (Stats, error) {
+	var restore, raw struct {
+		TotalSize      int64 `json:"total_size"`
+		SnapshotsCount int   `json:"snapshots_count"`
+	}
+	if err := runJSON(&restore, "restic", "-r", path, "stats", "--no-lock", "--json", "--mode", "restore-size"); err != nil {
+		return Stats{}, err
+	}
+	if err := runJSON(&raw, "restic", "-r", path, "stats", "--no-lock", "--json", "--mode", "raw-data"); err != nil {
+		return Stats{}, err
+	}
+	return Stats{Logical: restore.TotalSize, Stored: raw.TotalSize, Snapshots: restore.SnapshotsCount}, nil
+}
+
+func inspectBorg(path string) (Stats, error) {
+	var info struct {
+		Cache struct {
+			Stats struct {
+				TotalSize   int64 `json:"total_size"`
+				UniqueCSize int64 `json:"unique_csize"`
+			} `json:"stats"`
+		} `json:"cache"`
+	}
+	if err := runJSON(&info, "borg", "info", "--json", path); err != nil {
+		return Stats{}, err
+	}
+	return Stats{Logical: info.Cache.Stats.TotalSize, Stored: info.Cache.Stats.UniqueCSize}, nil
+}
+
+// runJSON runs a tool with no input and decodes what it prints into v.
+func runJSON(v any, name string, args ...string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s is not installed", name)
+	}
+
+	cmd := exec.Command(name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if lines := strings.Split(strings.TrimSpace(stderr.String()), "\n"); lines[0] != "" {
+			return fmt.Errorf("%s: %s", name, lines[len(lines)-1])
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// Package browsers finds the caches of Chrome, Chromium, Edge and Firefox,
+// which can be deleted, apart from the profile data beside them (bookmarks,
+// history, passwords, extensions), which can't.
+package browsers
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Browser is one browser's caches across all its profiles.
+type Browser struct {
+	Name string
+	// CacheDirs are deleted to clean up. The browser rebuilds them.
+	CacheDirs []string
+	CacheSize int64
+	// DataSize is the rest of the profiles, which cleaning leaves alone
+	DataSize int64
+	// Running is set when the browser holds its profile lock. Its caches
+	// shouldn't be deleted from under it.
+	Running bool
+}
+
+// chromiumCaches are the cache directories inside a Chromium-based browser's
+// profile, and chromiumShared the ones shared by all its profiles.
+var (
+	chromiumCaches = []string{
+		"Cache",
+		"Code Cache",
+		"GPUCache",
+		"DawnCache",
+		"DawnGraphiteCache",
+		"DawnWebGPUCache",
+		filepath.Join("Service Worker", "CacheStorage"),
+		filepath.Join("Service Worker", "ScriptCache"),
+	}
+	chromiumShared = []string{"ShaderCache", "GrShaderCache", "GraphiteDawnCache"}
+)
+
+// firefoxCaches are the cache directories of a Firefox profile, kept in its
+// local cache directory, which is the profile itself on some systems.
+var firefoxCaches = []string{"cache2", "startupCache", "thumbnails", "jumpListCache"}
+
+// chromium is where a Chromium-based browser keeps its profiles, and on
+// Linux and macOS the separate directory holding their HTTP caches.
+type chromium struct {
+	name  string
+	data  string
+	cache string
+}
+
+// Find measures the caches and profile data of the browsers installed for
+// this user.
+func Find() []Browser {
+	home, _ := os.UserHomeDir()
+	var browsers []Browser
+	for _, c := range chromiumLocations(home) {
+		if browser, ok := readChromium(c); ok {
+			browsers = append(browsers, browser)
+		}
+	}
+	profiles, cache := firefoxLocations(home)
+	if browser, ok := readFirefox(profiles, cache); ok {
+		browsers = append(browsers, browser)
+	}
+	return browsers
+}
+
+func chromiumLocations(home string) []chromium {
+	switch runtime.GOOS {
+	case "windows":
+		local := os.Getenv("LOCALAPPDATA")
+		return []chromium{
+			{name: "Chrome", data: filepath.Join(local, "Google", "Chrome", "User Data")},
+			{name: "Chromium", data: filepath.Join(local, "Chromium", "User Data")},
+			{name: "Edge", data: filepath.Join(local, "Microsoft", "Edge", "User Data")},
+		}
+	case "darwin":
+		support := filepath.Join(home, "Library", "Application Support")
+		caches := filepath.Join(home, "Library", "Caches")
+		return []chromium{
+			{name: "Chrome", data: filepath.Join(support, "Google", "Chrome"), cache: filepath.Join(caches, "Google", "Chrome")},
+			{name: "Chromium", data: filepath.Join(support, "Chromium"), cache: filepath.Join(caches, "Chromium")},
+			{name: "Edge", data: filepath.Join(support, "Microsoft Edge"), cache: filepath.Join(caches, "Microsoft Edge")},
+		}
+	}
+	config := filepath.Join(home, ".config")
+	cache := filepath.Join(home, ".cache")
+	return []chromium{
+		{name: "Chrome", data: filepath.Join(config, "google-chrome"), cache: filepath.Join(cache, "google-chrome")},
+		{name: "Chromium", data: filepath.Join(config, "chromium"), cache: filepath.Join(cache, "chromium")},
+		{name: "Edge", data: filepath.Join(config, "microsoft-edge"), cache: filepath.Join(cache, "microsoft-edge")},
+	}
+}
+
+// readChromium measures a Chromium-based browser. Its profiles are Default
+// and "Profile N" directories, and the cache directory mirrors them.
+func readChromium(c chromium) (Browser, bool) {
+	if _, err := os.Stat(c.data); err != nil {
+		return Browser{}, false
+	}
+	browser := Browser{Name: c.name}
+	_, err := os.Lstat(filepath.Join(c.data, "SingletonLock"))
+	browser.Running = err == nil
+
+	var inData int64
+	for _, root := range []string{c.data, c.cache} {
+		if root == "" {
+			continue
+		}
+		var dirs []string
+		for _, name := range chromiumShared {
+			dirs = append(dirs, filepath.Join(root, name))
+		}
+		for _, profile := range chromiumProfiles(root) {
+			for _, name := range chromiumCaches {
+				dirs = append(dirs, filepath.Join(profile, name))
+			}
+		}
+		for _, dir := range dirs {
+			if size, ok := dirSize(dir); ok && size > 0 {
+				browser.CacheDirs = append(browser.CacheDirs, dir)
+				browser.CacheSize += size
+				if root == c.data {
+					inData += size
+				}
+			}
+		}
+	}
+
+	total, _ := dirSize(c.data)
+	browser.DataSize = max(total-inData, 0)
+	return browser, true
+}
+
+func chromiumProfiles(root string) []string {
+	var profiles []string
+	entries, _ := os.ReadDir(root)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() && (name == "Default" || strings.HasPrefix(name, "Profile ") || name == "Guest Profile" || name == "System Profile") {
+			profiles = append(profiles, filepath.Join(root, name))
+		}
+	}
+	return profiles
+}
+
+// firefoxLocations returns the directory holding Firefox's profiles and the
+// one holding their caches, which on Linux mirrors the profile names.
+func firefoxLocations(home string) (string, string) {
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(os.Getenv("APPDATA"), "Mozilla", "Firefox", "Profiles"),
+			filepath.Join(os.Getenv("LOCALAPPDATA"), "Mozilla", "Firefox", "Profiles")
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "Firefox", "Profiles"),
+			filepath.Join(home, "Library", "Caches", "Firefox", "Profiles")
+	}
+	return filepath.Join(home, ".mozilla", "firefox"), filepath.Join(home, ".cache", "mozilla", "firefox")
+}
+
+// readFirefox measures the Firefox profiles, the directories with a
+// prefs.js. A running Firefox leaves a lock symlink in its profile on Linux.
+func readFirefox(profiles, cache string) (Browser, bool) {
+	entries, err := os.ReadDir(profiles)
+	if err != nil {
+		return Browser{}, false
+	}
+	browser := Browser{Name: "Firefox"}
+	found := false
+	for _, entry := range entries {
+		profile := filepath.Join(profiles, entry.Name())
+		if _, err := os.Stat(filepath.Join(profile, "prefs.js")); err != nil {
+			continue
+		}
+		found = true
+		if _, err := os.Lstat(filepath.Join(profile, "lock")); err == nil {
+			browser.Running = true
+		}
+
+		var inProfile int64
+		for _, root := range []string{profile, filepath.Join(cache, entry.Name())} {
+			for _, name := range firefoxCaches {
+				dir := filepath.Join(root, name)
+				if size, ok := dirSize(dir); ok && size > 0 {
+					browser.CacheDirs = append(browser.CacheDirs, dir)
+					browser.CacheSize += size
+					if root == profile {
+						inProfile += size
+					}
+				}
+			}
+		}
+		total, _ := dirSize(profile)
+		browser.DataSize += max(total-inProfile, 0)
+	}
+	return browser, found
+}
+
+// dirSize totals the files below dir, skipping what can't be read. It
+// reports false if dir doesn't exist.
+func dirSize(dir string) (int64, bool) {
+	if _, err := os.Lstat(dir); err != nil {
+		return 0, false
+	}
+	var size int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != dir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size, true
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/corpeningc/dua/internal/paths"
+)
+
+// Date column formats.
+const (
+	DateAbsolute = "absolute"
+	DateRelative = "relative"
+)
+
+// When rescans reuse the listings of directories whose modification time
+// hasn't changed.
+const (
+	MtimeRescanAuto   = "auto" // On network filesystems
+	MtimeRescanAlways = "always"
+	MtimeRescanNever  = "never"
+)
+
+// Config holds user preferences loaded from the config file.
+type Config struct {
+	// Locale selects locale-dependent formatting such as dates, e.g. "de-DE".
+	// When empty it is taken from the environment.
+	Locale string `json:"locale"`
+
+	// ShowDates shows the modified-time column on startup.
+	ShowDates bool `json:"show_dates"`
+	// DateFormat is DateAbsolute or DateRelative.
+	DateFormat string `json:"date_format"`
+	// DateLayout overrides the locale's absolute date layout, using Go's
+	// reference time, e.g. "02.01.2006".
+	DateLayout string `json:"date_layout"`
+
+	// Columns lists the columns shown right of the names, in order, from
+	// "size", "percent", "count", "mtime", "cost", "owner", "perms" and
+	// "links". Empty means size, cost and mtime, the last two shown once
+	// toggled on.
+	Columns []string `json:"columns"`
+
+	// DiskUsage shows the disk space files take instead of their size on
+	// startup (toggle with u).
+	DiskUsage bool `json:"disk_usage"`
+
+	// DisableTutorial stops the first-run tutorial from appearing.
+	DisableTutorial bool `json:"disable_tutorial"`
+
+	// ScanRateLimits caps the directory reads and stats a second that scans
+	// running in the background make under each path, e.g.
+	// {"/srv/data": 200}, so they don't compete with production workloads.
+	// The innermost path holding a scan's root applies, and scans under the
+	// same path share its limit. 0 doesn't limit.
+	ScanRateLimits map[string]float64 `json:"scan_rate_limits"`
+
+	// AgeHeatmap colors entries by last-modified age on startup.
+	AgeHeatmap bool `json:"age_heatmap"`
+	// AgeColors maps ages to heatmap colors, see AgeColor.
+	AgeColors []AgeColor `json:"age_colors"`
+
+	// ImagePreviews picks how the preview pane draws images: "kitty",
+	// "iterm2", "sixel" or "off". Empty or "auto" detects the terminal.
+	ImagePreviews string `json:"image_previews"`
+
+	// PagerMaxKB is how much of a file the pager reads, in KiB.
+	PagerMaxKB int `json:"pager_max_kb"`
+
+	// OwnersFile is a CODEOWNERS-style rules file attributing paths to
+	// owners, see the owners package.
+	OwnersFile string `json:"owners_file"`
+
+	// NotesDB is the database notes on paths are kept in. Empty uses
+	// state.db in the state directory; point it at a shared file so a team
+	// sees each other's notes.
+	NotesDB string `json:"notes_db"`
+
+	// CostPerGBMonth is the storage price per GB-month used to estimate
+	// monthly costs. It overrides StorageClass.
+	CostPerGBMonth float64 `json:"cost_per_gb_month"`
+	// StorageClass takes the rate from a cloud storage class's list price,
+	// e.g. "s3-standard" or "gcs-coldline", see cost.Classes.
+	StorageClass string `json:"storage_class"`
+	// Currency is written before costs, "$" by default.
+	Currency string `json:"currency"`
+	// ShowCost shows the monthly cost column on startup.
+	ShowCost bool `json:"show_cost"`
+
+	// LogMaxAgeDays is how old rotated logs must be before cleaning them up
+	// is suggested.
+	LogMaxAgeDays int `json:"log_max_age_days"`
+
+	// MtimeRescan is MtimeRescanAuto, MtimeRescanAlways or
+	// MtimeRescanNever. Empty means auto.
+	MtimeRescan string `json:"mtime_rescan"`
+
+	// ConfirmDeleteGB is how large a directory must be, in GiB, before
+	// deleting it asks for its name to be typed. 0 never asks.
+	ConfirmDeleteGB int `json:"confirm_delete_gb"`
+
+	// NotifyAfterSeconds is how long a scan or deletion must take before
+	// its end is announced with a desktop notification, if the terminal
+	// isn't focused by then. 0 never notifies.
+	NotifyAfterSeconds int `json:"notify_after_seconds"`
+
+	// MemoryLimitMB is how large the process may grow while scanning, in
+	// MiB, before the contents of collapsed directories deep in the tree are
+	// dropped, keeping their totals. 0 means no limit.
+	MemoryLimitMB int `json:"memory_limit_mb"`
+
+	// SampleAboveFiles is how many files a directory must hold before only
+	// a random sample of them is statted and its size estimated. 0 stats
+	// every file.
+	SampleAboveFiles int `json:"sample_above_files"`
+
+	// Precount counts the directories and files alongside each scan, to
+	// show its progress, as --precount does.
+	Precount bool `json:"precount"`
+
+	// Sort is the initial sort key: "name", "date", "size" or "type".
+	Sort string `json:"sort"`
+	// SortReverse flips the sort key's natural direction.
+	SortReverse bool `json:"sort_reverse"`
+
+	// Daemon configures the scheduled scans of dua daemon.
+	Daemon Daemon `json:"daemon"`
+
+	// Profiles are named sets of settings applied over the rest of the file
+	// with --profile, e.g. {"ci": {"locale": "en"}}.
+	Profiles map[string]json.RawMessage `json:"profiles"`
+}
+
+// Daemon lists the roots dua daemon scans and how long it keeps snapshots.
+type Daemon struct {
+	// DB is the snapshot database. Empty uses snapshots.db in the state
+	// directory.
+	DB string `json:"db"`
+	// KeepDays prunes snapshots older than this many days, 0 keeps all.
+	KeepDays int             `json:"keep_days"`
+	Roots    []ScheduledRoot `json:"roots"`
+}
+
+// ScheduledRoot is a directory scanned on a cron-style schedule, e.g.
+// "0 3 * * *" for 3am every day.
+type ScheduledRoot struct {
+	Path     string `json:"path"`
+	Schedule string `json:"schedule"`
+}
+
+// AgeColor colors entries modified at most MaxDays ago in the age heatmap. A
+// MaxDays of 0 matches anything older than the other entries.
+type AgeColor struct {
+	MaxDays int    `json:"max_days"`
+	Color   string `json:"color"`
+}
+
+// DefaultAgeColors fade from green for recent changes to red, then grey for
+// data untouched in years.
+var DefaultAgeColors = []AgeColor{
+	{MaxDays: 7, Color: "#04B575"},
+	{MaxDays: 30, Color: "#8FCC29"},
+	{MaxDays: 180, Color: "#E6C229"},
+	{MaxDays: 365, Color: "#E67E22"},
+	{MaxDays: 3 * 365, Color: "#CC3333"},
+	{MaxDays: 0, Color: "#6C6C6C"},
+}
+
+// Default returns the configuration used when no config file exists.
+func Default() Config {
+	return Config{
+		DateFormat:         DateAbsolute,
+		PagerMaxKB:         256,
+		LogMaxAgeDays:      30,
+		ConfirmDeleteGB:    10,
+		NotifyAfterSeconds: 30,
+		Daemon:             Daemon{KeepDays: 90},
+		// Cloned so decoding a user's colors doesn't write into the shared
+		// defaults
+		AgeColors: slices.Clone(DefaultAgeColors),
+	}
+}
+
+// DefaultPath returns where the config file lives when --config isn't given.
+func DefaultPath() (string, error) {
+	return paths.ConfigFile("config.json")
+}
+
+// Load reads the config file at path on top of the defaults. A missing file
+// is not an error.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// WithProfile returns the config with the named profile's settings applied
+// on top. Settings the profile doesn't mention keep their values.
+func (c Config) WithProfile(name string) (Config, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return c, fmt.Errorf("unknown profile %q", name)
+	}
+
+	merged := c
+	// Decoding reuses slice and map storage, which is shared with c
+	merged.AgeColors = slices.Clone(c.AgeColors)
+	merged.Profiles = maps.Clone(c.Profiles)
+	merged.Daemon.Roots = slices.Clone(c.Daemon.Roots)
+	merged.ScanRateLimits = maps.Clone(c.ScanRateLimits)
+	if err := json.Unmarshal(profile, &merged); err != nil {
+		return c, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return merged, nil
+}
+
+// ResolvedLocale returns the configured locale, falling back to LC_ALL,
+// LC_TIME and LANG, as a BCP 47 style tag like "en-US". It returns "" if none
+// of them name a locale.
+func (c Config) ResolvedLocale() string {
+	if c.Locale != "" {
+		return c.Locale
+	}
+
+	for _, name := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if locale := parseLocale(os.Getenv(name)); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// parseLocale turns a POSIX locale such as "de_DE.UTF-8" into "de-DE".
+func parseLocale(value string) string {
+	if i := strings.IndexAny(value, ".@"); i >= 0 {
+		value = value[:i]
+	}
+	if value == "" || value == "C" || value == "POSIX" {
+		return ""
+	}
+	return strings.ReplaceAll(value, "_", "-")
+}
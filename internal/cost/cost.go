@@ -0,0 +1,94 @@
+// Package cost estimates what storing data costs per month, from a flat rate
+// or the list price of a cloud storage class.
+package cost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// gigabyte is the unit storage is billed in. Cloud providers bill GB-months
+// in binary gigabytes.
+const gigabyte = 1 << 30
+
+// Classes are list prices per GB-month of common cloud storage classes, in
+// US dollars for a US region. Real bills vary by region, volume tier and
+// request charges, so these are for ballpark figures.
+var Classes = map[string]float64{
+	"s3-standard":         0.023,
+	"s3-standard-ia":      0.0125,
+	"s3-one-zone-ia":      0.01,
+	"s3-glacier-instant":  0.004,
+	"s3-glacier-flexible": 0.0036,
+	"s3-glacier-deep":     0.00099,
+	"gcs-standard":        0.020,
+	"gcs-nearline":        0.010,
+	"gcs-coldline":        0.004,
+	"gcs-archive":         0.0012,
+	"azure-hot":           0.0184,
+	"azure-cool":          0.01,
+	"azure-cold":          0.0036,
+	"azure-archive":       0.00099,
+	"ebs-gp3":             0.08,
+}
+
+// Rate is a price per GB-month.
+type Rate struct {
+	PerGBMonth float64
+	Currency   string // Symbol written before amounts, e.g. "$" or "€"
+}
+
+// NewRate returns the rate for a storage class, or the flat rate perGBMonth
+// when it's set, which overrides the class. A zero rate means costs aren't
+// configured.
+func NewRate(perGBMonth float64, class, currency string) (Rate, error) {
+	if currency == "" {
+		currency = "$"
+	}
+	if perGBMonth == 0 && class != "" {
+		var ok bool
+		if perGBMonth, ok = Classes[strings.ToLower(class)]; !ok {
+			return Rate{}, fmt.Errorf("unknown storage class %q, known classes are %s", class, strings.Join(classNames(), ", "))
+		}
+	}
+	if perGBMonth < 0 {
+		return Rate{}, fmt.Errorf("negative storage rate %v", perGBMonth)
+	}
+	return Rate{PerGBMonth: perGBMonth, Currency: currency}, nil
+}
+
+func classNames() []string {
+	names := make([]string, 0, len(Classes))
+	for name := range Classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Enabled reports whether a rate is configured.
+func (r Rate) Enabled() bool {
+	return r.PerGBMonth > 0
+}
+
+// Monthly is what storing bytes costs per month.
+func (r Rate) Monthly(bytes int64) float64 {
+	return float64(bytes) / gigabyte * r.PerGBMonth
+}
+
+// Format writes the monthly cost of bytes with cents, or whole units once the
+// cents stop mattering.
+func (r Rate) Format(bytes int64) string {
+	amount := r.Monthly(bytes)
+	switch {
+	case amount == 0:
+		return r.Currency + "0"
+	case amount < 0.01:
+		return "<" + r.Currency + "0.01"
+	case amount < 1000:
+		return fmt.Sprintf("%s%.2f", r.Currency, amount)
+	default:
+		return fmt.Sprintf("%s%.0f", r.Currency, amount)
+	}
+}
@@ -0,0 +1,155 @@
+package dupes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// HashFile returns the hex-encoded SHA-256 of the file's contents.
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// DirGroup is a set of directories with identical structure and contents.
+type DirGroup struct {
+	Hash  string
+	Size  int64
+	Paths []string
+}
+
+// Savings is the space freed by keeping a single copy of the group.
+func (g DirGroup) Savings() int64 {
+	return g.Size * int64(len(g.Paths)-1)
+}
+
+type dirHash struct {
+	hash  string
+	size  int64
+	files int
+	// incomplete marks trees with unreadable entries, which can't be
+	// proven identical to anything
+	incomplete bool
+}
+
+// FindDuplicateDirs walks root and groups directories whose subtrees hash
+// identically. A directory's hash covers the names, hashes and types of its
+// entries, merkle-style, so copies of a whole tree collide at its top. Groups
+// nested inside a reported group are left out since they are implied by it.
+// Groups are returned largest savings first.
+func FindDuplicateDirs(root string) ([]DirGroup, error) {
+	byHash := make(map[string]*DirGroup)
+
+	if _, err := hashTree(root, byHash); err != nil {
+		return nil, err
+	}
+
+	duplicated := make(map[string]bool)
+	for _, group := range byHash {
+		if len(group.Paths) > 1 {
+			for _, path := range group.Paths {
+				duplicated[path] = true
+			}
+		}
+	}
+
+	var groups []DirGroup
+	for _, group := range byHash {
+		if len(group.Paths) < 2 || nestedInDuplicate(group.Paths, duplicated) {
+			continue
+		}
+		sort.Strings(group.Paths)
+		groups = append(groups, *group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Savings() != groups[j].Savings() {
+			return groups[i].Savings() > groups[j].Savings()
+		}
+		return groups[i].Paths[0] < groups[j].Paths[0]
+	})
+
+	return groups, nil
+}
+
+func nestedInDuplicate(paths []string, duplicated map[string]bool) bool {
+	for _, path := range paths {
+		if !duplicated[filepath.Dir(path)] {
+			return false
+		}
+	}
+	return true
+}
+
+func hashTree(path string, byHash map[string]*DirGroup) (dirHash, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return dirHash{}, err
+	}
+
+	// os.ReadDir sorts by name, so the digest is independent of disk order
+	digest := sha256.New()
+	var result dirHash
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(path, entry.Name())
+
+		switch {
+		case entry.IsDir():
+			sub, err := hashTree(entryPath, byHash)
+			if err != nil {
+				result.incomplete = true
+				continue
+			}
+			fmt.Fprintf(digest, "d %q %s\n", entry.Name(), sub.hash)
+			result.size += sub.size
+			result.files += sub.files
+			result.incomplete = result.incomplete || sub.incomplete
+		case entry.Type().IsRegular():
+			info, err := entry.Info()
+			if err != nil {
+				result.incomplete = true
+				continue
+			}
+			fileHash, err := HashFile(entryPath)
+			if err != nil {
+				result.incomplete = true
+				continue
+			}
+			fmt.Fprintf(digest, "f %q %s\n", entry.Name(), fileHash)
+			result.size += info.Size()
+			result.files++
+		default:
+			// Symlinks and special files are compared by name and type only
+			fmt.Fprintf(digest, "o %q %v\n", entry.Name(), entry.Type())
+		}
+	}
+
+	result.hash = hex.EncodeToString(digest.Sum(nil))
+
+	// Empty trees are trivially identical and free nothing
+	if result.files > 0 && result.size > 0 && !result.incomplete {
+		group, ok := byHash[result.hash]
+		if !ok {
+			group = &DirGroup{Hash: result.hash, Size: result.size}
+			byHash[result.hash] = group
+		}
+		group.Paths = append(group.Paths, path)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,186 @@
+package dupes
+
+import (
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultSimilarity is the largest hash distance, out of 64 bits, at which
+// two images are still treated as the same picture. It tolerates resizing
+// and recompression but not crops or edits.
+const DefaultSimilarity = 10
+
+// imageExtensions lists the formats the standard library can decode. Videos
+// are not covered as that would need an external decoder.
+var imageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+}
+
+// MediaFile is an image and its perceptual hash.
+type MediaFile struct {
+	Path string
+	Size int64
+	Hash uint64
+}
+
+// MediaGroup is a set of images that look alike.
+type MediaGroup struct {
+	Files []MediaFile
+}
+
+// Savings is the space freed by keeping only the largest file in the group,
+// which is usually the best quality copy.
+func (g MediaGroup) Savings() int64 {
+	var total int64
+	for _, file := range g.Files[1:] {
+		total += file.Size
+	}
+	return total
+}
+
+// ImageHash computes a 64-bit difference hash: the image is shrunk to 9x8
+// grey levels and each bit records whether a pixel is brighter than its
+// right neighbour. Resized and re-encoded copies end up a few bits apart.
+func ImageHash(path string) (uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return 0, err
+	}
+
+	var grey [8][9]float64
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return 0, nil
+	}
+
+	// Average each cell of a 9x8 grid so detail doesn't alias into the hash
+	for row := 0; row < 8; row++ {
+		y0, y1 := bounds.Min.Y+row*h/8, bounds.Min.Y+(row+1)*h/8
+		for col := 0; col < 9; col++ {
+			x0, x1 := bounds.Min.X+col*w/9, bounds.Min.X+(col+1)*w/9
+			var sum float64
+			var n int
+			for y := y0; y < max(y1, y0+1); y++ {
+				for x := x0; x < max(x1, x0+1); x++ {
+					r, g, b, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+					n++
+				}
+			}
+			grey[row][col] = sum / float64(n)
+		}
+	}
+
+	var hash uint64
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			hash <<= 1
+			if grey[row][col] > grey[row][col+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash, nil
+}
+
+// FindSimilarImages hashes every image under root and groups those within
+// maxDistance bits of each other. Unreadable or undecodable files are
+// skipped. Files in a group are ordered largest first and groups by savings.
+func FindSimilarImages(root string, maxDistance int) ([]MediaGroup, error) {
+	var files []MediaFile
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() || !imageExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		hash, err := ImageHash(path)
+		if err != nil {
+			return nil
+		}
+
+		files = append(files, MediaFile{Path: path, Size: info.Size(), Hash: hash})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Union-find over every close pair, so chains of near matches end up in
+	// one group
+	parent := make([]int, len(files))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range files {
+		for j := i + 1; j < len(files); j++ {
+			if bits.OnesCount64(files[i].Hash^files[j].Hash) <= maxDistance {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	byRoot := make(map[int][]MediaFile)
+	for i, file := range files {
+		byRoot[find(i)] = append(byRoot[find(i)], file)
+	}
+
+	var groups []MediaGroup
+	for _, members := range byRoot {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if members[i].Size != members[j].Size {
+				return members[i].Size > members[j].Size
+			}
+			return members[i].Path < members[j].Path
+		})
+		groups = append(groups, MediaGroup{Files: members})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Savings() != groups[j].Savings() {
+			return groups[i].Savings() > groups[j].Savings()
+		}
+		return groups[i].Files[0].Path < groups[j].Files[0].Path
+	})
+
+	return groups, nil
+}
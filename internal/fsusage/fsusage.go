@@ -0,0 +1,21 @@
+// Package fsusage reports how full the filesystem holding a path is, as df
+// does.
+package fsusage
+
+import "errors"
+
+// ErrUnsupported is returned where filesystem usage can't be read.
+var ErrUnsupported = errors.New("filesystem usage is not available on this platform")
+
+// Usage is the capacity of a filesystem in bytes. Free counts blocks reserved
+// for the superuser, Available doesn't.
+type Usage struct {
+	Total     uint64
+	Free      uint64
+	Available uint64
+}
+
+// Used is what the filesystem reports as taken.
+func (u Usage) Used() uint64 {
+	return u.Total - u.Free
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package fsusage
+
+// Stat returns the usage of the filesystem holding path.
+func Stat(path string) (Usage, error) {
+	return Usage{}, ErrUnsupported
+}
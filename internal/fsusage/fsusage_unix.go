@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd
+
+package fsusage
+
+import "syscall"
+
+// Stat returns the usage of the filesystem holding path.
+func Stat(path string) (Usage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return Usage{}, err
+	}
+
+	// Field types differ between platforms
+	blockSize := uint64(stat.Bsize)
+	return Usage{
+		Total:     uint64(stat.Blocks) * blockSize,
+		Free:      uint64(stat.Bfree) * blockSize,
+		Available: uint64(stat.Bavail) * blockSize,
+	}, nil
+}
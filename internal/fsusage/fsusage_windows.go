@@ -0,0 +1,18 @@
+package fsusage
+
+import "golang.org/x/sys/windows"
+
+// Stat returns the usage of the volume holding path. Available is what the
+// current user may use, which quotas can make smaller than Free.
+func Stat(path string) (Usage, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var usage Usage
+	if err := windows.GetDiskFreeSpaceEx(name, &usage.Available, &usage.Total, &usage.Free); err != nil {
+		return Usage{}, err
+	}
+	return usage, nil
+}
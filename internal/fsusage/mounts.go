@@ -0,0 +1,96 @@
+package fsusage
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/corpeningc/dua/internal/paths"
+)
+
+// Mount is a mounted filesystem.
+type Mount struct {
+	Point   string
+	Source  string // Device, dataset or remote the filesystem comes from
+	Type    string
+	Options []string
+	// Device identifies the filesystem, so mounts of the same one share it.
+	// Root is the directory of that filesystem mounted at Point, which is
+	// below its top for bind mounts. Both are empty where unknown.
+	Device string
+	Root   string
+}
+
+// HasOption reports whether the filesystem was mounted with option.
+func (m Mount) HasOption(option string) bool {
+	return slices.Contains(m.Options, option)
+}
+
+// networkTypes are the filesystem types served over the network.
+var networkTypes = []string{
+	"nfs", "nfs4", "cifs", "smb3", "smbfs", "afs", "9p", "ceph", "glusterfs",
+	"lustre", "fuse.sshfs", "fuse.rclone", "davfs", "fuse.davfs2",
+}
+
+// Network reports whether the filesystem is served over the network.
+func (m Mount) Network() bool {
+	return slices.Contains(networkTypes, m.Type)
+}
+
+// Contains reports whether path is at or below the mount point.
+func (m Mount) Contains(path string) bool {
+	return paths.Within(path, m.Point)
+}
+
+// MountOf returns the mount path lives on: the one with the longest mount
+// point containing it.
+func MountOf(mounts []Mount, path string) (Mount, bool) {
+	var best Mount
+	found := false
+	for _, mount := range mounts {
+		if mount.Contains(path) && (!found || len(mount.Point) >= len(best.Point)) {
+			best, found = mount, true
+		}
+	}
+	return best, found
+}
+
+// Duplicates finds mounts below root that show data root already holds
+// elsewhere: bind mounts of directories in the scan, and filesystems mounted
+// at more than one point. It maps each such mount point to the path of the
+// same data it duplicates, so a scan can count it once. The first mount of a
+// filesystem is the one kept.
+func Duplicates(mounts []Mount, root string) map[string]string {
+	duplicates := make(map[string]string)
+	within := Mount{Point: root}
+	for i, mount := range mounts {
+		if mount.Device == "" || mount.Point == root || !within.Contains(mount.Point) {
+			continue
+		}
+		for j, other := range mounts {
+			if i == j || other.Device != mount.Device || !paths.Within(mount.Root, other.Root) {
+				continue
+			}
+			// Of two mounts of the same directory, the earlier one stays
+			if mount.Root == other.Root && j > i {
+				continue
+			}
+			original := other.Point
+			if rest := strings.TrimPrefix(mount.Root, other.Root); rest != "" && rest != "/" {
+				original = strings.TrimSuffix(other.Point, "/") + "/" + strings.TrimPrefix(rest, "/")
+			}
+			// Only skip it if the scan reaches the original
+			if within.Contains(original) && !mount.Contains(original) && shown(mounts, other, original) {
+				duplicates[mount.Point] = original
+				break
+			}
+		}
+	}
+	return duplicates
+}
+
+// shown reports whether path is still reached through mount, rather than
+// hidden by something mounted over it.
+func shown(mounts []Mount, mount Mount, path string) bool {
+	top, ok := MountOf(mounts, path)
+	return ok && top.Point == mount.Point && top.Device == mount.Device && top.Root == mount.Root
+}
@@ -0,0 +1,49 @@
+package fsusage
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Mounts lists the mounted filesystems, in the order they were mounted.
+func Mounts() ([]Mount, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var mounts []Mount
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		// id parent major:minor root point options [optional...] - type source super-options
+		fields := strings.Fields(lines.Text())
+		sep := slices.Index(fields, "-")
+		if sep < 6 || len(fields) < sep+4 {
+			continue
+		}
+		options := strings.Split(fields[5], ",")
+		for _, option := range strings.Split(fields[sep+3], ",") {
+			if !slices.Contains(options, option) {
+				options = append(options, option)
+			}
+		}
+		mounts = append(mounts, Mount{
+			Point:   unescapeMountPath(fields[4]),
+			Source:  unescapeMountPath(fields[sep+2]),
+			Type:    fields[sep+1],
+			Options: options,
+			Device:  fields[2],
+			Root:    unescapeMountPath(fields[3]),
+		})
+	}
+	return mounts, lines.Err()
+}
+
+// unescapeMountPath decodes the octal escapes the kernel uses for spaces and
+// other special characters in mount paths.
+func unescapeMountPath(path string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(path)
+}
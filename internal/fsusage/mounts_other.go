@@ -0,0 +1,8 @@
+//go:build !linux
+
+package fsusage
+
+// Mounts lists the mounted filesystems, in the order they were mounted.
+func Mounts() ([]Mount, error) {
+	return nil, ErrUnsupported
+}
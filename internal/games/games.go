@@ -0,0 +1,305 @@
+// Package games finds the games installed by Steam and the Epic Games
+// Launcher, with their install sizes and when they were last played, read
+// from the launchers' own manifests.
+package games
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Launcher is the store a game was installed from.
+type Launcher string
+
+const (
+	Steam Launcher = "Steam"
+	Epic  Launcher = "Epic"
+)
+
+// Game is one installed game.
+type Game struct {
+	Name     string
+	Launcher Launcher
+	Path     string
+	Size     int64
+	// LastPlayed is zero when the launcher hasn't recorded it. Epic's
+	// manifests never do.
+	LastPlayed time.Time
+}
+
+// Library is a folder a launcher installs games into.
+type Library struct {
+	Launcher Launcher
+	Path     string
+	Games    []Game
+	Size     int64
+}
+
+// Find reads the libraries Steam and Epic know of on this machine, and
+// extra, if it's a Steam library they don't, such as one on a drive from
+// another machine.
+func Find(extra string) []Library {
+	var libraries []Library
+	seen := make(map[string]bool)
+	add := func(library Library) {
+		key := library.Path
+		if resolved, err := filepath.EvalSymlinks(key); err == nil {
+			key = resolved
+		}
+		if seen[key] || len(library.Games) == 0 {
+			return
+		}
+		seen[key] = true
+		for _, game := range library.Games {
+			library.Size += game.Size
+		}
+		libraries = append(libraries, library)
+	}
+
+	for _, path := range steamLibraries() {
+		add(readSteamLibrary(path))
+	}
+	if extra != "" {
+		if filepath.Base(extra) == "steamapps" {
+			extra = filepath.Dir(extra)
+		}
+		add(readSteamLibrary(extra))
+	}
+	for _, library := range epicLibraries() {
+		add(library)
+	}
+	return libraries
+}
+
+// steamRoots are where Steam itself may be installed.
+func steamRoots() []string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		return []string{
+			filepath.Join(os.Getenv("ProgramFiles(x86)"), "Steam"),
+			filepath.Join(os.Getenv("ProgramFiles"), "Steam"),
+		}
+	case "darwin":
+		return []string{filepath.Join(home, "Library", "Application Support", "Steam")}
+	}
+	return []string{
+		filepath.Join(home, ".steam", "steam"),
+		filepath.Join(home, ".local", "share", "Steam"),
+		// Flatpak
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+	}
+}
+
+// steamLibraries lists Steam's own folder and the others added to it, from
+// libraryfolders.vdf. Older versions list them as plain paths, newer ones as
+// blocks with a path key.
+func steamLibraries() []string {
+	var libraries []string
+	for _, root := range steamRoots() {
+		if _, err := os.Stat(filepath.Join(root, "steamapps")); err != nil {
+			continue
+		}
+		libraries = append(libraries, root)
+
+		data, err := os.ReadFile(filepath.Join(root, "steamapps", "libraryfolders.vdf"))
+		if err != nil {
+			continue
+		}
+		vdf, err := parseVDF(string(data))
+		if err != nil {
+			continue
+		}
+		folders, _ := vdfLookup(vdf, "libraryfolders").(map[string]any)
+		for key, value := range folders {
+			if _, err := strconv.Atoi(key); err != nil {
+				continue
+			}
+			switch value := value.(type) {
+			case string:
+				libraries = append(libraries, value)
+			case map[string]any:
+				if path := vdfString(value, "path"); path != "" {
+					libraries = append(libraries, path)
+				}
+			}
+		}
+	}
+	return libraries
+}
+
+// readSteamLibrary reads the app manifests in a library's steamapps folder.
+// Sizes come from the manifest, or are measured when it has none.
+func readSteamLibrary(path string) Library {
+	library := Library{Launcher: Steam, Path: path}
+	manifests, _ := filepath.Glob(filepath.Join(path, "steamapps", "appmanifest_*.acf"))
+	for _, manifest := range manifests {
+		data, err := os.ReadFile(manifest)
+		if err != nil {
+			continue
+		}
+		vdf, err := parseVDF(string(data))
+		if err != nil {
+			continue
+		}
+		installDir := vdfString(vdf, "AppState", "installdir")
+		if installDir == "" {
+			continue
+		}
+
+		game := Game{
+			Name:     vdfString(vdf, "AppState", "name"),
+			Launcher: Steam,
+			Path:     filepath.Join(path, "steamapps", "common", installDir),
+		}
+		game.Size, _ = strconv.ParseInt(vdfString(vdf, "AppState", "SizeOnDisk"), 10, 64)
+		if game.Size == 0 {
+			game.Size = dirSize(game.Path)
+		}
+		if played, _ := strconv.ParseInt(vdfString(vdf, "AppState", "LastPlayed"), 10, 64); played > 0 {
+			game.LastPlayed = time.Unix(played, 0)
+		}
+		if game.Name == "" {
+			game.Name = installDir
+		}
+		library.Games = append(library.Games, game)
+	}
+	return library
+}
+
+// epicManifest is the part of an Epic Games Launcher .item file that's used.
+type epicManifest struct {
+	DisplayName     string
+	InstallLocation string
+	InstallSize     int64
+	AppCategories   []string
+}
+
+// legendaryGame is an entry in installed.json of legendary, the Epic client
+// behind Heroic on Linux.
+type legendaryGame struct {
+	Title       string `json:"title"`
+	InstallPath string `json:"install_path"`
+	InstallSize int64  `json:"install_size"`
+	IsDLC       bool   `json:"is_dlc"`
+}
+
+// epicLibraries reads the games the Epic Games Launcher, or legendary on
+// Linux, has installed, grouped by the folder they're in.
+func epicLibraries() []Library {
+	var games []Game
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		games = readEpicManifests(filepath.Join(os.Getenv("ProgramData"), "Epic", "EpicGamesLauncher", "Data", "Manifests"))
+	case "darwin":
+		games = readEpicManifests(filepath.Join(home, "Library", "Application Support", "Epic", "EpicGamesLauncher", "Data", "Manifests"))
+	default:
+		for _, config := range []string{
+			filepath.Join(home, ".config", "legendary"),
+			filepath.Join(home, ".var", "app", "com.heroicgameslauncher.hgl", "config", "legendary"),
+		} {
+			games = append(games, readLegendary(filepath.Join(config, "installed.json"))...)
+		}
+	}
+
+	var libraries []Library
+	index := make(map[string]int)
+	for _, game := range games {
+		folder := filepath.Dir(game.Path)
+		i, ok := index[folder]
+		if !ok {
+			i = len(libraries)
+			index[folder] = i
+			libraries = append(libraries, Library{Launcher: Epic, Path: folder})
+		}
+		libraries[i].Games = append(libraries[i].Games, game)
+	}
+	return libraries
+}
+
+func readEpicManifests(dir string) []Game {
+	var games []Game
+	items, _ := filepath.Glob(filepath.Join(dir, "*.item"))
+	for _, item := range items {
+		data, err := os.ReadFile(item)
+		if err != nil {
+			continue
+		}
+		var manifest epicManifest
+		if json.Unmarshal(data, &manifest) != nil || manifest.InstallLocation == "" {
+			continue
+		}
+		if isAddon(manifest.AppCategories) {
+			continue
+		}
+		games = append(games, Game{
+			Name:     manifest.DisplayName,
+			Launcher: Epic,
+			Path:     manifest.InstallLocation,
+			Size:     manifest.InstallSize,
+		})
+	}
+	return games
+}
+
+// isAddon tells DLC, which is installed into its game's folder and counted in
+// its size, from games.
+func isAddon(categories []string) bool {
+	for _, category := range categories {
+		if strings.EqualFold(category, "addons") {
+			return true
+		}
+	}
+	return false
+}
+
+func readLegendary(path string) []Game {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var installed map[string]legendaryGame
+	if json.Unmarshal(data, &installed) != nil {
+		return nil
+	}
+	var games []Game
+	for _, entry := range installed {
+		if entry.IsDLC || entry.InstallPath == "" {
+			continue
+		}
+		games = append(games, Game{
+			Name:     entry.Title,
+			Launcher: Epic,
+			Path:     entry.InstallPath,
+			Size:     entry.InstallSize,
+		})
+	}
+	return games
+}
+
+// dirSize totals the files below dir, skipping what can't be read.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != dir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
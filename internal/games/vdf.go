@@ -0,0 +1,154 @@
+package games
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var errVDF = errors.New("malformed VDF")
+
+// parseVDF reads Valve's KeyValues text format, used by Steam's library and
+// app manifests: quoted keys followed by a quoted value or a braced block.
+// Blocks become nested maps. Later duplicate keys win.
+func parseVDF(text string) (map[string]any, error) {
+	p := &vdfParser{text: text}
+	root, err := p.block(false)
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+type vdfParser struct {
+	text string
+	pos  int
+}
+
+// block reads key/value pairs up to the closing brace, or the end of the text
+// at the top level.
+func (p *vdfParser) block(nested bool) (map[string]any, error) {
+	values := make(map[string]any)
+	for {
+		token, quoted, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case token == "" && !quoted:
+			if nested {
+				return nil, errVDF
+			}
+			return values, nil
+		case token == "}" && !quoted:
+			if !nested {
+				return nil, errVDF
+			}
+			return values, nil
+		case token == "{" && !quoted:
+			return nil, errVDF
+		}
+
+		key := token
+		value, quoted, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case value == "{" && !quoted:
+			sub, err := p.block(true)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = sub
+		case value == "}" && !quoted, value == "" && !quoted:
+			return nil, errVDF
+		default:
+			values[key] = value
+		}
+	}
+}
+
+// token returns the next quoted string, brace or bare word, and whether it's
+// a string rather than a brace. The end of the text is an empty token that
+// isn't a string.
+func (p *vdfParser) token() (string, bool, error) {
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			p.pos++
+		case strings.HasPrefix(p.text[p.pos:], "//"):
+			if end := strings.IndexByte(p.text[p.pos:], '\n'); end >= 0 {
+				p.pos += end
+			} else {
+				p.pos = len(p.text)
+			}
+		case c == '{' || c == '}':
+			p.pos++
+			return string(c), false, nil
+		case c == '"':
+			return p.quoted()
+		default:
+			start := p.pos
+			for p.pos < len(p.text) && !unicode.IsSpace(rune(p.text[p.pos])) && !strings.ContainsRune(`{}"`, rune(p.text[p.pos])) {
+				p.pos++
+			}
+			return p.text[start:p.pos], true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (p *vdfParser) quoted() (string, bool, error) {
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.text); p.pos++ {
+		switch c := p.text[p.pos]; c {
+		case '"':
+			p.pos++
+			return b.String(), true, nil
+		case '\\':
+			if p.pos+1 < len(p.text) {
+				p.pos++
+				switch next := p.text[p.pos]; next {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(next)
+				}
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", false, errVDF
+}
+
+// vdfString looks up a string value by a path of keys. Keys are matched
+// case-insensitively, as Steam isn't consistent about them.
+func vdfString(values map[string]any, keys ...string) string {
+	var current any = values
+	for _, key := range keys {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return ""
+		}
+		current = vdfLookup(m, key)
+	}
+	s, _ := current.(string)
+	return s
+}
+
+func vdfLookup(values map[string]any, key string) any {
+	if v, ok := values[key]; ok {
+		return v
+	}
+	for k, v := range values {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
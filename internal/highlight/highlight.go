@@ -0,0 +1,49 @@
+// Package highlight colors source code for display in the terminal.
+package highlight
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// Style is the chroma color scheme used for highlighting.
+const Style = "monokai"
+
+// Lines highlights text as the language its file name suggests, or that its
+// content looks like when the name doesn't tell, and splits it into lines.
+// Every line carries its own colors, so any of them can be shown alone.
+// formatter is a chroma terminal formatter such as "terminal256".
+func Lines(name, text, formatter string) []string {
+	lexer := lexers.Match(name)
+	if lexer == nil {
+		lexer = lexers.Analyse(text)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, text)
+	if err != nil {
+		return strings.Split(text, "\n")
+	}
+
+	format := formatters.Get(formatter)
+	style := styles.Get(Style)
+
+	var lines []string
+	for _, tokens := range chroma.SplitTokensIntoLines(iterator.Tokens()) {
+		last := &tokens[len(tokens)-1]
+		last.Value = strings.TrimSuffix(last.Value, "\n")
+
+		var b strings.Builder
+		if err := format.Format(&b, style, chroma.Literator(tokens...)); err != nil {
+			return strings.Split(text, "\n")
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
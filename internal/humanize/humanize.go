@@ -0,0 +1,42 @@
+package humanize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bytes formats a byte count using binary units, e.g. "1.5 MB".
+func Bytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / div; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// ParseBytes parses sizes such as "512", "100K", "1.5GB" or "2 GiB". Units
+// are binary, matching Bytes.
+func ParseBytes(input string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(input))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "IB"), "B")
+
+	multiplier := int64(1)
+	if i := strings.IndexAny(s, "KMGTPE"); i >= 0 && i == len(s)-1 {
+		multiplier = 1 << (10 * (strings.IndexByte("KMGTPE", s[i]) + 1))
+		s = strings.TrimSpace(s[:i])
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", input)
+	}
+	return int64(value * float64(multiplier)), nil
+}
@@ -0,0 +1,320 @@
+package i18n
+
+var german = Catalog{
+	"header.title":       "DUA - Speicherplatzanalyse | Pfad: %s | Sortierung: %s%s",
+	"header.scanning":    " | SCANNE: %d Dateien, %d Ordner, %s in %v",
+	"header.scanned":     " | GESCANNT: %d Dateien, %d Ordner, %s",
+	"header.progress":    " | %s %d%%",
+	"header.counting":    " | zähle…",
+	"header.eta":         " | noch ~%s",
+	"header.degraded":    " | ⚠ Speicherlimit überschritten",
+	"header.disk_usage":  " | Belegung",
+	"header.kiosk":       " | Kiosk",
+	"header.quick":       " | SCHNELLSCAN: ~%d%% nicht gescannt",
+	"header.peers.one":   " | ⚠ %d weitere dua-Sitzung in diesem Baum",
+	"header.peers.other": " | ⚠ %d weitere dua-Sitzungen in diesem Baum",
+	"header.cached":      "%s (zwischengespeichert %s)",
+
+	"sort.name": "Name",
+	"sort.date": "Datum",
+	"sort.size": "Größe",
+	"sort.type": "Typ",
+
+	"row.loading": "Lädt",
+
+	"footer.search":           "Suche: %s_ • enter: bestätigen • esc: abbrechen",
+	"footer.note":             "Notiz: %s_ • enter: speichern (leer entfernt) • esc: abbrechen",
+	"footer.export":           "Exportieren nach: %s_ • .nul: NUL-getrennt • enter: schreiben • esc: abbrechen",
+	"footer.export_overwrite": "Exportieren nach: %s_ • existiert bereits • enter: überschreiben • esc: abbrechen",
+	"footer.rename":           "Umbenennen: %s_ • enter: bestätigen • esc: abbrechen",
+	"footer.rename_error":     "Umbenennen: %s_ • Fehler: %s • enter: bestätigen • esc: abbrechen",
+	"footer.rename_overwrite": "Umbenennen: %s_ • %s • enter: überschreiben • esc: abbrechen",
+	"footer.marked":           "%d zum Löschen markiert • d: LÖSCHEN • S: SCHREDDERN • esc: abbrechen",
+	"footer.shred":            "%d Einträge schreddern? Dateien werden vor dem Löschen überschrieben, SSDs können aber Kopien alter Daten behalten • S: bestätigen • esc: abbrechen",
+	"footer.shred_cow":        "%d Einträge schreddern? Dieses Dateisystem ist Copy-on-Write, Überschreiben erreicht die Originaldaten nicht • S: trotzdem bestätigen • esc: abbrechen",
+	"footer.filtered":         "Gefiltert: '%s' • /: suchen • esc: zurücksetzen • ↑↓/jk: navigieren • →l: aufklappen • ←h: zuklappen • q: beenden",
+	"footer.default":          "/: suchen • ↑↓/jk: navigieren • →l: aufklappen • ←h: zuklappen • enter: Datei ansehen • t: auswählen • r: umbenennen • n: Notiz • O: Eigentümer • L: Spalten • e: exportieren • x: vormerken • Q: Warteschlange • C: Aufräumvorschläge • D: df und Scan • R: neu scannen • d: löschen • s: sortieren • ctrl+s: umkehren • m/M: Datum • a: Altersfarben • c: Kosten • u: Belegung • p: Vorschau • q: beenden",
+	"footer.readonly":         "/: suchen • ↑↓/jk: navigieren • →l: aufklappen • ←h: zuklappen • t: auswählen • n: Notiz • O: Eigentümer • L: Spalten • e: exportieren • s: sortieren • ctrl+s: umkehren • m/M: Datum • a: Altersfarben • c: Kosten • u: Belegung • q: beenden (schreibgeschützt)",
+	"footer.pager":            "↑↓/jk: scrollen • pgup/pgdn: seitenweise • g/G: Anfang/Ende • esc/q: schließen",
+	"footer.owners":           "↑↓/jk: scrollen • esc/q: zurück",
+	"footer.help":             "esc/q/?: zurück",
+	"footer.more":             "?: mehr",
+	"footer.columns":          "↑↓/jk: bewegen • Leertaste: ein/aus • J/K: verschieben • esc/q: zurück",
+	"footer.suggest":          "↑↓/jk: navigieren • enter: aufräumen • esc/q: zurück",
+	"footer.reconcile":        "esc/q: zurück",
+	"footer.review":           "↑↓/jk: navigieren • t: abwählen • x: alle vormerken • d: alle löschen • esc/q: zurück",
+	"footer.review_confirm":   "%d ausgewählte Einträge löschen (%s)? y: löschen • n: abbrechen",
+	"footer.suggest_confirm":  "%s: löschen, um %s freizugeben? y: aufräumen • n: abbrechen",
+	"footer.suggest_run":      "%s: %s ausführen, um etwa %s freizugeben? y: ausführen • n: abbrechen",
+	"footer.queue":            "↑↓/jk: navigieren • x: entfernen • enter: Warteschlange ausführen • esc: zurück • q: beenden",
+	"footer.queue_confirm":    "%d Einträge (%s) aus der Warteschlange löschen? y: löschen • n: abbrechen",
+	"footer.queue_quit":       "Aufräum-Warteschlange vor dem Beenden ausführen? %d Einträge (%s) • y: löschen und beenden • n: ohne Löschen beenden • esc: zurück",
+	"footer.choose_dir":       "enter: Ordner wählen • /: suchen • ↑↓/jk: navigieren • →l: aufklappen • ←h: zuklappen • esc/q: abbrechen",
+	"footer.choose_file":      "enter: Datei wählen • /: suchen • ↑↓/jk: navigieren • →l: aufklappen • ←h: zuklappen • esc/q: abbrechen",
+	"footer.tutorial":         "enter: weiter • ←h: zurück • esc: Tour überspringen",
+	"footer.selected":         "%d ausgewählt • V: prüfen • ",
+	"footer.selected_cost":    "%d ausgewählt, %s/Monat • V: prüfen • ",
+
+	"rename.exists":    "'%s' existiert bereits",
+	"rename.empty":     "Name darf nicht leer sein",
+	"rename.invalid":   "'%s' ist kein gültiger Name",
+	"rename.separator": "Name darf keine Pfadtrenner enthalten",
+	"rename.control":   "Name darf keine Steuerzeichen enthalten",
+	"rename.nul":       "Name darf keine NUL-Zeichen enthalten",
+	"rename.utf8":      "Name muss gültiges UTF-8 sein",
+
+	"error.load":       "%s konnte nicht geladen werden: %v",
+	"readonly.refused": "Schreibgeschützt: dieser Baum wurde aus einem Export geladen",
+
+	"kiosk.refused":   "Kioskmodus: %s kann nicht geändert werden, nur was unter %s liegt",
+	"kiosk.read_only": "Kioskmodus: nur ansehen, %s kann nicht geändert werden",
+	"kiosk.commands":  "Kioskmodus: Aufräumbefehle sind deaktiviert",
+
+	"tutorial.welcome.title":     "Willkommen bei DUA",
+	"tutorial.welcome.body":      "Diese kurze Tour zeigt die wichtigsten Tasten, um Platzfresser\nzu finden und aufzuräumen. Sie wird nur einmal angezeigt.",
+	"tutorial.navigate.title":    "Im Baum navigieren",
+	"tutorial.navigate.body":     "Ordner füllen sich, während der Scan im Hintergrund läuft.",
+	"tutorial.navigate.move":     "nach oben und unten bewegen",
+	"tutorial.navigate.expand":   "Ordner aufklappen",
+	"tutorial.navigate.collapse": "Ordner zuklappen",
+	"tutorial.navigate.jump":     "zum Anfang oder Ende springen",
+	"tutorial.search.title":      "Suchen",
+	"tutorial.search.body":       "Die Suche findet Namen unscharf im gesamten Baum.",
+	"tutorial.search.start":      "Suche eingeben",
+	"tutorial.search.keep":       "Filter behalten und Ergebnisse durchsehen",
+	"tutorial.search.clear":      "Filter zurücksetzen",
+	"tutorial.mark.title":        "Einträge auswählen",
+	"tutorial.mark.body":         "Wähle mehrere Einträge aus, in beliebigen Ordnern, um sie gemeinsam zu bearbeiten. Die Auswahl bleibt beim Navigieren erhalten.",
+	"tutorial.mark.toggle":       "aktuellen Eintrag aus- oder abwählen",
+	"tutorial.mark.visual":       "einen Bereich beim Bewegen auswählen",
+	"tutorial.mark.review":       "Auswahl über alle Ordner hinweg prüfen",
+	"tutorial.delete.title":      "Löschen",
+	"tutorial.delete.body":       "Löschen braucht immer zwei Schritte, damit nichts versehentlich verschwindet.",
+	"tutorial.delete.mark":       "Auswahl oder aktuellen Eintrag markieren",
+	"tutorial.delete.confirm":    "erneut drücken, um alles Markierte zu löschen",
+	"tutorial.delete.cancel":     "Markierung aufheben und abbrechen",
+	"tutorial.done.title":        "Alles bereit",
+	"tutorial.done.body":         "Mit dua --tutorial lässt sich diese Tour erneut anzeigen.",
+	"tutorial.done.sort":         "Sortierung ändern",
+	"tutorial.done.quit":         "beenden",
+	"tutorial.progress":          "Schritt %d von %d",
+
+	"media.title":   "Ähnliche Bilder: %d Gruppen",
+	"media.empty":   "Keine ähnlichen Bilder gefunden",
+	"media.group":   "Gruppe %d • %d Bilder • %s freigebbar",
+	"media.footer":  "↑↓/jk: navigieren • t: markieren • a: alle außer größtem markieren • d: löschen • esc: leeren • q: beenden",
+	"media.confirm": "%d markierte Dateien löschen? d: bestätigen • andere Taste: abbrechen",
+	"media.deleted": "%d gelöscht, %d fehlgeschlagen",
+
+	"preview.details":   "%s • geändert %s",
+	"preview.file":      "Datei",
+	"preview.directory": "Ordner",
+	"preview.image":     "%s-Bild, %d×%d",
+	"preview.video":     "%s-Video",
+	"preview.audio":     "%s-Audio",
+	"preview.taken":     "aufgenommen %s",
+	"preview.error":     "Nicht lesbar: %v",
+	"preview.note":      "%s %s",
+	"preview.duplicate": "%s erneut eingehängt von %s, dort gezählt",
+	"preview.open":      "%s geöffnet von %s",
+
+	"pager.truncated": "erste %s angezeigt",
+	"pager.binary":    "Binärdatei, nicht angezeigt",
+
+	"backup.label":                 "(%s-Repository)",
+	"backup.label_stats":           "(%s-Repository, %s gesichert)",
+	"backup.loading":               "%s-Repository • Statistik wird gelesen…",
+	"backup.error":                 "%s-Repository • keine Statistik: %v",
+	"backup.stats":                 "%s-Repository • %s gesichert, %s belegt (%.1f× kleiner)",
+	"backup.stats_snapshots.one":   "%[2]s-Repository • %[1]d Snapshot, %[3]s gesichert, %[4]s belegt (%.1f× kleiner)",
+	"backup.stats_snapshots.other": "%[2]s-Repository • %[1]d Snapshots, %[3]s gesichert, %[4]s belegt (%.1f× kleiner)",
+
+	"owners.title.one":   "Belegung nach Eigentümer: %d Eigentümer, %s",
+	"owners.title.other": "Belegung nach Eigentümer: %d Eigentümer, %s",
+	"owners.files.one":   "%d Datei",
+	"owners.files.other": "%d Dateien",
+	"owners.unowned":     "(kein Eigentümer)",
+	"owners.none_loaded": "Keine Eigentümerregeln geladen, siehe --owners",
+
+	"suggest.title.one":       "Aufräumvorschläge: %d, %s freizugeben",
+	"suggest.title.other":     "Aufräumvorschläge: %d, %s freizugeben",
+	"suggest.none":            "Nichts vorzuschlagen im bisher Gescannten",
+	"suggest.logs.one":        "%d rotiertes Log älter als %d Tage",
+	"suggest.logs.other":      "%d rotierte Logs älter als %d Tage",
+	"suggest.package":         "%s-Cache",
+	"suggest.browser":         "%s-Cache, %s an Lesezeichen, Verlauf und anderen Profildaten bleiben",
+	"suggest.browser_running": "%s schließen, um den Cache aufzuräumen",
+	"suggest.trash.one":       "Papierkorb leeren: %d gelöschter Eintrag belegt noch Platz in %s",
+	"suggest.trash.other":     "Papierkorb leeren: %d gelöschte Einträge belegen noch Platz in %s",
+	"suggest.measuring":       "Caches und Papierkorb werden gemessen…",
+	"suggest.command_done":    "%s aufgeräumt",
+	"suggest.command_failed":  "Aufräumen von %s fehlgeschlagen: %v",
+
+	"cost.unconfigured": "Kein Speicherpreis konfiguriert, cost_per_gb_month oder storage_class setzen",
+
+	"diskusage.on":         "Zeige Belegung: die Blöcke, die Dateien auf der Platte belegen",
+	"diskusage.off":        "Zeige scheinbare Größen: die Summe der Dateigrößen",
+	"diskusage.unrecorded": "Für diesen Baum ist keine Belegung erfasst, nur Größen",
+
+	"note.saved":       "Notiz gespeichert",
+	"note.removed":     "Notiz entfernt",
+	"note.failed":      "Notiz konnte nicht gespeichert werden: %v",
+	"note.unavailable": "Notizen sind nicht verfügbar: kein Zustandsverzeichnis",
+
+	"delete.hidden":       "Nichts von der Auswahl passt zur Suche, nichts markiert",
+	"delete.failed.one":   "%d Eintrag konnte nicht gelöscht werden: %v",
+	"delete.failed.other": "%d Einträge konnten nicht gelöscht werden, der erste: %v",
+
+	"export.empty":      "Keine Auswahl zum Exportieren",
+	"export.failed":     "Export fehlgeschlagen: %v",
+	"export.done.one":   "%d Pfad nach %s geschrieben",
+	"export.done.other": "%d Pfade nach %s geschrieben",
+
+	"queue.title.one":   "Aufräum-Warteschlange: %d Eintrag, %s freigebbar",
+	"queue.title.other": "Aufräum-Warteschlange: %d Einträge, %s freigebbar",
+	"queue.empty":       "Nichts vorgemerkt. Mit x im Baum Einträge zum Löschen vormerken.",
+
+	"open.warning.one":   "%d markierte Datei ist in %s geöffnet, ihr Platz wird erst frei, wenn diese beendet sind",
+	"open.warning.other": "%d markierte Dateien sind in %s geöffnet, ihr Platz wird erst frei, wenn diese beendet sind",
+	"open.more":          "%s und %d weitere",
+
+	"reconcile.title":              "Belegung des Dateisystems und gescannte Summe",
+	"reconcile.loading":            "Dateisystem wird gelesen…",
+	"reconcile.usage_error":        "Belegung des Dateisystems nicht lesbar: %v",
+	"reconcile.filesystem":         "%s (%s) eingehängt unter %s",
+	"reconcile.used":               "Belegt laut Dateisystem (df)",
+	"reconcile.scanned":            "Gescannt unter %s",
+	"reconcile.more_used":          "Belegt, aber nicht im Scan",
+	"reconcile.more_scanned":       "Gescannt, aber nicht als belegt gezählt",
+	"reconcile.reasons":            "Mögliche Erklärungen:",
+	"reconcile.scanning":           "Der Scan läuft noch, die Summe wächst noch",
+	"reconcile.outside":            "Das Dateisystem ist unter %s eingehängt, was außerhalb des gescannten Ordners liegt, ist belegt, aber nicht gescannt",
+	"reconcile.nested.one":         "%d weiteres Dateisystem im Scan eingehängt, im Baum mitgezählt: %s",
+	"reconcile.nested.other":       "%d weitere Dateisysteme im Scan eingehängt, im Baum mitgezählt: %s",
+	"reconcile.unreadable.one":     "%d Ordner war nicht lesbar und ist nicht mitgezählt: %s",
+	"reconcile.unreadable.other":   "%d Ordner waren nicht lesbar und sind nicht mitgezählt, z. B. %s",
+	"reconcile.deleted_open.one":   "%d gelöschte Datei ist noch geöffnet, siehe --deleted-open",
+	"reconcile.deleted_open.other": "%d gelöschte Dateien sind noch geöffnet, siehe --deleted-open",
+	"reconcile.reserved":           "Für root reserviert, %.1f%% des Dateisystems (%d Bytes), weder belegt noch verfügbar",
+	"reconcile.reserved_tune":      "Reine Datendateisysteme brauchen wenig davon, verkleinern mit: %s (auf dem Root-Dateisystem des Systems beibehalten)",
+	"reconcile.snapshots_zfs":      "Von ZFS-Snapshots belegt",
+	"reconcile.snapshots":          "%s-Snapshots behalten gelöschte Daten, die im Baum nicht auftauchen",
+	"reconcile.none":               "Nichts gefunden, was einen Unterschied erklären würde",
+	"reconcile.apparent":           "dua zählt Dateigrößen, das Dateisystem belegte Blöcke: Sparse- und komprimierte Dateien belegen weniger, viele kleine Dateien mehr (u zeigt die Belegung)",
+
+	"review.title.one":   "Auswahl: %d Eintrag, %s",
+	"review.title.other": "Auswahl: %d Einträge, %s",
+	"review.none":        "Nichts ausgewählt",
+
+	"compare.title":      "Vergleich: %s │ %s • %s",
+	"compare.total":      "Gesamt",
+	"compare.name":       "Name",
+	"compare.left":       "Links",
+	"compare.right":      "Rechts",
+	"compare.delta":      "Differenz",
+	"compare.left_only":  "nur links",
+	"compare.right_only": "nur rechts",
+	"compare.empty":      "Auf beiden Seiten leer",
+	"compare.footer":     "↑↓/jk: navigieren • →l/enter: öffnen • ←h: zurück • s: nach Differenz sortieren • S: Sync-Vorschau • q: beenden",
+
+	"sync.title.one":     "Sync-Vorschau: %d Änderung, damit %[3]s %[2]s entspricht • %s zu kopieren, %s zu löschen",
+	"sync.title.other":   "Sync-Vorschau: %d Änderungen, damit %[3]s %[2]s entspricht • %s zu kopieren, %s zu löschen",
+	"sync.none":          "Nichts zu tun, die rechte Seite stimmt bereits überein",
+	"sync.copy":          "kopieren",
+	"sync.update":        "ersetzen",
+	"sync.delete":        "löschen",
+	"sync.deletes_on":    "d: überzählige Dateien behalten",
+	"sync.deletes_off":   "d: überzählige Dateien auch löschen",
+	"sync.confirm.one":   "%d Änderung auf %s anwenden? y: synchronisieren • n: abbrechen",
+	"sync.confirm.other": "%d Änderungen auf %s anwenden? y: synchronisieren • n: abbrechen",
+	"sync.running":       "Synchronisiere…",
+	"sync.done.one":      "Synchronisiert: %d Änderung vorgenommen",
+	"sync.done.other":    "Synchronisiert: %d Änderungen vorgenommen",
+	"sync.more_errors":   "und %d weitere Fehler",
+	"sync.unavailable":   "Synchronisieren geht nur, wenn beide Seiten Verzeichnisse auf diesem Rechner sind, r gibt stattdessen einen rsync-Befehl aus",
+	"footer.sync":        "↑↓/jk: navigieren • %s • r: rsync-Befehl ausgeben und beenden • enter: synchronisieren • esc/q: zurück",
+
+	"memory.degraded": "Speicherlimit von %s überschritten: eingeklappte Ordner tief im Baum behalten nur noch ihre Summen",
+	"memory.pruned":   "Inhalt wegen des Speicherlimits nicht behalten, nur die Summe",
+
+	"verify.running": "Prüfe %d Ordner gegen das Dateisystem…",
+	"verify.ok":      "Geprüft: %d Ordner stimmen mit dem Dateisystem überein",
+	"verify.failed":  "Prüfung: %d von %d Ordnern weichen vom Dateisystem ab, Liste beim Beenden",
+	"verify.none":    "Prüfung: keine vollständig gescannten Ordner zum Prüfen",
+
+	"rescan.started":      "Scanne erneut von oben",
+	"rescan.mtime":        "Scanne erneut von oben, nur Ordner mit geänderter Änderungszeit werden gelesen",
+	"rescan.reused.one":   "Erneuter Scan fertig, %d unveränderter Ordner wurde nicht erneut gelesen",
+	"rescan.reused.other": "Erneuter Scan fertig, %d unveränderte Ordner wurden nicht erneut gelesen",
+
+	"footer.bigdelete":       "%s ist %s groß. Zum Löschen den Namen eingeben: %s_ • enter: löschen • esc: abbrechen",
+	"bigdelete.others.one":   "%d weiterer großer Ordner",
+	"bigdelete.others.other": "%d weitere große Ordner",
+	"bigdelete.mismatch":     "Der Name stimmt nicht überein",
+	"bigdelete.cancelled":    "Löschen abgebrochen",
+
+	"peers.warning.one":   "Eine weitere dua-Sitzung ist in diesem Baum geöffnet (%[2]s, PID %[3]d, in %[4]s), sie sieht nicht, was hier gelöscht wird, und löscht womöglich dieselben Einträge",
+	"peers.warning.other": "%[1]d weitere dua-Sitzungen sind in diesem Baum geöffnet, etwa die von %[2]s (PID %[3]d, in %[4]s), sie sehen nicht, was hier gelöscht wird, und löschen womöglich dieselben Einträge",
+
+	"quick.stopped":   "Schnellscan nach %v beendet, geschätzt %d%% nicht gescannt • Ordner aufklappen, um ihn zu lesen, R: alles scannen",
+	"quick.partial":   "[~%d%% nicht gescannt]",
+	"quick.unscanned": "[nicht gescannt, ~%d%%]",
+
+	"sample.badge": "[±%s, %d von %d Dateien als Stichprobe]",
+
+	"links.counted_at": "[Hardlink, gezählt bei %s]",
+
+	"columns.title":      "Spalten (für diese Sitzung, columns in der Konfiguration behält sie)",
+	"columns.size":       "Größe",
+	"columns.percent":    "Anteil am übergeordneten Ordner",
+	"columns.count":      "Enthaltene Dateien und Ordner",
+	"columns.mtime":      "Änderungszeit",
+	"columns.cost":       "Monatliche Speicherkosten",
+	"columns.owner":      "Besitzender Benutzer",
+	"columns.perms":      "Berechtigungen",
+	"columns.links":      "Hardlinks einer Datei",
+	"columns.hidden":     "(ausgeblendet, m/c schaltet sie um)",
+	"columns.size_fixed": "Die Größenspalte wird immer angezeigt",
+
+	"help.title": "Tasten",
+
+	"notify.title":         "dua",
+	"notify.scanned":       "Scan von %s fertig: %s",
+	"notify.deleted.one":   "%d Element gelöscht, %s frei",
+	"notify.deleted.other": "%d Elemente gelöscht, %s frei",
+
+	"quit.marked.one":   "%d Eintrag markiert",
+	"quit.marked.other": "%d Einträge markiert",
+	"quit.queued.one":   "%d Vorgang in der Warteschlange",
+	"quit.queued.other": "%d Vorgänge in der Warteschlange",
+	"footer.quit":       "%s — trotzdem beenden? y: beenden • esc: zurück",
+	"footer.quit_queue": "%s — trotzdem beenden? y: beenden • r: erst Warteschlange ausführen • esc: zurück",
+
+	"choose.not_dir": "Kein Ordner",
+
+	"import.done": "%d Pfade importiert, %d übersprungen",
+
+	"summary.title":             "DUA-Sitzungsübersicht",
+	"summary.scanned":           "  Gescannt:  %d Dateien, %d Ordner, %s in %v",
+	"summary.interrupted":       "(abgebrochen)",
+	"summary.resume":            "  Fortsetzen: dua --path %s --resume",
+	"summary.errors":            "  Fehler:    %d",
+	"summary.verify":            "  Geprüft:   %d von %d Ordnern stimmen mit dem Dateisystem überein",
+	"summary.verify_diff":       "    %s: gescannt %d Bytes in %d Dateien, %d Ordnern; durchlaufen %d Bytes in %d Dateien, %d Ordnern",
+	"summary.verify_error":      "    %s: %v",
+	"summary.verify_unfinished": "  Geprüft:   beim Beenden noch nicht fertig",
+	"summary.deleted":           "  Gelöscht:  %d Einträge, %s freigegeben",
+	"summary.largest":           "  Größter:   %s (%s in eigenen Dateien)",
+
+	"time.just_now":      "gerade eben",
+	"time.minutes.one":   "vor %d Minute",
+	"time.minutes.other": "vor %d Minuten",
+	"time.hours.one":     "vor %d Stunde",
+	"time.hours.other":   "vor %d Stunden",
+	"time.days.one":      "vor %d Tag",
+	"time.days.other":    "vor %d Tagen",
+	"time.months.one":    "vor %d Monat",
+	"time.months.other":  "vor %d Monaten",
+	"time.years.one":     "vor %d Jahr",
+	"time.years.other":   "vor %d Jahren",
+}
@@ -0,0 +1,320 @@
+package i18n
+
+var english = Catalog{
+	"header.title":       "DUA - Disk Usage Analyzer | Path: %s | Sort: %s%s",
+	"header.scanning":    " | SCANNING: %d files, %d dirs, %s in %v",
+	"header.scanned":     " | SCANNED: %d files, %d dirs, %s",
+	"header.progress":    " | %s %d%%",
+	"header.counting":    " | counting…",
+	"header.eta":         " | ~%s left",
+	"header.degraded":    " | ⚠ over memory limit",
+	"header.disk_usage":  " | disk usage",
+	"header.kiosk":       " | kiosk",
+	"header.quick":       " | QUICK SCAN: ~%d%% unscanned",
+	"header.peers.one":   " | ⚠ %d other dua session in this tree",
+	"header.peers.other": " | ⚠ %d other dua sessions in this tree",
+	"header.cached":      "%s (cached %s)",
+
+	"sort.name": "Name",
+	"sort.date": "Date",
+	"sort.size": "Size",
+	"sort.type": "Type",
+
+	"row.loading": "Loading",
+
+	"footer.search":           "Search: %s_ • enter: confirm • esc: cancel",
+	"footer.note":             "Note: %s_ • enter: save (empty removes) • esc: cancel",
+	"footer.export":           "Export to: %s_ • .nul: NUL-separated • enter: write • esc: cancel",
+	"footer.export_overwrite": "Export to: %s_ • it already exists • enter: overwrite • esc: cancel",
+	"footer.rename":           "Rename: %s_ • enter: confirm • esc: cancel",
+	"footer.rename_error":     "Rename: %s_ • error: %s • enter: confirm • esc: cancel",
+	"footer.rename_overwrite": "Rename: %s_ • %s • enter: overwrite • esc: cancel",
+	"footer.marked":           "%d marked for deletion • d: DELETE • S: SHRED • esc: cancel",
+	"footer.shred":            "Shred %d items? Files are overwritten before deletion, but SSDs may keep copies of old data • S: confirm • esc: cancel",
+	"footer.shred_cow":        "Shred %d items? This filesystem is copy-on-write, so overwriting won't reach the original data • S: confirm anyway • esc: cancel",
+	"footer.filtered":         "Filtered: '%s' • /: search • esc: clear • ↑↓/jk: navigate • →l: expand • ←h: collapse • q: quit",
+	"footer.default":          "/: search • ↑↓/jk: navigate • →l: expand • ←h: collapse • enter: view file • t: select • r: rename • n: note • O: owners • L: columns • e: export • x: queue • Q: queue screen • C: cleanup suggestions • D: df vs. scan • R: rescan • d: delete • s: sort • ctrl+s: reverse sort • m/M: dates • a: age colors • c: cost • u: disk usage • p: preview • q: quit",
+	"footer.readonly":         "/: search • ↑↓/jk: navigate • →l: expand • ←h: collapse • t: select • n: note • O: owners • L: columns • e: export • s: sort • ctrl+s: reverse sort • m/M: dates • a: age colors • c: cost • u: disk usage • q: quit (read-only)",
+	"footer.pager":            "↑↓/jk: scroll • pgup/pgdn: page • g/G: top/bottom • esc/q: close",
+	"footer.owners":           "↑↓/jk: scroll • esc/q: back",
+	"footer.help":             "esc/q/?: back",
+	"footer.more":             "?: more",
+	"footer.columns":          "↑↓/jk: move • space: show/hide • J/K: reorder • esc/q: back",
+	"footer.suggest":          "↑↓/jk: navigate • enter: clean up • esc/q: back",
+	"footer.reconcile":        "esc/q: back",
+	"footer.review":           "↑↓/jk: navigate • t: unselect • x: queue all • d: delete all • esc/q: back",
+	"footer.review_confirm":   "Delete %d selected items (%s)? y: delete • n: cancel",
+	"footer.suggest_confirm":  "%s: delete to free %s? y: clean up • n: cancel",
+	"footer.suggest_run":      "%s: run %s to free about %s? y: run • n: cancel",
+	"footer.queue":            "↑↓/jk: navigate • x: remove • enter: run queue • esc: back • q: quit",
+	"footer.queue_confirm":    "Delete %d queued items (%s)? y: delete • n: cancel",
+	"footer.queue_quit":       "Run the cleanup queue before quitting? %d items (%s) • y: delete and quit • n: quit without deleting • esc: back",
+	"footer.choose_dir":       "enter: choose directory • /: search • ↑↓/jk: navigate • →l: expand • ←h: collapse • esc/q: cancel",
+	"footer.choose_file":      "enter: choose file • /: search • ↑↓/jk: navigate • →l: expand • ←h: collapse • esc/q: cancel",
+	"footer.tutorial":         "enter: next • ←h: back • esc: skip tutorial",
+	"footer.selected":         "%d selected • V: review • ",
+	"footer.selected_cost":    "%d selected, %s/month • V: review • ",
+
+	"rename.exists":    "'%s' already exists",
+	"rename.empty":     "name cannot be empty",
+	"rename.invalid":   "'%s' is not a valid name",
+	"rename.separator": "name cannot contain path separators",
+	"rename.control":   "name cannot contain control characters",
+	"rename.nul":       "name cannot contain NUL characters",
+	"rename.utf8":      "name must be valid UTF-8",
+
+	"error.load":       "Could not load %s: %v",
+	"readonly.refused": "Read-only: this tree was loaded from an export",
+
+	"kiosk.refused":   "Kiosk mode: %s can't be changed, only what's below %s",
+	"kiosk.read_only": "Kiosk mode: browsing only, %s can't be changed",
+	"kiosk.commands":  "Kiosk mode: cleanup commands are disabled",
+
+	"tutorial.welcome.title":     "Welcome to DUA",
+	"tutorial.welcome.body":      "This short tour shows the keys you need to find and clean up\nwhat is using your disk. It won't be shown again.",
+	"tutorial.navigate.title":    "Navigating the tree",
+	"tutorial.navigate.body":     "Directories fill in while the scan runs in the background.",
+	"tutorial.navigate.move":     "move up and down",
+	"tutorial.navigate.expand":   "expand a directory",
+	"tutorial.navigate.collapse": "collapse a directory",
+	"tutorial.navigate.jump":     "jump to the top or bottom",
+	"tutorial.search.title":      "Searching",
+	"tutorial.search.body":       "Search matches names fuzzily across the whole tree.",
+	"tutorial.search.start":      "start typing a search",
+	"tutorial.search.keep":       "keep the filter and browse the results",
+	"tutorial.search.clear":      "clear the filter",
+	"tutorial.mark.title":        "Selecting items",
+	"tutorial.mark.body":         "Select several items, in any directories, to act on them together. The selection stays as you move around.",
+	"tutorial.mark.toggle":       "select or unselect the current item",
+	"tutorial.mark.visual":       "select a range as you move",
+	"tutorial.mark.review":       "review the selection, across all directories",
+	"tutorial.delete.title":      "Deleting",
+	"tutorial.delete.body":       "Deletion always takes two steps, so nothing goes by accident.",
+	"tutorial.delete.mark":       "mark the selection, or the current item",
+	"tutorial.delete.confirm":    "press again to delete everything marked",
+	"tutorial.delete.cancel":     "unmark and cancel",
+	"tutorial.done.title":        "You're all set",
+	"tutorial.done.body":         "Run dua --tutorial to see this tour again.",
+	"tutorial.done.sort":         "change the sort order",
+	"tutorial.done.quit":         "quit",
+	"tutorial.progress":          "step %d of %d",
+
+	"media.title":   "Similar images: %d groups",
+	"media.empty":   "No similar images found",
+	"media.group":   "Group %d • %d images • %s reclaimable",
+	"media.footer":  "↑↓/jk: navigate • t: mark • a: mark all but largest • d: delete • esc: clear • q: quit",
+	"media.confirm": "Delete %d marked files? d: confirm • any other key: cancel",
+	"media.deleted": "%d deleted, %d failed",
+
+	"preview.details":   "%s • modified %s",
+	"preview.file":      "File",
+	"preview.directory": "Directory",
+	"preview.image":     "%s image, %d×%d",
+	"preview.video":     "%s video",
+	"preview.audio":     "%s audio",
+	"preview.taken":     "taken %s",
+	"preview.error":     "Can't read: %v",
+	"preview.note":      "%s %s",
+	"preview.duplicate": "%s mounted again from %s, counted there instead",
+	"preview.open":      "%s open in %s",
+
+	"pager.truncated": "first %s shown",
+	"pager.binary":    "Binary file, not shown",
+
+	"backup.label":                 "(%s repository)",
+	"backup.label_stats":           "(%s repository, %s backed up)",
+	"backup.loading":               "%s repository • reading stats…",
+	"backup.error":                 "%s repository • no stats: %v",
+	"backup.stats":                 "%s repository • %s backed up in %s stored (%.1f× smaller)",
+	"backup.stats_snapshots.one":   "%[2]s repository • %[1]d snapshot, %[3]s backed up in %[4]s stored (%.1f× smaller)",
+	"backup.stats_snapshots.other": "%[2]s repository • %[1]d snapshots, %[3]s backed up in %[4]s stored (%.1f× smaller)",
+
+	"owners.title.one":   "Usage by owner: %d owner, %s",
+	"owners.title.other": "Usage by owner: %d owners, %s",
+	"owners.files.one":   "%d file",
+	"owners.files.other": "%d files",
+	"owners.unowned":     "(no owner)",
+	"owners.none_loaded": "No owner rules loaded, see --owners",
+
+	"suggest.title.one":       "Cleanup suggestions: %d, %s to free",
+	"suggest.title.other":     "Cleanup suggestions: %d, %s to free",
+	"suggest.none":            "Nothing to suggest in what's been scanned",
+	"suggest.logs.one":        "%d rotated log older than %d days",
+	"suggest.logs.other":      "%d rotated logs older than %d days",
+	"suggest.package":         "%s cache",
+	"suggest.browser":         "%s cache, keeping %s of bookmarks, history and other profile data",
+	"suggest.browser_running": "Close %s to clean up its cache",
+	"suggest.trash.one":       "Empty the trash: %d deleted item still taking up space in %s",
+	"suggest.trash.other":     "Empty the trash: %d deleted items still taking up space in %s",
+	"suggest.measuring":       "Measuring caches and the trash…",
+	"suggest.command_done":    "%s cleaned",
+	"suggest.command_failed":  "Cleaning %s failed: %v",
+
+	"cost.unconfigured": "No storage rate configured, set cost_per_gb_month or storage_class",
+
+	"diskusage.on":         "Showing disk usage: the blocks files take on disk",
+	"diskusage.off":        "Showing apparent sizes: the sum of file sizes",
+	"diskusage.unrecorded": "This tree has no disk usage recorded, only sizes",
+
+	"note.saved":       "Note saved",
+	"note.removed":     "Note removed",
+	"note.failed":      "Could not save note: %v",
+	"note.unavailable": "Notes are unavailable: no state directory",
+
+	"delete.hidden":       "None of the selection matches the search, nothing marked",
+	"delete.failed.one":   "Couldn't delete %d item: %v",
+	"delete.failed.other": "Couldn't delete %d items, the first: %v",
+
+	"export.empty":      "Nothing selected to export",
+	"export.failed":     "Export failed: %v",
+	"export.done.one":   "Wrote %d path to %s",
+	"export.done.other": "Wrote %d paths to %s",
+
+	"queue.title.one":   "Cleanup queue: %d item, %s reclaimable",
+	"queue.title.other": "Cleanup queue: %d items, %s reclaimable",
+	"queue.empty":       "Nothing queued. Press x on items in the tree to queue them for deletion.",
+
+	"open.warning.one":   "%d marked file is open in %s, so its space is only freed once they exit",
+	"open.warning.other": "%d marked files are open in %s, so their space is only freed once they exit",
+	"open.more":          "%s and %d more",
+
+	"reconcile.title":              "Filesystem usage vs. scanned total",
+	"reconcile.loading":            "Reading the filesystem…",
+	"reconcile.usage_error":        "Can't read the filesystem's usage: %v",
+	"reconcile.filesystem":         "%s (%s) mounted at %s",
+	"reconcile.used":               "Used according to the filesystem (df)",
+	"reconcile.scanned":            "Scanned under %s",
+	"reconcile.more_used":          "Used but not in the scan",
+	"reconcile.more_scanned":       "Scanned but not counted as used",
+	"reconcile.reasons":            "Likely explanations:",
+	"reconcile.scanning":           "The scan is still running, so its total is still growing",
+	"reconcile.outside":            "The filesystem is mounted at %s, so what's outside the scanned directory is used but not scanned",
+	"reconcile.nested.one":         "%d other filesystem mounted inside the scan, counted in the tree: %s",
+	"reconcile.nested.other":       "%d other filesystems mounted inside the scan, counted in the tree: %s",
+	"reconcile.unreadable.one":     "%d directory couldn't be read and isn't counted: %s",
+	"reconcile.unreadable.other":   "%d directories couldn't be read and aren't counted, e.g. %s",
+	"reconcile.deleted_open.one":   "%d deleted file still held open, see --deleted-open",
+	"reconcile.deleted_open.other": "%d deleted files still held open, see --deleted-open",
+	"reconcile.reserved":           "Reserved for root, %.1f%% of the filesystem (%d bytes), neither used nor available",
+	"reconcile.reserved_tune":      "Data-only filesystems need little of it, lower it with: %s (keep it on the system's root filesystem)",
+	"reconcile.snapshots_zfs":      "Held by ZFS snapshots",
+	"reconcile.snapshots":          "%s snapshots keep deleted data that doesn't show up in the tree",
+	"reconcile.none":               "Nothing found that would explain a difference",
+	"reconcile.apparent":           "dua counts file sizes, the filesystem allocated blocks: sparse and compressed files take less, many small files more (u shows disk usage)",
+
+	"review.title.one":   "Selection: %d item, %s",
+	"review.title.other": "Selection: %d items, %s",
+	"review.none":        "Nothing selected",
+
+	"compare.title":      "Compare: %s │ %s • %s",
+	"compare.total":      "Total",
+	"compare.name":       "Name",
+	"compare.left":       "Left",
+	"compare.right":      "Right",
+	"compare.delta":      "Difference",
+	"compare.left_only":  "left only",
+	"compare.right_only": "right only",
+	"compare.empty":      "Empty on both sides",
+	"compare.footer":     "↑↓/jk: navigate • →l/enter: open • ←h: back • s: sort by difference • S: sync preview • q: quit",
+
+	"sync.title.one":     "Sync preview: %d change to make %[3]s match %[2]s • %s to copy, %s to delete",
+	"sync.title.other":   "Sync preview: %d changes to make %[3]s match %[2]s • %s to copy, %s to delete",
+	"sync.none":          "Nothing to do, the right side already matches",
+	"sync.copy":          "copy",
+	"sync.update":        "update",
+	"sync.delete":        "delete",
+	"sync.deletes_on":    "d: keep extra files",
+	"sync.deletes_off":   "d: also delete extra files",
+	"sync.confirm.one":   "Apply %d change to %s? y: sync • n: cancel",
+	"sync.confirm.other": "Apply %d changes to %s? y: sync • n: cancel",
+	"sync.running":       "Syncing…",
+	"sync.done.one":      "Synced: %d change made",
+	"sync.done.other":    "Synced: %d changes made",
+	"sync.more_errors":   "and %d more errors",
+	"sync.unavailable":   "Syncing needs both sides to be directories on this machine, press r for an rsync command instead",
+	"footer.sync":        "↑↓/jk: navigate • %s • r: print rsync command and quit • enter: sync • esc/q: back",
+
+	"memory.degraded": "Over the memory limit of %s: collapsed directories deep in the tree now only keep their totals",
+	"memory.pruned":   "Contents not kept to stay under the memory limit, only the total",
+
+	"verify.running": "Verifying %d directories against the filesystem…",
+	"verify.ok":      "Verified: %d directories match the filesystem",
+	"verify.failed":  "Verify: %d of %d directories differ from the filesystem, listed on exit",
+	"verify.none":    "Verify: no fully scanned directories to check",
+
+	"rescan.started":      "Rescanning from the top",
+	"rescan.mtime":        "Rescanning from the top, reading only directories whose modification time changed",
+	"rescan.reused.one":   "Rescan done, %d unchanged directory wasn't read again",
+	"rescan.reused.other": "Rescan done, %d unchanged directories weren't read again",
+
+	"footer.bigdelete":       "%s is %s. Type its name to delete it: %s_ • enter: delete • esc: cancel",
+	"bigdelete.others.one":   "%d more large directory",
+	"bigdelete.others.other": "%d more large directories",
+	"bigdelete.mismatch":     "The name doesn't match",
+	"bigdelete.cancelled":    "Deletion cancelled",
+
+	"peers.warning.one":   "Another dua session is open in this tree (%[2]s, pid %[3]d, at %[4]s), it won't see what's deleted here and may delete the same items",
+	"peers.warning.other": "%[1]d other dua sessions are open in this tree, such as %[2]s's (pid %[3]d, at %[4]s), they won't see what's deleted here and may delete the same items",
+
+	"quick.stopped":   "Quick scan stopped after %v with an estimated %d%% unscanned • expand a directory to read it, R: scan everything",
+	"quick.partial":   "[~%d%% unscanned]",
+	"quick.unscanned": "[unscanned, ~%d%%]",
+
+	"sample.badge": "[±%s, sampled %d of %d files]",
+
+	"links.counted_at": "[hard link, counted at %s]",
+
+	"columns.title":      "Columns (for this session, set columns in the config to keep them)",
+	"columns.size":       "Size",
+	"columns.percent":    "Share of the parent directory",
+	"columns.count":      "Files and directories inside",
+	"columns.mtime":      "Modified time",
+	"columns.cost":       "Monthly storage cost",
+	"columns.owner":      "Owning user",
+	"columns.perms":      "Permissions",
+	"columns.links":      "Hard links to a file",
+	"columns.hidden":     "(hidden, m/c toggles it)",
+	"columns.size_fixed": "The size column is always shown",
+
+	"help.title": "Keys",
+
+	"notify.title":         "dua",
+	"notify.scanned":       "Finished scanning %s: %s",
+	"notify.deleted.one":   "Deleted %d item, freeing %s",
+	"notify.deleted.other": "Deleted %d items, freeing %s",
+
+	"quit.marked.one":   "%d item marked",
+	"quit.marked.other": "%d items marked",
+	"quit.queued.one":   "%d operation queued",
+	"quit.queued.other": "%d operations queued",
+	"footer.quit":       "%s — quit anyway? y: quit • esc: back",
+	"footer.quit_queue": "%s — quit anyway? y: quit • r: run queue first • esc: back",
+
+	"choose.not_dir": "Not a directory",
+
+	"import.done": "Imported %d paths, %d skipped",
+
+	"summary.title":             "DUA session summary",
+	"summary.scanned":           "  Scanned:  %d files, %d dirs, %s in %v",
+	"summary.interrupted":       "(interrupted)",
+	"summary.resume":            "  Resume:   dua --path %s --resume",
+	"summary.errors":            "  Errors:   %d",
+	"summary.verify":            "  Verified: %d of %d directories match the filesystem",
+	"summary.verify_diff":       "    %s: scanned %d bytes in %d files, %d dirs; walked %d bytes in %d files, %d dirs",
+	"summary.verify_error":      "    %s: %v",
+	"summary.verify_unfinished": "  Verified: still walking at exit",
+	"summary.deleted":           "  Deleted:  %d items, %s reclaimed",
+	"summary.largest":           "  Largest:  %s (%s in its own files)",
+
+	"time.just_now":      "just now",
+	"time.minutes.one":   "%d minute ago",
+	"time.minutes.other": "%d minutes ago",
+	"time.hours.one":     "%d hour ago",
+	"time.hours.other":   "%d hours ago",
+	"time.days.one":      "%d day ago",
+	"time.days.other":    "%d days ago",
+	"time.months.one":    "%d month ago",
+	"time.months.other":  "%d months ago",
+	"time.years.one":     "%d year ago",
+	"time.years.other":   "%d years ago",
+}
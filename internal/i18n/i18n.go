@@ -0,0 +1,57 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Catalog maps message keys to fmt format strings.
+type Catalog map[string]string
+
+// catalogs holds the bundled translations by language. English is complete
+// and used for any key a translation is missing.
+var catalogs = map[string]Catalog{
+	"en": english,
+	"de": german,
+}
+
+// Translator looks up UI strings for one locale.
+type Translator struct {
+	catalog Catalog
+}
+
+// New returns a Translator for locale, e.g. "de-DE". Only the language part
+// is used; unknown languages get English.
+func New(locale string) *Translator {
+	language, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	if catalog, ok := catalogs[language]; ok {
+		return &Translator{catalog: catalog}
+	}
+	return &Translator{catalog: english}
+}
+
+// T formats the message for key with args.
+func (t *Translator) T(key string, args ...any) string {
+	format, ok := t.catalog[key]
+	if !ok {
+		if format, ok = english[key]; !ok {
+			return key
+		}
+	}
+
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// N formats the singular or plural form of key for the count n, which is
+// passed as the first format argument.
+func (t *Translator) N(key string, n int, args ...any) string {
+	if n == 1 {
+		key += ".one"
+	} else {
+		key += ".other"
+	}
+	return t.T(key, append([]any{n}, args...)...)
+}
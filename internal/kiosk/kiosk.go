@@ -0,0 +1,126 @@
+// Package kiosk restricts dua for self-service use on shared servers. An
+// admin lists in a file owned by root which directories may be scanned and
+// below which directories things may be deleted or renamed; everything else
+// is browse-only. It guards the installed dua, it doesn't replace file
+// permissions: a user running their own copy isn't bound by it.
+package kiosk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+
+	"github.com/corpeningc/dua/internal/paths"
+)
+
+// Policy is a kiosk configuration. A nil Policy allows everything, so
+// callers can check it whether or not kiosk mode is on.
+type Policy struct {
+	// Roots are the directories that may be scanned, with everything below
+	// them.
+	Roots []string `json:"roots"`
+	// Writable are the directories below which deleting, renaming and
+	// cleaning up are allowed. $USER and $HOME stand for the user running
+	// dua, e.g. "/scratch/$USER".
+	Writable []string `json:"writable"`
+}
+
+// Load reads the kiosk configuration, returning nil if there is none and
+// kiosk mode is off. A configuration anyone but root could have written is
+// an error rather than ignored, so it can't be turned off by tampering.
+// Root, who writes it, isn't restricted.
+func Load() (*Policy, error) {
+	if File == "" || os.Geteuid() == 0 {
+		return nil, nil
+	}
+	return load(File)
+}
+
+func load(file string) (*Policy, error) {
+	info, err := os.Stat(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range []string{file, filepath.Dir(file)} {
+		if err := trusted(path); err != nil {
+			return nil, fmt.Errorf("kiosk config %s: %w", file, err)
+		}
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("kiosk config %s: not a regular file", file)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("kiosk config %s: %w", file, err)
+	}
+	if len(policy.Roots) == 0 {
+		return nil, fmt.Errorf("kiosk config %s: no roots allowed", file)
+	}
+
+	// The user's name and home come from their ID, the environment is theirs
+	// to set
+	u, err := user.LookupId(strconv.Itoa(os.Getuid()))
+	if err != nil {
+		return nil, fmt.Errorf("kiosk config %s: %w", file, err)
+	}
+	expand := func(dirs []string) []string {
+		var expanded []string
+		for _, dir := range dirs {
+			dir = os.Expand(dir, func(name string) string {
+				switch name {
+				case "USER":
+					return u.Username
+				case "HOME":
+					return u.HomeDir
+				}
+				return ""
+			})
+			if !filepath.IsAbs(dir) {
+				continue
+			}
+			// Scan roots have their links resolved, so the directories
+			// allowed must too for them to match
+			if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+				dir = resolved
+			}
+			expanded = append(expanded, filepath.Clean(dir))
+		}
+		return expanded
+	}
+	policy.Roots = expand(policy.Roots)
+	policy.Writable = expand(policy.Writable)
+	return &policy, nil
+}
+
+// CanScan reports whether path may be scanned.
+func (p *Policy) CanScan(path string) bool {
+	return p == nil || withinAny(path, p.Roots)
+}
+
+// CanChange reports whether path may be deleted or renamed.
+func (p *Policy) CanChange(path string) bool {
+	return p == nil || withinAny(path, p.Writable)
+}
+
+// withinAny reports whether path is one of dirs or below one of them.
+func withinAny(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if paths.Within(path, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+package kiosk
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// File is where admins configure kiosk mode.
+const File = "/etc/dua/kiosk.json"
+
+// trusted checks that only root could have written path.
+func trusted(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return errors.New("owner unknown")
+	}
+	if stat.Uid != 0 {
+		return errors.New("must be owned by root")
+	}
+	if info.Mode().Perm()&0o022 != 0 {
+		return errors.New("must not be writable by group or others")
+	}
+	return nil
+}
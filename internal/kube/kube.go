@@ -0,0 +1,292 @@
+// Package kube measures persistent volume claims in a Kubernetes cluster
+// through kubectl: their usage from the kubelets' volume stats, and their
+// contents by running find in a pod that mounts them.
+package kube
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/corpeningc/dua/internal/treefile"
+)
+
+// Client runs kubectl with global flags such as --context or --kubeconfig.
+type Client struct {
+	Flags []string
+}
+
+// ErrNoKubectl is returned when kubectl isn't installed.
+var ErrNoKubectl = errors.New("kubectl not found in PATH")
+
+// Available checks that kubectl can be run.
+func Available() error {
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return ErrNoKubectl
+	}
+	return nil
+}
+
+// Claim is the usage of a persistent volume claim as its kubelet reports it.
+type Claim struct {
+	Namespace string
+	Name      string
+	Used      int64
+	Capacity  int64
+	Available int64
+	Inodes    int64
+	Node      string
+}
+
+// Full is how full the claim's volume is, from 0 to 1.
+func (c Claim) Full() float64 {
+	if c.Capacity <= 0 {
+		return 0
+	}
+	return float64(c.Used) / float64(c.Capacity)
+}
+
+// summary is the part of the kubelet's /stats/summary that has volumes.
+type summary struct {
+	Pods []struct {
+		Volumes []struct {
+			UsedBytes      int64 `json:"usedBytes"`
+			CapacityBytes  int64 `json:"capacityBytes"`
+			AvailableBytes int64 `json:"availableBytes"`
+			InodesUsed     int64 `json:"inodesUsed"`
+			PVCRef         *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// run runs kubectl and returns its output, with its error message on failure.
+func (c Client) run(args ...string) ([]byte, error) {
+	cmd := exec.Command("kubectl", append(append([]string(nil), c.Flags...), args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("kubectl %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("kubectl %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// Claims returns the usage of every mounted claim in namespace, or in all
+// namespaces if it's empty, fullest first. Claims no running pod mounts have
+// no stats and are left out.
+func (c Client) Claims(namespace string) ([]Claim, error) {
+	out, err := c.run("get", "nodes", "-o", "jsonpath={.items[*].metadata.name}")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var claims []Claim
+	for _, node := range strings.Fields(string(out)) {
+		raw, err := c.run("get", "--raw", "/api/v1/nodes/"+node+"/proxy/stats/summary")
+		if err != nil {
+			return nil, err
+		}
+		var stats summary
+		if err := json.Unmarshal(raw, &stats); err != nil {
+			return nil, fmt.Errorf("stats of node %s: %w", node, err)
+		}
+
+		for _, pod := range stats.Pods {
+			for _, volume := range pod.Volumes {
+				ref := volume.PVCRef
+				if ref == nil || (namespace != "" && ref.Namespace != namespace) {
+					continue
+				}
+				// Claims mounted by several pods are reported by each
+				key := ref.Namespace + "/" + ref.Name
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				claims = append(claims, Claim{
+					Namespace: ref.Namespace,
+					Name:      ref.Name,
+					Used:      volume.UsedBytes,
+					Capacity:  volume.CapacityBytes,
+					Available: volume.AvailableBytes,
+					Inodes:    volume.InodesUsed,
+					Node:      node,
+				})
+			}
+		}
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		if claims[i].Full() != claims[j].Full() {
+			return claims[i].Full() > claims[j].Full()
+		}
+		return claims[i].Namespace+"/"+claims[i].Name < claims[j].Namespace+"/"+claims[j].Name
+	})
+	return claims, nil
+}
+
+// Mount is where a running pod mounts a claim.
+type Mount struct {
+	Pod       string
+	Container string
+	Path      string
+}
+
+// podList is the part of a pod list needed to find mounts.
+type podList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Volumes []struct {
+				Name                  string `json:"name"`
+				PersistentVolumeClaim *struct {
+					ClaimName string `json:"claimName"`
+				} `json:"persistentVolumeClaim"`
+			} `json:"volumes"`
+			Containers []struct {
+				Name         string `json:"name"`
+				VolumeMounts []struct {
+					Name      string `json:"name"`
+					MountPath string `json:"mountPath"`
+					SubPath   string `json:"subPath"`
+				} `json:"volumeMounts"`
+			} `json:"containers"`
+		} `json:"spec"`
+		Status struct {
+			Phase string `json:"phase"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// FindMount finds a running pod with a container that mounts all of the
+// claim, as opposed to a subPath of it.
+func (c Client) FindMount(namespace, claim string) (Mount, error) {
+	out, err := c.run("get", "pods", "-n", namespace, "-o", "json")
+	if err != nil {
+		return Mount{}, err
+	}
+	var pods podList
+	if err := json.Unmarshal(out, &pods); err != nil {
+		return Mount{}, fmt.Errorf("pods in %s: %w", namespace, err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != "Running" {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != claim {
+				continue
+			}
+			for _, container := range pod.Spec.Containers {
+				for _, mount := range container.VolumeMounts {
+					if mount.Name == volume.Name && mount.SubPath == "" {
+						return Mount{Pod: pod.Metadata.Name, Container: container.Name, Path: mount.MountPath}, nil
+					}
+				}
+			}
+		}
+	}
+	return Mount{}, fmt.Errorf("no running pod in %s mounts %s", namespace, claim)
+}
+
+// scanScript lists every directory and file below $1 on its filesystem, one
+// per line as "d MTIME PATH" or "f SIZE MTIME PATH". It only needs find and
+// stat, which busybox images have too. Directories that can't be read are
+// skipped rather than failing the scan.
+const scanScript = `find "$1" -xdev -type d -exec stat -c 'd %Y %n' {} + 2>/dev/null; find "$1" -xdev -type f -exec stat -c 'f %s %Y %n' {} + 2>/dev/null; exit 0`
+
+// Scan lists the contents of a claim from inside the pod that mounts it. The
+// document's root is /NAMESPACE/CLAIM, so scans of several claims merge into
+// one tree by namespace.
+func (c Client) Scan(namespace, claim string, mount Mount) (treefile.Document, error) {
+	out, err := c.run("exec", "-n", namespace, mount.Pod, "-c", mount.Container, "--", "sh", "-c", scanScript, "sh", mount.Path)
+	if err != nil {
+		return treefile.Document{}, err
+	}
+
+	doc := treefile.Document{
+		Version: treefile.Version,
+		Root:    "/" + namespace + "/" + claim,
+		Scanned: time.Now(),
+		Tree:    &treefile.Dir{Name: claim},
+	}
+	dirs := map[string]*treefile.Dir{".": doc.Tree}
+	var dirAt func(rel string) *treefile.Dir
+	dirAt = func(rel string) *treefile.Dir {
+		if dir := dirs[rel]; dir != nil {
+			return dir
+		}
+		parent := dirAt(path.Dir(rel))
+		dir := &treefile.Dir{Name: path.Base(rel)}
+		parent.Dirs = append(parent.Dirs, dir)
+		dirs[rel] = dir
+		return dir
+	}
+
+	lines := bufio.NewScanner(bytes.NewReader(out))
+	lines.Buffer(nil, 1<<20)
+	for lines.Scan() {
+		kind, size, mtime, name, err := parseLine(lines.Text())
+		if err != nil {
+			return treefile.Document{}, fmt.Errorf("scanning %s/%s: %w", namespace, claim, err)
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, path.Clean(mount.Path)), "/")
+		if rel == "" {
+			rel = "."
+		}
+
+		switch kind {
+		case "d":
+			dirAt(rel).ModTime = mtime
+		case "f":
+			dir := dirAt(path.Dir(rel))
+			dir.Files = append(dir.Files, treefile.File{Name: path.Base(rel), Size: size, ModTime: mtime})
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return treefile.Document{}, err
+	}
+	return doc, nil
+}
+
+// parseLine splits a line of scanScript output.
+func parseLine(line string) (kind string, size int64, mtime time.Time, name string, err error) {
+	fields := 3
+	if strings.HasPrefix(line, "f ") {
+		fields = 4
+	}
+	parts := strings.SplitN(line, " ", fields)
+	if len(parts) != fields {
+		return "", 0, time.Time{}, "", fmt.Errorf("unexpected output %q", line)
+	}
+
+	kind, name = parts[0], parts[fields-1]
+	if kind == "f" {
+		if size, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return "", 0, time.Time{}, "", err
+		}
+	}
+	seconds, err := strconv.ParseInt(parts[fields-2], 10, 64)
+	if err != nil {
+		return "", 0, time.Time{}, "", err
+	}
+	return kind, size, time.Unix(seconds, 0), name, nil
+}
@@ -0,0 +1,68 @@
+// Package logfiles recognizes logs that have been rotated out of use, which
+// are safe to delete once they're old enough, unlike the live log beside
+// them.
+package logfiles
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Kind is a type of rotated log.
+type Kind int
+
+const (
+	None Kind = iota
+	// Rotated is a log renamed by logrotate or newsyslog, e.g. app.log.1,
+	// syslog.2.gz or app.log-20240301.
+	Rotated
+	// Journal is a systemd journal file archived after rotation or an
+	// unclean shutdown. The active system.journal is left alone.
+	Journal
+)
+
+var (
+	// numbered matches rotation by number: app.log.1, syslog.3.gz
+	numbered = regexp.MustCompile(`^(.+)\.\d{1,3}(\.(gz|bz2|xz|zst|lz4|Z))?$`)
+	// dated matches rotation by date: app.log-20240301, app.log.2024-03-01.gz
+	dated = regexp.MustCompile(`^(.+)[.-](\d{8}|\d{4}-\d{2}-\d{2})(\.(gz|bz2|xz|zst|lz4|Z))?$`)
+	// compressed matches logs compressed in place, e.g. app.log.gz
+	compressed = regexp.MustCompile(`^(.+\.log)\.(gz|bz2|xz|zst|lz4|Z)$`)
+)
+
+// Classify tells whether the file at path is a rotated log. Numbered and
+// dated names only count for .log files or inside a log directory, so
+// backup.1 or report-2024-03-01 elsewhere aren't taken for logs.
+func Classify(path string) Kind {
+	name := filepath.Base(path)
+	if strings.HasSuffix(name, ".journal~") || (strings.HasSuffix(name, ".journal") && strings.Contains(name, "@")) {
+		return Journal
+	}
+	if compressed.MatchString(name) {
+		return Rotated
+	}
+
+	var base string
+	if match := numbered.FindStringSubmatch(name); match != nil {
+		base = match[1]
+	} else if match := dated.FindStringSubmatch(name); match != nil {
+		base = match[1]
+	} else {
+		return None
+	}
+	if strings.HasSuffix(base, ".log") || inLogDir(path) {
+		return Rotated
+	}
+	return None
+}
+
+// inLogDir reports whether any directory above path is named log or logs.
+func inLogDir(path string) bool {
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir = strings.ToLower(dir); dir == "log" || dir == "logs" {
+			return true
+		}
+	}
+	return false
+}
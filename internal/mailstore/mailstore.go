@@ -0,0 +1,264 @@
+// Package mailstore finds Maildir folders and mbox files and reads their
+// messages, to count them and find the attachments taking up the space.
+package mailstore
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Format is how a store keeps its messages.
+type Format string
+
+const (
+	Maildir Format = "Maildir"
+	Mbox    Format = "mbox"
+)
+
+// Store is a Maildir folder or an mbox file.
+type Store struct {
+	Path     string
+	Format   Format
+	Messages int
+	Size     int64
+}
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	Name    string
+	Size    int64 // As stored, so base64 attachments count their encoding
+	Subject string
+	Date    time.Time
+	// Where the message is: a file in a Maildir, or an mbox and the
+	// message's position in it, counting from 1
+	Path  string
+	Index int
+}
+
+// Find walks root for mail stores. Maildir folders are directories with
+// cur, new and tmp; mbox files have no extension or .mbox and start with a
+// "From " line.
+func Find(root string) ([]Store, error) {
+	var stores []Store
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+
+		if entry.IsDir() {
+			if isMaildir(path) {
+				stores = append(stores, Store{Path: path, Format: Maildir})
+			} else if name := entry.Name(); (name == "cur" || name == "new" || name == "tmp") && isMaildir(filepath.Dir(path)) {
+				// Messages, not stores, and there can be a lot of them
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() && isMbox(path) {
+			stores = append(stores, Store{Path: path, Format: Mbox})
+		}
+		return nil
+	})
+	return stores, err
+}
+
+func isMaildir(path string) bool {
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if info, err := os.Stat(filepath.Join(path, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+func isMbox(path string) bool {
+	if ext := filepath.Ext(path); ext != "" && ext != ".mbox" && ext != ".mbx" {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head := make([]byte, 5)
+	_, err = io.ReadFull(f, head)
+	return err == nil && string(head) == "From "
+}
+
+// Read counts the messages in store and calls attachment for each file
+// attached to them. Messages that don't parse still count.
+func Read(store *Store, attachment func(Attachment)) error {
+	if store.Format == Maildir {
+		return readMaildir(store, attachment)
+	}
+	return readMbox(store, attachment)
+}
+
+func readMaildir(store *Store, attachment func(Attachment)) error {
+	for _, sub := range []string{"cur", "new"} {
+		dir := filepath.Join(store.Path, sub)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			f, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			n, _ := readMessage(f, Attachment{Path: path}, attachment)
+			f.Close()
+			store.Messages++
+			store.Size += n
+		}
+	}
+	return nil
+}
+
+// readMbox splits an mbox into messages on its "From " lines. Lines in
+// bodies that start with "From " are escaped by mail software, so any such
+// line after a blank line starts a new message.
+func readMbox(store *Store, attachment func(Attachment)) error {
+	f, err := os.Open(store.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var message bytes.Buffer
+	flush := func() {
+		if message.Len() == 0 {
+			return
+		}
+		store.Messages++
+		store.Size += int64(message.Len())
+		readMessage(&message, Attachment{Path: store.Path, Index: store.Messages}, attachment)
+		message.Reset()
+	}
+
+	lines := bufio.NewReader(f)
+	blank := true
+	for {
+		line, err := lines.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// A very long line can't be a separator
+			message.Write(line)
+			for err == bufio.ErrBufferFull {
+				line, err = lines.ReadSlice('\n')
+				message.Write(line)
+			}
+			blank = false
+			continue
+		}
+		if len(line) > 0 {
+			if blank && bytes.HasPrefix(line, []byte("From ")) {
+				flush()
+			} else {
+				message.Write(line)
+			}
+			blank = len(bytes.TrimRight(line, "\r\n")) == 0
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	flush()
+	return nil
+}
+
+// readMessage parses a message and reports its attachments, filled in from
+// where. It returns how many bytes the message had.
+func readMessage(r io.Reader, where Attachment, attachment func(Attachment)) (int64, error) {
+	counted := &countingReader{r: r}
+	msg, err := mail.ReadMessage(counted)
+	if err == nil {
+		decoder := new(mime.WordDecoder)
+		where.Subject, _ = decoder.DecodeHeader(msg.Header.Get("Subject"))
+		where.Date, _ = msg.Header.Date()
+		readPart(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Disposition"), msg.Body, where, attachment)
+	}
+
+	// Count whatever the parser left unread
+	io.Copy(io.Discard, counted)
+	return counted.n, err
+}
+
+// readPart reports the part if it's an attachment, or looks inside it if it's
+// multipart.
+func readPart(contentType, disposition string, body io.Reader, where Attachment, attachment func(Attachment)) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := parts.NextRawPart()
+			if err != nil {
+				return
+			}
+			readPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Disposition"), part, where, attachment)
+		}
+	}
+
+	name := attachmentName(disposition, params)
+	if name == "" {
+		return
+	}
+	size, _ := io.Copy(io.Discard, body)
+	where.Name = name
+	where.Size = size
+	attachment(where)
+}
+
+// attachmentName is the file name of a part that's an attachment, or empty
+// for parts meant to be shown inline without one.
+func attachmentName(disposition string, typeParams map[string]string) string {
+	kind, params, _ := mime.ParseMediaType(disposition)
+	if name := params["filename"]; name != "" {
+		return decodeName(name)
+	}
+	if name := typeParams["name"]; name != "" {
+		return decodeName(name)
+	}
+	if kind == "attachment" {
+		return "(unnamed)"
+	}
+	return ""
+}
+
+func decodeName(name string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(name)
+	if err != nil {
+		return name
+	}
+	return decoded
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,189 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// mp3SyncWindow is how far past the ID3 tag to look for the first frame.
+const mp3SyncWindow = 64 << 10
+
+// MPEG audio layer III bitrates in kbit/s by header index, for MPEG-1 and for
+// MPEG-2 and 2.5.
+var (
+	mp3Bitrates1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3Bitrates2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+)
+
+// probeWAV reads a RIFF WAVE file's format chunk and works out the duration
+// from the size of its data chunk.
+func probeWAV(r io.ReadSeeker, size int64) (Info, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:4]) != "RIFF" || string(header[8:]) != "WAVE" {
+		return Info{}, errMalformed
+	}
+
+	info := Info{Kind: Audio}
+	var byteRate uint32
+	chunk := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			break
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(chunk[4:]))
+
+		switch string(chunk[:4]) {
+		case "fmt ":
+			format := make([]byte, min(chunkSize, 16))
+			if _, err := io.ReadFull(r, format); err != nil || len(format) < 16 {
+				return Info{}, errMalformed
+			}
+			byteRate = binary.LittleEndian.Uint32(format[8:])
+			info.addCodec(wavCodec(binary.LittleEndian.Uint16(format)))
+			chunkSize -= int64(len(format))
+		case "data":
+			if byteRate > 0 {
+				info.Duration = time.Duration(float64(chunkSize) / float64(byteRate) * float64(time.Second))
+			}
+			return info, nil
+		}
+
+		// Chunks are padded to an even size
+		if _, err := r.Seek(chunkSize+chunkSize%2, io.SeekCurrent); err != nil {
+			break
+		}
+	}
+	return info, nil
+}
+
+func wavCodec(format uint16) string {
+	switch format {
+	case 1, 0xFFFE:
+		return "PCM"
+	case 3:
+		return "PCM float"
+	case 6:
+		return "A-law"
+	case 7:
+		return "µ-law"
+	case 0x55:
+		return "MP3"
+	}
+	return "WAV"
+}
+
+// probeFLAC reads the stream info block, which holds the sample rate and the
+// total number of samples.
+func probeFLAC(r io.ReadSeeker, size int64) (Info, error) {
+	header := make([]byte, 8+34)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:4]) != "fLaC" || header[4]&0x7F != 0 {
+		return Info{}, errMalformed
+	}
+
+	streamInfo := header[8:]
+	sampleRate := uint64(streamInfo[10])<<12 | uint64(streamInfo[11])<<4 | uint64(streamInfo[12])>>4
+	samples := uint64(streamInfo[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(streamInfo[14:]))
+
+	info := Info{Kind: Audio, Codecs: []string{"FLAC"}}
+	if sampleRate > 0 {
+		info.Duration = time.Duration(float64(samples) / float64(sampleRate) * float64(time.Second))
+	}
+	return info, nil
+}
+
+// probeMP3 finds the first MPEG audio frame after any ID3v2 tag. Variable
+// bitrate files carry a Xing or VBRI header there with the frame count;
+// otherwise the bitrate is taken as constant.
+func probeMP3(r io.ReadSeeker, size int64) (Info, error) {
+	var start int64
+	tag := make([]byte, 10)
+	if _, err := io.ReadFull(r, tag); err == nil && string(tag[:3]) == "ID3" {
+		// The tag size is stored in 7-bit bytes and excludes the header
+		tagSize := int64(tag[6]&0x7F)<<21 | int64(tag[7]&0x7F)<<14 | int64(tag[8]&0x7F)<<7 | int64(tag[9]&0x7F)
+		start = 10 + tagSize
+		if tag[5]&0x10 != 0 {
+			// Footer present
+			start += 10
+		}
+	}
+
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return Info{}, err
+	}
+	window := make([]byte, mp3SyncWindow)
+	n, _ := io.ReadFull(r, window)
+	window = window[:n]
+
+	for i := 0; i+4 <= len(window); i++ {
+		if window[i] != 0xFF || window[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+		if info, ok := mp3Frame(window[i:], size-start-int64(i)); ok {
+			return info, nil
+		}
+	}
+	return Info{}, errMalformed
+}
+
+// mp3Frame reads a layer III frame header at the start of frame, which has
+// audioBytes of audio from there to the end of the file.
+func mp3Frame(frame []byte, audioBytes int64) (Info, bool) {
+	version := frame[1] >> 3 & 3 // 3 is MPEG-1, 2 is MPEG-2, 0 is MPEG-2.5
+	layer := frame[1] >> 1 & 3   // 1 is layer III
+	bitrateIndex := frame[2] >> 4
+	rateIndex := frame[2] >> 2 & 3
+	if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return Info{}, false
+	}
+
+	sampleRate := [3]int{44100, 48000, 32000}[rateIndex]
+	samplesPerFrame := 1152
+	bitrate := mp3Bitrates1[bitrateIndex]
+	// The Xing header follows the side information, whose size depends on
+	// the version and whether the frame is mono
+	mono := frame[3]>>6 == 3
+	sideInfo := 32
+	if mono {
+		sideInfo = 17
+	}
+	if version != 3 {
+		sampleRate /= 2
+		if version == 0 {
+			sampleRate /= 2
+		}
+		samplesPerFrame = 576
+		bitrate = mp3Bitrates2[bitrateIndex]
+		sideInfo = 17
+		if mono {
+			sideInfo = 9
+		}
+	}
+
+	info := Info{Kind: Audio, Codecs: []string{"MP3"}}
+	frames := xingFrames(frame, 4+sideInfo)
+	if frames > 0 {
+		info.Duration = time.Duration(float64(frames) * float64(samplesPerFrame) / float64(sampleRate) * float64(time.Second))
+	} else {
+		info.Duration = time.Duration(float64(audioBytes) * 8 / float64(bitrate*1000) * float64(time.Second))
+	}
+	return info, true
+}
+
+// xingFrames returns the frame count from a Xing, Info or VBRI header in the
+// first frame, or 0 if there is none.
+func xingFrames(frame []byte, xingOffset int) uint32 {
+	if len(frame) >= xingOffset+12 {
+		tag := frame[xingOffset : xingOffset+4]
+		flags := binary.BigEndian.Uint32(frame[xingOffset+4:])
+		if (bytes.Equal(tag, []byte("Xing")) || bytes.Equal(tag, []byte("Info"))) && flags&1 != 0 {
+			return binary.BigEndian.Uint32(frame[xingOffset+8:])
+		}
+	}
+	// VBRI always sits 32 bytes after the frame header
+	if len(frame) >= 36+18 && bytes.Equal(frame[36:40], []byte("VBRI")) {
+		return binary.BigEndian.Uint32(frame[36+14:])
+	}
+	return 0
+}
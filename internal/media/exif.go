@@ -0,0 +1,131 @@
+package media
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"strings"
+	"time"
+)
+
+// EXIF tags holding dates, and the pointer to the sub-IFD with the original
+// capture date.
+const (
+	exifDateTime         = 0x0132
+	exifIFDPointer       = 0x8769
+	exifDateTimeOriginal = 0x9003
+)
+
+// exifDateLayout is how EXIF writes dates, in the camera's local time.
+const exifDateLayout = "2006:01:02 15:04:05"
+
+// exifDate returns when a JPEG was taken, preferring the original capture
+// date over the last-modified date. It returns the zero time if the file has
+// neither.
+func exifDate(r io.Reader) time.Time {
+	tiff := jpegExif(bufio.NewReader(r))
+	if len(tiff) < 8 {
+		return time.Time{}
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return time.Time{}
+	}
+
+	ifd0 := order.Uint32(tiff[4:])
+	modified := exifString(tiff, order, ifd0, exifDateTime)
+	if sub, ok := exifLong(tiff, order, ifd0, exifIFDPointer); ok {
+		if original := exifString(tiff, order, sub, exifDateTimeOriginal); original != "" {
+			modified = original
+		}
+	}
+
+	taken, err := time.ParseInLocation(exifDateLayout, modified, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return taken
+}
+
+// jpegExif returns the TIFF structure inside a JPEG's EXIF segment, or nil.
+// Segments are walked up to the start of the image data.
+func jpegExif(r *bufio.Reader) []byte {
+	soi := make([]byte, 2)
+	if _, err := io.ReadFull(r, soi); err != nil || soi[0] != 0xFF || soi[1] != 0xD8 {
+		return nil
+	}
+
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil || header[0] != 0xFF {
+			return nil
+		}
+		marker := header[1]
+		length := int(binary.BigEndian.Uint16(header[2:])) - 2
+		if marker == 0xDA || length < 0 {
+			// Start of scan, no metadata after this
+			return nil
+		}
+
+		if marker != 0xE1 {
+			if _, err := r.Discard(length); err != nil {
+				return nil
+			}
+			continue
+		}
+
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return nil
+		}
+		if strings.HasPrefix(string(segment), "Exif\x00\x00") {
+			return segment[6:]
+		}
+	}
+}
+
+// exifEntry finds tag in the IFD at offset and returns its 12-byte entry.
+func exifEntry(tiff []byte, order binary.ByteOrder, offset uint32, tag uint16) []byte {
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return nil
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	entries := tiff[offset+2:]
+	for i := 0; i < count && (i+1)*12 <= len(entries); i++ {
+		entry := entries[i*12 : (i+1)*12]
+		if order.Uint16(entry) == tag {
+			return entry
+		}
+	}
+	return nil
+}
+
+func exifLong(tiff []byte, order binary.ByteOrder, offset uint32, tag uint16) (uint32, bool) {
+	entry := exifEntry(tiff, order, offset, tag)
+	if entry == nil {
+		return 0, false
+	}
+	return order.Uint32(entry[8:]), true
+}
+
+// exifString reads an ASCII value, which lives at an offset when it's longer
+// than four bytes, as dates always are.
+func exifString(tiff []byte, order binary.ByteOrder, offset uint32, tag uint16) string {
+	entry := exifEntry(tiff, order, offset, tag)
+	if entry == nil || order.Uint16(entry[2:]) != 2 {
+		return ""
+	}
+
+	count := uint64(order.Uint32(entry[4:]))
+	start := uint64(order.Uint32(entry[8:]))
+	if count <= 4 || start+count > uint64(len(tiff)) {
+		return ""
+	}
+	return strings.TrimRight(string(tiff[start:start+count]), "\x00 ")
+}
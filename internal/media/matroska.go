@@ -0,0 +1,246 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Matroska element IDs, with their length markers kept as in the spec.
+const (
+	ebmlHeader     = 0x1A45DFA3
+	ebmlSegment    = 0x18538067
+	ebmlInfo       = 0x1549A966
+	ebmlTimescale  = 0x2AD7B1
+	ebmlDuration   = 0x4489
+	ebmlTracks     = 0x1654AE6B
+	ebmlTrackEntry = 0xAE
+	ebmlTrackType  = 0x83
+	ebmlCodecID    = 0x86
+	ebmlVideo      = 0xE0
+	ebmlWidth      = 0xB0
+	ebmlHeight     = 0xBA
+	ebmlCluster    = 0x1F43B675
+)
+
+// maxEBMLElement caps how much of one metadata element is read into memory.
+const maxEBMLElement = 16 << 20
+
+// unknownSize marks an element whose size wasn't known when it was written,
+// as in live recordings. It runs to the end of its parent.
+const unknownSize = math.MaxUint64
+
+// matroskaCodecs names common codec IDs.
+var matroskaCodecs = map[string]string{
+	"V_MPEG4/ISO/AVC": "H.264", "V_MPEGH/ISO/HEVC": "HEVC",
+	"V_AV1": "AV1", "V_VP8": "VP8", "V_VP9": "VP9",
+	"A_AAC": "AAC", "A_OPUS": "Opus", "A_VORBIS": "Vorbis",
+	"A_AC3": "AC-3", "A_EAC3": "E-AC-3", "A_FLAC": "FLAC",
+	"A_DTS": "DTS", "A_MPEG/L3": "MP3",
+}
+
+// probeMatroska reads a Matroska or WebM file's segment info for the duration
+// and its track entries for codecs and dimensions. Both come before the
+// first cluster of media data, where reading stops.
+func probeMatroska(r io.ReadSeeker, size int64) (Info, error) {
+	br := bufio.NewReader(r)
+
+	id, headerSize, err := readElementHeader(br)
+	if err != nil || id != ebmlHeader {
+		return Info{}, errMalformed
+	}
+	if _, err := br.Discard(int(min(headerSize, maxEBMLElement))); err != nil {
+		return Info{}, errMalformed
+	}
+
+	if id, _, err = readElementHeader(br); err != nil || id != ebmlSegment {
+		return Info{}, errMalformed
+	}
+
+	info := Info{Kind: Audio}
+	timescale := uint64(time.Millisecond)
+	var duration float64
+	var seenInfo, seenTracks bool
+
+	for !seenInfo || !seenTracks {
+		id, elementSize, err := readElementHeader(br)
+		if err != nil || id == ebmlCluster || elementSize == unknownSize {
+			break
+		}
+
+		if (id != ebmlInfo && id != ebmlTracks) || elementSize > maxEBMLElement {
+			if _, err := discard(br, elementSize); err != nil {
+				break
+			}
+			continue
+		}
+
+		data := make([]byte, elementSize)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return Info{}, errMalformed
+		}
+
+		if id == ebmlInfo {
+			seenInfo = true
+			for id, value := range ebmlElements(data) {
+				switch id {
+				case ebmlTimescale:
+					timescale = ebmlUint(value)
+				case ebmlDuration:
+					duration = ebmlFloat(value)
+				}
+			}
+		} else {
+			seenTracks = true
+			for id, value := range ebmlElements(data) {
+				if id == ebmlTrackEntry {
+					probeTrackEntry(value, &info)
+				}
+			}
+		}
+	}
+
+	info.Duration = time.Duration(duration * float64(timescale))
+	return info, nil
+}
+
+// probeTrackEntry adds a track's codec, and for video its dimensions, to info.
+func probeTrackEntry(entry []byte, info *Info) {
+	var trackType uint64
+	var codec string
+	var width, height int
+
+	for id, value := range ebmlElements(entry) {
+		switch id {
+		case ebmlTrackType:
+			trackType = ebmlUint(value)
+		case ebmlCodecID:
+			codec = strings.TrimRight(string(value), "\x00")
+		case ebmlVideo:
+			for id, value := range ebmlElements(value) {
+				switch id {
+				case ebmlWidth:
+					width = int(ebmlUint(value))
+				case ebmlHeight:
+					height = int(ebmlUint(value))
+				}
+			}
+		}
+	}
+
+	switch trackType {
+	case 1:
+		info.Kind = Video
+		info.Width, info.Height = max(info.Width, width), max(info.Height, height)
+	case 2:
+	default:
+		return
+	}
+	if name, ok := matroskaCodecs[codec]; ok {
+		codec = name
+	}
+	if codec != "" {
+		info.addCodec(codec)
+	}
+}
+
+// readElementHeader reads an element's ID and the size of its data.
+func readElementHeader(r io.ByteReader) (uint64, uint64, error) {
+	id, _, err := readVint(r, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	size, length, err := readVint(r, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	if size == 1<<(7*length)-1 {
+		// All value bits set means the size is unknown
+		size = unknownSize
+	}
+	return id, size, nil
+}
+
+// readVint reads an EBML variable-length integer, whose leading zero bits
+// give its length. IDs keep the length marker, sizes drop it.
+func readVint(r io.ByteReader, keepMarker bool) (uint64, int, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	length := 1
+	for mask := byte(0x80); first&mask == 0; mask >>= 1 {
+		length++
+		if length > 8 {
+			return 0, 0, errMalformed
+		}
+	}
+
+	value := uint64(first)
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	for i := 1; i < length; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, 0, err
+		}
+		value = value<<8 | uint64(b)
+	}
+	return value, length, nil
+}
+
+// discard skips n bytes, which may be more than fits in an int.
+func discard(r *bufio.Reader, n uint64) (uint64, error) {
+	var skipped uint64
+	for skipped < n {
+		chunk, err := r.Discard(int(min(n-skipped, 1<<30)))
+		skipped += uint64(chunk)
+		if err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
+
+// ebmlElements iterates over the child elements packed in data, stopping at
+// the first one that doesn't fit.
+func ebmlElements(data []byte) func(yield func(uint64, []byte) bool) {
+	return func(yield func(uint64, []byte) bool) {
+		r := bytes.NewReader(data)
+		for {
+			id, size, err := readElementHeader(r)
+			if err != nil || size > uint64(r.Len()) {
+				return
+			}
+			start := len(data) - r.Len()
+			r.Seek(int64(size), io.SeekCurrent)
+			if !yield(id, data[start:start+int(size)]) {
+				return
+			}
+		}
+	}
+}
+
+func ebmlUint(data []byte) uint64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func ebmlFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+// Package media reads basic metadata from audio, video and image files:
+// duration, resolution, codecs and when a photo was taken. Only the headers
+// are read, so probing a multi-gigabyte video is cheap.
+package media
+
+import (
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kind is the broad type of a media file.
+type Kind int
+
+const (
+	Image Kind = iota + 1
+	Audio
+	Video
+)
+
+// Info is what could be read from a media file. Fields the format doesn't
+// record, or that couldn't be read, are left zero.
+type Info struct {
+	Kind     Kind
+	Format   string // Container or image format, e.g. "MP4" or "JPEG"
+	Duration time.Duration
+	Width    int
+	Height   int
+	Codecs   []string  // Codec of each video and audio track
+	Taken    time.Time // When a photo was taken, from its EXIF data
+}
+
+// ErrUnsupported is returned for files that aren't in a known media format.
+var ErrUnsupported = errors.New("not a supported media file")
+
+// errMalformed is returned when a file's headers don't parse.
+var errMalformed = errors.New("malformed media file")
+
+// Probe reads the metadata of the media file at path, picking the parser by
+// file extension.
+func Probe(path string) (Info, error) {
+	probe, format := probeFor(filepath.Ext(path))
+	if probe == nil {
+		return Info{}, ErrUnsupported
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return Info{}, err
+	}
+
+	info, err := probe(f, stat.Size())
+	if err != nil {
+		return Info{}, err
+	}
+	if info.Format == "" {
+		info.Format = format
+	}
+	return info, nil
+}
+
+type probeFunc func(r io.ReadSeeker, size int64) (Info, error)
+
+func probeFor(ext string) (probeFunc, string) {
+	switch strings.ToLower(ext) {
+	case ".mp4", ".m4v", ".mov", ".3gp":
+		return probeMP4, "MP4"
+	case ".m4a":
+		return probeMP4, "M4A"
+	case ".mkv", ".mka":
+		return probeMatroska, "Matroska"
+	case ".webm":
+		return probeMatroska, "WebM"
+	case ".wav":
+		return probeWAV, "WAV"
+	case ".flac":
+		return probeFLAC, "FLAC"
+	case ".mp3":
+		return probeMP3, "MP3"
+	case ".jpg", ".jpeg":
+		return probeJPEG, "JPEG"
+	case ".png":
+		return probeImage, "PNG"
+	case ".gif":
+		return probeImage, "GIF"
+	}
+	return nil, ""
+}
+
+// probeImage reads an image's dimensions.
+func probeImage(r io.ReadSeeker, size int64) (Info, error) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return Info{}, err
+	}
+	return Info{Kind: Image, Width: config.Width, Height: config.Height}, nil
+}
+
+// probeJPEG reads a JPEG's dimensions and, if present, its EXIF capture date.
+func probeJPEG(r io.ReadSeeker, size int64) (Info, error) {
+	info, err := probeImage(r, size)
+	if err != nil {
+		return Info{}, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err == nil {
+		info.Taken = exifDate(r)
+	}
+	return info, nil
+}
+
+// addCodec appends a codec unless it's already listed, so files with several
+// audio tracks in the same codec read cleanly.
+func (i *Info) addCodec(codec string) {
+	for _, c := range i.Codecs {
+		if c == codec {
+			return
+		}
+	}
+	i.Codecs = append(i.Codecs, codec)
+}
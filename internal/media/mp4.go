@@ -0,0 +1,203 @@
+package media
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxMoovSize caps how much of an MP4's metadata box is read into memory.
+const maxMoovSize = 64 << 20
+
+// mp4Codecs names common sample entry types.
+var mp4Codecs = map[string]string{
+	"avc1": "H.264", "avc3": "H.264",
+	"hvc1": "HEVC", "hev1": "HEVC",
+	"av01": "AV1", "vp09": "VP9", "mp4v": "MPEG-4",
+	"mp4a": "AAC", "ac-3": "AC-3", "ec-3": "E-AC-3",
+	"Opus": "Opus", "alac": "ALAC", "fLaC": "FLAC",
+}
+
+// probeMP4 reads an ISO base media file (MP4, MOV, M4A): the movie header for
+// the duration and each track's handler, sample entry and dimensions.
+func probeMP4(r io.ReadSeeker, size int64) (Info, error) {
+	moov, err := findMoov(r, size)
+	if err != nil {
+		return Info{}, err
+	}
+
+	info := Info{Kind: Audio}
+	for box := range mp4Boxes(moov) {
+		switch box.kind {
+		case "mvhd":
+			info.Duration = mvhdDuration(box.data)
+		case "trak":
+			probeTrack(box.data, &info)
+		}
+	}
+	return info, nil
+}
+
+// findMoov walks the top-level boxes for the movie box and reads it. It
+// usually sits before or after the media data, which is skipped.
+func findMoov(r io.ReadSeeker, size int64) ([]byte, error) {
+	var offset int64
+	header := make([]byte, 16)
+	for offset+8 <= size {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return nil, errMalformed
+		}
+
+		boxSize := int64(binary.BigEndian.Uint32(header))
+		headerSize := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = size - offset
+		case 1:
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return nil, errMalformed
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:]))
+			headerSize = 16
+		}
+		if boxSize < headerSize {
+			return nil, errMalformed
+		}
+
+		if string(header[4:8]) == "moov" {
+			if boxSize-headerSize > maxMoovSize {
+				return nil, errMalformed
+			}
+			moov := make([]byte, boxSize-headerSize)
+			if _, err := io.ReadFull(r, moov); err != nil {
+				return nil, errMalformed
+			}
+			return moov, nil
+		}
+		offset += boxSize
+	}
+	return nil, errMalformed
+}
+
+type mp4Box struct {
+	kind string
+	data []byte
+}
+
+// mp4Boxes iterates over the boxes packed in data, stopping at the first one
+// that doesn't fit.
+func mp4Boxes(data []byte) func(yield func(mp4Box) bool) {
+	return func(yield func(mp4Box) bool) {
+		for len(data) >= 8 {
+			size := uint64(binary.BigEndian.Uint32(data))
+			header := uint64(8)
+			if size == 1 && len(data) >= 16 {
+				size = binary.BigEndian.Uint64(data[8:])
+				header = 16
+			} else if size == 0 {
+				size = uint64(len(data))
+			}
+			if size < header || size > uint64(len(data)) {
+				return
+			}
+			if !yield(mp4Box{kind: string(data[4:8]), data: data[header:size]}) {
+				return
+			}
+			data = data[size:]
+		}
+	}
+}
+
+// mvhdDuration reads the movie duration, stored in units of its own timescale.
+func mvhdDuration(data []byte) time.Duration {
+	var timescale, duration uint64
+	switch {
+	case len(data) >= 20 && data[0] == 0:
+		timescale = uint64(binary.BigEndian.Uint32(data[12:]))
+		duration = uint64(binary.BigEndian.Uint32(data[16:]))
+	case len(data) >= 32 && data[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(data[20:]))
+		duration = binary.BigEndian.Uint64(data[24:])
+	}
+	if timescale == 0 {
+		return 0
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+}
+
+// probeTrack adds a track's codec, and for video its dimensions, to info.
+func probeTrack(trak []byte, info *Info) {
+	var handler, codec string
+	var width, height int
+
+	for box := range mp4Boxes(trak) {
+		switch box.kind {
+		case "tkhd":
+			width, height = tkhdSize(box.data)
+		case "mdia":
+			handler, codec = probeMedia(box.data)
+		}
+	}
+
+	switch handler {
+	case "vide":
+		info.Kind = Video
+		info.Width, info.Height = max(info.Width, width), max(info.Height, height)
+	case "soun":
+	default:
+		// Subtitles, chapters and timecodes aren't interesting here
+		return
+	}
+	if codec != "" {
+		info.addCodec(codec)
+	}
+}
+
+// tkhdSize reads a track's display size, stored as 16.16 fixed point after
+// the transformation matrix.
+func tkhdSize(data []byte) (int, int) {
+	offset := 76
+	if len(data) > 0 && data[0] == 1 {
+		offset = 88
+	}
+	if len(data) < offset+8 {
+		return 0, 0
+	}
+	return int(binary.BigEndian.Uint32(data[offset:]) >> 16), int(binary.BigEndian.Uint32(data[offset+4:]) >> 16)
+}
+
+// probeMedia finds a track's handler type and the codec of its first sample
+// description.
+func probeMedia(mdia []byte) (handler, codec string) {
+	for box := range mp4Boxes(mdia) {
+		switch box.kind {
+		case "hdlr":
+			if len(box.data) >= 12 {
+				handler = string(box.data[8:12])
+			}
+		case "minf":
+			for box := range mp4Boxes(box.data) {
+				if box.kind != "stbl" {
+					continue
+				}
+				for box := range mp4Boxes(box.data) {
+					if box.kind == "stsd" && len(box.data) >= 16 {
+						codec = mp4CodecName(string(box.data[12:16]))
+					}
+				}
+			}
+		}
+	}
+	return handler, codec
+}
+
+func mp4CodecName(fourcc string) string {
+	if name, ok := mp4Codecs[fourcc]; ok {
+		return name
+	}
+	return strings.TrimSpace(fourcc)
+}
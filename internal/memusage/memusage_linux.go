@@ -0,0 +1,19 @@
+package memusage
+
+import (
+	"fmt"
+	"os"
+)
+
+// RSS returns the process's resident set size in bytes.
+func RSS() uint64 {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return goHeld()
+	}
+	var size, resident uint64
+	if _, err := fmt.Sscan(string(data), &size, &resident); err != nil {
+		return goHeld()
+	}
+	return resident * uint64(os.Getpagesize())
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package memusage
+
+// RSS returns the process's resident set size in bytes, as far as the Go
+// runtime can tell.
+func RSS() uint64 {
+	return goHeld()
+}
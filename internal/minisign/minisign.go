@@ -0,0 +1,231 @@
+// Package minisign signs and verifies files in minisign's format, so
+// signatures made by dua can be checked with minisign itself and keys made
+// with minisign -G sign in dua. Signatures are made prehashed, minisign's
+// default, and legacy ones are verified too.
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// algEd marks keys, and signatures of the file itself
+	algEd = "Ed"
+	// algHashed marks signatures of the file's BLAKE2b-512 hash
+	algHashed = "ED"
+
+	untrustedPrefix = "untrusted comment: "
+	trustedPrefix   = "trusted comment: "
+)
+
+// ErrPassword is returned for an encrypted secret key given the wrong
+// password.
+var ErrPassword = errors.New("wrong password for the secret key")
+
+// KeyID identifies a key pair, shown as minisign does.
+type KeyID [8]byte
+
+func (id KeyID) String() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+// PublicKey verifies signatures.
+type PublicKey struct {
+	ID  KeyID
+	Key ed25519.PublicKey
+}
+
+// PrivateKey makes signatures.
+type PrivateKey struct {
+	ID  KeyID
+	Key ed25519.PrivateKey
+}
+
+// Signature is a parsed signature file.
+type Signature struct {
+	Algorithm      string
+	KeyID          KeyID
+	Signature      []byte
+	TrustedComment string
+	// GlobalSignature signs Signature and TrustedComment together, so the
+	// comment can't be swapped
+	GlobalSignature []byte
+}
+
+// decodeLines returns the base64 payload of a key or signature file, along
+// with the lines after it. The untrusted comment before it is optional.
+func decodeLines(data []byte) ([]byte, []string, error) {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n")), "\n")
+	if strings.HasPrefix(lines[0], untrustedPrefix) {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return nil, nil, errors.New("missing key or signature")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("malformed key or signature: %w", err)
+	}
+	return raw, lines[1:], nil
+}
+
+// ParsePublicKey reads a public key file as minisign -G writes it, or just
+// the key's line as given to minisign -P.
+func ParsePublicKey(data []byte) (PublicKey, error) {
+	raw, _, err := decodeLines(data)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	if len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != algEd {
+		return PublicKey{}, errors.New("not a minisign public key")
+	}
+	var key PublicKey
+	copy(key.ID[:], raw[2:10])
+	key.Key = ed25519.PublicKey(raw[10:])
+	return key, nil
+}
+
+// ParsePrivateKey reads a secret key file as minisign -G writes it.
+// password is only called for an encrypted key, which is most of them.
+func ParsePrivateKey(data []byte, password func() ([]byte, error)) (PrivateKey, error) {
+	raw, _, err := decodeLines(data)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	// Algorithms, KDF salt and limits, then the key ID, key and checksum
+	const size = 2 + 2 + 2 + 32 + 8 + 8 + 8 + ed25519.PrivateKeySize + 32
+	if len(raw) != size || string(raw[:2]) != algEd || string(raw[4:6]) != "B2" {
+		return PrivateKey{}, errors.New("not a minisign secret key")
+	}
+	kdf, salt := string(raw[2:4]), raw[6:38]
+	ops, mem := binary.LittleEndian.Uint64(raw[38:46]), binary.LittleEndian.Uint64(raw[46:54])
+	keynum := bytes.Clone(raw[54:])
+
+	switch kdf {
+	case "\x00\x00":
+		// Made with minisign -W, not encrypted
+	case "Sc":
+		pass, err := password()
+		if err != nil {
+			return PrivateKey{}, err
+		}
+		n, r, p := scryptParams(ops, mem)
+		stream, err := scrypt.Key(pass, salt, n, r, p, len(keynum))
+		if err != nil {
+			return PrivateKey{}, err
+		}
+		subtle.XORBytes(keynum, keynum, stream)
+	default:
+		return PrivateKey{}, fmt.Errorf("unsupported key derivation %q", kdf)
+	}
+
+	var key PrivateKey
+	copy(key.ID[:], keynum[:8])
+	key.Key = ed25519.PrivateKey(keynum[8 : 8+ed25519.PrivateKeySize])
+	checksum := blake2b.Sum256(append(append([]byte(algEd), key.ID[:]...), key.Key...))
+	if subtle.ConstantTimeCompare(checksum[:], keynum[8+ed25519.PrivateKeySize:]) != 1 {
+		if kdf == "Sc" {
+			return PrivateKey{}, ErrPassword
+		}
+		return PrivateKey{}, errors.New("corrupt minisign secret key")
+	}
+	return key, nil
+}
+
+// scryptParams turns the limits stored with a key into scrypt's parameters
+// the way libsodium, which minisign uses, does.
+func scryptParams(ops, mem uint64) (n, r, p int) {
+	ops = max(ops, 32768)
+	r = 8
+	var logN uint
+	if ops < mem/32 {
+		p = 1
+		maxN := ops / uint64(r*4)
+		for logN = 1; logN < 63; logN++ {
+			if 1<<logN > maxN/2 {
+				break
+			}
+		}
+	} else {
+		maxN := mem / uint64(r*128)
+		for logN = 1; logN < 63; logN++ {
+			if 1<<logN > maxN/2 {
+				break
+			}
+		}
+		maxRP := min((ops/4)/(1<<logN), 0x3fffffff)
+		p = int(maxRP) / r
+	}
+	return 1 << logN, r, p
+}
+
+// Sign signs message, returning the contents of its signature file. The
+// trusted comment is signed too, so it can't be changed without notice.
+func (k PrivateKey) Sign(message []byte, trustedComment string) []byte {
+	hash := blake2b.Sum512(message)
+	sig := ed25519.Sign(k.Key, hash[:])
+	global := ed25519.Sign(k.Key, append(bytes.Clone(sig), trustedComment...))
+
+	blob := append(append([]byte(algHashed), k.ID[:]...), sig...)
+	var b bytes.Buffer
+	b.WriteString(untrustedPrefix + "signature from dua secret key\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(blob) + "\n")
+	b.WriteString(trustedPrefix + trustedComment + "\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(global) + "\n")
+	return b.Bytes()
+}
+
+// ParseSignature reads a signature file.
+func ParseSignature(data []byte) (Signature, error) {
+	raw, rest, err := decodeLines(data)
+	if err != nil {
+		return Signature{}, err
+	}
+	if len(raw) != 2+8+ed25519.SignatureSize {
+		return Signature{}, errors.New("not a minisign signature")
+	}
+	sig := Signature{Algorithm: string(raw[:2]), Signature: raw[10:]}
+	copy(sig.KeyID[:], raw[2:10])
+	if sig.Algorithm != algEd && sig.Algorithm != algHashed {
+		return Signature{}, fmt.Errorf("unsupported signature algorithm %q", sig.Algorithm)
+	}
+	if len(rest) < 2 || !strings.HasPrefix(rest[0], trustedPrefix) {
+		return Signature{}, errors.New("signature has no trusted comment")
+	}
+	sig.TrustedComment = strings.TrimPrefix(rest[0], trustedPrefix)
+	sig.GlobalSignature, err = base64.StdEncoding.DecodeString(strings.TrimSpace(rest[1]))
+	if err != nil || len(sig.GlobalSignature) != ed25519.SignatureSize {
+		return Signature{}, errors.New("malformed trusted comment signature")
+	}
+	return sig, nil
+}
+
+// Verify checks that sig was made by k over message, and that its trusted
+// comment is the one signed.
+func (k PublicKey) Verify(message []byte, sig Signature) error {
+	if sig.KeyID != k.ID {
+		return fmt.Errorf("signed with key %s, not %s", sig.KeyID, k.ID)
+	}
+	signed := message
+	if sig.Algorithm == algHashed {
+		hash := blake2b.Sum512(message)
+		signed = hash[:]
+	}
+	if !ed25519.Verify(k.Key, signed, sig.Signature) {
+		return errors.New("signature doesn't match: the file was changed after signing")
+	}
+	if !ed25519.Verify(k.Key, append(bytes.Clone(sig.Signature), sig.TrustedComment...), sig.GlobalSignature) {
+		return errors.New("trusted comment doesn't match: it was changed after signing")
+	}
+	return nil
+}
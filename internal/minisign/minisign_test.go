@@ -0,0 +1,178 @@
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Signatures made by minisign itself. The first two are over "test" with
+// the key minisign's releases are signed with, from the tests of
+// github.com/jedisct1/go-minisign. The last is over "Hello World!\n" with a
+// key made by minisign -G, from the test data of aead.dev/minisign.
+const (
+	releaseKey = "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"
+
+	legacySig = `untrusted comment: signature from minisign secret key
+RWQf6LRCGA9i59SLOFxz6NxvASXDJeRtuZykwQepbDEGt87ig1BNpWaVWuNrm73YiIiJbq71Wi+dP9eKL8OC351vwIasSSbXxwA=
+trusted comment: timestamp:1635442742	file:test
+0YteLgV960ia80vnA/fHbvkyjl/IoP/HNOCaZfrF0CdhAlp7ok+Tpkya+VpWPX5C/Is3q8a/kEDSY7fBmmgJCg==
+`
+	hashedSig = `untrusted comment: signature from minisign secret key
+RUQf6LRCGA9i559r3g7V1qNyJDApGip8MfqcadIgT9CuhV3EMhHoN1mGTkUidF/z7SrlQgXdy8ofjb7bNJJylDOocrCo8KLzZwo=
+trusted comment: timestamp:1635443258	file:test	hashed
+/cj37GK60vryibFn+ftOgbCvW9NKhKYgjVpFFQUcWPAnjO23wrvVDTt7cloNC06maoBli9q6qwZDXXoaxweICQ==
+`
+
+	helloKey = `untrusted comment: minisign public key C373193807678450
+RWRQhGcHOBlzw4CoKyugkk4ioDfoxlXxC9LBx+VNhJ3w9w+cAxgvPsuo
+`
+	helloSecret = `untrusted comment: minisign encrypted secret key
+RWRTY0Iytaz5znJmUO5kBt5xVkvpBl+29A7pZH86phD4h8vD3V8AAAACAAAAAAAAAEAAAAAA9vH9EcS6NdXNIEGhYGoqG1CiL4aptyJreJ4IfuT4+1h+OgVaY/vi0HsbCP0Y6n/wcy0AN0wOXmVDPP33jZqv82YCj2fH+/6MRuAfzNQYoLvc3sH/8bIwqdfpKIjDRZhvqRf063RFYoI=
+`
+	helloPassword = "correct horse battery staple"
+	helloSig      = `untrusted comment: signature from minisign secret key
+RWRQhGcHOBlzwxrJCyuC+rJfHSfyRKRxkuwa3JJ0bWEs7RHjL1OUmqnTr+V1B9JzFuJIH/ybR2Eus9oEZKt9RbitpF/L4D3+5wg=
+trusted comment: timestamp:1614549543	file:message.txt
+P/722+ynQ+tIy0qadFHwLx5MsyNz/jDKJkDWQj4dDD2OKnVte8m/M14mwPE/1NMwzShPMSBhMXqZGdbe+UZjDg==
+`
+)
+
+// testKeys returns a key pair made from a fixed seed.
+func testKeys() (PrivateKey, PublicKey) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	id := KeyID{1, 2, 3, 4, 5, 6, 7, 8}
+	return PrivateKey{ID: id, Key: key}, PublicKey{ID: id, Key: key.Public().(ed25519.PublicKey)}
+}
+
+// verify parses a signature file and checks it over message.
+func verify(key PublicKey, message []byte, sigFile string) error {
+	sig, err := ParseSignature([]byte(sigFile))
+	if err != nil {
+		return err
+	}
+	return key.Verify(message, sig)
+}
+
+func TestSignVerify(t *testing.T) {
+	private, public := testKeys()
+	message := []byte("export contents")
+	signed := string(private.Sign(message, "timestamp:1700000000\tfile:export.json"))
+
+	if err := verify(public, message, signed); err != nil {
+		t.Fatalf("own signature: %v", err)
+	}
+	sig, _ := ParseSignature([]byte(signed))
+	if sig.Algorithm != algHashed || sig.TrustedComment != "timestamp:1700000000\tfile:export.json" {
+		t.Errorf("signature is %q with comment %q, want prehashed and the comment given", sig.Algorithm, sig.TrustedComment)
+	}
+
+	other := public
+	other.ID = KeyID{8, 7, 6, 5, 4, 3, 2, 1}
+	tests := []struct {
+		name    string
+		key     PublicKey
+		message string
+		sig     string
+		want    string
+	}{
+		{"tampered message", public, "export contents!", signed, "signature doesn't match"},
+		{"tampered trusted comment", public, string(message), strings.Replace(signed, "file:export.json", "file:other.json", 1), "trusted comment doesn't match"},
+		{"wrong key ID", other, string(message), signed, "signed with key 0807060504030201, not 0102030405060708"},
+	}
+	for _, test := range tests {
+		err := verify(test.key, []byte(test.message), test.sig)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got %v, want %q", test.name, err, test.want)
+		}
+	}
+}
+
+// TestVerifyMinisign checks signatures made by minisign, legacy and
+// prehashed, verify here.
+func TestVerifyMinisign(t *testing.T) {
+	release, err := ParsePublicKey([]byte(releaseKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hello, err := ParsePublicKey([]byte(helloKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hello.ID.String() != "C373193807678450" {
+		t.Errorf("key ID %s, want C373193807678450 as minisign shows it", hello.ID)
+	}
+
+	tests := []struct {
+		name    string
+		key     PublicKey
+		message string
+		sig     string
+	}{
+		{"legacy", release, "test", legacySig},
+		{"prehashed", release, "test", hashedSig},
+		{"key made by minisign -G", hello, "Hello World!\n", helloSig},
+	}
+	for _, test := range tests {
+		if err := verify(test.key, []byte(test.message), test.sig); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+		if err := verify(test.key, []byte(test.message+"."), test.sig); err == nil {
+			t.Errorf("%s: verified over a changed message", test.name)
+		}
+	}
+	if err := verify(hello, []byte("test"), hashedSig); err == nil || !strings.Contains(err.Error(), "signed with key") {
+		t.Errorf("verified with another key: %v", err)
+	}
+}
+
+// TestParsePrivateKeyMinisign decrypts a secret key minisign made, with its
+// default limits, which take seconds and a GiB of memory each time.
+func TestParsePrivateKeyMinisign(t *testing.T) {
+	if testing.Short() {
+		t.Skip("scrypt with minisign's limits is slow")
+	}
+	public, err := ParsePublicKey([]byte(helloKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, err := ParsePrivateKey([]byte(helloSecret), func() ([]byte, error) { return []byte(helloPassword), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if private.ID != public.ID {
+		t.Fatalf("key ID %s, want %s", private.ID, public.ID)
+	}
+	message := []byte("Hello World!\n")
+	if err := verify(public, message, string(private.Sign(message, "signed by dua"))); err != nil {
+		t.Errorf("signature with minisign's key: %v", err)
+	}
+	_, err = ParsePrivateKey([]byte(helloSecret), func() ([]byte, error) { return []byte("wrong"), nil })
+	if !errors.Is(err, ErrPassword) {
+		t.Errorf("wrong password: %v, want ErrPassword", err)
+	}
+}
+
+// TestScryptParams checks the limits minisign -G stores turn into the
+// parameters libsodium derives from them.
+func TestScryptParams(t *testing.T) {
+	tests := []struct {
+		ops, mem uint64
+		n, r, p  int
+	}{
+		// minisign's defaults
+		{33554432, 1073741824, 1 << 20, 8, 1},
+		// libsodium's interactive limits
+		{524288, 16777216, 1 << 14, 8, 1},
+		// Fewer operations than the minimum count as the minimum
+		{1, 16777216, 1 << 10, 8, 1},
+	}
+	for _, test := range tests {
+		n, r, p := scryptParams(test.ops, test.mem)
+		if n != test.n || r != test.r || p != test.p {
+			t.Errorf("scryptParams(%d, %d) = %d, %d, %d, want %d, %d, %d", test.ops, test.mem, n, r, p, test.n, test.r, test.p)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package names finds file names that cause trouble when moved between
+// systems, and suggests safe replacements.
+package names
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Problem is something wrong with a name.
+type Problem string
+
+const (
+	TrailingSpace Problem = "trailing space or dot"
+	LeadingSpace  Problem = "leading space"
+	ControlChar   Problem = "control character"
+	InvalidUTF8   Problem = "invalid UTF-8"
+	ReservedName  Problem = "reserved on Windows"
+	ReservedChar  Problem = "character not allowed on Windows"
+	CaseCollision Problem = "differs only in case from a sibling"
+)
+
+// windowsReserved are device names Windows refuses as file names, with or
+// without an extension.
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// windowsReservedChars can't appear in names on Windows.
+const windowsReservedChars = `<>:"\|?*`
+
+// Check returns the problems with a single name. Case collisions need the
+// sibling names, see Collisions.
+func Check(name string) []Problem {
+	var problems []Problem
+
+	if strings.HasSuffix(name, " ") || (strings.HasSuffix(name, ".") && name != "." && name != "..") {
+		problems = append(problems, TrailingSpace)
+	}
+	if strings.HasPrefix(name, " ") {
+		problems = append(problems, LeadingSpace)
+	}
+	if !utf8.ValidString(name) {
+		problems = append(problems, InvalidUTF8)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		problems = append(problems, ControlChar)
+	}
+	if strings.ContainsAny(name, windowsReservedChars) {
+		problems = append(problems, ReservedChar)
+	}
+	if isReserved(name) {
+		problems = append(problems, ReservedName)
+	}
+	return problems
+}
+
+func isReserved(name string) bool {
+	base, _, _ := strings.Cut(name, ".")
+	return windowsReserved[strings.ToUpper(strings.TrimRight(base, " "))]
+}
+
+// Collisions groups names in one directory that would clash on a
+// case-insensitive filesystem.
+func Collisions(siblings []string) [][]string {
+	byFolded := make(map[string][]string)
+	var order []string
+	for _, name := range siblings {
+		folded := strings.ToLower(name)
+		if _, ok := byFolded[folded]; !ok {
+			order = append(order, folded)
+		}
+		byFolded[folded] = append(byFolded[folded], name)
+	}
+
+	var groups [][]string
+	for _, folded := range order {
+		if len(byFolded[folded]) > 1 {
+			groups = append(groups, byFolded[folded])
+		}
+	}
+	return groups
+}
+
+// Fix returns a version of name without the problems Check reports:
+// invalid bytes, control and reserved characters become "_", surrounding
+// spaces and trailing dots are trimmed and reserved names get a "_" after
+// the part before the first dot, where Windows looks for them.
+func Fix(name string) string {
+	fixed := strings.ToValidUTF8(name, "_")
+	fixed = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(windowsReservedChars, r) {
+			return '_'
+		}
+		return r
+	}, fixed)
+
+	fixed = strings.TrimLeft(fixed, " ")
+	fixed = strings.TrimRight(fixed, " .")
+	if fixed == "" {
+		fixed = "_"
+	}
+
+	for isReserved(fixed) {
+		base, ext, _ := strings.Cut(fixed, ".")
+		fixed = base + "_"
+		if ext != "" {
+			fixed += "." + ext
+		}
+	}
+	return fixed
+}
+
+// Disambiguate returns name with a " (n)" counter before its extension,
+// for renaming one of a group of colliding names.
+func Disambiguate(name string, n int) string {
+	ext := filepath.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+	return strings.TrimSuffix(name, ext) + " (" + strconv.Itoa(n) + ")" + ext
+}
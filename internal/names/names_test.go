@@ -0,0 +1,29 @@
+package names
+
+import "testing"
+
+func TestFix(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"fine.txt", "fine.txt"},
+		{"con", "con_"},
+		{"CON.txt", "CON_.txt"},
+		{"con.tar.gz", "con_.tar.gz"},
+		{"nul ", "nul_"},
+		{"lpt1 .log", "lpt1 _.log"},
+		{"a:b?.txt", "a_b_.txt"},
+		{" lead.", "lead"},
+		{"...", "_"},
+		{"bad\x00\xffname", "bad__name"},
+	}
+	for _, test := range tests {
+		got := Fix(test.name)
+		if got != test.want {
+			t.Errorf("Fix(%q) = %q, want %q", test.name, got, test.want)
+		}
+		if problems := Check(got); len(problems) > 0 {
+			t.Errorf("Fix(%q) = %q, which still has %v", test.name, got, problems)
+		}
+	}
+}
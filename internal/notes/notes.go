@@ -0,0 +1,118 @@
+// Package notes keeps free-form notes attached to paths, such as "owned by
+// the data team, don't delete", in a SQLite database in the state directory
+// so they survive between runs and can be shared by pointing several users
+// at the same file.
+package notes
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/corpeningc/dua/internal/paths"
+	_ "modernc.org/sqlite"
+)
+
+// stateDBFile is the state database in the state directory.
+const stateDBFile = "state.db"
+
+const schema = `
+CREATE TABLE IF NOT EXISTS notes (
+	path       TEXT PRIMARY KEY,
+	note       TEXT    NOT NULL,
+	updated_at INTEGER NOT NULL -- Unix seconds
+);
+`
+
+// DefaultPath returns the state database's location.
+func DefaultPath() (string, error) {
+	return paths.StateFile(stateDBFile)
+}
+
+// open opens the database at file, creating it and its tables if needed.
+func open(file string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", file)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return db, nil
+}
+
+// Load returns every note in the database at file by path. A database that
+// doesn't exist yet has no notes.
+func Load(file string) (map[string]string, error) {
+	notes := make(map[string]string)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return notes, nil
+	}
+
+	db, err := open(file)
+	if err != nil {
+		return notes, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT path, note FROM notes`)
+	if err != nil {
+		return notes, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var path, note string
+		if err := rows.Scan(&path, &note); err != nil {
+			return notes, err
+		}
+		notes[path] = note
+	}
+	return notes, rows.Err()
+}
+
+// Set stores the note for path in the database at file, replacing any note it
+// had. An empty note removes it.
+func Set(file, path, note string) error {
+	db, err := open(file)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if note == "" {
+		_, err = db.Exec(`DELETE FROM notes WHERE path = ?`, path)
+	} else {
+		_, err = db.Exec(`INSERT INTO notes (path, note, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT(path) DO UPDATE SET note = excluded.note, updated_at = excluded.updated_at`,
+			path, note, time.Now().Unix())
+	}
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// Move carries the note for oldPath over to newPath after a rename.
+func Move(file, oldPath, newPath string) error {
+	db, err := open(file)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`INSERT OR REPLACE INTO notes (path, note, updated_at)
+		SELECT ?, note, updated_at FROM notes WHERE path = ?`, newPath, oldPath); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`DELETE FROM notes WHERE path = ?`, oldPath); err != nil {
+		return err
+	}
+	return db.Close()
+}
@@ -0,0 +1,10 @@
+// Package notify shows desktop notifications through the platform's own
+// tools: notify-send on Linux and the BSDs, osascript on macOS and a
+// PowerShell toast on Windows.
+package notify
+
+// Send shows a notification with title and body. It fails if the platform's
+// tool is missing, as notify-send is on headless machines.
+func Send(title, body string) error {
+	return command(title, body).Run()
+}
@@ -0,0 +1,13 @@
+package notify
+
+import "os/exec"
+
+// The text is passed as arguments rather than in the script, so it needs
+// no quoting.
+func command(title, body string) *exec.Cmd {
+	return exec.Command("osascript",
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		title, body)
+}
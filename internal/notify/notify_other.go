@@ -0,0 +1,10 @@
+//go:build !darwin && !windows
+
+package notify
+
+import "os/exec"
+
+// command is the command line showing the notification.
+func command(title, body string) *exec.Cmd {
+	return exec.Command("notify-send", "--app-name=dua", title, body)
+}
@@ -0,0 +1,34 @@
+// Package openfiles finds the files processes have open, including ones
+// that have been deleted but whose space can't be freed until every process
+// holding them closes them.
+package openfiles
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupported is returned where open files can't be listed.
+var ErrUnsupported = errors.New("listing open files is not available on this platform")
+
+// Process is a process holding a file open.
+type Process struct {
+	PID     int
+	Command string
+}
+
+func (p Process) String() string {
+	return fmt.Sprintf("%d (%s)", p.PID, p.Command)
+}
+
+// File is a regular file held open by one or more processes.
+type File struct {
+	Path string
+	// Deleted files have no name left; Path is the one they were opened by
+	Deleted bool
+	Size    int64
+	Holders []Process
+	// Descriptors are the /proc/PID/fd links the file is open by, which
+	// can still be read or truncated after it's deleted
+	Descriptors []string
+}
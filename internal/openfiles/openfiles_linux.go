@@ -0,0 +1,84 @@
+package openfiles
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// deletedSuffix is what the kernel appends to the link of an unlinked file.
+const deletedSuffix = " (deleted)"
+
+// fileID identifies a file however many descriptors and names it has.
+type fileID struct {
+	dev, ino uint64
+}
+
+// Scan lists the regular files open in processes that can be inspected,
+// reading each /proc/PID/fd. Without root that's only this user's own
+// processes.
+func Scan() ([]File, error) {
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []File
+	index := make(map[fileID]int)
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(proc.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			// Gone, or someone else's
+			continue
+		}
+
+		process := Process{PID: pid, Command: command(pid)}
+		for _, fd := range fds {
+			fdPath := filepath.Join(fdDir, fd.Name())
+			target, err := os.Readlink(fdPath)
+			if err != nil || !strings.HasPrefix(target, "/") {
+				// Sockets, pipes and anonymous inodes
+				continue
+			}
+			// Stat through the descriptor, as a deleted file has no path
+			var stat syscall.Stat_t
+			if err := syscall.Stat(fdPath, &stat); err != nil || stat.Mode&syscall.S_IFMT != syscall.S_IFREG {
+				continue
+			}
+
+			id := fileID{dev: uint64(stat.Dev), ino: stat.Ino}
+			i, ok := index[id]
+			if !ok {
+				i = len(files)
+				index[id] = i
+				deleted := stat.Nlink == 0
+				if deleted {
+					target = strings.TrimSuffix(target, deletedSuffix)
+				}
+				files = append(files, File{Path: target, Deleted: deleted, Size: stat.Size})
+			}
+			files[i].Descriptors = append(files[i].Descriptors, fdPath)
+			if !slices.Contains(files[i].Holders, process) {
+				files[i].Holders = append(files[i].Holders, process)
+			}
+		}
+	}
+	return files, nil
+}
+
+// command is the name of the process's executable.
+func command(pid int) string {
+	comm, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return "?"
+	}
+	return strings.TrimSpace(string(comm))
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package openfiles
+
+// Scan lists the regular files open in processes that can be inspected.
+func Scan() ([]File, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,126 @@
+// Package owners attributes paths to owners with CODEOWNERS-style rules, for
+// charging back or showing usage per team on shared filesystems.
+//
+// Each line of a rules file is a pattern followed by one or more owners:
+//
+//	# Everything defaults to the platform team
+//	*                 @platform
+//	/datasets/        @data-team
+//	*.iso             @it
+//	/home/**/cache/   @everyone
+//
+// Patterns follow CODEOWNERS: one starting with or containing a slash is
+// relative to the scanned root, anything else matches a name at any depth, a
+// trailing slash matches only directories, and a match on a directory covers
+// everything inside it. The last matching rule wins.
+package owners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Rules maps paths to owners.
+type Rules struct {
+	rules []rule
+}
+
+type rule struct {
+	// exact matches the pattern itself, inside anything below it
+	exact, inside *regexp.Regexp
+	dirOnly       bool
+	owners        []string
+}
+
+// Load reads a rules file.
+func Load(file string) (*Rules, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return rules, nil
+}
+
+// Parse reads rules, skipping blank lines and # comments.
+func Parse(r io.Reader) (*Rules, error) {
+	var rules Rules
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: %q has no owners", line, fields[0])
+		}
+		rules.rules = append(rules.rules, compile(fields[0], fields[1:]))
+	}
+	return &rules, scanner.Err()
+}
+
+// compile turns a pattern into the regular expressions that match it.
+func compile(pattern string, owners []string) rule {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	prefix := "^(?:.*/)?"
+	if strings.Contains(pattern, "/") {
+		prefix = "^"
+		pattern = strings.TrimPrefix(pattern, "/")
+	}
+
+	body := prefix + globToRegexp(pattern)
+	return rule{
+		exact:   regexp.MustCompile(body + "$"),
+		inside:  regexp.MustCompile(body + "/"),
+		dirOnly: dirOnly,
+		owners:  owners,
+	}
+}
+
+// globToRegexp converts a glob where * and ? stay within a path segment and
+// ** spans any number of them.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			b.WriteString("[^/]*")
+		case glob[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// Match returns the owners of rel, a slash-separated path relative to the
+// scanned root, or nil if no rule covers it.
+func (r *Rules) Match(rel string, isDir bool) []string {
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		rule := r.rules[i]
+		if rule.inside.MatchString(rel) || (rule.exact.MatchString(rel) && (isDir || !rule.dirOnly)) {
+			return rule.owners
+		}
+	}
+	return nil
+}
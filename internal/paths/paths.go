@@ -0,0 +1,77 @@
+// Package paths resolves where dua keeps files between runs, following each
+// platform's conventions: the XDG base directories on Linux and other Unix
+// systems, ~/Library on macOS and %AppData% on Windows. It also tells
+// whether one path lies within another.
+package paths
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appName is the directory dua's files live under in each location.
+const appName = "dua"
+
+// ConfigDir holds settings the user edits, such as config.json.
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName), nil
+}
+
+// CacheDir holds data that can be rebuilt at any time, such as scan results.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "windows" {
+		// %LocalAppData% is shared with state, keep the cache separately
+		// clearable
+		return filepath.Join(dir, appName, "cache"), nil
+	}
+	return filepath.Join(dir, appName), nil
+}
+
+// StateDir holds data worth keeping across runs that isn't configuration,
+// such as session history, audit logs and one-time prompts.
+func StateDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		dir := os.Getenv("LocalAppData")
+		if dir == "" {
+			return "", errors.New("%LocalAppData% is not defined")
+		}
+		return filepath.Join(dir, appName, "state"), nil
+	case "darwin", "ios":
+		// macOS has no separate state location, Application Support is it
+		return ConfigDir()
+	default:
+		if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+			return filepath.Join(dir, appName), nil
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "state", appName), nil
+	}
+}
+
+// ConfigFile, CacheFile and StateFile return the path of a named file in the
+// respective directory.
+func ConfigFile(name string) (string, error) { return join(ConfigDir, name) }
+func CacheFile(name string) (string, error)  { return join(CacheDir, name) }
+func StateFile(name string) (string, error)  { return join(StateDir, name) }
+
+func join(dir func() (string, error), name string) (string, error) {
+	base, err := dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, name), nil
+}
@@ -0,0 +1,21 @@
+package paths
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Within reports whether path is dir or below it. Both are cleaned, so a
+// trailing separator doesn't matter, but they're compared as given: neither
+// is made absolute nor has its links resolved.
+func Within(path, dir string) bool {
+	path, dir = filepath.Clean(path), filepath.Clean(dir)
+	if path == dir {
+		return true
+	}
+	// Only roots such as / and C:\ keep theirs once cleaned
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, dir)
+}
@@ -0,0 +1,30 @@
+package paths
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		want      bool
+	}{
+		{"/data", "/data", true},
+		{"/data/projects", "/data", true},
+		{"/data/projects", "/data/", true},
+		{"/data/", "/data", true},
+		{"/data", "/data/projects", false},
+		{"/database", "/data", false},
+		{"/data/../etc", "/data", false},
+		{"/anything", "/", true},
+		{"/", "/", true},
+		{"relative/dir", "relative", true},
+	}
+	for _, test := range tests {
+		path, dir := filepath.FromSlash(test.path), filepath.FromSlash(test.dir)
+		if got := Within(path, dir); got != test.want {
+			t.Errorf("Within(%q, %q) = %v, want %v", path, dir, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Package pkgcache finds the download caches of package managers and build
+// tools installed on this machine, how much they hold and how each is
+// cleaned.
+package pkgcache
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Cache is one package manager's cache.
+type Cache struct {
+	Name string
+	Dirs []string
+	Size int64
+	// Clean is the manager's own command for emptying the cache. When it's
+	// empty there's no such command and Dirs are deleted instead, as the
+	// tool downloads whatever it needs again.
+	Clean []string
+}
+
+// manager knows where one tool keeps its cache and how to clean it.
+type manager struct {
+	name    string
+	command string // Must be installed for the cache to be looked at
+	dirs    func() []string
+	clean   []string
+	root    bool // Cleaning needs root
+}
+
+var managers = []manager{
+	{name: "apt", command: "apt-get", dirs: fixed("/var/cache/apt/archives"), clean: []string{"apt-get", "clean"}, root: true},
+	{name: "dnf", command: "dnf", dirs: fixed("/var/cache/dnf"), clean: []string{"dnf", "clean", "all"}, root: true},
+	{name: "pacman", command: "pacman", dirs: fixed("/var/cache/pacman/pkg"), clean: []string{"pacman", "-Sc"}, root: true},
+	{name: "npm", command: "npm", dirs: npmDirs, clean: []string{"npm", "cache", "clean", "--force"}},
+	{name: "pip", command: "pip3", dirs: pipDirs, clean: []string{"pip3", "cache", "purge"}},
+	{name: "pip", command: "pip", dirs: pipDirs, clean: []string{"pip", "cache", "purge"}},
+	{name: "cargo", command: "cargo", dirs: cargoDirs},
+	{name: "Go module", command: "go", dirs: goEnv("GOMODCACHE"), clean: []string{"go", "clean", "-modcache"}},
+	{name: "Go build", command: "go", dirs: goEnv("GOCACHE"), clean: []string{"go", "clean", "-cache"}},
+	{name: "Homebrew", command: "brew", dirs: output("brew", "--cache"), clean: []string{"brew", "cleanup", "--prune=all"}},
+}
+
+// Find returns the caches of the installed managers that hold anything,
+// measuring each. Cleaning commands that need root are run through sudo
+// unless dua already is root.
+func Find() []Cache {
+	var caches []Cache
+	seen := make(map[string]bool)
+	for _, m := range managers {
+		if seen[m.name] {
+			continue
+		}
+		if _, err := exec.LookPath(m.command); err != nil {
+			continue
+		}
+		seen[m.name] = true
+
+		cache := Cache{Name: m.name, Clean: m.clean}
+		for _, dir := range m.dirs() {
+			size := dirSize(dir)
+			if size > 0 {
+				cache.Dirs = append(cache.Dirs, dir)
+				cache.Size += size
+			}
+		}
+		if cache.Size == 0 {
+			continue
+		}
+		if m.root && os.Geteuid() != 0 {
+			cache.Clean = append([]string{"sudo"}, cache.Clean...)
+		}
+		caches = append(caches, cache)
+	}
+	return caches
+}
+
+func fixed(dirs ...string) func() []string {
+	return func() []string { return dirs }
+}
+
+// output runs a command that prints a cache directory.
+func output(name string, args ...string) func() []string {
+	return func() []string {
+		out, err := exec.Command(name, args...).Output()
+		if dir := strings.TrimSpace(string(out)); err == nil && filepath.IsAbs(dir) {
+			return []string{dir}
+		}
+		return nil
+	}
+}
+
+func goEnv(name string) func() []string {
+	return output("go", "env", name)
+}
+
+func npmDirs() []string {
+	dirs := output("npm", "config", "get", "cache")()
+	for i, dir := range dirs {
+		// Cleaning only empties the content cache, not logs beside it
+		dirs[i] = filepath.Join(dir, "_cacache")
+	}
+	return dirs
+}
+
+func pipDirs() []string {
+	if dirs := output("pip3", "cache", "dir")(); dirs != nil {
+		return dirs
+	}
+	return output("pip", "cache", "dir")()
+}
+
+// cargoDirs are the downloaded crates and their unpacked sources. cargo has
+// no command to clean them on stable, and rebuilds both on demand.
+func cargoDirs() []string {
+	home := os.Getenv("CARGO_HOME")
+	if home == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		home = filepath.Join(userHome, ".cargo")
+	}
+	return []string{
+		filepath.Join(home, "registry", "cache"),
+		filepath.Join(home, "registry", "src"),
+		filepath.Join(home, "git", "checkouts"),
+	}
+}
+
+// dirSize totals the files below dir, skipping what can't be read.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != dir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,577 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: dua/v1/scan.proto
+
+// Scans run by a dua server, streamed to clients one directory at a time as
+// the parallel scanner reaches them.
+
+package duapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StartScanRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory to scan, on the server's filesystem.
+	Root          string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartScanRequest) Reset() {
+	*x = StartScanRequest{}
+	mi := &file_dua_v1_scan_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartScanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartScanRequest) ProtoMessage() {}
+
+func (x *StartScanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dua_v1_scan_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartScanRequest.ProtoReflect.Descriptor instead.
+func (*StartScanRequest) Descriptor() ([]byte, []int) {
+	return file_dua_v1_scan_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StartScanRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+type StartScanResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	ScanId string                 `protobuf:"bytes,1,opt,name=scan_id,json=scanId,proto3" json:"scan_id,omitempty"`
+	// The root as resolved by the server: absolute, with symlinks evaluated.
+	Root          string `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartScanResponse) Reset() {
+	*x = StartScanResponse{}
+	mi := &file_dua_v1_scan_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartScanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartScanResponse) ProtoMessage() {}
+
+func (x *StartScanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dua_v1_scan_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartScanResponse.ProtoReflect.Descriptor instead.
+func (*StartScanResponse) Descriptor() ([]byte, []int) {
+	return file_dua_v1_scan_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StartScanResponse) GetScanId() string {
+	if x != nil {
+		return x.ScanId
+	}
+	return ""
+}
+
+func (x *StartScanResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+type StreamUpdatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScanId        string                 `protobuf:"bytes,1,opt,name=scan_id,json=scanId,proto3" json:"scan_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamUpdatesRequest) Reset() {
+	*x = StreamUpdatesRequest{}
+	mi := &file_dua_v1_scan_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamUpdatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamUpdatesRequest) ProtoMessage() {}
+
+func (x *StreamUpdatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dua_v1_scan_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamUpdatesRequest.ProtoReflect.Descriptor instead.
+func (*StreamUpdatesRequest) Descriptor() ([]byte, []int) {
+	return file_dua_v1_scan_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StreamUpdatesRequest) GetScanId() string {
+	if x != nil {
+		return x.ScanId
+	}
+	return ""
+}
+
+type ScanUpdate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A directory that was just scanned, with its own files. Subdirectories
+	// follow in later updates.
+	Directory *Directory `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// A directory that couldn't be read.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Set on the last update once every directory has been scanned.
+	Complete bool `protobuf:"varint,3,opt,name=complete,proto3" json:"complete,omitempty"`
+	// Running totals for the scan so far.
+	FilesScanned  int64 `protobuf:"varint,4,opt,name=files_scanned,json=filesScanned,proto3" json:"files_scanned,omitempty"`
+	DirsScanned   int64 `protobuf:"varint,5,opt,name=dirs_scanned,json=dirsScanned,proto3" json:"dirs_scanned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanUpdate) Reset() {
+	*x = ScanUpdate{}
+	mi := &file_dua_v1_scan_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanUpdate) ProtoMessage() {}
+
+func (x *ScanUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_dua_v1_scan_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanUpdate.ProtoReflect.Descriptor instead.
+func (*ScanUpdate) Descriptor() ([]byte, []int) {
+	return file_dua_v1_scan_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScanUpdate) GetDirectory() *Directory {
+	if x != nil {
+		return x.Directory
+	}
+	return nil
+}
+
+func (x *ScanUpdate) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ScanUpdate) GetComplete() bool {
+	if x != nil {
+		return x.Complete
+	}
+	return false
+}
+
+func (x *ScanUpdate) GetFilesScanned() int64 {
+	if x != nil {
+		return x.FilesScanned
+	}
+	return 0
+}
+
+func (x *ScanUpdate) GetDirsScanned() int64 {
+	if x != nil {
+		return x.DirsScanned
+	}
+	return 0
+}
+
+type Directory struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Total size of the files directly in this directory, in bytes.
+	Size          int64    `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	MtimeUnix     int64    `protobuf:"varint,3,opt,name=mtime_unix,json=mtimeUnix,proto3" json:"mtime_unix,omitempty"`
+	Files         []*File  `protobuf:"bytes,4,rep,name=files,proto3" json:"files,omitempty"`
+	Subdirs       []string `protobuf:"bytes,5,rep,name=subdirs,proto3" json:"subdirs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Directory) Reset() {
+	*x = Directory{}
+	mi := &file_dua_v1_scan_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Directory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Directory) ProtoMessage() {}
+
+func (x *Directory) ProtoReflect() protoreflect.Message {
+	mi := &file_dua_v1_scan_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Directory.ProtoReflect.Descriptor instead.
+func (*Directory) Descriptor() ([]byte, []int) {
+	return file_dua_v1_scan_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Directory) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Directory) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Directory) GetMtimeUnix() int64 {
+	if x != nil {
+		return x.MtimeUnix
+	}
+	return 0
+}
+
+func (x *Directory) GetFiles() []*File {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *Directory) GetSubdirs() []string {
+	if x != nil {
+		return x.Subdirs
+	}
+	return nil
+}
+
+type File struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	MtimeUnix     int64                  `protobuf:"varint,3,opt,name=mtime_unix,json=mtimeUnix,proto3" json:"mtime_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *File) Reset() {
+	*x = File{}
+	mi := &file_dua_v1_scan_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *File) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*File) ProtoMessage() {}
+
+func (x *File) ProtoReflect() protoreflect.Message {
+	mi := &file_dua_v1_scan_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use File.ProtoReflect.Descriptor instead.
+func (*File) Descriptor() ([]byte, []int) {
+	return file_dua_v1_scan_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *File) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *File) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *File) GetMtimeUnix() int64 {
+	if x != nil {
+		return x.MtimeUnix
+	}
+	return 0
+}
+
+type CancelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScanId        string                 `protobuf:"bytes,1,opt,name=scan_id,json=scanId,proto3" json:"scan_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelRequest) Reset() {
+	*x = CancelRequest{}
+	mi := &file_dua_v1_scan_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRequest) ProtoMessage() {}
+
+func (x *CancelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dua_v1_scan_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRequest.ProtoReflect.Descriptor instead.
+func (*CancelRequest) Descriptor() ([]byte, []int) {
+	return file_dua_v1_scan_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CancelRequest) GetScanId() string {
+	if x != nil {
+		return x.ScanId
+	}
+	return ""
+}
+
+type CancelResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False if the scan had already finished or didn't exist.
+	Cancelled     bool `protobuf:"varint,1,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelResponse) Reset() {
+	*x = CancelResponse{}
+	mi := &file_dua_v1_scan_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelResponse) ProtoMessage() {}
+
+func (x *CancelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dua_v1_scan_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelResponse.ProtoReflect.Descriptor instead.
+func (*CancelResponse) Descriptor() ([]byte, []int) {
+	return file_dua_v1_scan_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CancelResponse) GetCancelled() bool {
+	if x != nil {
+		return x.Cancelled
+	}
+	return false
+}
+
+var File_dua_v1_scan_proto protoreflect.FileDescriptor
+
+const file_dua_v1_scan_proto_rawDesc = "" +
+	"\n" +
+	"\x11dua/v1/scan.proto\x12\x06dua.v1\"&\n" +
+	"\x10StartScanRequest\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\"@\n" +
+	"\x11StartScanResponse\x12\x17\n" +
+	"\ascan_id\x18\x01 \x01(\tR\x06scanId\x12\x12\n" +
+	"\x04root\x18\x02 \x01(\tR\x04root\"/\n" +
+	"\x14StreamUpdatesRequest\x12\x17\n" +
+	"\ascan_id\x18\x01 \x01(\tR\x06scanId\"\xb7\x01\n" +
+	"\n" +
+	"ScanUpdate\x12/\n" +
+	"\tdirectory\x18\x01 \x01(\v2\x11.dua.v1.DirectoryR\tdirectory\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1a\n" +
+	"\bcomplete\x18\x03 \x01(\bR\bcomplete\x12#\n" +
+	"\rfiles_scanned\x18\x04 \x01(\x03R\ffilesScanned\x12!\n" +
+	"\fdirs_scanned\x18\x05 \x01(\x03R\vdirsScanned\"\x90\x01\n" +
+	"\tDirectory\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x1d\n" +
+	"\n" +
+	"mtime_unix\x18\x03 \x01(\x03R\tmtimeUnix\x12\"\n" +
+	"\x05files\x18\x04 \x03(\v2\f.dua.v1.FileR\x05files\x12\x18\n" +
+	"\asubdirs\x18\x05 \x03(\tR\asubdirs\"M\n" +
+	"\x04File\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x1d\n" +
+	"\n" +
+	"mtime_unix\x18\x03 \x01(\x03R\tmtimeUnix\"(\n" +
+	"\rCancelRequest\x12\x17\n" +
+	"\ascan_id\x18\x01 \x01(\tR\x06scanId\".\n" +
+	"\x0eCancelResponse\x12\x1c\n" +
+	"\tcancelled\x18\x01 \x01(\bR\tcancelled2\xcd\x01\n" +
+	"\vScanService\x12@\n" +
+	"\tStartScan\x12\x18.dua.v1.StartScanRequest\x1a\x19.dua.v1.StartScanResponse\x12C\n" +
+	"\rStreamUpdates\x12\x1c.dua.v1.StreamUpdatesRequest\x1a\x12.dua.v1.ScanUpdate0\x01\x127\n" +
+	"\x06Cancel\x12\x15.dua.v1.CancelRequest\x1a\x16.dua.v1.CancelResponseB.Z,github.com/corpeningc/dua/internal/rpc/duapbb\x06proto3"
+
+var (
+	file_dua_v1_scan_proto_rawDescOnce sync.Once
+	file_dua_v1_scan_proto_rawDescData []byte
+)
+
+func file_dua_v1_scan_proto_rawDescGZIP() []byte {
+	file_dua_v1_scan_proto_rawDescOnce.Do(func() {
+		file_dua_v1_scan_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_dua_v1_scan_proto_rawDesc), len(file_dua_v1_scan_proto_rawDesc)))
+	})
+	return file_dua_v1_scan_proto_rawDescData
+}
+
+var file_dua_v1_scan_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_dua_v1_scan_proto_goTypes = []any{
+	(*StartScanRequest)(nil),     // 0: dua.v1.StartScanRequest
+	(*StartScanResponse)(nil),    // 1: dua.v1.StartScanResponse
+	(*StreamUpdatesRequest)(nil), // 2: dua.v1.StreamUpdatesRequest
+	(*ScanUpdate)(nil),           // 3: dua.v1.ScanUpdate
+	(*Directory)(nil),            // 4: dua.v1.Directory
+	(*File)(nil),                 // 5: dua.v1.File
+	(*CancelRequest)(nil),        // 6: dua.v1.CancelRequest
+	(*CancelResponse)(nil),       // 7: dua.v1.CancelResponse
+}
+var file_dua_v1_scan_proto_depIdxs = []int32{
+	4, // 0: dua.v1.ScanUpdate.directory:type_name -> dua.v1.Directory
+	5, // 1: dua.v1.Directory.files:type_name -> dua.v1.File
+	0, // 2: dua.v1.ScanService.StartScan:input_type -> dua.v1.StartScanRequest
+	2, // 3: dua.v1.ScanService.StreamUpdates:input_type -> dua.v1.StreamUpdatesRequest
+	6, // 4: dua.v1.ScanService.Cancel:input_type -> dua.v1.CancelRequest
+	1, // 5: dua.v1.ScanService.StartScan:output_type -> dua.v1.StartScanResponse
+	3, // 6: dua.v1.ScanService.StreamUpdates:output_type -> dua.v1.ScanUpdate
+	7, // 7: dua.v1.ScanService.Cancel:output_type -> dua.v1.CancelResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_dua_v1_scan_proto_init() }
+func file_dua_v1_scan_proto_init() {
+	if File_dua_v1_scan_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dua_v1_scan_proto_rawDesc), len(file_dua_v1_scan_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_dua_v1_scan_proto_goTypes,
+		DependencyIndexes: file_dua_v1_scan_proto_depIdxs,
+		MessageInfos:      file_dua_v1_scan_proto_msgTypes,
+	}.Build()
+	File_dua_v1_scan_proto = out.File
+	file_dua_v1_scan_proto_goTypes = nil
+	file_dua_v1_scan_proto_depIdxs = nil
+}
@@ -0,0 +1,214 @@
+// Package rpc serves the streaming scanner over gRPC, so other programs can
+// start scans on a machine and consume their results as they arrive.
+package rpc
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=github.com/corpeningc/dua --go-grpc_out=../.. --go-grpc_opt=module=github.com/corpeningc/dua dua/v1/scan.proto
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/corpeningc/dua/internal/kiosk"
+	"github.com/corpeningc/dua/internal/rpc/duapb"
+	"github.com/corpeningc/dua/internal/scanner"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// unclaimedTimeout is how long a started scan waits for StreamUpdates before
+// it's cancelled, so clients that go away don't leave scans running.
+const unclaimedTimeout = 5 * time.Minute
+
+// Server implements duapb.ScanServiceServer.
+type Server struct {
+	duapb.UnimplementedScanServiceServer
+
+	// Paces the scans under configured paths
+	limits *scanner.RateLimits
+	// Which roots clients may scan, nil allowing any
+	policy *kiosk.Policy
+
+	mu    sync.Mutex
+	scans map[string]*scan
+}
+
+// scan is a running scan and the channels its results arrive on.
+type scan struct {
+	streamer *scanner.StreamingScanner
+	updates  <-chan scanner.StreamingUpdate
+	errs     <-chan error
+	streamed bool
+	expiry   *time.Timer
+}
+
+// NewServer returns a server with no scans running, whose scans keep to
+// limits and to the roots policy allows scanning.
+func NewServer(limits *scanner.RateLimits, policy *kiosk.Policy) *Server {
+	return &Server{limits: limits, policy: policy, scans: make(map[string]*scan)}
+}
+
+// StartScan starts scanning the requested root. Scanning only runs ahead of
+// the client by a few buffered directories until the scan is streamed.
+func (s *Server) StartScan(ctx context.Context, req *duapb.StartScanRequest) (*duapb.StartScanResponse, error) {
+	if req.GetRoot() == "" {
+		return nil, status.Error(codes.InvalidArgument, "root is required")
+	}
+	root, err := scanner.NormalizeRoot(req.GetRoot())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !s.policy.CanScan(root) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s is outside the roots this server scans", root)
+	}
+
+	id, err := newScanID()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	streamer := scanner.NewStreamingScanner()
+	streamer.SetRateLimit(s.limits.For(root))
+	updates, errs := streamer.StartStreaming(root)
+	sc := &scan{streamer: streamer, updates: updates, errs: errs}
+
+	s.mu.Lock()
+	s.scans[id] = sc
+	sc.expiry = time.AfterFunc(unclaimedTimeout, func() {
+		s.mu.Lock()
+		expired := !sc.streamed
+		s.mu.Unlock()
+		if expired {
+			s.stop(id)
+		}
+	})
+	s.mu.Unlock()
+
+	return &duapb.StartScanResponse{ScanId: id, Root: root}, nil
+}
+
+// StreamUpdates sends each scanned directory of a scan, then a complete
+// update. Unreadable directories are reported in the stream rather than
+// ending it. The scan is cancelled if the client goes away.
+func (s *Server) StreamUpdates(req *duapb.StreamUpdatesRequest, stream duapb.ScanService_StreamUpdatesServer) error {
+	id := req.GetScanId()
+
+	s.mu.Lock()
+	sc, ok := s.scans[id]
+	if ok && sc.streamed {
+		s.mu.Unlock()
+		return status.Errorf(codes.FailedPrecondition, "scan %s is already being streamed", id)
+	}
+	if ok {
+		sc.streamed = true
+		sc.expiry.Stop()
+	}
+	s.mu.Unlock()
+	if !ok {
+		return status.Errorf(codes.NotFound, "no scan %s", id)
+	}
+	defer s.stop(id)
+
+	var files, dirs int64
+	errs := sc.errs
+	for {
+		var update *duapb.ScanUpdate
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			update = &duapb.ScanUpdate{Error: err.Error()}
+		case result, ok := <-sc.updates:
+			if !ok {
+				// Cancelled before the scan finished
+				return status.Errorf(codes.Canceled, "scan %s was cancelled", id)
+			}
+			switch {
+			case result.IsComplete:
+				update = &duapb.ScanUpdate{Complete: true}
+			case result.DirInfo != nil:
+				files += int64(result.FileCount)
+				dirs++
+				update = &duapb.ScanUpdate{Directory: directory(result.DirInfo)}
+			default:
+				continue
+			}
+		}
+
+		update.FilesScanned = files
+		update.DirsScanned = dirs
+		if err := stream.Send(update); err != nil {
+			return err
+		}
+		if update.Complete {
+			return nil
+		}
+	}
+}
+
+// Cancel stops a scan, ending its stream.
+func (s *Server) Cancel(ctx context.Context, req *duapb.CancelRequest) (*duapb.CancelResponse, error) {
+	return &duapb.CancelResponse{Cancelled: s.stop(req.GetScanId())}, nil
+}
+
+// Stop cancels every running scan, for shutting the server down.
+func (s *Server) Stop() {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.scans))
+	for id := range s.scans {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	for _, id := range ids {
+		s.stop(id)
+	}
+}
+
+// stop cancels a scan and forgets it, reporting whether it was running.
+func (s *Server) stop(id string) bool {
+	s.mu.Lock()
+	sc, ok := s.scans[id]
+	delete(s.scans, id)
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	sc.expiry.Stop()
+	sc.streamer.Stop()
+	return true
+}
+
+// directory converts one scanned directory to its message, listing its own
+// files and the subdirectories that later updates will cover.
+func directory(dir *scanner.DirInfo) *duapb.Directory {
+	msg := &duapb.Directory{
+		Path:      dir.Path,
+		Size:      dir.Size,
+		MtimeUnix: dir.ModTime.Unix(),
+		Files:     make([]*duapb.File, 0, len(dir.Files)),
+		Subdirs:   make([]string, 0, len(dir.Subdirs)),
+	}
+	for _, file := range dir.Files {
+		msg.Files = append(msg.Files, &duapb.File{Name: file.Name, Size: file.Size, MtimeUnix: file.ModTime.Unix()})
+	}
+	for _, subdir := range dir.Subdirs {
+		msg.Subdirs = append(msg.Subdirs, subdir.Path)
+	}
+	return msg
+}
+
+func newScanID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/corpeningc/dua/internal/kiosk"
+	"github.com/corpeningc/dua/internal/rpc/duapb"
+	"github.com/corpeningc/dua/internal/scanner"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestStartScanRoots(t *testing.T) {
+	allowed, err := scanner.NormalizeRoot(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(nil, &kiosk.Policy{Roots: []string{allowed}})
+	defer s.Stop()
+
+	if _, err := s.StartScan(context.Background(), &duapb.StartScanRequest{Root: allowed}); err != nil {
+		t.Errorf("scanning an allowed root: %v", err)
+	}
+	_, err = s.StartScan(context.Background(), &duapb.StartScanRequest{Root: t.TempDir()})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("scanning outside the roots: %v, want PermissionDenied", err)
+	}
+}
+
+func TestCheckToken(t *testing.T) {
+	tests := []struct {
+		name string
+		md   metadata.MD
+		ok   bool
+	}{
+		{"right token", metadata.Pairs("authorization", "Bearer secret"), true},
+		{"wrong token", metadata.Pairs("authorization", "Bearer guess"), false},
+		{"token without scheme", metadata.Pairs("authorization", "secret"), false},
+		{"no token", metadata.MD{}, false},
+	}
+	for _, test := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), test.md)
+		if err := checkToken(ctx, "secret"); (err == nil) != test.ok {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+// Package scandb records scans in a SQLite database, one snapshot per scan,
+// for ad-hoc SQL analysis and comparing scans over time.
+package scandb
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/corpeningc/dua/internal/treefile"
+	_ "modernc.org/sqlite"
+)
+
+// schema creates the tables on first use. Directory sizes are totals of
+// everything below them, as in the tree view.
+const schema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	id       INTEGER PRIMARY KEY,
+	root     TEXT    NOT NULL,
+	taken_at INTEGER NOT NULL, -- Unix seconds
+	files    INTEGER NOT NULL,
+	dirs     INTEGER NOT NULL,
+	bytes    INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS entries (
+	snapshot_id INTEGER NOT NULL REFERENCES snapshots(id) ON DELETE CASCADE,
+	path        TEXT    NOT NULL,
+	parent      TEXT    NOT NULL,
+	name        TEXT    NOT NULL,
+	is_dir      INTEGER NOT NULL,
+	size        INTEGER NOT NULL,
+	mtime       INTEGER NOT NULL -- Unix seconds
+);
+
+CREATE INDEX IF NOT EXISTS entries_path  ON entries(path, snapshot_id);
+CREATE INDEX IF NOT EXISTS entries_size  ON entries(snapshot_id, size);
+CREATE INDEX IF NOT EXISTS entries_mtime ON entries(snapshot_id, mtime);
+`
+
+// DB is a database of scan snapshots.
+type DB struct {
+	db *sql.DB
+}
+
+// Snapshot summarizes one recorded scan.
+type Snapshot struct {
+	ID    int64
+	Root  string
+	Taken time.Time
+	Files int
+	Dirs  int
+	Bytes int64
+}
+
+// entry is a scanned file or directory waiting to be written.
+type entry struct {
+	path    string
+	isDir   bool
+	size    int64
+	modTime time.Time
+}
+
+// Open opens the database at path, creating it and its tables if needed.
+func Open(path string) (*DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Record scans root and writes it as a new snapshot. Directories that can't
+// be read are recorded without their contents.
+func (d *DB) Record(root string) (Snapshot, error) {
+	snapshot := Snapshot{Root: root, Taken: time.Now()}
+
+	var entries []entry
+	index := make(map[string]int) // Directory path to its position in entries
+	err := filepath.WalkDir(root, func(path string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			if dirEntry != nil && dirEntry.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			return nil
+		}
+
+		e := entry{path: path, isDir: dirEntry.IsDir(), modTime: info.ModTime()}
+		if e.isDir {
+			index[path] = len(entries)
+			snapshot.Dirs++
+		} else {
+			e.size = info.Size()
+			snapshot.Files++
+			snapshot.Bytes += e.size
+
+			// Count the file towards every directory above it
+			for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+				if i, ok := index[dir]; ok {
+					entries[i].size += e.size
+				}
+				if dir == root || dir == filepath.Dir(dir) {
+					break
+				}
+			}
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return snapshot, err
+	}
+	return d.insert(snapshot, entries)
+}
+
+// RecordTree writes an already scanned tree as a new snapshot, for callers
+// that need the tree as well and shouldn't walk the disk twice.
+func (d *DB) RecordTree(doc treefile.Document) (Snapshot, error) {
+	snapshot := Snapshot{Root: doc.Root, Taken: doc.Scanned}
+
+	var entries []entry
+	var walk func(dir *treefile.Dir, path string)
+	walk = func(dir *treefile.Dir, path string) {
+		entries = append(entries, entry{path: path, isDir: true, size: dir.Size, modTime: dir.ModTime})
+		snapshot.Dirs++
+		if dir.Aggregated {
+			// Rolled up, only the totals are left
+			snapshot.Files += dir.FileCount
+			snapshot.Dirs += dir.DirCount
+			snapshot.Bytes += dir.Size
+		}
+		for _, file := range dir.Files {
+			entries = append(entries, entry{path: filepath.Join(path, file.Name), size: file.Size, modTime: file.ModTime})
+			snapshot.Files++
+			snapshot.Bytes += file.Size
+		}
+		for _, sub := range dir.Dirs {
+			walk(sub, filepath.Join(path, sub.Name))
+		}
+	}
+	walk(doc.Tree, doc.Root)
+
+	return d.insert(snapshot, entries)
+}
+
+// insert writes a snapshot and its entries in one transaction.
+func (d *DB) insert(snapshot Snapshot, entries []entry) (Snapshot, error) {
+	root := snapshot.Root
+	tx, err := d.db.Begin()
+	if err != nil {
+		return snapshot, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO snapshots (root, taken_at, files, dirs, bytes) VALUES (?, ?, ?, ?, ?)`,
+		root, snapshot.Taken.Unix(), snapshot.Files, snapshot.Dirs, snapshot.Bytes)
+	if err != nil {
+		return snapshot, err
+	}
+	if snapshot.ID, err = result.LastInsertId(); err != nil {
+		return snapshot, err
+	}
+
+	insert, err := tx.Prepare(`INSERT INTO entries (snapshot_id, path, parent, name, is_dir, size, mtime) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return snapshot, err
+	}
+	defer insert.Close()
+
+	for _, e := range entries {
+		parent := filepath.Dir(e.path)
+		if e.path == root {
+			parent = ""
+		}
+		if _, err := insert.Exec(snapshot.ID, e.path, parent, filepath.Base(e.path), e.isDir, e.size, e.modTime.Unix()); err != nil {
+			return snapshot, err
+		}
+	}
+
+	return snapshot, tx.Commit()
+}
+
+// Snapshots lists the snapshots of root, oldest first.
+func (d *DB) Snapshots(root string) ([]Snapshot, error) {
+	rows, err := d.db.Query(`SELECT id, root, taken_at, files, dirs, bytes FROM snapshots WHERE root = ? ORDER BY taken_at, id`, root)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []Snapshot
+	for rows.Next() {
+		var s Snapshot
+		var taken int64
+		if err := rows.Scan(&s.ID, &s.Root, &taken, &s.Files, &s.Dirs, &s.Bytes); err != nil {
+			return nil, err
+		}
+		s.Taken = time.Unix(taken, 0)
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// DirGrowth is how much a directory changed between two snapshots.
+type DirGrowth struct {
+	Path   string
+	Before int64 // 0 if the directory didn't exist yet
+	After  int64
+}
+
+// ChildGrowth compares the directories directly below root in two snapshots.
+// Directories that were removed since aren't listed.
+func (d *DB) ChildGrowth(root string, from, to int64) ([]DirGrowth, error) {
+	rows, err := d.db.Query(`
+		SELECT after.path, COALESCE(before.size, 0), after.size
+		FROM entries after
+		LEFT JOIN entries before ON before.snapshot_id = ? AND before.path = after.path
+		WHERE after.snapshot_id = ? AND after.parent = ? AND after.is_dir`, from, to, root)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var growth []DirGrowth
+	for rows.Next() {
+		var g DirGrowth
+		if err := rows.Scan(&g.Path, &g.Before, &g.After); err != nil {
+			return nil, err
+		}
+		growth = append(growth, g)
+	}
+	return growth, rows.Err()
+}
+
+// GrowthPerDay fits a line through the snapshots' total sizes and returns its
+// slope in bytes per day. It needs snapshots taken at two different times.
+func GrowthPerDay(snapshots []Snapshot) (float64, bool) {
+	if len(snapshots) < 2 {
+		return 0, false
+	}
+
+	// Least squares, with times in days relative to the first snapshot
+	origin := snapshots[0].Taken
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range snapshots {
+		x := s.Taken.Sub(origin).Hours() / 24
+		y := float64(s.Bytes)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(snapshots))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / denominator, true
+}
+
+// Prune deletes snapshots of root taken before cutoff, always keeping the
+// latest one so there's something to compare new scans with. It returns how
+// many were deleted.
+func (d *DB) Prune(root string, cutoff time.Time) (int, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	old := `SELECT id FROM snapshots WHERE root = ? AND taken_at < ?
+		AND id != (SELECT id FROM snapshots WHERE root = ? ORDER BY taken_at DESC, id DESC LIMIT 1)`
+	if _, err := tx.Exec(`DELETE FROM entries WHERE snapshot_id IN (`+old+`)`, root, cutoff.Unix(), root); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(`DELETE FROM snapshots WHERE id IN (`+old+`)`, root, cutoff.Unix(), root)
+	if err != nil {
+		return 0, err
+	}
+	pruned, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(pruned), tx.Commit()
+}
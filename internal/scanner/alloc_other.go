@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package scanner
+
+import "io/fs"
+
+// Allocated returns the disk space the file at path takes. It isn't
+// recorded here, so it's the size.
+func Allocated(path string, info fs.FileInfo) int64 {
+	return info.Size()
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// Allocated returns the disk space the file at path takes, which is less
+// than its size for sparse files and on compressing filesystems, and more
+// for files not filling their last block. Where that isn't known it's the
+// size.
+func Allocated(path string, info fs.FileInfo) int64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size()
+	}
+	// st_blocks counts 512-byte units whatever the filesystem's block size
+	return int64(stat.Blocks) * 512
+}
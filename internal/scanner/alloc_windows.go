@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32                  = windows.NewLazySystemDLL("kernel32.dll")
+	procGetDiskFreeSpace      = kernel32.NewProc("GetDiskFreeSpaceW")
+	procGetCompressedFileSize = kernel32.NewProc("GetCompressedFileSizeW")
+
+	// clusterSizes caches each volume's cluster size, 0 if unknown
+	clusterSizes sync.Map
+)
+
+// invalidFileSize is what GetCompressedFileSizeW returns on failure, unless
+// the high half is set.
+const invalidFileSize = 0xFFFFFFFF
+
+// Allocated returns the disk space the file at path takes: its size rounded
+// up to whole clusters, and for sparse and NTFS-compressed files what's
+// actually stored. Where that isn't known it's the size.
+func Allocated(path string, info fs.FileInfo) int64 {
+	size := info.Size()
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok &&
+		data.FileAttributes&(windows.FILE_ATTRIBUTE_SPARSE_FILE|windows.FILE_ATTRIBUTE_COMPRESSED) != 0 {
+		if stored, ok := compressedSize(path); ok {
+			size = stored
+		}
+	}
+	cluster := clusterSize(path)
+	if cluster == 0 {
+		return size
+	}
+	return (size + cluster - 1) / cluster * cluster
+}
+
+// compressedSize returns how much of a sparse or compressed file is on disk.
+func compressedSize(path string) (int64, bool) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, false
+	}
+	var high uint32
+	low, _, err := procGetCompressedFileSize.Call(uintptr(unsafe.Pointer(name)), uintptr(unsafe.Pointer(&high)))
+	if low == invalidFileSize && err != windows.ERROR_SUCCESS {
+		return 0, false
+	}
+	return int64(high)<<32 | int64(low), true
+}
+
+// clusterSize returns the allocation unit of the volume holding path, looked
+// up once per volume.
+func clusterSize(path string) int64 {
+	volume := filepath.VolumeName(path) + `\`
+	if size, ok := clusterSizes.Load(volume); ok {
+		return size.(int64)
+	}
+	var sectorsPerCluster, bytesPerSector, freeClusters, totalClusters uint32
+	var size int64
+	if name, err := windows.UTF16PtrFromString(volume); err == nil {
+		ok, _, _ := procGetDiskFreeSpace.Call(
+			uintptr(unsafe.Pointer(name)),
+			uintptr(unsafe.Pointer(&sectorsPerCluster)),
+			uintptr(unsafe.Pointer(&bytesPerSector)),
+			uintptr(unsafe.Pointer(&freeClusters)),
+			uintptr(unsafe.Pointer(&totalClusters)),
+		)
+		if ok != 0 {
+			size = int64(sectorsPerCluster) * int64(bytesPerSector)
+		}
+	}
+	clusterSizes.Store(volume, size)
+	return size
+}
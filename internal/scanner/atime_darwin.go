@@ -0,0 +1,23 @@
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// AccessTime returns when the file was last read, if the platform records it.
+func AccessTime(info fs.FileInfo) (time.Time, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(stat.Atimespec.Sec, stat.Atimespec.Nsec), true
+}
+
+// AccessTimesTracked reports whether the filesystem holding path updates
+// access times. It is assumed on macOS, which only skips updates for mounts
+// explicitly set to noatime.
+func AccessTimesTracked(path string) bool {
+	return true
+}
@@ -0,0 +1,30 @@
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+
+	"github.com/corpeningc/dua/internal/fsusage"
+)
+
+// AccessTime returns when the file was last read, if the platform records it.
+func AccessTime(info fs.FileInfo) (time.Time, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(stat.Atim.Sec, stat.Atim.Nsec), true
+}
+
+// AccessTimesTracked reports whether the filesystem holding path updates
+// access times. Mounts with noatime never do; relatime only updates them once
+// a day, which is plenty for finding files unread for months.
+func AccessTimesTracked(path string) bool {
+	mounts, err := fsusage.Mounts()
+	if err != nil {
+		return true
+	}
+	mount, ok := fsusage.MountOf(mounts, path)
+	return !ok || !mount.HasOption("noatime")
+}
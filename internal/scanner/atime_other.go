@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !windows
+
+package scanner
+
+import (
+	"io/fs"
+	"time"
+)
+
+// AccessTime returns when the file was last read, if the platform records it.
+func AccessTime(info fs.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// AccessTimesTracked reports whether the filesystem holding path updates
+// access times.
+func AccessTimesTracked(path string) bool {
+	return false
+}
@@ -0,0 +1,23 @@
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// AccessTime returns when the file was last read, if the platform records it.
+func AccessTime(info fs.FileInfo) (time.Time, bool) {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, data.LastAccessTime.Nanoseconds()), true
+}
+
+// AccessTimesTracked reports whether the filesystem holding path updates
+// access times. NTFS last-access updates can be turned off system wide, which
+// isn't visible here, so this assumes they are on.
+func AccessTimesTracked(path string) bool {
+	return true
+}
@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"slices"
+	"sync/atomic"
+
+	"github.com/corpeningc/dua/internal/fsusage"
+)
+
+// baseline is the tree of an earlier scan, whose listings a rescan reuses
+// for directories that haven't changed.
+type baseline struct {
+	dirs   map[string]*DirInfo
+	reused atomic.Int64
+}
+
+// SetBaseline has the scan reuse the listing of each directory in previous,
+// an earlier scan of the same root, whose modification time is unchanged,
+// rather than reading it again. Adding, removing or renaming an entry
+// changes a directory's modification time, so a single stat tells whether
+// its listing still holds, saving a read and a stat per entry. That's most
+// of the cost on a network filesystem, where each is a round trip to the
+// server. Files changed in place keep their old size and time, as that
+// doesn't touch the directory. previous must not change during the scan,
+// and it must be called before streaming starts.
+func (s *StreamingScanner) SetBaseline(previous *DirInfo) {
+	b := &baseline{dirs: make(map[string]*DirInfo)}
+	var index func(dir *DirInfo)
+	index = func(dir *DirInfo) {
+		// Pruned directories lost their files, duplicates never had any
+		if dir.IsLoaded && !dir.Pruned && dir.DuplicateOf == "" && !dir.ModTime.IsZero() {
+			b.dirs[dir.Path] = dir
+		}
+		for i := range dir.Subdirs {
+			index(&dir.Subdirs[i])
+		}
+	}
+	index(previous)
+	s.baseline = b
+}
+
+// Reused returns how many directories the scan took from the baseline
+// without reading them.
+func (s *StreamingScanner) Reused() int {
+	if s.baseline == nil {
+		return 0
+	}
+	return int(s.baseline.reused.Load())
+}
+
+// reuse returns the listing of the directory at path from the baseline, if
+// its modification time shows it hasn't changed since.
+func (s *StreamingScanner) reuse(path string) (*DirInfo, bool) {
+	if s.baseline == nil {
+		return nil, false
+	}
+	previous, ok := s.baseline.dirs[path]
+	if !ok {
+		return nil, false
+	}
+	info, err := s.fs.Stat(path)
+	if err != nil || !info.ModTime().Equal(previous.ModTime) {
+		return nil, false
+	}
+
+	dirInfo := DirInfo{
+		Path:        path,
+		ModTime:     previous.ModTime,
+		Mode:        previous.Mode,
+		Files:       slices.Clone(previous.Files),
+		Subdirs:     make([]DirInfo, 0, len(previous.Subdirs)),
+		IsLoaded:    true,
+		FileCount:   len(previous.Files),
+		SubdirCount: len(previous.Subdirs),
+		PendingDirs: len(previous.Subdirs),
+	}
+	if previous.Sample != nil {
+		sample := *previous.Sample
+		dirInfo.Sample = &sample
+		dirInfo.Size = sample.Size
+		dirInfo.Alloc = sample.Alloc
+		dirInfo.FileCount = sample.Files
+	} else {
+		s.links.relink(path, dirInfo.Files)
+		for _, file := range dirInfo.Files {
+			size, alloc := file.Counted()
+			dirInfo.Size += size
+			dirInfo.Alloc += alloc
+		}
+	}
+	// Subdirectories are checked in turn, changes deep down don't reach
+	// the modification times above them
+	for _, subdir := range previous.Subdirs {
+		dirInfo.Subdirs = append(dirInfo.Subdirs, DirInfo{
+			Path:        subdir.Path,
+			ModTime:     subdir.ModTime,
+			Mode:        subdir.Mode,
+			Files:       []FileInfo{},
+			Subdirs:     []DirInfo{},
+			PendingDirs: 1,
+		})
+	}
+	s.baseline.reused.Add(1)
+	return &dirInfo, true
+}
+
+// NetworkFS reports whether path is on a network filesystem, where reusing
+// unchanged listings pays off most.
+func NetworkFS(path string) bool {
+	mounts, err := fsusage.Mounts()
+	if err != nil {
+		return false
+	}
+	mount, ok := fsusage.MountOf(mounts, path)
+	return ok && mount.Network()
+}
@@ -0,0 +1,224 @@
+package scanner
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/corpeningc/dua/internal/paths"
+)
+
+// checkpointInterval is how often the journal is flushed to disk, and so
+// about how much work a crash can lose.
+const checkpointInterval = 5 * time.Second
+
+// checkpointDir is one scanned directory as journaled: its files and the
+// names of its subdirectories, which are either journaled too or still to
+// be scanned.
+type checkpointDir struct {
+	Path    string             `json:"path"`
+	ModTime time.Time          `json:"mtime"`
+	Mode    fs.FileMode        `json:"mode,omitempty"`
+	Files   []FileInfo         `json:"files,omitempty"`
+	Dirs    []checkpointSubdir `json:"dirs,omitempty"`
+	Sample  *Sample            `json:"sample,omitempty"`
+}
+
+type checkpointSubdir struct {
+	Name        string    `json:"name"`
+	ModTime     time.Time `json:"mtime"`
+	DuplicateOf string    `json:"duplicate_of,omitempty"`
+}
+
+// journal appends a line per scanned directory to a checkpoint file. Lines
+// are only ever appended, so a crash leaves at worst a torn last line.
+type journal struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	done   chan struct{}
+}
+
+// CheckpointPath is where an interrupted scan of root is journaled, for
+// --resume to pick up.
+func CheckpointPath(root string) (string, error) {
+	sum := sha256.Sum256([]byte(root))
+	return paths.CacheFile(filepath.Join("checkpoints", hex.EncodeToString(sum[:8])+".jsonl"))
+}
+
+// openJournal starts a checkpoint file, or continues the one being resumed.
+func openJournal(file string, resume bool) (*journal, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return nil, err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if resume {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	f, err := os.OpenFile(file, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	j := &journal{file: f, writer: bufio.NewWriterSize(f, 1<<20), done: make(chan struct{})}
+	go j.flushPeriodically()
+	return j, nil
+}
+
+func (j *journal) flushPeriodically() {
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			j.mu.Lock()
+			if j.writer != nil {
+				j.writer.Flush()
+			}
+			j.mu.Unlock()
+		case <-j.done:
+			return
+		}
+	}
+}
+
+// record journals a scanned directory.
+func (j *journal) record(dir *DirInfo) {
+	entry := checkpointDir{Path: dir.Path, ModTime: dir.ModTime, Mode: dir.Mode, Files: dir.Files, Sample: dir.Sample}
+	for _, subdir := range dir.Subdirs {
+		entry.Dirs = append(entry.Dirs, checkpointSubdir{
+			Name:        filepath.Base(subdir.Path),
+			ModTime:     subdir.ModTime,
+			DuplicateOf: subdir.DuplicateOf,
+		})
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.writer != nil {
+		j.writer.Write(append(line, '\n'))
+	}
+}
+
+// close flushes the journal and closes it. A finished scan has nothing to
+// resume, so its journal is removed.
+func (j *journal) close(finished bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.writer == nil {
+		return
+	}
+	close(j.done)
+	j.writer.Flush()
+	j.file.Close()
+	if finished {
+		os.Remove(j.file.Name())
+	}
+	j.writer = nil
+}
+
+// LoadCheckpoint rebuilds the tree of an interrupted scan of root from its
+// journal. Directories that weren't reached are returned as placeholders
+// in the tree and listed in pending, to be scanned on resuming. Directories
+// deleted since are left out.
+func LoadCheckpoint(file, root string) (*DirInfo, []string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	scanned := make(map[string]*checkpointDir)
+	reader := bufio.NewReaderSize(f, 1<<20)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var entry checkpointDir
+			// A torn line from a crash is skipped, its directory is
+			// scanned again
+			if json.Unmarshal(line, &entry) == nil {
+				scanned[entry.Path] = &entry
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if scanned[root] == nil {
+		return nil, nil, fmt.Errorf("%s: no progress recorded for %s", file, root)
+	}
+	var pending []string
+	tree := buildCheckpointDir(scanned, scanned[root], newHardlinks(), &pending)
+	return tree, pending, nil
+}
+
+// buildCheckpointDir rebuilds the journaled directory at entry. Hard links
+// are worked out again with links, as directories deleted since may have
+// held the ones that were counted.
+func buildCheckpointDir(scanned map[string]*checkpointDir, entry *checkpointDir, links *hardlinks, pending *[]string) *DirInfo {
+	dir := &DirInfo{
+		Path:        entry.Path,
+		ModTime:     entry.ModTime,
+		Mode:        entry.Mode,
+		Files:       entry.Files,
+		Subdirs:     []DirInfo{},
+		IsLoaded:    true,
+		FileCount:   len(entry.Files),
+		SubdirCount: len(entry.Dirs),
+	}
+	if dir.Files == nil {
+		dir.Files = []FileInfo{}
+	}
+	if entry.Sample != nil {
+		dir.Sample = entry.Sample
+		dir.Size = entry.Sample.Size
+		dir.Alloc = entry.Sample.Alloc
+		dir.FileCount = entry.Sample.Files
+	} else {
+		links.relink(dir.Path, dir.Files)
+		for _, file := range dir.Files {
+			size, alloc := file.Counted()
+			dir.Size += size
+			dir.Alloc += alloc
+		}
+	}
+
+	for _, sub := range entry.Dirs {
+		path := filepath.Join(entry.Path, sub.Name)
+		if _, err := os.Lstat(path); err != nil {
+			dir.SubdirCount--
+			continue
+		}
+		var child *DirInfo
+		switch {
+		case sub.DuplicateOf != "":
+			child = &DirInfo{Path: path, ModTime: sub.ModTime, Files: []FileInfo{}, Subdirs: []DirInfo{}, IsLoaded: true, DuplicateOf: sub.DuplicateOf}
+		case scanned[path] != nil:
+			child = buildCheckpointDir(scanned, scanned[path], links, pending)
+		default:
+			child = &DirInfo{Path: path, ModTime: sub.ModTime, Files: []FileInfo{}, Subdirs: []DirInfo{}, PendingDirs: 1}
+			*pending = append(*pending, path)
+		}
+		dir.Subdirs = append(dir.Subdirs, *child)
+		dir.Size += child.Size
+		dir.Alloc += child.Alloc
+		dir.PendingDirs += child.PendingDirs
+	}
+	return dir
+}
@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// fileID identifies a file across its hard links. It's journaled with the
+// file, so a resumed scan can tell the links apart again.
+type fileID struct {
+	Dev uint64 `json:"dev"`
+	Ino uint64 `json:"ino"`
+}
+
+// hardlinks remembers the files with several links seen in a scan, so each
+// is counted once, under the first of its paths reached.
+type hardlinks struct {
+	mu   sync.Mutex
+	seen map[fileID]string
+}
+
+func newHardlinks() *hardlinks {
+	return &hardlinks{seen: make(map[fileID]string)}
+}
+
+// counted returns the path id was already counted under, or "" after
+// recording path as the one counting it.
+func (h *hardlinks) counted(id fileID, path string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if first, ok := h.seen[id]; ok {
+		return first
+	}
+	h.seen[id] = path
+	return ""
+}
+
+// relink works out again which of files, listed in dir by an earlier scan,
+// are links to a file this scan counts elsewhere. What was counted then
+// may not be reached now, or be reached after another of its links. nil
+// counts every link, as readDirectory does.
+func (h *hardlinks) relink(dir string, files []FileInfo) {
+	for i := range files {
+		file := &files[i]
+		file.LinkOf = ""
+		if h != nil && file.Links > 1 && file.ID != (fileID{}) {
+			file.LinkOf = h.counted(file.ID, filepath.Join(dir, file.Name))
+		}
+	}
+}
+
+// seed records the files tree counts, so links to them found by scanning
+// on from it aren't counted again.
+func (h *hardlinks) seed(tree *DirInfo) {
+	for _, file := range tree.Files {
+		if file.Links > 1 && file.LinkOf == "" && file.ID != (fileID{}) {
+			h.counted(file.ID, filepath.Join(tree.Path, file.Name))
+		}
+	}
+	for i := range tree.Subdirs {
+		h.seed(&tree.Subdirs[i])
+	}
+}
+
+// Counted returns what the file adds to its directory's totals: its size
+// and disk space, or nothing for a hard link to a file counted elsewhere.
+func (f FileInfo) Counted() (size, alloc int64) {
+	if f.LinkOf != "" {
+		return 0, 0
+	}
+	return f.Size, f.Alloc
+}
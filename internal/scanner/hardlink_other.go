@@ -0,0 +1,12 @@
+//go:build !unix
+
+package scanner
+
+import "io/fs"
+
+// linkInfo returns the identity of the file and how many hard links it has.
+// Directory listings elsewhere don't carry them, so hard links are counted
+// at each of their paths.
+func linkInfo(info fs.FileInfo) (fileID, int, bool) {
+	return fileID{}, 0, false
+}
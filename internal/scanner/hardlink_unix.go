@@ -0,0 +1,17 @@
+//go:build unix
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// linkInfo returns the identity of the file and how many hard links it has.
+func linkInfo(info fs.FileInfo) (fileID, int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, 0, false
+	}
+	return fileID{Dev: uint64(stat.Dev), Ino: uint64(stat.Ino)}, int(stat.Nlink), true
+}
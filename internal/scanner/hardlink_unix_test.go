@@ -0,0 +1,144 @@
+//go:build unix
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const linkedSize = 10_000
+
+// linkedTree builds a, b and c under a temporary root, each holding a link
+// to the same file.
+func linkedTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range []string{"a", "b", "c"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first := filepath.Join(root, "a", "f")
+	if err := os.WriteFile(first, make([]byte, linkedSize), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range []string{"b/g", "c/h"} {
+		if err := os.Link(first, filepath.Join(root, link)); err != nil {
+			t.Skipf("no hard links here: %v", err)
+		}
+	}
+	return root
+}
+
+// countedLinks returns how many of the links in tree are counted.
+func countedLinks(tree *DirInfo) int {
+	n := 0
+	for _, file := range tree.Files {
+		if file.Links > 1 && file.LinkOf == "" {
+			n++
+		}
+	}
+	for i := range tree.Subdirs {
+		n += countedLinks(&tree.Subdirs[i])
+	}
+	return n
+}
+
+func scanTree(t *testing.T, s *StreamingScanner, root string) DirInfo {
+	t.Helper()
+	updates, errs := s.StartStreaming(root)
+	dirs, scanErrs := drain(t, s, updates, errs)
+	if len(scanErrs) > 0 {
+		t.Fatal(scanErrs)
+	}
+	return assemble(root, dirs)
+}
+
+func TestHardLinksCountedOnce(t *testing.T) {
+	root := linkedTree(t)
+	tree := scanTree(t, NewStreamingScanner(), root)
+	if tree.Size != linkedSize || countedLinks(&tree) != 1 {
+		t.Errorf("scan counts %d bytes in %d links, want %d in 1", tree.Size, countedLinks(&tree), linkedSize)
+	}
+}
+
+// TestHardLinksBaseline removes the link the first scan counted. The rescan
+// reuses the listings holding the other two, which must count one of them
+// rather than keep pointing at the link that's gone.
+func TestHardLinksBaseline(t *testing.T) {
+	root := linkedTree(t)
+	previous := scanTree(t, NewStreamingScanner(), root)
+
+	counted := ""
+	for _, dir := range previous.Subdirs {
+		for _, file := range dir.Files {
+			if file.LinkOf == "" {
+				counted = filepath.Join(dir.Path, file.Name)
+			}
+		}
+	}
+	if err := os.Remove(counted); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStreamingScanner()
+	s.SetBaseline(&previous)
+	tree := scanTree(t, s, root)
+	if s.Reused() == 0 {
+		t.Fatal("rescan reused nothing")
+	}
+	if tree.Size != linkedSize || countedLinks(&tree) != 1 {
+		t.Errorf("rescan counts %d bytes in %d links, want %d in 1", tree.Size, countedLinks(&tree), linkedSize)
+	}
+}
+
+// TestHardLinksCheckpoint resumes a scan that journaled a and b before it
+// was interrupted, with a deleted since. b's link is the one to count then,
+// and c's, scanned on resuming, is a link to it.
+func TestHardLinksCheckpoint(t *testing.T) {
+	root := linkedTree(t)
+	s := NewStreamingScanner()
+	updates, errs := s.StartStreaming(root)
+	dirs, _ := drain(t, s, updates, errs)
+
+	file := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+	j, err := openJournal(file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Journaled in this order, a's link is counted and b's points at it
+	a, b := *dirs[filepath.Join(root, "a")], *dirs[filepath.Join(root, "b")]
+	a.Files[0].LinkOf = ""
+	b.Files[0].LinkOf = filepath.Join(a.Path, a.Files[0].Name)
+	for _, dir := range []*DirInfo{dirs[root], &a, &b} {
+		j.record(dir)
+	}
+	j.close(false)
+	if err := os.RemoveAll(a.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, pending, err := LoadCheckpoint(file, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Size != linkedSize {
+		t.Errorf("resumed tree counts %d bytes, want %d", tree.Size, linkedSize)
+	}
+	if len(pending) != 1 || pending[0] != filepath.Join(root, "c") {
+		t.Fatalf("pending %v, want c", pending)
+	}
+
+	s = NewStreamingScanner()
+	updates, errs = s.ResumeStreaming(tree, pending)
+	resumed, _ := drain(t, s, updates, errs)
+	c := resumed[filepath.Join(root, "c")]
+	if c == nil {
+		t.Fatal("c wasn't scanned")
+	}
+	if c.Size != 0 || c.Files[0].LinkOf == "" {
+		t.Errorf("c counts %d bytes, its link counted again", c.Size)
+	}
+}
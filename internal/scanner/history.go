@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/corpeningc/dua/internal/paths"
+)
+
+// LastScan is what the last complete scan of a root found and how long it
+// took, kept to estimate how long the next one will take.
+type LastScan struct {
+	Files    int64         `json:"files"`
+	Dirs     int64         `json:"dirs"`
+	Duration time.Duration `json:"duration"`
+	Finished time.Time     `json:"finished"`
+}
+
+// LastScanPath is where the last complete scan of root is recorded.
+func LastScanPath(root string) (string, error) {
+	sum := sha256.Sum256([]byte(root))
+	return paths.CacheFile(filepath.Join("scans", hex.EncodeToString(sum[:8])+".json"))
+}
+
+// LoadLastScan reads the record of a scan saved with SaveLastScan.
+func LoadLastScan(file string) (LastScan, error) {
+	var scan LastScan
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return scan, err
+	}
+	err = json.Unmarshal(data, &scan)
+	return scan, err
+}
+
+// SaveLastScan records scan to file, replacing the previous one.
+func SaveLastScan(file string, scan LastScan) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(scan)
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
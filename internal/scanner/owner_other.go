@@ -0,0 +1,11 @@
+//go:build !unix
+
+package scanner
+
+import "io/fs"
+
+// Owner returns the name of the user owning the file, or their numeric ID if
+// the name can't be looked up. It returns "" where ownership isn't recorded.
+func Owner(info fs.FileInfo) string {
+	return ""
+}
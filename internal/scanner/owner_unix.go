@@ -0,0 +1,36 @@
+//go:build unix
+
+package scanner
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// ownerNames caches user lookups, which read /etc/passwd or ask the
+// directory service every time.
+var ownerNames sync.Map
+
+// Owner returns the name of the user owning the file, or their numeric ID if
+// the name can't be looked up. It returns "" where ownership isn't recorded.
+func Owner(info fs.FileInfo) string {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+
+	uid := strconv.FormatUint(uint64(stat.Uid), 10)
+	if name, ok := ownerNames.Load(uid); ok {
+		return name.(string)
+	}
+
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	ownerNames.Store(uid, name)
+	return name
+}
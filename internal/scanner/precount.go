@@ -0,0 +1,73 @@
+package scanner
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/corpeningc/dua/internal/fsusage"
+	"github.com/corpeningc/dua/internal/vfs"
+)
+
+// Count is how many directories and files are below a directory, as a scan
+// of it would find them.
+type Count struct {
+	Dirs  int64
+	Files int64
+}
+
+// Precount counts what a scan of root will find, for showing how far along
+// the scan is. It only lists directories, without statting files, so it
+// runs well ahead of a scan started at the same time. Mount points
+// duplicating other paths under root are counted but not entered, as the
+// scan leaves them unscanned.
+func Precount(ctx context.Context, fsys vfs.FS, root string) (Count, error) {
+	var duplicates map[string]string
+	if mounts, err := fsusage.Mounts(); err == nil && fsys == vfs.OS {
+		duplicates = fsusage.Duplicates(mounts, root)
+	}
+
+	var dirs, files atomic.Int64
+	var wg sync.WaitGroup
+	// Directories are read on new goroutines while there are free slots,
+	// and on the one that found them otherwise
+	slots := make(chan struct{}, runtime.NumCPU()*8)
+	var walk func(path string)
+	walk = func(path string) {
+		if ctx.Err() != nil {
+			return
+		}
+		entries, err := fsys.ReadDir(path)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files.Add(1)
+				continue
+			}
+			dirs.Add(1)
+			subdir := filepath.Join(path, entry.Name())
+			if _, ok := duplicates[subdir]; ok {
+				continue
+			}
+			select {
+			case slots <- struct{}{}:
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					walk(subdir)
+					<-slots
+				}()
+			default:
+				walk(subdir)
+			}
+		}
+	}
+	walk(root)
+	wg.Wait()
+
+	return Count{Dirs: dirs.Load(), Files: files.Load()}, ctx.Err()
+}
@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+
+	"github.com/corpeningc/dua/internal/paths"
+	"github.com/corpeningc/dua/internal/vfs"
+	"golang.org/x/time/rate"
+)
+
+// RateLimits caps how many directory reads and stats scans under configured
+// paths make a second, so scans running in the background don't compete
+// with the workloads using the disk. Each path has a single limiter, shared
+// by every scan under it however many run at once.
+type RateLimits struct {
+	// Longest first, so the innermost path holding a root applies
+	paths    []string
+	limiters map[string]*rate.Limiter
+}
+
+// NewRateLimits limits scans under each path in perSecond to that many
+// operations a second. Limits of 0 or less don't limit.
+func NewRateLimits(perSecond map[string]float64) *RateLimits {
+	r := &RateLimits{limiters: make(map[string]*rate.Limiter)}
+	for path, limit := range perSecond {
+		if limit <= 0 {
+			continue
+		}
+		if resolved, err := NormalizeRoot(path); err == nil {
+			path = resolved
+		} else {
+			// Not there yet, it may be mounted later
+			path = filepath.Clean(path)
+		}
+		r.paths = append(r.paths, path)
+		r.limiters[path] = rate.NewLimiter(rate.Limit(limit), max(int(limit), 1))
+	}
+	slices.SortFunc(r.paths, func(a, b string) int { return len(b) - len(a) })
+	return r
+}
+
+// For returns the limiter of the innermost configured path holding root,
+// or nil if none does.
+func (r *RateLimits) For(root string) *rate.Limiter {
+	if r == nil {
+		return nil
+	}
+	for _, path := range r.paths {
+		if paths.Within(root, path) {
+			return r.limiters[path]
+		}
+	}
+	return nil
+}
+
+// SetRateLimit has the scan wait for limiter before each directory read and
+// stat, nil not waiting. It must be called before streaming starts.
+func (s *StreamingScanner) SetRateLimit(limiter *rate.Limiter) {
+	s.limiter = limiter
+}
+
+// limitedFS waits for its limiter before each operation, including the stat
+// behind each listed entry's Info.
+type limitedFS struct {
+	vfs.FS
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (l limitedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := l.limiter.Wait(l.ctx); err != nil {
+		return nil, err
+	}
+	entries, err := l.FS.ReadDir(name)
+	for i, entry := range entries {
+		entries[i] = limitedEntry{DirEntry: entry, fs: l}
+	}
+	return entries, err
+}
+
+func (l limitedFS) Stat(name string) (fs.FileInfo, error) {
+	if err := l.limiter.Wait(l.ctx); err != nil {
+		return nil, err
+	}
+	return l.FS.Stat(name)
+}
+
+func (l limitedFS) Lstat(name string) (fs.FileInfo, error) {
+	if err := l.limiter.Wait(l.ctx); err != nil {
+		return nil, err
+	}
+	return l.FS.Lstat(name)
+}
+
+type limitedEntry struct {
+	fs.DirEntry
+	fs limitedFS
+}
+
+func (e limitedEntry) Info() (fs.FileInfo, error) {
+	if err := e.fs.limiter.Wait(e.fs.ctx); err != nil {
+		return nil, err
+	}
+	return e.DirEntry.Info()
+}
@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/corpeningc/dua/internal/vfs"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitsFor(t *testing.T) {
+	limits := NewRateLimits(map[string]float64{"/nonexistent/srv": 500, "/nonexistent/srv/db": 50, "/nonexistent/off": 0})
+	tests := []struct {
+		root string
+		want rate.Limit
+	}{
+		{"/nonexistent/srv", 500},
+		{"/nonexistent/srv/www", 500},
+		{"/nonexistent/srv/db", 50},
+		{"/nonexistent/srv/db/data", 50},
+		{"/nonexistent/srv/dbx", 500},
+		{"/nonexistent", 0},
+		{"/nonexistent/off", 0},
+	}
+	for _, test := range tests {
+		var got rate.Limit
+		if limiter := limits.For(test.root); limiter != nil {
+			got = limiter.Limit()
+		}
+		if got != test.want {
+			t.Errorf("For(%q) limits to %v, want %v", test.root, got, test.want)
+		}
+	}
+	if limits.For("/nonexistent/srv/a") != limits.For("/nonexistent/srv/b") {
+		t.Error("scans under the same path don't share its limiter")
+	}
+	if (*RateLimits)(nil).For("/") != nil {
+		t.Error("no limits limit")
+	}
+}
+
+func TestRateLimitedScan(t *testing.T) {
+	fsys := vfs.NewMem()
+	for i := range 20 {
+		fsys.WriteFile(fmt.Sprintf("/root/d%d/f", i%4), int64(i), time.Time{})
+	}
+
+	// At least the root's read, and its 4 directories' reads and stats of
+	// their file
+	const ops, perSecond = 9, 100
+	s := NewStreamingScanner()
+	s.SetFS(fsys)
+	s.SetRateLimit(rate.NewLimiter(perSecond, 1))
+	start := time.Now()
+	updates, errs := s.StartStreaming("/root")
+	defer s.Stop()
+	dirs := 0
+	for done := false; !done; {
+		select {
+		case update := <-updates:
+			if update.IsComplete {
+				done = true
+			} else if update.DirInfo != nil {
+				dirs++
+			}
+		case err := <-errs:
+			t.Fatal(err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("scan didn't finish")
+		}
+	}
+	elapsed := time.Since(start)
+
+	if dirs != 5 {
+		t.Fatalf("scanned %d directories, want 5", dirs)
+	}
+	if elapsed < (ops-1)*time.Second/perSecond {
+		t.Errorf("%d operations took %v, faster than %d a second", ops, elapsed, perSecond)
+	}
+}
@@ -0,0 +1,32 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// NormalizeRoot resolves path to a clean, absolute path with symlinks
+// evaluated, so every path the scanner produces shares the root as a prefix
+// and upward walks from any of them terminate at it.
+func NormalizeRoot(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", path)
+	}
+
+	return resolved, nil
+}
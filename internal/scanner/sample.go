@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"context"
+	"io/fs"
+	"math"
+	"math/rand/v2"
+	"path/filepath"
+)
+
+// sampleSize is how many files are statted in a directory that's sampled.
+const sampleSize = 10000
+
+// Sample describes a directory holding too many files to stat them all. Its
+// Files are a random sample of them, and its size is estimated from that.
+type Sample struct {
+	Files int   `json:"files"` // Files in the directory, statted or not
+	Size  int64 `json:"size"`  // Estimated total size of the files
+	Alloc int64 `json:"alloc"` // Estimated disk space they take
+	// Margin is the half-width of the 95% confidence interval of Size. It
+	// assumes sizes are roughly normal, so directories with a few huge files
+	// among many small ones can be further off.
+	Margin int64 `json:"margin"`
+}
+
+// sampleFiles stats a random sample of sampleSize of entries, the files of
+// the directory dir, and estimates their total size from it.
+func sampleFiles(ctx context.Context, dir string, entries []fs.DirEntry) ([]FileInfo, *Sample, error) {
+	files := make([]FileInfo, 0, sampleSize)
+	var sum, sumSquares, sumAlloc float64
+	// Selection sampling takes each entry with the chance of it being among
+	// those still needed, in one pass in directory order
+	needed := sampleSize
+	for i, entry := range entries {
+		if needed == 0 {
+			break
+		}
+		if rand.IntN(len(entries)-i) >= needed {
+			continue
+		}
+		needed--
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		alloc := Allocated(filepath.Join(dir, entry.Name()), info)
+		files = append(files, FileInfo{Name: entry.Name(), Size: info.Size(), Alloc: alloc, ModTime: info.ModTime(), Mode: info.Mode()})
+		size := float64(info.Size())
+		sum += size
+		sumSquares += size * size
+		sumAlloc += float64(alloc)
+	}
+
+	sample := &Sample{Files: len(entries)}
+	n := float64(len(files))
+	if n == 0 {
+		return files, sample, nil
+	}
+	total := float64(len(entries))
+	mean := sum / n
+	sample.Size = int64(mean * total)
+	sample.Alloc = int64(sumAlloc / n * total)
+	if n > 1 {
+		variance := (sumSquares - n*mean*mean) / (n - 1)
+		// The finite population correction narrows the interval as the
+		// sample covers more of the directory
+		correction := (total - n) / (total - 1)
+		sample.Margin = int64(1.96 * total * math.Sqrt(max(variance, 0)/n*correction))
+	}
+	return files, sample, nil
+}
@@ -1,20 +1,191 @@
 package scanner
 
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/corpeningc/dua/internal/vfs"
+)
+
 // DirInfo represents a directory with size information and lazy loading support.
 type DirInfo struct {
 	Path        string
 	Size        int64
+	Alloc       int64 // Disk space the files below take, see Allocated
+	ModTime     time.Time
+	Mode        fs.FileMode
 	Files       []FileInfo
 	Subdirs     []DirInfo
 	IsLoaded    bool
 	IsLoading   bool
 	FileCount   int
 	SubdirCount int
+	// PendingDirs counts directories in this subtree, including itself, that
+	// have not been scanned yet. While non-zero, Size is only a lower bound.
+	PendingDirs int
+	// DuplicateOf is set on a mount point showing data that's also at this
+	// path in the scan, such as a bind mount. It isn't scanned, so the data
+	// is only counted once.
+	DuplicateOf string
+	// Pruned is set once the contents were dropped to save memory, leaving
+	// the totals.
+	Pruned bool
+	// Sample is set if the directory held too many files to stat them all,
+	// in which case Files is a sample of them and Size an estimate.
+	Sample *Sample
 }
 
 // FileInfo represents a file with its name and size.
 type FileInfo struct {
-	Name string
-	Size int64
+	Name    string
+	Size    int64
+	Alloc   int64 // Disk space taken, see Allocated
+	ModTime time.Time
+	Mode    fs.FileMode
+	// Links is the number of hard links to the file when it has more than
+	// one, 0 otherwise.
+	Links int
+	// LinkOf is set on a hard link to a file already counted at this path in
+	// the scan. Its size is left out of the totals, see Counted.
+	LinkOf string
+	// ID identifies the file across its links when it has several.
+	ID fileID `json:",omitzero"`
+}
+
+// ScanError is a directory the scanner couldn't read, so whatever it holds is
+// missing from the tree.
+type ScanError struct {
+	Path string
+	Err  error
+}
+
+func (e *ScanError) Error() string {
+	return fmt.Sprintf("Error reading directory %s: %v", e.Path, e.Err)
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// ScanDirectory reads a single level of path outside of a streaming scan,
+// returning its files and unloaded placeholders for its subdirectories.
+func ScanDirectory(path string) (*DirInfo, error) {
+	return ScanDirectoryFS(vfs.OS, path)
+}
+
+// ScanDirectoryFS is ScanDirectory on the filesystem fsys.
+func ScanDirectoryFS(fsys vfs.FS, path string) (*DirInfo, error) {
+	return readDirectory(context.Background(), fsys, path, 0, nil)
 }
 
+// readDirectory reads a single level of path. Directories with more than
+// sampleAbove files have a sample of them statted, 0 stats them all. Files
+// with several hard links are only counted once across links, nil counts
+// them all.
+func readDirectory(ctx context.Context, fsys vfs.FS, path string, sampleAbove int, links *hardlinks) (*DirInfo, error) {
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var modTime time.Time
+	var mode fs.FileMode
+	if info, err := fsys.Stat(path); err == nil {
+		modTime, mode = info.ModTime(), info.Mode()
+	}
+
+	dirInfo := DirInfo{
+		Path:      path,
+		Size:      0,
+		ModTime:   modTime,
+		Mode:      mode,
+		Files:     []FileInfo{},
+		Subdirs:   []DirInfo{},
+		IsLoaded:  true,
+		IsLoading: false,
+	}
+
+	var fileCount, dirCount int
+	var totalBytes, totalAlloc int64
+	var fileEntries []fs.DirEntry
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if entry.IsDir() {
+			subdir := DirInfo{
+				Path:        filepath.Join(path, entry.Name()),
+				Size:        0,
+				Files:       []FileInfo{},
+				Subdirs:     []DirInfo{},
+				IsLoaded:    false,
+				IsLoading:   false,
+				FileCount:   0,
+				SubdirCount: 0,
+				PendingDirs: 1,
+			}
+			if info, err := entry.Info(); err == nil {
+				subdir.ModTime, subdir.Mode = info.ModTime(), info.Mode()
+			}
+
+			dirInfo.Subdirs = append(dirInfo.Subdirs, subdir)
+			dirCount++
+		} else {
+			fileEntries = append(fileEntries, entry)
+		}
+	}
+
+	if sampleAbove > 0 && len(fileEntries) > max(sampleAbove, sampleSize) {
+		files, sample, err := sampleFiles(ctx, path, fileEntries)
+		if err != nil {
+			return nil, err
+		}
+		dirInfo.Files = files
+		dirInfo.Sample = sample
+		fileCount = sample.Files
+		totalBytes = sample.Size
+		totalAlloc = sample.Alloc
+	} else {
+		for _, entry := range fileEntries {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if info, err := entry.Info(); err == nil {
+				filePath := filepath.Join(path, entry.Name())
+				file := FileInfo{
+					Name:    entry.Name(),
+					Size:    info.Size(),
+					Alloc:   Allocated(filePath, info),
+					ModTime: info.ModTime(),
+					Mode:    info.Mode(),
+				}
+				if id, count, ok := linkInfo(info); ok && count > 1 {
+					file.Links = count
+					file.ID = id
+					if links != nil {
+						file.LinkOf = links.counted(id, filePath)
+					}
+				}
+
+				dirInfo.Files = append(dirInfo.Files, file)
+				fileCount++
+				size, alloc := file.Counted()
+				totalBytes += size
+				totalAlloc += alloc
+			}
+		}
+	}
+
+	dirInfo.Size = totalBytes
+	dirInfo.Alloc = totalAlloc
+	dirInfo.FileCount = fileCount
+	dirInfo.SubdirCount = dirCount
+	dirInfo.PendingDirs = dirCount
+
+	return &dirInfo, nil
+}
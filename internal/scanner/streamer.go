@@ -2,12 +2,13 @@ package scanner
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"path/filepath"
 	"runtime"
 	"sync"
 	"time"
+
+	"github.com/corpeningc/dua/internal/fsusage"
+	"github.com/corpeningc/dua/internal/vfs"
+	"golang.org/x/time/rate"
 )
 
 type StreamingUpdate struct {
@@ -22,10 +23,15 @@ type StreamingUpdate struct {
 
 type StreamingScanner struct {
 	maxWorkers int
+	fs vfs.FS
+
+	// Paces reads and stats, see SetRateLimit
+	limiter *rate.Limiter
 
 	// Channels
 	workQueue chan string      // Fixed size for workers to consume
 	workInput chan string      // Unbounded input via goroutine
+	priorityInput chan string  // On-demand requests jump the queue
 	updateChan chan StreamingUpdate
 	errorChan chan error
 
@@ -39,6 +45,29 @@ type StreamingScanner struct {
 	// State tracking
 	activeJobs int64
 	jobMutex sync.Mutex
+
+	// Paths that have been picked up by a worker, so a directory requested
+	// on demand and reached by the normal walk is only scanned once
+	claimed map[string]bool
+	claimMutex sync.Mutex
+
+	// Mount points in the scan duplicating other paths in it, which are left
+	// unscanned
+	duplicates map[string]string
+
+	// Where each scanned directory is journaled, so an interrupted scan can
+	// be resumed
+	checkpointFile string
+	journal *journal
+
+	// An earlier scan of the root, see SetBaseline
+	baseline *baseline
+
+	// Directories with more files than this are sampled, see SetSampling
+	sampleAbove int
+
+	// Files with several hard links seen so far, counted once
+	links *hardlinks
 }
 
 func NewStreamingScanner() *StreamingScanner {
@@ -46,17 +75,64 @@ func NewStreamingScanner() *StreamingScanner {
 
 	return &StreamingScanner{
 		maxWorkers: runtime.NumCPU() * 8,
+		fs: vfs.OS,
 		workQueue: make(chan string, 100),           // Workers consume from this
 		workInput: make(chan string, 1000),          // Large buffer for immediate queuing
+		priorityInput: make(chan string, 100),
 		updateChan: make(chan StreamingUpdate, 50),
 		errorChan: make(chan error, 10),
 		context: context,
 		cancel: cancel,
 		activeJobs: 0,
+		claimed: make(map[string]bool),
+		links: newHardlinks(),
 	}
 }
 
+// SetFS scans fsys instead of the operating system's filesystem. It must be
+// called before streaming starts.
+func (s *StreamingScanner) SetFS(fsys vfs.FS) {
+	s.fs = fsys
+}
+
+// SetSampling has directories with more than above files stat a random
+// sample of them and estimate their size, rather than stat them all. 0 stats
+// everything. It must be called before streaming starts.
+func (s *StreamingScanner) SetSampling(above int) {
+	s.sampleAbove = above
+}
+
+// SetCheckpoint journals the scan to file as it goes, for ResumeStreaming to
+// continue it if it's interrupted. It must be called before streaming starts.
+func (s *StreamingScanner) SetCheckpoint(file string) {
+	s.checkpointFile = file
+}
+
 func (s *StreamingScanner) StartStreaming(rootPath string) (<-chan StreamingUpdate, <-chan error) {
+	return s.start(rootPath, []string{rootPath}, false)
+}
+
+// ResumeStreaming continues the interrupted scan LoadCheckpoint recovered
+// as root, scanning only the pending directories it hadn't reached. Hard
+// links to files root already counts aren't counted again.
+func (s *StreamingScanner) ResumeStreaming(root *DirInfo, pending []string) (<-chan StreamingUpdate, <-chan error) {
+	s.links.seed(root)
+	return s.start(root.Path, pending, true)
+}
+
+func (s *StreamingScanner) start(rootPath string, queue []string, resume bool) (<-chan StreamingUpdate, <-chan error) {
+	// The mount table only describes the real filesystem
+	if mounts, err := fsusage.Mounts(); err == nil && s.fs == vfs.OS {
+		s.duplicates = fsusage.Duplicates(mounts, rootPath)
+	}
+	if s.limiter != nil {
+		s.fs = limitedFS{FS: s.fs, ctx: s.context, limiter: s.limiter}
+	}
+	if s.checkpointFile != "" {
+		// Scanning works the same without one, it just can't be resumed
+		s.journal, _ = openJournal(s.checkpointFile, resume)
+	}
+
 	// Start the unbounded queue manager
 	go s.manageUnboundedQueue()
 
@@ -66,8 +142,10 @@ func (s *StreamingScanner) StartStreaming(rootPath string) (<-chan StreamingUpda
 		go s.worker()
 	}
 
+	for _, path := range queue {
+		s.queueWork(path)
+	}
 	go s.monitorCompletion()
-	s.queueWork(rootPath)
 
 	return s.updateChan, s.errorChan
 }
@@ -81,21 +159,35 @@ func (s *StreamingScanner) worker() {
 				return
 			}
 
-			s.incrementActiveJobs()
+			if !s.claim(dirPath) {
+				// Already scanned via an on-demand request
+				s.decrementActiveJobs()
+				continue
+			}
+
 			update := s.scanDirectory(dirPath)
-			s.decrementActiveJobs()
 
 			if update != nil {
+				if s.journal != nil {
+					s.journal.record(update.DirInfo)
+				}
 				select {
 				case s.updateChan <- *update:
 				case <-s.context.Done():
+					s.decrementActiveJobs()
 					return
 				}
 
 				for _, subdir := range update.DirInfo.Subdirs {
-					s.queueWork(subdir.Path)
+					if subdir.DuplicateOf == "" {
+						s.queueWork(subdir.Path)
+					}
 				}
 			}
+
+			// Only release the job once its children are queued so the
+			// completion monitor never sees a transient zero.
+			s.decrementActiveJobs()
 		case <-s.context.Done():
 			return
 		}
@@ -113,6 +205,9 @@ func (s *StreamingScanner) Stop() {
 	s.cancel()
 	s.workerGroup.Wait()
 	s.stopped = true
+	if s.journal != nil {
+		s.journal.close(false)
+	}
 
 	// Channels will be closed by the queue manager goroutine
 }
@@ -120,85 +215,88 @@ func (s *StreamingScanner) Stop() {
 func (s *StreamingScanner) scanDirectory(path string) *StreamingUpdate {
 	startTime := time.Now()
 
-	entries, err := os.ReadDir(path)
+	dirInfo, ok := s.reuse(path)
+	var err error
+	if !ok {
+		dirInfo, err = readDirectory(s.context, s.fs, path, s.sampleAbove, s.links)
+	}
 
 	if err != nil {
-		select {
-		case s.errorChan <- fmt.Errorf("Error reading directory %s: %v", path, err):
-		case <-s.context.Done():
+		if s.context.Err() == nil {
+			select {
+			case s.errorChan <- &ScanError{Path: path, Err: err}:
+			case <-s.context.Done():
+			}
 		}
 		return nil
 	}
 
-	dirInfo := DirInfo{
-		Path: path,
-		Size: 0,
-		Files: []FileInfo{},
-		Subdirs: []DirInfo{},
-		IsLoaded: true,
-		IsLoading: false,
-	}
-
-	var fileCount, dirCount, totalBytes int64
-
-	for _, entry := range entries {
-		select {
-		case <-s.context.Done():
-			return nil
-		default:
-		}
-
-		if entry.IsDir() {
-			fullPath := filepath.Join(path, entry.Name())
-			subdir := DirInfo {
-				Path: fullPath,
-				Size: 0,
-				Files: []FileInfo{},
-				Subdirs: []DirInfo{},
-				IsLoaded: false,
-				IsLoading: false,
-				FileCount: 0,
-				SubdirCount: 0,
-			}
-
-			dirInfo.Subdirs = append(dirInfo.Subdirs, subdir)
-			dirCount++
-		} else {
-			if info, err := entry.Info(); err == nil {
-				file := FileInfo {
-					Name: entry.Name(),
-					Size: info.Size(),
-				}
-
-				dirInfo.Files = append(dirInfo.Files, file)
-				fileCount++
-				totalBytes += info.Size()
-			}
+	for i := range dirInfo.Subdirs {
+		subdir := &dirInfo.Subdirs[i]
+		if original, ok := s.duplicates[subdir.Path]; ok {
+			subdir.DuplicateOf = original
+			subdir.IsLoaded = true
+			subdir.PendingDirs = 0
+			dirInfo.PendingDirs--
 		}
 	}
 
-	dirInfo.Size = totalBytes
-	dirInfo.FileCount = int(fileCount)
-	dirInfo.SubdirCount = int(dirCount)
-
 	scanDuration := time.Since(startTime)
 
 	return &StreamingUpdate{
 		Path: path,
-		FileCount: int(fileCount),
-		DirCount: int(dirCount),
-		TotalSize: totalBytes,
-		DirInfo: &dirInfo,
+		FileCount: dirInfo.FileCount,
+		DirCount: dirInfo.SubdirCount,
+		TotalSize: dirInfo.Size,
+		DirInfo: dirInfo,
 		IsComplete: false,
 		ScanTime: scanDuration,
 	}
 }
 
 func (s *StreamingScanner) queueWork(path string) {
+	s.incrementActiveJobs()
 	select {
 	case s.workInput <- path:  // Queue to unbounded input instead
 	case <-s.context.Done():
+		s.decrementActiveJobs()
+	}
+}
+
+// RequestScan moves path to the front of the work queue so that a directory
+// the user is looking at loads before the rest of the backlog. It returns false
+// if the path has already been scanned or the scanner is no longer running.
+func (s *StreamingScanner) RequestScan(path string) bool {
+	if s.isClaimed(path) {
+		return false
+	}
+
+	s.incrementActiveJobs()
+	select {
+	case s.priorityInput <- path:
+		return true
+	case <-s.context.Done():
+		s.decrementActiveJobs()
+		return false
+	}
+}
+
+// claim marks path as taken by a worker, returning false if it already was.
+func (s *StreamingScanner) claim(path string) bool {
+	s.claimMutex.Lock()
+	defer s.claimMutex.Unlock()
+
+	if s.claimed[path] {
+		return false
 	}
+	s.claimed[path] = true
+	return true
+}
+
+func (s *StreamingScanner) isClaimed(path string) bool {
+	s.claimMutex.Lock()
+	defer s.claimMutex.Unlock()
+	return s.claimed[path]
 }
 
 func (s *StreamingScanner) incrementActiveJobs() {
@@ -233,6 +331,8 @@ func (s *StreamingScanner) manageUnboundedQueue() {
 			select {
 			case item := <-s.workInput:
 				queue = append(queue, item)
+			case item := <-s.priorityInput:
+				queue = append(queue, item)
 			case <-s.context.Done():
 				return
 			}
@@ -243,6 +343,8 @@ func (s *StreamingScanner) manageUnboundedQueue() {
 				queue = queue[1:] // Remove sent item
 			case item := <-s.workInput:
 				queue = append(queue, item) // Add new item
+			case item := <-s.priorityInput:
+				queue = append([]string{item}, queue...) // Jump the queue
 			case <-s.context.Done():
 				return
 			}
@@ -257,15 +359,17 @@ func (s *StreamingScanner) monitorCompletion() {
 	for {
 		select {
 		case <-ticker.C:
-			if len(s.workInput) == 0 && len(s.workQueue) == 0 && s.getActiveJobs() == 0 {
-				time.Sleep(100 * time.Millisecond)
-				if len(s.workInput) == 0 && len(s.workQueue) == 0 && s.getActiveJobs() == 0 {
-					select {
-					case s.updateChan <- StreamingUpdate{IsComplete: true}:
-					case <-s.context.Done():
-					}
-					return
+			// Jobs are counted from the moment they are queued until their
+			// subdirectories have been queued, so zero means the walk is done.
+			if s.getActiveJobs() == 0 {
+				if s.journal != nil {
+					s.journal.close(true)
 				}
+				select {
+				case s.updateChan <- StreamingUpdate{IsComplete: true}:
+				case <-s.context.Done():
+				}
+				return
 			}
 		case <-s.context.Done():
 			return
@@ -0,0 +1,215 @@
+package scanner
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corpeningc/dua/internal/vfs"
+)
+
+// drain collects the directories a scan streams until it completes, then
+// stops the scanner.
+func drain(t *testing.T, s *StreamingScanner, updates <-chan StreamingUpdate, errs <-chan error) (map[string]*DirInfo, []error) {
+	t.Helper()
+	defer s.Stop()
+	dirs := make(map[string]*DirInfo)
+	var scanErrs []error
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case update := <-updates:
+			if update.IsComplete {
+				return dirs, scanErrs
+			}
+			if update.DirInfo != nil {
+				dirs[update.Path] = update.DirInfo
+			}
+		case err := <-errs:
+			if err != nil {
+				scanErrs = append(scanErrs, err)
+			}
+		case <-deadline:
+			t.Fatal("scan didn't complete")
+		}
+	}
+}
+
+// assemble puts the streamed directories back together into the tree below
+// path, the way the UI does, adding each one's totals to those above it.
+func assemble(path string, dirs map[string]*DirInfo) DirInfo {
+	scanned, ok := dirs[path]
+	if !ok {
+		return DirInfo{Path: path, Files: []FileInfo{}, Subdirs: []DirInfo{}, PendingDirs: 1}
+	}
+	dir := *scanned
+	dir.Subdirs = make([]DirInfo, 0, len(scanned.Subdirs))
+	dir.PendingDirs = 0
+	for _, placeholder := range scanned.Subdirs {
+		subdir := placeholder
+		if placeholder.DuplicateOf == "" {
+			subdir = assemble(placeholder.Path, dirs)
+		}
+		dir.Subdirs = append(dir.Subdirs, subdir)
+		dir.Size += subdir.Size
+		dir.Alloc += subdir.Alloc
+		dir.PendingDirs += subdir.PendingDirs
+	}
+	return dir
+}
+
+// unreadable is the real filesystem with some directories refusing to be
+// listed, which chmod can't arrange when the tests run as root.
+type unreadable struct {
+	vfs.FS
+	dirs map[string]bool
+}
+
+func (u unreadable) ReadDir(name string) ([]fs.DirEntry, error) {
+	if u.dirs[name] {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return u.FS.ReadDir(name)
+}
+
+// TestStreamingMatchesWalk scans a tree and checks every directory's totals
+// against what filepath.WalkDir finds below it.
+func TestStreamingMatchesWalk(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"empty", "a/b/c", "a/empty", "locked/inside", "wide", ".hidden"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]int{
+		"top.txt":            10,
+		"a/one":              100,
+		"a/b/two":            2_000,
+		"a/b/c/three":        30_000,
+		"a/b/c/zero":         0,
+		"locked/secret":      500,
+		"locked/inside/more": 700,
+		".hidden/dot":        42,
+	}
+	for i := range 50 {
+		files[filepath.Join("wide", "f"+strings.Repeat("x", i))] = i * 7
+	}
+	for name, size := range files {
+		if err := os.WriteFile(filepath.Join(root, name), make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	locked := filepath.Join(root, "locked")
+
+	type totals struct {
+		size  int64
+		files int
+	}
+	want := make(map[string]*totals)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			want[path] = &totals{}
+			if path == locked {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			want[dir].size += info.Size()
+			want[dir].files++
+			if dir == root {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStreamingScanner()
+	s.SetFS(unreadable{FS: vfs.OS, dirs: map[string]bool{locked: true}})
+	updates, errs := s.StartStreaming(root)
+	dirs, scanErrs := drain(t, s, updates, errs)
+	tree := assemble(root, dirs)
+
+	var scanErr *ScanError
+	if len(scanErrs) != 1 || !errors.As(scanErrs[0], &scanErr) || scanErr.Path != locked {
+		t.Errorf("scan errors %v, want only %s", scanErrs, locked)
+	}
+
+	seen := 0
+	var check func(dir *DirInfo) int
+	check = func(dir *DirInfo) int {
+		seen++
+		files := dir.FileCount
+		for i := range dir.Subdirs {
+			files += check(&dir.Subdirs[i])
+		}
+		w, ok := want[dir.Path]
+		switch {
+		case !ok:
+			t.Errorf("scan found %s, the walk didn't", dir.Path)
+		case dir.Size != w.size || files != w.files:
+			t.Errorf("%s holds %d bytes in %d files, the walk found %d in %d", dir.Path, dir.Size, files, w.size, w.files)
+		}
+		// Only the directory that couldn't be read is left pending
+		pending := 0
+		if dir.Path == locked || dir.Path == root {
+			pending = 1
+		}
+		if dir.PendingDirs != pending || dir.IsLoaded == (dir.Path == locked) {
+			t.Errorf("%s has %d directories pending, want %d", dir.Path, dir.PendingDirs, pending)
+		}
+		return files
+	}
+	check(&tree)
+	if seen != len(want) {
+		t.Errorf("scan found %d directories, the walk %d", seen, len(want))
+	}
+}
+
+// TestStreamingFailedReadDir scans a vfs.Mem told to refuse listing one
+// directory, which is reported and left pending while the rest loads.
+func TestStreamingFailedReadDir(t *testing.T) {
+	fsys := vfs.NewMem()
+	for path, size := range map[string]int64{
+		"/root/a/one":         100,
+		"/root/locked/secret": 500,
+		"/root/locked/in/x":   700,
+		"/root/c/d/two":       2_000,
+	} {
+		fsys.WriteFile(path, size, time.Time{})
+	}
+	fsys.Fail(vfs.OpReadDir, "/root/locked", fs.ErrPermission)
+
+	s := NewStreamingScanner()
+	s.SetFS(fsys)
+	updates, errs := s.StartStreaming("/root")
+	dirs, scanErrs := drain(t, s, updates, errs)
+
+	var scanErr *ScanError
+	if len(scanErrs) != 1 || !errors.As(scanErrs[0], &scanErr) || scanErr.Path != "/root/locked" || !errors.Is(scanErr, fs.ErrPermission) {
+		t.Fatalf("scan errors %v, want only permission denied on /root/locked", scanErrs)
+	}
+	tree := assemble("/root", dirs)
+	if tree.Size != 2_100 || tree.PendingDirs != 1 {
+		t.Errorf("root holds %d bytes with %d directories pending, want 2100 and 1", tree.Size, tree.PendingDirs)
+	}
+	for _, dir := range tree.Subdirs {
+		if loaded := dir.Path != "/root/locked"; dir.IsLoaded != loaded {
+			t.Errorf("%s loaded %v, want %v", dir.Path, dir.IsLoaded, loaded)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"io/fs"
+	"math/rand/v2"
+	"path/filepath"
+)
+
+// Tally is what a directory holds, everything below it included.
+type Tally struct {
+	Size  int64
+	Files int
+	Dirs  int
+}
+
+// Check is a directory's totals as scanned and as found walking it again.
+type Check struct {
+	Path    string
+	Scanned Tally
+	Walked  Tally
+	Err     error // Set if the directory couldn't be walked
+}
+
+// OK reports whether the walk found what the scan did.
+func (c Check) OK() bool {
+	return c.Err == nil && c.Scanned == c.Walked
+}
+
+// SampleChecks picks the root and up to n other directories of a finished
+// scan at random to verify, with their scanned totals. Directories are only
+// picked if everything below them is in the tree: nothing pending, pruned,
+// sampled or skipped as a duplicate mount.
+func SampleChecks(root *DirInfo, n int) []Check {
+	var eligible []Check
+	var tally func(dir *DirInfo) (Tally, bool)
+	tally = func(dir *DirInfo) (Tally, bool) {
+		total := Tally{Size: dir.Size, Files: len(dir.Files), Dirs: len(dir.Subdirs)}
+		complete := dir.IsLoaded && dir.PendingDirs == 0 && !dir.Pruned && dir.Sample == nil && dir.DuplicateOf == ""
+		for i := range dir.Subdirs {
+			sub, ok := tally(&dir.Subdirs[i])
+			total.Files += sub.Files
+			total.Dirs += sub.Dirs
+			complete = complete && ok
+		}
+		if complete {
+			eligible = append(eligible, Check{Path: dir.Path, Scanned: total})
+		}
+		return total, complete
+	}
+	tally(root)
+
+	// The root is tallied last, and is always checked when it can be
+	var checks []Check
+	if last := len(eligible) - 1; last >= 0 && eligible[last].Path == root.Path {
+		checks = append(checks, eligible[last])
+		eligible = eligible[:last]
+	}
+	rand.Shuffle(len(eligible), func(i, j int) {
+		eligible[i], eligible[j] = eligible[j], eligible[i]
+	})
+	return append(checks, eligible[:min(n, len(eligible))]...)
+}
+
+// Verify walks the directory of each check with filepath.WalkDir, the
+// plain sequential way, filling in what it finds. Like the scanner, it
+// doesn't follow symlinks and counts their own size, and leaves out what's
+// in directories it can't read.
+func Verify(checks []Check) []Check {
+	for i := range checks {
+		checks[i].Walked, checks[i].Err = walk(checks[i].Path)
+	}
+	return checks
+}
+
+func walk(root string) (Tally, error) {
+	var total Tally
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// The directory was counted on the way in, its contents are
+			// missing from the scan too
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		if entry.IsDir() {
+			total.Dirs++
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			total.Files++
+			total.Size += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
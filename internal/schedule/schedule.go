@@ -0,0 +1,148 @@
+// Package schedule parses cron-style schedules and works out when they next
+// fire.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed five-field cron expression: minute, hour, day of
+// month, month and day of week.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // Bit sets of allowed values
+	// Like cron, when both day fields are restricted a day matching either
+	// one fires
+	domStar, dowStar bool
+}
+
+// field is the range of values one cron field allows.
+type field struct {
+	min, max int
+}
+
+var fields = [5]field{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// macros are the shorthand schedules cron accepts.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse reads a schedule like "30 3 * * 1-5" or "*/15 * * * *". Each field
+// takes *, numbers, ranges, comma-separated lists and /step, and the @daily
+// style shorthands are accepted. Sunday is 0 or 7.
+func Parse(spec string) (Schedule, error) {
+	if expanded, ok := macros[strings.TrimSpace(spec)]; ok {
+		spec = expanded
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return Schedule{}, fmt.Errorf("schedule %q: want 5 fields, got %d", spec, len(parts))
+	}
+
+	var sets [5]uint64
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("schedule %q: %w", spec, err)
+		}
+		sets[i] = set
+	}
+
+	// Fold Sunday as 7 onto 0
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return Schedule{
+		minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: sets[4],
+		domStar: parts[2] == "*", dowStar: parts[4] == "*",
+	}, nil
+}
+
+func parseField(text string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return 0, fmt.Errorf("bad step in %q", item)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangeText != "*" {
+			lowText, highText, isRange := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = strconv.Atoi(lowText); err != nil {
+				return 0, fmt.Errorf("bad value in %q", item)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highText); err != nil {
+					return 0, fmt.Errorf("bad range in %q", item)
+				}
+			} else if hasStep {
+				// "5/15" means from 5 to the end in steps of 15
+				high = f.max
+			}
+		}
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("%q is outside %d-%d", item, f.min, f.max)
+		}
+
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// Next returns the first time after t the schedule fires, in t's location,
+// or the zero time if it never does, as with "0 0 31 2 *".
+func (s Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Five years covers every day-of-month and weekday combination, with
+	// leap years
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dow
+	case s.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
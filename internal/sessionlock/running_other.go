@@ -0,0 +1,28 @@
+//go:build !unix
+
+package sessionlock
+
+import (
+	"io/fs"
+	"os"
+)
+
+// noFollow is left out where opening has no such flag.
+const noFollow = 0
+
+// running reports whether process pid exists. Finding a process only
+// succeeds for one that exists on Windows, elsewhere it's taken as running.
+func running(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
+
+// owned reports whether the lock directory info belongs to this user. The
+// temporary directory is already per user on Windows.
+func owned(info fs.FileInfo) bool {
+	return true
+}
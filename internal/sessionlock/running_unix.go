@@ -0,0 +1,27 @@
+//go:build unix
+
+package sessionlock
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// noFollow keeps the lock file from being opened through a symlink.
+const noFollow = syscall.O_NOFOLLOW
+
+// running reports whether process pid exists. Signal 0 only checks, and is
+// refused rather than failing for other users' processes.
+func running(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// owned reports whether the lock directory info belongs to this user and
+// nobody else can write to it.
+func owned(info fs.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Geteuid() && info.Mode().Perm()&0o022 == 0
+}
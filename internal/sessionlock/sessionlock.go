@@ -0,0 +1,148 @@
+// Package sessionlock lets dua sessions working in the same tree notice each
+// other. Each session writes a lock file naming its root and process to its
+// user's directory in the temporary directory, shared by everyone on a
+// machine, and reads the others' to find sessions whose roots overlap its
+// own. The locks are advisory: they warn, as two sessions deleting in the
+// same tree see stale trees and may delete the same items, but never stop a
+// session.
+package sessionlock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/corpeningc/dua/internal/paths"
+)
+
+// Session is a dua session as recorded in its lock file.
+type Session struct {
+	PID     int       `json:"pid"`
+	User    string    `json:"user"`
+	Host    string    `json:"host"`
+	Root    string    `json:"root"`
+	Started time.Time `json:"started"`
+}
+
+// Lock is this session's lock file.
+type Lock struct {
+	file    string
+	session Session
+}
+
+// dirPattern matches the directories of every user's lock files.
+const dirPattern = "dua-sessions-*"
+
+// dir is where this user's lock files are kept: a directory of their own, so
+// other users' sessions can read the locks but not plant or replace them.
+func dir() (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(os.TempDir(), strings.Replace(dirPattern, "*", u.Uid, 1))
+	// Readable by all, so other users' sessions see the locks
+	if err := os.Mkdir(path, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return "", err
+	}
+	// Anyone can create it first in the shared temporary directory
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() || !owned(info) {
+		return "", fmt.Errorf("%s isn't a directory only you can write to", path)
+	}
+	return path, nil
+}
+
+// Acquire writes the lock file of this session scanning root.
+func Acquire(root string) (*Lock, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	session := Session{PID: os.Getpid(), Root: root, Started: time.Now()}
+	if u, err := user.Current(); err == nil {
+		session.User = u.Username
+	}
+	session.Host, _ = os.Hostname()
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	locks, err := dir()
+	if err != nil {
+		return nil, err
+	}
+	file := filepath.Join(locks, strconv.Itoa(session.PID)+".lock")
+	// Left by an earlier process with the same pid
+	os.Remove(file)
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY|noFollow, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(file)
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(file)
+		return nil, err
+	}
+	return &Lock{file: file, session: session}, nil
+}
+
+// Release removes the lock file.
+func (l *Lock) Release() error {
+	return os.Remove(l.file)
+}
+
+// Others returns the other running sessions whose root is this session's,
+// or above or below it. Lock files left behind by sessions that are gone are
+// removed where permissions allow.
+func (l *Lock) Others() ([]Session, error) {
+	files, err := filepath.Glob(filepath.Join(os.TempDir(), dirPattern, "*.lock"))
+	if err != nil {
+		return nil, err
+	}
+	var others []Session
+	for _, file := range files {
+		if file == l.file {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var session Session
+		if json.Unmarshal(data, &session) != nil {
+			continue
+		}
+		// Processes can only be looked up on this machine, where the
+		// temporary directory is shared with another one, its sessions are
+		// taken as running
+		if session.Host == l.session.Host && !running(session.PID) {
+			os.Remove(file)
+			continue
+		}
+		if overlaps(session.Root, l.session.Root) {
+			others = append(others, session)
+		}
+	}
+	return others, nil
+}
+
+// overlaps reports whether either root is the other or below it.
+func overlaps(a, b string) bool {
+	return paths.Within(a, b) || paths.Within(b, a)
+}
@@ -0,0 +1,97 @@
+//go:build unix
+
+package sessionlock
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAcquire(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	lock, err := Acquire("/some/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(filepath.Dir(lock.file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0o022 != 0 {
+		t.Errorf("lock directory mode %v, others can write to it", perm)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireRefusesPlantedDir(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	u, err := user.Current()
+	if err != nil {
+		t.Skip(err)
+	}
+	path := filepath.Join(tmp, strings.Replace(dirPattern, "*", u.Uid, 1))
+
+	// Somewhere else, linked to where the locks go
+	target := t.TempDir()
+	if err := os.Symlink(target, path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Acquire("/some/root"); err == nil {
+		t.Error("lock written through a symlinked directory")
+	}
+	os.Remove(path)
+
+	// Writable by anyone
+	if err := os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Acquire("/some/root"); err == nil {
+		t.Error("lock written to a directory anyone can write to")
+	}
+}
+
+func TestOthers(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	lock, err := Acquire("/data/projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+
+	// Sessions of this user and another's, both still running
+	other := filepath.Join(os.TempDir(), strings.Replace(dirPattern, "*", "other", 1))
+	if err := os.Mkdir(other, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for file, root := range map[string]string{
+		filepath.Join(filepath.Dir(lock.file), "1.lock"): "/data",
+		filepath.Join(other, "1.lock"):                   "/data/projects/dua",
+		filepath.Join(other, "2.lock"):                   "/elsewhere",
+	} {
+		data := `{"pid":` + strings.TrimSuffix(filepath.Base(file), ".lock") + `,"host":"elsewhere","root":"` + root + `"}`
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	others, err := lock.Others()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roots []string
+	for _, session := range others {
+		roots = append(roots, session.Root)
+	}
+	if len(roots) != 2 {
+		t.Errorf("found sessions in %v, want /data and /data/projects/dua", roots)
+	}
+}
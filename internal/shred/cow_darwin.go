@@ -0,0 +1,26 @@
+package shred
+
+import "syscall"
+
+// CopyOnWrite reports whether path lives on a copy-on-write filesystem,
+// where overwriting a file doesn't touch its original blocks.
+func CopyOnWrite(path string) bool {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return false
+	}
+
+	var name []byte
+	for _, c := range stat.Fstypename {
+		if c == 0 {
+			break
+		}
+		name = append(name, byte(c))
+	}
+
+	switch string(name) {
+	case "apfs", "zfs":
+		return true
+	}
+	return false
+}
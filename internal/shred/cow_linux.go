@@ -0,0 +1,25 @@
+package shred
+
+import "syscall"
+
+// Filesystem magic numbers from statfs(2) for copy-on-write filesystems.
+const (
+	btrfsMagic    = 0x9123683e
+	zfsMagic      = 0x2fc12fc1
+	bcachefsMagic = 0xca451a4e
+)
+
+// CopyOnWrite reports whether path lives on a copy-on-write filesystem,
+// where overwriting a file doesn't touch its original blocks.
+func CopyOnWrite(path string) bool {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return false
+	}
+
+	switch uint32(stat.Type) {
+	case btrfsMagic, zfsMagic, bcachefsMagic:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package shred
+
+// CopyOnWrite reports whether path lives on a copy-on-write filesystem,
+// where overwriting a file doesn't touch its original blocks. It can't be
+// detected on this platform.
+func CopyOnWrite(path string) bool {
+	return false
+}
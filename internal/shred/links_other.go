@@ -0,0 +1,12 @@
+//go:build !unix
+
+package shred
+
+import "io/fs"
+
+// sharedInode reports whether other hard links point at the file's data,
+// which overwriting would destroy as well. Link counts aren't available
+// here.
+func sharedInode(info fs.FileInfo) bool {
+	return false
+}
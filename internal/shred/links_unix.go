@@ -0,0 +1,15 @@
+//go:build unix
+
+package shred
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// sharedInode reports whether other hard links point at the file's data,
+// which overwriting would destroy as well.
+func sharedInode(info fs.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && uint64(stat.Nlink) > 1
+}
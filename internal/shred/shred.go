@@ -0,0 +1,73 @@
+// Package shred overwrites files before deleting them.
+//
+// Overwriting only reaches the original blocks when the filesystem writes in
+// place. Copy-on-write filesystems (btrfs, ZFS, APFS) put new data in fresh
+// blocks, and SSDs remap writes internally, so the old contents may survive
+// on both. Full-disk encryption is the reliable answer there.
+package shred
+
+import (
+	"crypto/rand"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// File overwrites a regular file with random data, syncs it to disk and then
+// removes it. Anything that isn't a regular file, and files with other hard
+// links, are just removed.
+func File(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode().IsRegular() && info.Size() > 0 && !sharedInode(info) {
+		if err := overwrite(path, info.Size()); err != nil {
+			return err
+		}
+	}
+	return os.Remove(path)
+}
+
+// RemoveAll shreds every regular file under path, then removes what's left,
+// like os.RemoveAll.
+func RemoveAll(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return File(path)
+	}
+
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			return File(file)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+func overwrite(path string, size int64) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.CopyN(file, rand.Reader, size); err != nil {
+		return err
+	}
+	return file.Sync()
+}
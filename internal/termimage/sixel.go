@@ -0,0 +1,108 @@
+package termimage
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// sixelLevels is the number of shades per channel in the sixel palette, a
+// 6x6x6 color cube.
+const sixelLevels = 6
+
+// sixel encodes img as a DEC sixel image. Colors are reduced to a fixed color
+// cube, which is crude but needs no palette search, and fully transparent
+// pixels are left undrawn.
+func sixel(img *image.RGBA) string {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// Palette index of every pixel, -1 for transparent ones
+	indexes := make([]int, width*height)
+	used := make([]bool, sixelLevels*sixelLevels*sixelLevels)
+	for y := range height {
+		for x := range width {
+			c := img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+			if c.A < 128 {
+				indexes[y*width+x] = -1
+				continue
+			}
+			index := (quantize(c.R)*sixelLevels+quantize(c.G))*sixelLevels + quantize(c.B)
+			indexes[y*width+x] = index
+			used[index] = true
+		}
+	}
+
+	var b strings.Builder
+	// P2=1 keeps unset pixels transparent, the raster attributes give the size
+	fmt.Fprintf(&b, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for index, ok := range used {
+		if !ok {
+			continue
+		}
+		r := index / (sixelLevels * sixelLevels)
+		g := index / sixelLevels % sixelLevels
+		bl := index % sixelLevels
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", index, r*100/(sixelLevels-1), g*100/(sixelLevels-1), bl*100/(sixelLevels-1))
+	}
+
+	// Each band covers six pixel rows, drawn once per color present in it
+	bits := make([]byte, width)
+	for top := 0; top < height; top += 6 {
+		first := true
+		for index, ok := range used {
+			if !ok {
+				continue
+			}
+
+			present := false
+			for x := range width {
+				bits[x] = 0
+				for dy := 0; dy < 6 && top+dy < height; dy++ {
+					if indexes[(top+dy)*width+x] == index {
+						bits[x] |= 1 << dy
+						present = true
+					}
+				}
+			}
+			if !present {
+				continue
+			}
+
+			if !first {
+				// Back to the start of the band for the next color
+				b.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&b, "#%d", index)
+			writeSixelRuns(&b, bits)
+		}
+		b.WriteByte('-')
+	}
+
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeSixelRuns writes one band row for a color, run-length encoding repeats.
+func writeSixelRuns(b *strings.Builder, bits []byte) {
+	for x := 0; x < len(bits); {
+		run := 1
+		for x+run < len(bits) && bits[x+run] == bits[x] {
+			run++
+		}
+
+		char := byte('?' + bits[x])
+		if run > 3 {
+			fmt.Fprintf(b, "!%d%c", run, char)
+		} else {
+			b.WriteString(strings.Repeat(string(char), run))
+		}
+		x += run
+	}
+}
+
+// quantize maps a color channel onto one of the palette's shades.
+func quantize(v uint8) int {
+	return (int(v)*(sixelLevels-1) + 127) / 255
+}
@@ -0,0 +1,184 @@
+// Package termimage draws images in the terminal with the kitty, iTerm2 and
+// sixel graphics protocols.
+package termimage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+)
+
+// Protocol is a terminal graphics protocol.
+type Protocol int
+
+const (
+	None Protocol = iota
+	Kitty
+	ITerm
+	Sixel
+)
+
+// Approximate pixel size of a terminal cell, used to size images before
+// sending them. Cells are roughly twice as tall as they are wide.
+const (
+	cellWidth  = 10
+	cellHeight = 20
+)
+
+// kittyChunk is the largest payload kitty accepts in one escape sequence.
+const kittyChunk = 4096
+
+// Parse reads a protocol setting: "kitty", "iterm2", "sixel" or "off". Any
+// other value, such as "" or "auto", detects it from the environment.
+func Parse(value string) Protocol {
+	switch strings.ToLower(value) {
+	case "kitty":
+		return Kitty
+	case "iterm2", "iterm":
+		return ITerm
+	case "sixel":
+		return Sixel
+	case "off", "none":
+		return None
+	}
+	return Detect()
+}
+
+// Detect guesses the terminal's image support from the environment. Terminal
+// multiplexers swallow the escape sequences, so nothing is drawn inside tmux
+// or screen.
+func Detect() Protocol {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("TMUX") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux"):
+		return None
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" || program == "ghostty":
+		return Kitty
+	case program == "iTerm.app" || program == "WezTerm" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return ITerm
+	case strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "mlterm") || strings.Contains(term, "sixel"):
+		return Sixel
+	}
+	return None
+}
+
+// Fit returns the cell size to draw a width by height pixel image in, as
+// large as fits in cols by rows while keeping its aspect ratio.
+func Fit(width, height, cols, rows int) (int, int) {
+	if width <= 0 || height <= 0 || cols <= 0 || rows <= 0 {
+		return 0, 0
+	}
+
+	fitCols := rows * cellHeight * width / (height * cellWidth)
+	if fitCols <= cols {
+		return max(fitCols, 1), rows
+	}
+	return cols, max(cols*cellWidth*height/(width*cellHeight), 1)
+}
+
+// Encode returns the escape sequence drawing img over cols by rows cells,
+// starting at the cursor. The cursor ends up wherever the terminal leaves it,
+// which differs between protocols, so callers should save and restore it.
+func Encode(img image.Image, cols, rows int, protocol Protocol) (string, error) {
+	switch protocol {
+	case Kitty:
+		data, err := encodePNG(img, cols, rows)
+		if err != nil {
+			return "", err
+		}
+		return kitty(data, cols, rows), nil
+	case ITerm:
+		data, err := encodePNG(img, cols, rows)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+			len(data), cols, rows, base64.StdEncoding.EncodeToString(data)), nil
+	case Sixel:
+		return sixel(Thumbnail(img, cols*cellWidth, rows*cellHeight)), nil
+	}
+	return "", nil
+}
+
+// KittyClear removes every image kitty is showing. Kitty draws images above
+// the text, so they stay on screen until deleted.
+const KittyClear = "\x1b_Ga=d,q=2\x1b\\"
+
+// kitty transmits and shows a PNG in one go, split into chunks. The cursor
+// stays put (C=1) and replies are suppressed (q=2), since nothing reads them.
+func kitty(data []byte, cols, rows int) string {
+	payload := base64.StdEncoding.EncodeToString(data)
+
+	var b strings.Builder
+	b.WriteString(KittyClear)
+	for first := true; first || payload != ""; first = false {
+		chunk := payload[:min(kittyChunk, len(payload))]
+		payload = payload[len(chunk):]
+
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&b, "\x1b_Gf=100,a=T,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String()
+}
+
+// encodePNG shrinks img to roughly the pixels the cells can show, so large
+// photos aren't sent at full resolution.
+func encodePNG(img image.Image, cols, rows int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, Thumbnail(img, cols*cellWidth, rows*cellHeight)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Thumbnail scales img down to fit within width by height pixels, keeping its
+// aspect ratio. Each output pixel averages a grid of up to 4x4 of the source
+// pixels it covers, which keeps large photos quick to shrink. Images that
+// already fit are copied at their own size.
+func Thumbnail(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	w, h := srcW, srcH
+	if w > width || h > height {
+		if srcW*height > srcH*width {
+			w, h = width, max(srcH*width/srcW, 1)
+		} else {
+			w, h = max(srcW*height/srcH, 1), height
+		}
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		y0, y1 := bounds.Min.Y+y*srcH/h, bounds.Min.Y+max((y+1)*srcH/h, y*srcH/h+1)
+		for x := range w {
+			x0, x1 := bounds.Min.X+x*srcW/w, bounds.Min.X+max((x+1)*srcW/w, x*srcW/w+1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy += max((y1-y0)/4, 1) {
+				for sx := x0; sx < x1; sx += max((x1-x0)/4, 1) {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a, n = r+pr, g+pg, b+pb, a+pa, n+1
+				}
+			}
+			thumb.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(b / n >> 8), A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return thumb
+}
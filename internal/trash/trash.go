@@ -0,0 +1,68 @@
+// Package trash finds the trash, or recycle bin, that deleted files go to on
+// the filesystem holding a path. Files moved there still take up space until
+// it's emptied, which is easy to miss when checking df after a cleanup.
+package trash
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Bin is a trash directory holding something.
+type Bin struct {
+	Dir   string
+	Size  int64
+	Items int // Deleted files and directories, counted at the top
+	// Entries are what emptying it deletes, leaving the trash itself in
+	// place. They're unset when Empty is.
+	Entries []string
+	// Empty is the system's own command for emptying it, where deleting
+	// the entries isn't enough or isn't allowed.
+	Empty []string
+}
+
+// Find returns the trash directories for the filesystem holding path that
+// hold anything, measuring each.
+func Find(path string) []Bin {
+	var bins []Bin
+	for _, bin := range find(path) {
+		if bin.Items > 0 {
+			bins = append(bins, bin)
+		}
+	}
+	return bins
+}
+
+// list returns the entries of dir, or nothing if it can't be read.
+func list(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	paths := make([]string, len(entries))
+	for i, entry := range entries {
+		paths[i] = filepath.Join(dir, entry.Name())
+	}
+	return paths
+}
+
+// dirSize totals the files below dir, skipping what can't be read.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != dir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
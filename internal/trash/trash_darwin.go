@@ -0,0 +1,37 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// emptyTrash has the Finder empty the trash, which unlike deleting the
+// files itself needs no Full Disk Access. It empties the trash of every
+// volume.
+var emptyTrash = []string{"osascript", "-e", `tell application "Finder" to empty trash`}
+
+// find looks for the user's trash, which holds files deleted from the boot
+// volume, and the trash of the volume holding path if that's another one.
+func find(path string) []Bin {
+	var dir string
+	if home, err := os.UserHomeDir(); err == nil && sameDevice(home, path) {
+		dir = filepath.Join(home, ".Trash")
+	} else if top := topDir(path); top != "" {
+		dir = filepath.Join(top, ".Trashes", strconv.Itoa(os.Getuid()))
+	}
+	if dir == "" {
+		return nil
+	}
+	items := 0
+	for _, entry := range list(dir) {
+		// Finder's view settings aren't something deleted
+		if filepath.Base(entry) != ".DS_Store" {
+			items++
+		}
+	}
+	if items == 0 {
+		return nil
+	}
+	return []Bin{{Dir: dir, Size: dirSize(dir), Items: items, Empty: emptyTrash}}
+}
@@ -0,0 +1,57 @@
+//go:build !darwin && !windows
+
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// find looks for the trash directories of the freedesktop.org trash spec:
+// the user's home trash, if it's on path's filesystem, and the ones at the
+// top of that filesystem for files deleted outside the home directory.
+func find(path string) []Bin {
+	var dirs []string
+	if home := homeTrash(); home != "" && sameDevice(home, path) {
+		dirs = append(dirs, home)
+	}
+	if top := topDir(path); top != "" {
+		uid := strconv.Itoa(os.Getuid())
+		dirs = append(dirs, filepath.Join(top, ".Trash", uid), filepath.Join(top, ".Trash-"+uid))
+	}
+
+	var bins []Bin
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		files := list(filepath.Join(dir, "files"))
+		if len(files) == 0 {
+			continue
+		}
+		bins = append(bins, Bin{
+			Dir:   dir,
+			Size:  dirSize(dir),
+			Items: len(files),
+			// Each deleted item has a .trashinfo file beside it saying
+			// where it came from, which goes with it
+			Entries: append(files, list(filepath.Join(dir, "info"))...),
+		})
+	}
+	return bins
+}
+
+// homeTrash is the trash for files deleted from the home directory.
+func homeTrash() string {
+	if data := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(data) {
+		return filepath.Join(data, "Trash")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "Trash")
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// device returns the filesystem path is on.
+func device(path string) (uint64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
+
+// sameDevice reports whether a and b are on the same filesystem.
+func sameDevice(a, b string) bool {
+	devA, okA := device(a)
+	devB, okB := device(b)
+	return okA && okB && devA == devB
+}
+
+// topDir returns the mount point of the filesystem holding path, the
+// highest directory above it on the same device.
+func topDir(path string) string {
+	dev, ok := device(path)
+	if !ok {
+		return ""
+	}
+	top := path
+	for parent := filepath.Dir(top); parent != top; top, parent = parent, filepath.Dir(parent) {
+		if parentDev, ok := device(parent); !ok || parentDev != dev {
+			break
+		}
+	}
+	return top
+}
@@ -0,0 +1,33 @@
+package trash
+
+import (
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// find looks for the current user's recycle bin on the volume holding
+// path, a directory named after the user's security identifier.
+func find(path string) []Bin {
+	volume := filepath.VolumeName(path)
+	current, err := user.Current()
+	if volume == "" || err != nil {
+		return nil
+	}
+	dir := filepath.Join(volume+`\`, "$Recycle.Bin", current.Uid)
+	items := 0
+	for _, entry := range list(dir) {
+		// Each deleted item is a $R file with a $I file describing it
+		if strings.HasPrefix(filepath.Base(entry), "$R") {
+			items++
+		}
+	}
+	if items == 0 {
+		return nil
+	}
+	empty := []string{"powershell", "-NoProfile", "-Command", "Clear-RecycleBin", "-Force"}
+	if len(volume) == 2 && volume[1] == ':' {
+		empty = append(empty, "-DriveLetter", volume[:1])
+	}
+	return []Bin{{Dir: dir, Size: dirSize(dir), Items: items, Empty: empty}}
+}
@@ -0,0 +1,284 @@
+// Package tree keeps a scanned directory tree up to date as directories are
+// scanned, and items deleted, renamed and moved, with every directory's
+// totals covering what's below it.
+package tree
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// Tree is a scanned directory tree. Its root never changes path, though a
+// Scanned event for it replaces it whole.
+type Tree struct {
+	Root *scanner.DirInfo
+	// dirs indexes every directory by path, so finding one, and walking up
+	// from it, doesn't search the tree. Directories live in their parents'
+	// Subdirs slices, so the pointers are updated whenever those move.
+	dirs map[string]*scanner.DirInfo
+}
+
+// New returns a tree of root.
+func New(root *scanner.DirInfo) *Tree {
+	t := &Tree{Root: root}
+	t.reindex()
+	return t
+}
+
+// reindex rebuilds the index of directories.
+func (t *Tree) reindex() {
+	t.dirs = make(map[string]*scanner.DirInfo)
+	if t.Root != nil {
+		t.index(t.Root)
+	}
+}
+
+func (t *Tree) index(dir *scanner.DirInfo) {
+	t.dirs[dir.Path] = dir
+	for i := range dir.Subdirs {
+		t.index(&dir.Subdirs[i])
+	}
+}
+
+func (t *Tree) unindex(dir *scanner.DirInfo) {
+	delete(t.dirs, dir.Path)
+	for i := range dir.Subdirs {
+		t.unindex(&dir.Subdirs[i])
+	}
+}
+
+// repoint updates the index for the subdirectories of dir after they moved
+// within its Subdirs slice. Their own subdirectories stay where they were.
+func (t *Tree) repoint(dir *scanner.DirInfo) {
+	for i := range dir.Subdirs {
+		t.dirs[dir.Subdirs[i].Path] = &dir.Subdirs[i]
+	}
+}
+
+// Event is one change to the tree.
+type Event interface {
+	event()
+}
+
+// Scanned delivers a directory read by the scanner or loaded on demand,
+// replacing its placeholder.
+type Scanned struct {
+	Dir *scanner.DirInfo
+}
+
+// Removed drops a deleted file or directory.
+type Removed struct {
+	Path string
+}
+
+// Moved moves a file or directory along with everything below it, replacing
+// whatever was at the destination. A rename is a move within the same
+// directory, and moving somewhere outside the tree drops the item.
+type Moved struct {
+	From, To string
+}
+
+// Pruned drops the files of a directory and everything below it to save
+// memory, keeping the totals. Subdirectories are dropped too once fully
+// scanned; those still being scanned stay for their results to land in.
+type Pruned struct {
+	Path string
+}
+
+func (Scanned) event() {}
+func (Removed) event() {}
+func (Moved) event()   {}
+func (Pruned) event()  {}
+
+// Change is a directory an event changed the contents or totals of.
+type Change struct {
+	Path string
+	Grew int64 // Bytes its total grew by, negative if it shrank
+}
+
+// Find returns the directory at path, or nil if it isn't in the tree.
+func (t *Tree) Find(path string) *scanner.DirInfo {
+	return t.dirs[path]
+}
+
+// Apply makes a change to the tree, returning the directories it changed
+// from the bottom up.
+func (t *Tree) Apply(event Event) []Change {
+	var changes []Change
+	switch e := event.(type) {
+	case Scanned:
+		changes = t.scanned(e.Dir)
+	case Removed:
+		_, _, changes = t.detach(e.Path)
+	case Moved:
+		changes = t.moved(e.From, e.To)
+	case Pruned:
+		if dir := t.Find(e.Path); dir != nil && t.prune(dir) {
+			changes = []Change{{Path: e.Path}}
+		}
+	}
+	return changes
+}
+
+func (t *Tree) scanned(dir *scanner.DirInfo) []Change {
+	if dir.Path == t.Root.Path {
+		grew := dir.Size - t.Root.Size
+		t.Root = dir
+		t.reindex()
+		return []Change{{Path: dir.Path, Grew: grew}}
+	}
+
+	parent := t.Find(filepath.Dir(dir.Path))
+	if parent == nil {
+		return nil
+	}
+	for i := range parent.Subdirs {
+		if parent.Subdirs[i].Path != dir.Path {
+			continue
+		}
+		grew := dir.Size - parent.Subdirs[i].Size
+		alloc := dir.Alloc - parent.Subdirs[i].Alloc
+		pending := dir.PendingDirs - parent.Subdirs[i].PendingDirs
+		t.unindex(&parent.Subdirs[i])
+		parent.Subdirs[i] = *dir
+		t.index(&parent.Subdirs[i])
+		return append([]Change{{Path: dir.Path, Grew: grew}}, t.adjust(parent.Path, grew, alloc, pending)...)
+	}
+	return nil
+}
+
+func (t *Tree) moved(from, to string) []Change {
+	if from == to {
+		return nil
+	}
+	_, _, changes := t.detach(to)
+	file, dir, detached := t.detach(from)
+	changes = append(changes, detached...)
+	if file == nil && dir == nil {
+		return changes
+	}
+
+	parent := t.Find(filepath.Dir(to))
+	if parent == nil {
+		return changes
+	}
+	if dir != nil {
+		rebase(dir, from, to)
+		parent.Subdirs = append(parent.Subdirs, *dir)
+		parent.SubdirCount++
+		t.repoint(parent)
+		t.index(&parent.Subdirs[len(parent.Subdirs)-1])
+		return append(changes, t.adjust(parent.Path, dir.Size, dir.Alloc, dir.PendingDirs)...)
+	}
+	file.Name = filepath.Base(to)
+	parent.Files = append(parent.Files, *file)
+	parent.FileCount++
+	size, alloc := file.Counted()
+	return append(changes, t.adjust(parent.Path, size, alloc, 0)...)
+}
+
+// prune drops what Pruned does below dir, reporting whether there was
+// anything to drop.
+func (t *Tree) prune(dir *scanner.DirInfo) bool {
+	dropped := false
+	if len(dir.Files) > 0 {
+		dir.Files = nil
+		dir.Pruned = true
+		dropped = true
+	}
+	if dir.PendingDirs == 0 {
+		if len(dir.Subdirs) > 0 {
+			for i := range dir.Subdirs {
+				t.unindex(&dir.Subdirs[i])
+			}
+			dir.Subdirs = nil
+			dir.Pruned = true
+			dropped = true
+		}
+		return dropped
+	}
+	for i := range dir.Subdirs {
+		if t.prune(&dir.Subdirs[i]) {
+			dropped = true
+		}
+	}
+	return dropped
+}
+
+// detach takes the file or directory at path out of its parent, returning
+// what it took.
+func (t *Tree) detach(path string) (*scanner.FileInfo, *scanner.DirInfo, []Change) {
+	if path == t.Root.Path {
+		return nil, nil, nil
+	}
+	parent := t.Find(filepath.Dir(path))
+	if parent == nil {
+		return nil, nil, nil
+	}
+
+	name := filepath.Base(path)
+	for i, file := range parent.Files {
+		if file.Name == name {
+			parent.Files = append(parent.Files[:i], parent.Files[i+1:]...)
+			parent.FileCount--
+			size, alloc := file.Counted()
+			return &file, nil, t.adjust(parent.Path, -size, -alloc, 0)
+		}
+	}
+	for i, dir := range parent.Subdirs {
+		if dir.Path == path {
+			t.unindex(&parent.Subdirs[i])
+			parent.Subdirs = append(parent.Subdirs[:i], parent.Subdirs[i+1:]...)
+			t.repoint(parent)
+			parent.SubdirCount--
+			return nil, &dir, t.adjust(parent.Path, -dir.Size, -dir.Alloc, -dir.PendingDirs)
+		}
+	}
+	return nil, nil, nil
+}
+
+// adjust adds to the totals of the directory at path and those above it, up
+// to the root.
+func (t *Tree) adjust(path string, size, alloc int64, pending int) []Change {
+	var changes []Change
+	for {
+		if dir := t.Find(path); dir != nil {
+			dir.Size += size
+			dir.Alloc += alloc
+			dir.PendingDirs += pending
+			changes = append(changes, Change{Path: path, Grew: size})
+		}
+		if path == t.Root.Path || isTop(path) {
+			return changes
+		}
+		path = filepath.Dir(path)
+	}
+}
+
+// isTop reports whether path has nothing above it.
+func isTop(path string) bool {
+	return path == "." || filepath.Dir(path) == path
+}
+
+// rebase rewrites the paths of dir and everything below it from under from
+// to under to.
+func rebase(dir *scanner.DirInfo, from, to string) {
+	dir.Path, _ = MovedPath(dir.Path, from, to)
+	for i := range dir.Subdirs {
+		rebase(&dir.Subdirs[i], from, to)
+	}
+}
+
+// MovedPath is where path ends up when from is moved to to, if it's from or
+// below it.
+func MovedPath(path, from, to string) (string, bool) {
+	if path == from {
+		return to, true
+	}
+	if rest, ok := strings.CutPrefix(path, from+string(filepath.Separator)); ok {
+		return filepath.Join(to, rest), true
+	}
+	return "", false
+}
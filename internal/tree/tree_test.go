@@ -0,0 +1,389 @@
+package tree
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+func file(name string, size, alloc int64) scanner.FileInfo {
+	return scanner.FileInfo{Name: name, Size: size, Alloc: alloc}
+}
+
+// loaded is a scanned directory, its totals added up from what's given.
+func loaded(path string, files []scanner.FileInfo, subdirs ...scanner.DirInfo) scanner.DirInfo {
+	dir := scanner.DirInfo{
+		Path:        path,
+		Files:       files,
+		Subdirs:     subdirs,
+		IsLoaded:    true,
+		FileCount:   len(files),
+		SubdirCount: len(subdirs),
+	}
+	for _, f := range files {
+		size, alloc := f.Counted()
+		dir.Size += size
+		dir.Alloc += alloc
+	}
+	for _, subdir := range subdirs {
+		dir.Size += subdir.Size
+		dir.Alloc += subdir.Alloc
+		dir.PendingDirs += subdir.PendingDirs
+	}
+	return dir
+}
+
+// placeholder is a directory the scan hasn't reached.
+func placeholder(path string) scanner.DirInfo {
+	return scanner.DirInfo{Path: path, PendingDirs: 1}
+}
+
+// fixture is
+//
+//	/r          f (10)
+//	/r/a        a1 (100)
+//	/r/a/deep   d1 (1000)
+//	/r/a/deep/x d2 (5000)
+//	/r/b        not scanned yet
+//	/r/c        empty
+func fixture() *Tree {
+	root := loaded("/r", []scanner.FileInfo{file("f", 10, 4096)},
+		loaded("/r/a", []scanner.FileInfo{file("a1", 100, 4096)},
+			loaded("/r/a/deep", []scanner.FileInfo{file("d1", 1000, 4096)},
+				loaded("/r/a/deep/x", []scanner.FileInfo{file("d2", 5000, 8192)}))),
+		placeholder("/r/b"),
+		loaded("/r/c", nil),
+	)
+	return New(&root)
+}
+
+type totals struct {
+	size, alloc int64
+	pending     int
+}
+
+// checkConsistent verifies what every test relies on: each directory's
+// totals add up what's below it, and Find returns the directory in the tree
+// itself rather than a stale copy.
+func checkConsistent(t *testing.T, tree *Tree) {
+	t.Helper()
+	var walk func(dir *scanner.DirInfo) totals
+	walk = func(dir *scanner.DirInfo) totals {
+		if found := tree.Find(dir.Path); found != dir {
+			t.Errorf("Find(%q) = %p, the tree holds it at %p", dir.Path, found, dir)
+		}
+		if !dir.IsLoaded {
+			return totals{dir.Size, dir.Alloc, dir.PendingDirs}
+		}
+		var sum totals
+		for _, f := range dir.Files {
+			size, alloc := f.Counted()
+			sum.size += size
+			sum.alloc += alloc
+		}
+		for i := range dir.Subdirs {
+			if parent := filepath.Dir(dir.Subdirs[i].Path); parent != dir.Path {
+				t.Errorf("%s is listed under %s", dir.Subdirs[i].Path, dir.Path)
+			}
+			below := walk(&dir.Subdirs[i])
+			sum.size += below.size
+			sum.alloc += below.alloc
+			sum.pending += below.pending
+		}
+		got := totals{dir.Size, dir.Alloc, dir.PendingDirs}
+		// Pruned directories keep the totals of what they no longer hold
+		if dir.Pruned {
+			return got
+		}
+		if got != sum {
+			t.Errorf("%s totals %+v, what's below it adds up to %+v", dir.Path, got, sum)
+		}
+		return sum
+	}
+	walk(tree.Root)
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   Event
+		want    map[string]totals
+		gone    []string
+		changed []string // Paths returned as changed, bottom up
+	}{
+		{
+			name: "scanned placeholder",
+			event: Scanned{Dir: func() *scanner.DirInfo {
+				dir := loaded("/r/b", []scanner.FileInfo{file("b1", 50, 4096)}, placeholder("/r/b/later"))
+				return &dir
+			}()},
+			want: map[string]totals{
+				"/r/b":       {50, 4096, 1},
+				"/r":         {6160, 24576, 1},
+				"/r/b/later": {0, 0, 1},
+			},
+			changed: []string{"/r/b", "/r"},
+		},
+		{
+			name: "scanned last pending directory",
+			event: Scanned{Dir: func() *scanner.DirInfo {
+				dir := loaded("/r/b", nil)
+				return &dir
+			}()},
+			want: map[string]totals{
+				"/r/b": {0, 0, 0},
+				"/r":   {6110, 20480, 0},
+			},
+			changed: []string{"/r/b", "/r"},
+		},
+		{
+			name: "rescanned directory replaces its subtree",
+			event: Scanned{Dir: func() *scanner.DirInfo {
+				dir := loaded("/r/a", []scanner.FileInfo{file("a1", 300, 4096)}, placeholder("/r/a/deep"))
+				return &dir
+			}()},
+			want: map[string]totals{
+				"/r/a": {300, 4096, 1},
+				"/r":   {310, 8192, 2},
+			},
+			gone:    []string{"/r/a/deep/x"},
+			changed: []string{"/r/a", "/r"},
+		},
+		{
+			name: "scanned root",
+			event: Scanned{Dir: func() *scanner.DirInfo {
+				dir := loaded("/r", []scanner.FileInfo{file("f", 20, 4096)}, placeholder("/r/a"))
+				return &dir
+			}()},
+			want:    map[string]totals{"/r": {20, 4096, 1}},
+			gone:    []string{"/r/a/deep", "/r/c"},
+			changed: []string{"/r"},
+		},
+		{
+			name:  "removed file",
+			event: Removed{Path: "/r/a/deep/d1"},
+			want: map[string]totals{
+				"/r/a/deep": {5000, 8192, 0},
+				"/r/a":      {5100, 12288, 0},
+				"/r":        {5110, 16384, 1},
+			},
+			changed: []string{"/r/a/deep", "/r/a", "/r"},
+		},
+		{
+			name:  "removed directory",
+			event: Removed{Path: "/r/a/deep"},
+			want: map[string]totals{
+				"/r/a": {100, 4096, 0},
+				"/r":   {110, 8192, 1},
+			},
+			gone:    []string{"/r/a/deep", "/r/a/deep/x"},
+			changed: []string{"/r/a", "/r"},
+		},
+		{
+			name:  "removed placeholder",
+			event: Removed{Path: "/r/b"},
+			want:  map[string]totals{"/r": {6110, 20480, 0}},
+			gone:  []string{"/r/b"},
+		},
+		{
+			name:  "removed outside the tree",
+			event: Removed{Path: "/elsewhere/x"},
+			want:  map[string]totals{"/r": {6110, 20480, 1}},
+		},
+		{
+			name:  "renamed file",
+			event: Moved{From: "/r/a/a1", To: "/r/a/a2"},
+			want: map[string]totals{
+				"/r/a": {6100, 16384, 0},
+				"/r":   {6110, 20480, 1},
+			},
+		},
+		{
+			name:  "moved directory under a new parent",
+			event: Moved{From: "/r/a/deep", To: "/r/c/deep"},
+			want: map[string]totals{
+				"/r/a":        {100, 4096, 0},
+				"/r/c":        {6000, 12288, 0},
+				"/r/c/deep":   {6000, 12288, 0},
+				"/r/c/deep/x": {5000, 8192, 0},
+				"/r":          {6110, 20480, 1},
+			},
+			gone: []string{"/r/a/deep", "/r/a/deep/x"},
+		},
+		{
+			name:  "moved directory up to the root",
+			event: Moved{From: "/r/a/deep/x", To: "/r/x"},
+			want: map[string]totals{
+				"/r/a/deep": {1000, 4096, 0},
+				"/r/a":      {1100, 8192, 0},
+				"/r/x":      {5000, 8192, 0},
+				"/r":        {6110, 20480, 1},
+			},
+			gone: []string{"/r/a/deep/x"},
+		},
+		{
+			name:  "moved pending directory",
+			event: Moved{From: "/r/b", To: "/r/c/b"},
+			want: map[string]totals{
+				"/r/c":   {0, 0, 1},
+				"/r/c/b": {0, 0, 1},
+				"/r":     {6110, 20480, 1},
+			},
+			gone: []string{"/r/b"},
+		},
+		{
+			name:  "moved over an existing directory",
+			event: Moved{From: "/r/c", To: "/r/a"},
+			want: map[string]totals{
+				"/r/a": {0, 0, 0},
+				"/r":   {10, 4096, 1},
+			},
+			gone: []string{"/r/c", "/r/a/deep"},
+		},
+		{
+			name:  "moved out of the tree",
+			event: Moved{From: "/r/a", To: "/elsewhere/a"},
+			want:  map[string]totals{"/r": {10, 4096, 1}},
+			gone:  []string{"/r/a", "/r/a/deep", "/elsewhere/a"},
+		},
+		{
+			name:  "pruned directory",
+			event: Pruned{Path: "/r/a"},
+			want: map[string]totals{
+				"/r/a": {6100, 16384, 0},
+				"/r":   {6110, 20480, 1},
+			},
+			gone:    []string{"/r/a/deep", "/r/a/deep/x"},
+			changed: []string{"/r/a"},
+		},
+		{
+			name:  "pruned around a pending directory",
+			event: Pruned{Path: "/r"},
+			want: map[string]totals{
+				"/r/a": {6100, 16384, 0},
+				"/r/b": {0, 0, 1},
+				"/r/c": {0, 0, 0},
+				"/r":   {6110, 20480, 1},
+			},
+			gone:    []string{"/r/a/deep", "/r/a/deep/x"},
+			changed: []string{"/r"},
+		},
+		{
+			name:  "pruned empty directory",
+			event: Pruned{Path: "/r/c"},
+			want:  map[string]totals{"/r/c": {0, 0, 0}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree := fixture()
+			checkConsistent(t, tree)
+			changes := tree.Apply(test.event)
+			checkConsistent(t, tree)
+
+			for path, want := range test.want {
+				dir := tree.Find(path)
+				if dir == nil {
+					t.Errorf("%s not found", path)
+					continue
+				}
+				if got := (totals{dir.Size, dir.Alloc, dir.PendingDirs}); got != want {
+					t.Errorf("%s totals %+v, want %+v", path, got, want)
+				}
+			}
+			for _, path := range test.gone {
+				if tree.Find(path) != nil {
+					t.Errorf("%s is still in the tree", path)
+				}
+			}
+			if test.changed != nil {
+				var paths []string
+				for _, change := range changes {
+					paths = append(paths, change.Path)
+				}
+				if !slices.Equal(paths, test.changed) {
+					t.Errorf("changed %v, want %v", paths, test.changed)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyHardLinks(t *testing.T) {
+	linked := file("link", 700, 4096)
+	linked.LinkOf = "/r/a/a1"
+	root := loaded("/r", nil, loaded("/r/a", []scanner.FileInfo{file("a1", 700, 4096), linked}))
+	tree := New(&root)
+	if root.Size != 700 {
+		t.Fatalf("root holds %d bytes, the link is counted twice", root.Size)
+	}
+
+	// Removing a link counted elsewhere frees nothing
+	tree.Apply(Removed{Path: "/r/a/link"})
+	checkConsistent(t, tree)
+	if tree.Root.Size != 700 {
+		t.Errorf("root holds %d bytes after removing the uncounted link, want 700", tree.Root.Size)
+	}
+}
+
+// TestFindAfterGrowth moves directories into one whose Subdirs has no room
+// left, so appending reallocates it. Find must return the directories in the
+// new array, or changes made through it are lost.
+func TestFindAfterGrowth(t *testing.T) {
+	const moved = 20
+	root := loaded("/r", nil, loaded("/r/src", nil))
+	// Exactly as long as it holds
+	root.Subdirs[0].Subdirs = slices.Clip(make([]scanner.DirInfo, 0, 1))
+	for i := range moved {
+		path := fmt.Sprintf("/r/m%d", i)
+		root.Subdirs = append(root.Subdirs, loaded(path, []scanner.FileInfo{file("f", 1, 4096)}, placeholder(path+"/pending")))
+		root.Size++
+		root.Alloc += 4096
+		root.PendingDirs++
+	}
+	tree := New(&root)
+
+	for i := range moved {
+		from := fmt.Sprintf("/r/m%d", i)
+		to := fmt.Sprintf("/r/src/m%d", i)
+		tree.Apply(Moved{From: from, To: to})
+		checkConsistent(t, tree)
+	}
+	if c := cap(tree.Find("/r/src").Subdirs); c < moved {
+		t.Fatalf("/r/src holds %d subdirectories, the moves didn't land", c)
+	}
+
+	// Scanning the placeholders goes through the pointers Find returns, so
+	// they have to be the live ones for the totals to reach the root
+	for i := range moved {
+		dir := loaded(fmt.Sprintf("/r/src/m%d/pending", i), []scanner.FileInfo{file("g", 10, 4096)})
+		tree.Apply(Scanned{Dir: &dir})
+	}
+	checkConsistent(t, tree)
+	if want := (totals{moved * 11, moved * 8192, 0}); (totals{tree.Root.Size, tree.Root.Alloc, tree.Root.PendingDirs}) != want {
+		t.Errorf("root totals %d/%d/%d, want %+v", tree.Root.Size, tree.Root.Alloc, tree.Root.PendingDirs, want)
+	}
+}
+
+func TestMovedPath(t *testing.T) {
+	tests := []struct {
+		path, from, to string
+		want           string
+		ok             bool
+	}{
+		{"/r/a", "/r/a", "/r/b", "/r/b", true},
+		{"/r/a/x/y", "/r/a", "/r/b", "/r/b/x/y", true},
+		{"/r/ab", "/r/a", "/r/b", "", false},
+		{"/r", "/r/a", "/r/b", "", false},
+	}
+	for _, test := range tests {
+		got, ok := MovedPath(test.path, test.from, test.to)
+		if got != test.want || ok != test.ok {
+			t.Errorf("MovedPath(%q, %q, %q) = %q, %v, want %q, %v", test.path, test.from, test.to, got, ok, test.want, test.ok)
+		}
+	}
+}
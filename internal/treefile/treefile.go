@@ -0,0 +1,396 @@
+// Package treefile saves scanned trees as JSON and merges exports from
+// several hosts or mounts into one virtual tree, so a fleet can be browsed
+// like a single disk.
+package treefile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/corpeningc/dua/internal/paths"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// Version is the format written by Save. Files from newer versions are
+// refused rather than misread.
+const Version = 1
+
+// Document is one exported tree. Merged documents have no host and are
+// rooted at "/", with each export below its host's name.
+type Document struct {
+	Version int       `json:"version"`
+	Host    string    `json:"host,omitempty"`
+	Root    string    `json:"root"`
+	Scanned time.Time `json:"scanned"`
+	Tree    *Dir      `json:"tree"`
+}
+
+// Dir is a directory in an exported tree. Size is the total of everything
+// below it, and Alloc the disk space that takes. Exports from before it was
+// recorded have no Alloc.
+type Dir struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	Alloc   int64     `json:"alloc,omitempty"`
+	ModTime time.Time `json:"mtime"`
+	Files   []File    `json:"files,omitempty"`
+	Dirs    []*Dir    `json:"dirs,omitempty"`
+	// Aggregated is set on a directory whose contents Aggregate rolled up
+	// into its Size, FileCount and DirCount.
+	Aggregated bool `json:"aggregated,omitempty"`
+	FileCount  int  `json:"file_count,omitempty"`
+	DirCount   int  `json:"dir_count,omitempty"`
+}
+
+// File is a file in an exported tree.
+type File struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	Alloc   int64     `json:"alloc,omitempty"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// Scan walks root into a document labelled with this machine's host name.
+// Directories that can't be read are kept without their contents.
+func Scan(root string) (Document, error) {
+	host, _ := os.Hostname()
+	doc := Document{Version: Version, Host: host, Root: root, Scanned: time.Now()}
+
+	dirs := make(map[string]*Dir)
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && p != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		if entry.IsDir() {
+			dir := &Dir{Name: entry.Name(), ModTime: info.ModTime()}
+			dirs[p] = dir
+			if p == root {
+				doc.Tree = dir
+			} else if parent := dirs[filepath.Dir(p)]; parent != nil {
+				parent.Dirs = append(parent.Dirs, dir)
+			}
+			return nil
+		}
+
+		if parent := dirs[filepath.Dir(p)]; parent != nil {
+			parent.Files = append(parent.Files, File{Name: entry.Name(), Size: info.Size(), Alloc: scanner.Allocated(p, info), ModTime: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return doc, err
+	}
+
+	doc.Tree.total()
+	return doc, nil
+}
+
+// ScanWith scans root like Scan, but with s, a streaming scanner set up as
+// the caller needs, such as rate limited. s is stopped once it's done.
+func ScanWith(s *scanner.StreamingScanner, root string) (Document, error) {
+	host, _ := os.Hostname()
+	doc := Document{Version: Version, Host: host, Root: root, Scanned: time.Now()}
+
+	updates, errs := s.StartStreaming(root)
+	defer s.Stop()
+	// Directories listed so far and not yet read, by path
+	pending := map[string]*Dir{root: {Name: filepath.Base(root)}}
+	var scanErr error
+	for done := false; !done; {
+		select {
+		case update := <-updates:
+			if update.IsComplete {
+				done = true
+				continue
+			}
+			info := update.DirInfo
+			dir := pending[info.Path]
+			if dir == nil {
+				continue
+			}
+			delete(pending, info.Path)
+			if info.Path == root {
+				doc.Tree = dir
+			}
+			dir.ModTime = info.ModTime
+			for _, file := range info.Files {
+				dir.Files = append(dir.Files, File{Name: file.Name, Size: file.Size, Alloc: file.Alloc, ModTime: file.ModTime})
+			}
+			for _, sub := range info.Subdirs {
+				child := &Dir{Name: filepath.Base(sub.Path), ModTime: sub.ModTime}
+				dir.Dirs = append(dir.Dirs, child)
+				pending[sub.Path] = child
+			}
+		case err := <-errs:
+			// Directories below the root are kept without their contents
+			if scanErr == nil {
+				scanErr = err
+			}
+		}
+	}
+
+	if doc.Tree == nil {
+		// The root couldn't be read, which may only be reported once the
+		// scan has completed
+		if scanErr == nil {
+			select {
+			case scanErr = <-errs:
+			default:
+				scanErr = fmt.Errorf("could not read %s", root)
+			}
+		}
+		return doc, scanErr
+	}
+	doc.Tree.total()
+	return doc, nil
+}
+
+// Save writes doc to file as JSON. It's written under a temporary name and
+// renamed into place, so a reader never sees half a tree.
+func Save(file string, doc Document) error {
+	tmp := file + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	defer out.Close()
+
+	if err := json.NewEncoder(out).Encode(doc); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// CachePath is where the latest tree of root is cached, for the interface to
+// open without scanning. The daemon keeps it up to date.
+func CachePath(root string) (string, error) {
+	sum := sha256.Sum256([]byte(root))
+	return paths.CacheFile(filepath.Join("trees", hex.EncodeToString(sum[:8])+".json"))
+}
+
+// SaveCache writes doc as the cached tree of its root.
+func SaveCache(doc Document) error {
+	file, err := CachePath(doc.Root)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return Save(file, doc)
+}
+
+// Load reads a document written by Save or Merge.
+func Load(file string) (Document, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Document{}, err
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return Document{}, fmt.Errorf("%s: %w", file, err)
+	}
+	if doc.Version > Version {
+		return Document{}, fmt.Errorf("%s: format version %d is newer than this dua supports", file, doc.Version)
+	}
+	if doc.Tree == nil {
+		return Document{}, fmt.Errorf("%s: no tree", file)
+	}
+	return doc, nil
+}
+
+// Merge combines documents into one tree rooted at "/". Each export is placed
+// at its host's name followed by its root path, so two mounts of the same
+// host end up side by side, and merged documents can be merged again. A file
+// present in more than one document is an error, since it would be counted
+// twice.
+func Merge(docs []Document) (Document, error) {
+	merged := Document{Version: Version, Root: "/", Scanned: time.Now(), Tree: &Dir{Name: "/"}}
+
+	for _, doc := range docs {
+		// Intermediate directories are virtual and get the newest time below
+		// them once everything is in place
+		parent := merged.Tree
+		for _, name := range prefix(doc) {
+			parent = parent.child(name)
+		}
+		if err := parent.merge(doc.Tree); err != nil {
+			return Document{}, fmt.Errorf("%s%s: %w", doc.Host, doc.Root, err)
+		}
+	}
+
+	merged.Tree.total()
+	return merged, nil
+}
+
+// prefix is where a document's tree goes in a merge: its host, then each
+// component of its root. Windows volumes such as C: are kept as a component.
+func prefix(doc Document) []string {
+	var names []string
+	if doc.Host != "" {
+		names = append(names, doc.Host)
+	}
+	for _, name := range strings.Split(filepath.ToSlash(doc.Root), "/") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// child returns the subdirectory called name, adding it if needed.
+func (d *Dir) child(name string) *Dir {
+	for _, dir := range d.Dirs {
+		if dir.Name == name {
+			return dir
+		}
+	}
+	dir := &Dir{Name: name}
+	d.Dirs = append(d.Dirs, dir)
+	return dir
+}
+
+// merge adds other's contents to d, combining subdirectories with the same
+// name.
+func (d *Dir) merge(other *Dir) error {
+	if other.ModTime.After(d.ModTime) {
+		d.ModTime = other.ModTime
+	}
+	if d.Aggregated || other.Aggregated {
+		// Rolled up contents can't be merged item by item, so both sides
+		// are rolled up and added together, without checking for overlap
+		d.total()
+		d.Aggregate(0)
+		other.Aggregate(0)
+		d.Size += other.Size
+		d.Alloc += other.Alloc
+		d.FileCount += other.FileCount
+		d.DirCount += other.DirCount
+		return nil
+	}
+
+	names := make(map[string]bool, len(d.Files))
+	for _, file := range d.Files {
+		names[file.Name] = true
+	}
+	for _, file := range other.Files {
+		if names[file.Name] {
+			return fmt.Errorf("%s is in more than one export", file.Name)
+		}
+		d.Files = append(d.Files, file)
+	}
+
+	for _, dir := range other.Dirs {
+		if err := d.child(dir.Name).merge(dir); err != nil {
+			return fmt.Errorf("%s/%w", dir.Name, err)
+		}
+	}
+	return nil
+}
+
+// Aggregate rolls everything more than depth levels below d up into the
+// directories depth levels down, which keep their totals but lose their
+// contents, for a summary of the same size however deep the tree goes. At
+// depth 0, d itself is rolled up. d's sizes must be totalled.
+func (d *Dir) Aggregate(depth int) {
+	if depth > 0 {
+		for _, dir := range d.Dirs {
+			dir.Aggregate(depth - 1)
+		}
+		return
+	}
+	if d.Aggregated {
+		return
+	}
+	var files, dirs int
+	var count func(dir *Dir)
+	count = func(dir *Dir) {
+		files += len(dir.Files) + dir.FileCount
+		dirs += len(dir.Dirs) + dir.DirCount
+		for _, sub := range dir.Dirs {
+			count(sub)
+		}
+	}
+	count(d)
+	d.Aggregated = true
+	d.FileCount, d.DirCount = files, dirs
+	d.Files, d.Dirs = nil, nil
+}
+
+// total sets the sizes of d and every directory below it, and gives virtual
+// directories the newest modification time of their contents.
+func (d *Dir) total() {
+	if d.Aggregated {
+		return
+	}
+	virtual := d.ModTime.IsZero()
+	d.Size, d.Alloc = 0, 0
+	for _, file := range d.Files {
+		d.Size += file.Size
+		d.Alloc += file.Alloc
+	}
+	for _, dir := range d.Dirs {
+		dir.total()
+		d.Size += dir.Size
+		d.Alloc += dir.Alloc
+		if virtual && dir.ModTime.After(d.ModTime) {
+			d.ModTime = dir.ModTime
+		}
+	}
+}
+
+// DirInfo converts the document to a fully loaded scanner tree. Paths are the
+// root's path joined with each name, so a merged tree's paths start with
+// "/host/...".
+func (doc Document) DirInfo() *scanner.DirInfo {
+	info := doc.Tree.dirInfo(filepath.Clean(doc.Root))
+	return &info
+}
+
+func (d *Dir) dirInfo(p string) scanner.DirInfo {
+	info := scanner.DirInfo{
+		Path:        p,
+		Size:        d.Size,
+		Alloc:       d.Alloc,
+		ModTime:     d.ModTime,
+		Files:       make([]scanner.FileInfo, 0, len(d.Files)),
+		Subdirs:     make([]scanner.DirInfo, 0, len(d.Dirs)),
+		IsLoaded:    true,
+		FileCount:   len(d.Files),
+		SubdirCount: len(d.Dirs),
+		// The totals are there without the contents, as after pruning
+		Pruned: d.Aggregated,
+	}
+	for _, file := range d.Files {
+		info.Files = append(info.Files, scanner.FileInfo{Name: file.Name, Size: file.Size, Alloc: file.Alloc, ModTime: file.ModTime})
+	}
+
+	dirs := append([]*Dir(nil), d.Dirs...)
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Name < dirs[j].Name })
+	for _, dir := range dirs {
+		info.Subdirs = append(info.Subdirs, dir.dirInfo(filepath.Join(p, dir.Name)))
+	}
+	return info
+}
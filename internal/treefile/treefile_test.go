@@ -0,0 +1,62 @@
+package treefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// TestScanWith checks the streaming scan the daemon uses against Scan.
+func TestScanWith(t *testing.T) {
+	root := t.TempDir()
+	for name, size := range map[string]int{"a": 10, "sub/b": 200, "sub/deep/c": 3000, "other/d": 40} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(root, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	walked, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamed, err := ScanWith(scanner.NewStreamingScanner(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var compare func(path string, want, got *Dir)
+	compare = func(path string, want, got *Dir) {
+		if got.Name != want.Name || got.Size != want.Size || len(got.Files) != len(want.Files) || len(got.Dirs) != len(want.Dirs) {
+			t.Errorf("%s: streamed %s with %d bytes, %d files and %d dirs, walked %s with %d, %d and %d",
+				path, got.Name, got.Size, len(got.Files), len(got.Dirs), want.Name, want.Size, len(want.Files), len(want.Dirs))
+			return
+		}
+		streamedDirs := make(map[string]*Dir)
+		for _, dir := range got.Dirs {
+			streamedDirs[dir.Name] = dir
+		}
+		for _, dir := range want.Dirs {
+			if other, ok := streamedDirs[dir.Name]; ok {
+				compare(filepath.Join(path, dir.Name), dir, other)
+			} else {
+				t.Errorf("%s: streamed scan is missing %s", path, dir.Name)
+			}
+		}
+	}
+	compare(root, walked.Tree, streamed.Tree)
+}
+
+func TestScanWithUnreadableRoot(t *testing.T) {
+	if _, err := ScanWith(scanner.NewStreamingScanner(), filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("scanning a missing root succeeded")
+	}
+}
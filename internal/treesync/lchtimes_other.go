@@ -0,0 +1,9 @@
+//go:build !unix
+
+package treesync
+
+import "time"
+
+func lchtimes(path string, mod time.Time) error {
+	return nil
+}
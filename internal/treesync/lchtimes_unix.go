@@ -0,0 +1,16 @@
+//go:build unix
+
+package treesync
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// lchtimes sets a symlink's own modification time, so it compares equal to
+// the one it was copied from.
+func lchtimes(path string, mod time.Time) error {
+	tv := unix.NsecToTimeval(mod.UnixNano())
+	return unix.Lutimes(path, []unix.Timeval{tv, tv})
+}
@@ -0,0 +1,335 @@
+// Package treesync works out what it takes to make one tree a copy of
+// another, the way rsync would, and carries it out on local directories.
+package treesync
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/corpeningc/dua/internal/dupes"
+	"github.com/corpeningc/dua/internal/treefile"
+)
+
+// ErrNotRegular is reported for entries that are neither regular files,
+// directories nor symlinks, such as FIFOs, sockets and devices. They're
+// skipped, since reading one may block or never end.
+var ErrNotRegular = errors.New("not a regular file, skipped")
+
+// ErrMismatch is reported when a copied file doesn't hash the same as its
+// source. The copy is thrown away and the destination left as it was.
+var ErrMismatch = errors.New("copy doesn't match the source")
+
+// Action is what happens to one path on the destination.
+type Action int
+
+const (
+	// Copy adds a file or directory only the source has
+	Copy Action = iota
+	// Update replaces a file that differs in size or modification time
+	Update
+	// Delete removes a file or directory only the destination has
+	Delete
+)
+
+func (a Action) String() string {
+	switch a {
+	case Copy:
+		return "copy"
+	case Update:
+		return "update"
+	}
+	return "delete"
+}
+
+// Change is one step of a plan. Path is slash-separated and relative to the
+// roots. Directories are copied and deleted whole.
+type Change struct {
+	Path   string
+	Action Action
+	Dir    bool
+	Size   int64
+}
+
+// Plan lists the changes that make dst match src, sorted by path. Deletions
+// are left out unless deletes is set. Either directory may be nil, as when
+// one side doesn't have it.
+func Plan(src, dst *treefile.Dir, deletes bool) []Change {
+	var changes []Change
+	plan(src, dst, "", deletes, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func plan(src, dst *treefile.Dir, prefix string, deletes bool, changes *[]Change) {
+	srcDirs, srcFiles := index(src)
+	dstDirs, dstFiles := index(dst)
+
+	for name, dir := range srcDirs {
+		p := path.Join(prefix, name)
+		if other, ok := dstDirs[name]; ok {
+			plan(dir, other, p, deletes, changes)
+			continue
+		}
+		if file, ok := dstFiles[name]; ok {
+			// A file where the source has a directory has to go first
+			*changes = append(*changes, Change{Path: p, Action: Delete, Size: file.Size})
+		}
+		*changes = append(*changes, Change{Path: p, Action: Copy, Dir: true, Size: dir.Size})
+	}
+
+	for name, file := range srcFiles {
+		p := path.Join(prefix, name)
+		if other, ok := dstFiles[name]; ok {
+			if differs(file, other) {
+				*changes = append(*changes, Change{Path: p, Action: Update, Size: file.Size})
+			}
+			continue
+		}
+		if dir, ok := dstDirs[name]; ok {
+			*changes = append(*changes, Change{Path: p, Action: Delete, Dir: true, Size: dir.Size})
+		}
+		*changes = append(*changes, Change{Path: p, Action: Copy, Size: file.Size})
+	}
+
+	if !deletes {
+		return
+	}
+	for name, dir := range dstDirs {
+		if _, ok := srcDirs[name]; !ok {
+			if _, ok := srcFiles[name]; !ok {
+				*changes = append(*changes, Change{Path: path.Join(prefix, name), Action: Delete, Dir: true, Size: dir.Size})
+			}
+		}
+	}
+	for name, file := range dstFiles {
+		if _, ok := srcFiles[name]; !ok {
+			if _, ok := srcDirs[name]; !ok {
+				*changes = append(*changes, Change{Path: path.Join(prefix, name), Action: Delete, Size: file.Size})
+			}
+		}
+	}
+}
+
+func index(dir *treefile.Dir) (map[string]*treefile.Dir, map[string]treefile.File) {
+	dirs := make(map[string]*treefile.Dir)
+	files := make(map[string]treefile.File)
+	if dir == nil {
+		return dirs, files
+	}
+	for _, sub := range dir.Dirs {
+		dirs[sub.Name] = sub
+	}
+	for _, file := range dir.Files {
+		files[file.Name] = file
+	}
+	return dirs, files
+}
+
+// differs is rsync's quick check: the same size and modification time mean
+// the same file. Times are compared to the second, since some filesystems
+// and copies keep no more.
+func differs(a, b treefile.File) bool {
+	return a.Size != b.Size || !a.ModTime.Truncate(time.Second).Equal(b.ModTime.Truncate(time.Second))
+}
+
+// Totals adds up the bytes a plan copies and deletes.
+func Totals(changes []Change) (copied, deleted int64) {
+	for _, change := range changes {
+		if change.Action == Delete {
+			deleted += change.Size
+		} else {
+			copied += change.Size
+		}
+	}
+	return copied, deleted
+}
+
+// RsyncCommand is the rsync invocation carrying out the same plan.
+func RsyncCommand(src, dst string, deletes bool) string {
+	args := []string{"rsync", "-a"}
+	if deletes {
+		args = append(args, "--delete")
+	}
+	// A trailing slash copies the directory's contents rather than the
+	// directory itself
+	args = append(args, shellQuote(strings.TrimSuffix(src, "/")+"/"), shellQuote(strings.TrimSuffix(dst, "/")+"/"))
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for a POSIX shell when it needs it.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("/._-+,:@%", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Apply carries out a plan from the src directory to dst. It goes on past
+// failures and returns how many changes were made and the errors of the rest.
+func Apply(src, dst string, changes []Change) (int, []error) {
+	done := 0
+	var errs []error
+	for _, change := range changes {
+		from := filepath.Join(src, filepath.FromSlash(change.Path))
+		to := filepath.Join(dst, filepath.FromSlash(change.Path))
+		var err error
+		switch {
+		case change.Action == Delete:
+			err = os.RemoveAll(to)
+		case change.Dir:
+			err = copyTree(from, to)
+		default:
+			err = copyEntry(from, to)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", change.Action, change.Path, err))
+			continue
+		}
+		done++
+	}
+	return done, errs
+}
+
+// copyTree copies the directory from to the new path to. Directory times are
+// set last, since filling a directory changes its modification time. Entries
+// copyEntry skips don't stop the rest from being copied, but are reported.
+func copyTree(from, to string) error {
+	type dirTime struct {
+		path string
+		mod  time.Time
+	}
+	var dirs []dirTime
+	var skipped []error
+	err := filepath.WalkDir(from, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, rel)
+		if !entry.IsDir() {
+			err := copyEntry(p, target)
+			if errors.Is(err, ErrNotRegular) {
+				skipped = append(skipped, err)
+				return nil
+			}
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(target, info.Mode().Perm()|0o700); err != nil {
+			return err
+		}
+		dirs = append(dirs, dirTime{target, info.ModTime()})
+		return nil
+	})
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Chtimes(dirs[i].path, dirs[i].mod, dirs[i].mod)
+	}
+	return errors.Join(append([]error{err}, skipped...)...)
+}
+
+// verifyCopy hashes the source and its copy side by side, so a large file
+// read from one disk and written to another takes about as long as one of
+// them, and fails with ErrMismatch when they differ.
+func verifyCopy(from, to string) error {
+	var fromHash string
+	var fromErr error
+	hashed := make(chan struct{})
+	go func() {
+		fromHash, fromErr = dupes.HashFile(from)
+		close(hashed)
+	}()
+	toHash, err := dupes.HashFile(to)
+	<-hashed
+	if err := errors.Join(fromErr, err); err != nil {
+		return err
+	}
+	if fromHash != toHash {
+		return &fs.PathError{Op: "verify", Path: from, Err: ErrMismatch}
+	}
+	return nil
+}
+
+// copyEntry copies a file or symlink over to, keeping its permissions and
+// modification time. The copy is written under a temporary name, checked
+// against the source and only then renamed into place, so an interrupted or
+// corrupted update never leaves a bad file. Anything
+// else is skipped with ErrNotRegular.
+func copyEntry(from, to string) error {
+	info, err := os.Lstat(from)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() && info.Mode()&fs.ModeSymlink == 0 {
+		return &fs.PathError{Op: "copy", Path: from, Err: ErrNotRegular}
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return err
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(from)
+		if err != nil {
+			return err
+		}
+		os.Remove(to)
+		if err := os.Symlink(target, to); err != nil {
+			return err
+		}
+		return lchtimes(to, info.ModTime())
+	}
+
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// A name of its own, so it can't clobber a file that happens to be
+	// called like it or a concurrent copy
+	out, err := os.CreateTemp(filepath.Dir(to), "."+filepath.Base(to)+".dua-sync-*")
+	if err != nil {
+		return err
+	}
+	tmp := out.Name()
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Chmod(info.Mode().Perm()); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := verifyCopy(from, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, to)
+}
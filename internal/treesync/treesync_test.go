@@ -0,0 +1,185 @@
+package treesync
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corpeningc/dua/internal/treefile"
+)
+
+// stamp is the modification time of the files the tests write, so the quick
+// check sees copies as unchanged.
+var stamp = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// write creates each slash-separated path below root with its contents.
+func write(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, stamp, stamp); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// scan reads root into a tree as compare mode does.
+func scan(t *testing.T, root string) *treefile.Dir {
+	t.Helper()
+	doc, err := treefile.Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.Tree
+}
+
+// describe lists changes as "action path", with a slash after directories.
+func describe(changes []Change) []string {
+	var lines []string
+	for _, change := range changes {
+		line := change.Action.String() + " " + change.Path
+		if change.Dir {
+			line += "/"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fixture has src and dst differ in every way a plan handles: files and
+// directories only on one side, a file that changed, one that didn't and a
+// file where the other side has a directory.
+func fixture(t *testing.T) (src, dst string) {
+	src, dst = t.TempDir(), t.TempDir()
+	write(t, src, map[string]string{
+		"same":         "unchanged",
+		"changed":      "new contents",
+		"new":          "only in src",
+		"sub/deep/a":   "a",
+		"sub/same":     "unchanged",
+		"was_file/b":   "b",
+		"nested/x/y/z": "z",
+	})
+	write(t, dst, map[string]string{
+		"same":     "unchanged",
+		"changed":  "old",
+		"extra":    "only in dst",
+		"sub/same": "unchanged",
+		"sub/gone": "only in dst",
+		"was_file": "a file in dst",
+	})
+	return src, dst
+}
+
+// TestPlan is the dry run: the changes listed, with deletions only when
+// asked for, and nothing touched on disk.
+func TestPlan(t *testing.T) {
+	src, dst := fixture(t)
+	before := scan(t, dst)
+
+	tests := []struct {
+		deletes bool
+		want    []string
+	}{
+		{false, []string{"update changed", "copy nested/", "copy new", "copy sub/deep/", "delete was_file", "copy was_file/"}},
+		{true, []string{"update changed", "delete extra", "copy nested/", "copy new", "copy sub/deep/", "delete sub/gone", "delete was_file", "copy was_file/"}},
+	}
+	for _, test := range tests {
+		got := describe(Plan(scan(t, src), scan(t, dst), test.deletes))
+		if !slices.Equal(got, test.want) {
+			t.Errorf("Plan with deletes %v = %q, want %q", test.deletes, got, test.want)
+		}
+	}
+
+	if changes := Plan(before, scan(t, dst), true); len(changes) > 0 {
+		t.Errorf("planning changed the destination: %q", describe(changes))
+	}
+}
+
+// TestApply carries out a plan and checks the destination then matches,
+// contents and modification times included.
+func TestApply(t *testing.T) {
+	src, dst := fixture(t)
+	changes := Plan(scan(t, src), scan(t, dst), true)
+	done, errs := Apply(src, dst, changes)
+	if len(errs) > 0 || done != len(changes) {
+		t.Fatalf("made %d of %d changes: %v", done, len(changes), errs)
+	}
+
+	if left := Plan(scan(t, src), scan(t, dst), true); len(left) > 0 {
+		t.Errorf("changes left after applying: %q", describe(left))
+	}
+	err := filepath.WalkDir(src, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		want, _ := os.ReadFile(path)
+		got, err := os.ReadFile(filepath.Join(dst, rel))
+		if err != nil {
+			return err
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s holds %q, want %q", rel, got, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filepath.WalkDir(dst, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && strings.Contains(entry.Name(), ".dua-sync") {
+			t.Errorf("temporary file %s left behind", path)
+		}
+		return err
+	})
+}
+
+// TestApplyLeavesSimilarNames checks a copy doesn't touch a file named like
+// the temporary files of one.
+func TestApplyLeavesSimilarNames(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	write(t, src, map[string]string{"file": "copied"})
+	write(t, dst, map[string]string{"file.dua-sync": "keep me"})
+
+	if _, errs := Apply(src, dst, Plan(scan(t, src), scan(t, dst), false)); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if got, err := os.ReadFile(filepath.Join(dst, "file.dua-sync")); err != nil || string(got) != "keep me" {
+		t.Errorf("file.dua-sync holds %q (%v) after the copy", got, err)
+	}
+}
+
+func TestApplyMissingSource(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	changes := []Change{{Path: "missing", Action: Copy}, {Path: "also/missing", Action: Copy, Dir: true}}
+	done, errs := Apply(src, dst, changes)
+	if done != 0 || len(errs) != 2 || !errors.Is(errs[0], os.ErrNotExist) {
+		t.Errorf("made %d changes with errors %v, want none and two missing files", done, errs)
+	}
+}
+
+func TestVerifyCopy(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, map[string]string{"src": "contents", "good": "contents", "bad": "contents!"})
+
+	if err := verifyCopy(filepath.Join(dir, "src"), filepath.Join(dir, "good")); err != nil {
+		t.Errorf("identical copy: %v", err)
+	}
+	if err := verifyCopy(filepath.Join(dir, "src"), filepath.Join(dir, "bad")); !errors.Is(err, ErrMismatch) {
+		t.Errorf("differing copy: %v, want ErrMismatch", err)
+	}
+	if err := verifyCopy(filepath.Join(dir, "missing"), filepath.Join(dir, "good")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing source: %v, want it not to exist", err)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build unix
+
+package treesync
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// TestApplySkipsSpecialFiles copies a FIFO on its own and inside a new
+// directory. Both are skipped and reported, and the rest is still copied.
+func TestApplySkipsSpecialFiles(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	write(t, src, map[string]string{"file": "copied", "dir/file": "copied"})
+	for _, fifo := range []string{"pipe", "dir/pipe"} {
+		if err := syscall.Mkfifo(filepath.Join(src, fifo), 0o644); err != nil {
+			t.Skip("can't make a FIFO:", err)
+		}
+	}
+
+	done, errs := Apply(src, dst, Plan(scan(t, src), scan(t, dst), false))
+	if len(errs) != 2 {
+		t.Fatalf("made %d changes with errors %v, want the 2 FIFOs reported", done, errs)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrNotRegular) {
+			t.Errorf("error %v, want ErrNotRegular", err)
+		}
+	}
+	for _, name := range []string{"file", "dir/file"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err != nil {
+			t.Errorf("%s wasn't copied: %v", name, err)
+		}
+	}
+	for _, name := range []string{"pipe", "dir/pipe"} {
+		if _, err := os.Lstat(filepath.Join(dst, name)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s was created: %v", name, err)
+		}
+	}
+}
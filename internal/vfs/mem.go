@@ -0,0 +1,261 @@
+package vfs
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operations a Mem can be told to fail, see Fail.
+const (
+	OpReadDir = "readdir"
+	OpStat    = "stat"
+	OpLstat   = "lstat"
+	OpRemove  = "remove"
+	OpRename  = "rename"
+)
+
+// Mem is an in-memory filesystem of directories and files with a size but
+// no contents. Paths are cleaned with filepath.Clean and the root is "/".
+// It is safe for concurrent use, as the scanner's workers need.
+type Mem struct {
+	mu     sync.Mutex
+	nodes  map[string]*memNode
+	fails  map[memFail]error
+	nextID int
+}
+
+type memNode struct {
+	name     string
+	dir      bool
+	size     int64
+	modTime  time.Time
+	id       int
+	children map[string]bool // Names, for directories
+}
+
+type memFail struct {
+	op   string
+	path string
+}
+
+// NewMem returns a Mem holding an empty root directory.
+func NewMem() *Mem {
+	m := &Mem{nodes: make(map[string]*memNode), fails: make(map[memFail]error)}
+	m.nodes[string(filepath.Separator)] = m.newNode(string(filepath.Separator), true, 0, time.Time{})
+	return m
+}
+
+func (m *Mem) newNode(name string, dir bool, size int64, modTime time.Time) *memNode {
+	m.nextID++
+	node := &memNode{name: name, dir: dir, size: size, modTime: modTime, id: m.nextID}
+	if dir {
+		node.children = make(map[string]bool)
+	}
+	return node
+}
+
+// MkdirAll creates the directory at path and any missing parents.
+func (m *Mem) MkdirAll(path string, modTime time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mkdirAll(filepath.Clean(path), modTime)
+}
+
+func (m *Mem) mkdirAll(path string, modTime time.Time) *memNode {
+	if node, ok := m.nodes[path]; ok {
+		return node
+	}
+	parent := m.mkdirAll(filepath.Dir(path), modTime)
+	node := m.newNode(filepath.Base(path), true, 0, modTime)
+	m.nodes[path] = node
+	parent.children[node.name] = true
+	return node
+}
+
+// WriteFile creates or replaces the file at path, creating missing parent
+// directories.
+func (m *Mem) WriteFile(path string, size int64, modTime time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	parent := m.mkdirAll(filepath.Dir(path), modTime)
+	node := m.newNode(filepath.Base(path), false, size, modTime)
+	m.nodes[path] = node
+	parent.children[node.name] = true
+}
+
+// Fail makes op on path return err from now on. RemoveAll fails if it would
+// remove a path set to fail, removing nothing.
+func (m *Mem) Fail(op, path string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fails[memFail{op, filepath.Clean(path)}] = err
+}
+
+// Exists reports whether anything is at path.
+func (m *Mem) Exists(path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.nodes[filepath.Clean(path)]
+	return ok
+}
+
+// check returns the injected failure for op on path, if any.
+func (m *Mem) check(op, path string) error {
+	if err, ok := m.fails[memFail{op, path}]; ok {
+		return &fs.PathError{Op: op, Path: path, Err: err}
+	}
+	return nil
+}
+
+func (m *Mem) lookup(op, path string) (*memNode, error) {
+	path = filepath.Clean(path)
+	if err := m.check(op, path); err != nil {
+		return nil, err
+	}
+	node, ok := m.nodes[path]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+	return node, nil
+}
+
+func (m *Mem) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	node, err := m.lookup(OpReadDir, name)
+	if err != nil {
+		return nil, err
+	}
+	if !node.dir {
+		return nil, &fs.PathError{Op: OpReadDir, Path: name, Err: fs.ErrInvalid}
+	}
+
+	names := make([]string, 0, len(node.children))
+	for child := range node.children {
+		names = append(names, child)
+	}
+	sort.Strings(names)
+	entries := make([]fs.DirEntry, 0, len(names))
+	for _, child := range names {
+		info := memInfo{*m.nodes[filepath.Join(filepath.Clean(name), child)]}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	return entries, nil
+}
+
+func (m *Mem) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	node, err := m.lookup(OpStat, name)
+	if err != nil {
+		return nil, err
+	}
+	return memInfo{*node}, nil
+}
+
+// Lstat is Stat, as a Mem has no symlinks.
+func (m *Mem) Lstat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	node, err := m.lookup(OpLstat, name)
+	if err != nil {
+		return nil, err
+	}
+	return memInfo{*node}, nil
+}
+
+func (m *Mem) RemoveAll(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	below := m.below(name)
+	for _, path := range below {
+		if err := m.check(OpRemove, path); err != nil {
+			return err
+		}
+	}
+	for _, path := range below {
+		delete(m.nodes, path)
+	}
+	if parent, ok := m.nodes[filepath.Dir(name)]; ok && parent != m.nodes[name] {
+		delete(parent.children, filepath.Base(name))
+	}
+	return nil
+}
+
+// below lists path and everything under it that exists.
+func (m *Mem) below(path string) []string {
+	var paths []string
+	if _, ok := m.nodes[path]; ok {
+		paths = append(paths, path)
+	}
+	prefix := strings.TrimSuffix(path, string(filepath.Separator)) + string(filepath.Separator)
+	for p := range m.nodes {
+		if strings.HasPrefix(p, prefix) {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+func (m *Mem) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	if err := m.check(OpRename, oldpath); err != nil {
+		return err
+	}
+	node, ok := m.nodes[oldpath]
+	if !ok {
+		return &fs.PathError{Op: OpRename, Path: oldpath, Err: fs.ErrNotExist}
+	}
+	parent, ok := m.nodes[filepath.Dir(newpath)]
+	if !ok || !parent.dir {
+		return &fs.PathError{Op: OpRename, Path: newpath, Err: fs.ErrNotExist}
+	}
+	if target, ok := m.nodes[newpath]; ok && target.dir && len(target.children) > 0 {
+		return &fs.PathError{Op: OpRename, Path: newpath, Err: fs.ErrExist}
+	}
+
+	moved := make(map[string]*memNode)
+	for _, path := range m.below(oldpath) {
+		moved[newpath+strings.TrimPrefix(path, oldpath)] = m.nodes[path]
+		delete(m.nodes, path)
+	}
+	delete(m.nodes[filepath.Dir(oldpath)].children, node.name)
+	node.name = filepath.Base(newpath)
+	for path, moving := range moved {
+		m.nodes[path] = moving
+	}
+	parent.children[node.name] = true
+	return nil
+}
+
+func (m *Mem) SameFile(a, b fs.FileInfo) bool {
+	ai, aok := a.(memInfo)
+	bi, bok := b.(memInfo)
+	return aok && bok && ai.node.id == bi.node.id
+}
+
+// memInfo describes a node as it was when it was looked up.
+type memInfo struct {
+	node memNode
+}
+
+func (i memInfo) Name() string       { return i.node.name }
+func (i memInfo) Size() int64        { return i.node.size }
+func (i memInfo) ModTime() time.Time { return i.node.modTime }
+func (i memInfo) IsDir() bool        { return i.node.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.node.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
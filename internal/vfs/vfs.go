@@ -0,0 +1,34 @@
+// Package vfs is the filesystem the scanner, and the interface's deletions
+// and renames, go through. OS is the real one. Mem keeps a tree in memory
+// and can be told to fail, so the scan, delete and rename pipeline can be
+// exercised deterministically, including permission and I/O errors.
+package vfs
+
+import (
+	"io/fs"
+	"os"
+)
+
+// FS is the filesystem operations dua's scanning and cleanup need.
+type FS interface {
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Stat(name string) (fs.FileInfo, error)
+	Lstat(name string) (fs.FileInfo, error)
+	RemoveAll(name string) error
+	Rename(oldpath, newpath string) error
+	// SameFile reports whether two infos from this filesystem describe the
+	// same file, as os.SameFile does.
+	SameFile(a, b fs.FileInfo) bool
+}
+
+// OS is the operating system's filesystem.
+var OS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (osFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (osFS) Lstat(name string) (fs.FileInfo, error)     { return os.Lstat(name) }
+func (osFS) RemoveAll(name string) error                { return os.RemoveAll(name) }
+func (osFS) Rename(oldpath, newpath string) error       { return os.Rename(oldpath, newpath) }
+func (osFS) SameFile(a, b fs.FileInfo) bool             { return os.SameFile(a, b) }
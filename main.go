@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -9,7 +10,12 @@ import (
 
 func main() {
 	if err := cmd.Execute(); err != nil {
+		// Exiting only here lets Execute's deferred cleanup run first
+		var code cmd.ExitCode
+		if errors.As(err, &code) {
+			os.Exit(int(code))
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var (
+	footerKeyStyle = lipgloss.NewStyle().Bold(true)
+	footerSepStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+)
+
+// footerSep separates the segments of the footer.
+const footerSep = " • "
+
+// actionBar lays out the footer's segments on one line, as many as fit in
+// order. Those left out are replaced by a pointer to the help overlay if it
+// can be opened, or just an ellipsis. The first segment, which is what's
+// being typed or asked in prompts, is always shown, cut to fit if need be.
+func (m Model) actionBar(segments []string, help bool) string {
+	more := "…"
+	if help {
+		more = m.tr.T("footer.more")
+	}
+
+	var shown []string
+	used := 0
+	for i, segment := range segments {
+		width := lipgloss.Width(segment)
+		if i == 0 {
+			segment = ansi.Truncate(segment, m.width, "…")
+			width = lipgloss.Width(segment)
+		} else {
+			// Unless this is the last, leave room for saying there's more
+			needed := used + lipgloss.Width(footerSep) + width
+			if i < len(segments)-1 {
+				needed += lipgloss.Width(footerSep) + lipgloss.Width(more)
+			}
+			if needed > m.width {
+				shown = append(shown, more)
+				break
+			}
+			width += lipgloss.Width(footerSep)
+		}
+		shown = append(shown, segment)
+		used += width
+	}
+
+	styled := make([]string, len(shown))
+	for i, segment := range shown {
+		styled[i] = styleSegment(segment)
+	}
+	return strings.Join(styled, footerSepStyle.Render(footerSep))
+}
+
+// styleSegment makes the key of a "key: action" segment stand out.
+func styleSegment(segment string) string {
+	key, action, ok := strings.Cut(segment, ": ")
+	if !ok || strings.Contains(key, " ") {
+		return segment
+	}
+	return footerKeyStyle.Render(key) + ": " + action
+}
+
+// contextual drops the actions of controls that do nothing on the row
+// under the cursor: expanding and collapsing on files, viewing on
+// directories.
+func (m Model) contextual(controls string) string {
+	path, isDir := m.getCurrentItem()
+	if path == "" {
+		return controls
+	}
+	var kept []string
+	for _, segment := range strings.Split(controls, footerSep) {
+		key, _, _ := strings.Cut(segment, ": ")
+		if isDir && key == "enter" || !isDir && (key == "→l" || key == "←h") {
+			continue
+		}
+		kept = append(kept, segment)
+	}
+	return strings.Join(kept, footerSep)
+}
+
+// helpKeys lists every key of the mode the help overlay was opened from.
+func (m Model) helpKeys() []string {
+	var controls string
+	switch {
+	case m.chooseMode == ChooseDir:
+		controls = m.tr.T("footer.choose_dir")
+	case m.chooseMode == ChooseFile:
+		controls = m.tr.T("footer.choose_file")
+	case m.readOnly:
+		controls = m.tr.T("footer.readonly")
+	default:
+		controls = m.tr.T("footer.default")
+	}
+	return strings.Split(controls, footerSep)
+}
+
+func (m Model) handleHelpKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "?":
+		m.helpView = false
+	}
+	return m, nil
+}
+
+// renderHelp draws the help overlay in at most height lines, in as many
+// columns as it takes.
+func (m Model) renderHelp(height int) string {
+	keys := m.helpKeys()
+	keyWidth, width := 0, 0
+	for _, segment := range keys {
+		key, _, _ := strings.Cut(segment, ": ")
+		keyWidth = max(keyWidth, lipgloss.Width(key))
+		width = max(width, lipgloss.Width(segment))
+	}
+	width += 4 // Room between columns
+
+	var b strings.Builder
+	b.WriteString(m.tr.T("help.title") + "\n\n")
+	rows := max(height-2, 1)
+	for row := 0; row < rows && row < len(keys); row++ {
+		var line string
+		for i := row; i < len(keys); i += rows {
+			key, action, _ := strings.Cut(keys[i], ": ")
+			cell := footerKeyStyle.Render(key) + strings.Repeat(" ", keyWidth-lipgloss.Width(key)+2) + action
+			line += cell + strings.Repeat(" ", max(width+2-lipgloss.Width(cell), 0))
+		}
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return b.String()
+}
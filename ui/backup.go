@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/backuprepo"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// backupInfo is what's known about a backup repository's contents.
+type backupInfo struct {
+	kind    backuprepo.Kind
+	stats   backuprepo.Stats
+	err     error
+	loading bool
+}
+
+// BackupStatsMsg carries a repository's stats read in the background.
+type BackupStatsMsg struct {
+	Path  string
+	Stats backuprepo.Stats
+	Error error
+}
+
+// backupKind tells whether dir is a backup repository.
+func backupKind(dir *scanner.DirInfo) backuprepo.Kind {
+	hasFile := func(name string) bool {
+		for _, file := range dir.Files {
+			if file.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	hasDir := func(name string) bool {
+		for _, subdir := range dir.Subdirs {
+			if filepath.Base(subdir.Path) == name {
+				return true
+			}
+		}
+		return false
+	}
+	return backuprepo.Detect(filepath.Base(dir.Path), hasFile, hasDir)
+}
+
+// backupLabel is shown after a repository's name, with how much it backs up
+// once that's known.
+func (m Model) backupLabel(dir *scanner.DirInfo) string {
+	kind := backupKind(dir)
+	if kind == backuprepo.None {
+		return ""
+	}
+	if info, ok := m.backups[dir.Path]; ok && info.err == nil && !info.loading {
+		return m.tr.T("backup.label_stats", kind, formatSize(info.stats.Logical))
+	}
+	return m.tr.T("backup.label", kind)
+}
+
+// loadBackupStats starts reading the stats of the repository under the
+// cursor the first time it's previewed.
+func (m *Model) loadBackupStats() tea.Cmd {
+	path := m.cursorPath
+	if _, ok := m.backups[path]; ok {
+		return nil
+	}
+	dir := m.tree.Find(path)
+	if dir == nil {
+		return nil
+	}
+	kind := backupKind(dir)
+	if kind == backuprepo.None {
+		return nil
+	}
+
+	m.backups[path] = backupInfo{kind: kind, loading: true}
+	return func() tea.Msg {
+		stats, err := backuprepo.Inspect(path, kind)
+		return BackupStatsMsg{Path: path, Stats: stats, Error: err}
+	}
+}
+
+// backupLine describes the repository at path in the preview pane, or is
+// empty if it isn't one.
+func (m Model) backupLine(path string) string {
+	info, ok := m.backups[path]
+	switch {
+	case !ok:
+		return ""
+	case info.loading:
+		return m.tr.T("backup.loading", info.kind)
+	case info.err != nil:
+		return m.tr.T("backup.error", info.kind, info.err)
+	case info.stats.Snapshots > 0:
+		return m.tr.N("backup.stats_snapshots", info.stats.Snapshots, info.kind, formatSize(info.stats.Logical),
+			formatSize(info.stats.Stored), info.stats.Ratio())
+	}
+	return m.tr.T("backup.stats", info.kind, formatSize(info.stats.Logical), formatSize(info.stats.Stored), info.stats.Ratio())
+}
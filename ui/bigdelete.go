@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/paths"
+)
+
+// bigDeletion is a deletion held back until the name of the largest
+// directory in it is typed, since it takes at least one directory over the
+// confirmation threshold with it.
+type bigDeletion struct {
+	paths  []string
+	remove func(string) error
+	path   string // Largest directory, whose name has to be typed
+	size   int64
+	others int // Further directories over the threshold
+	input  string
+}
+
+// deleteLarge removes targets like deletePaths, first asking for the name of
+// the largest directory to be typed if any is over the threshold. measured
+// is the size of all of targets where it's known from elsewhere, as for
+// caches outside the scanned tree, 0 going by the tree alone. What of it
+// the tree doesn't hold counts as one directory, where those targets meet.
+func (m *Model) deleteLarge(targets []string, measured int64, remove func(string) error) tea.Cmd {
+	if m.kioskRefuses(targets...) {
+		return nil
+	}
+	if m.confirmDeleteBytes <= 0 {
+		return deletePaths(targets, remove)
+	}
+	var big *bigDeletion
+	var outside []string
+	for _, path := range targets {
+		dir := m.tree.Find(path)
+		if dir == nil {
+			outside = append(outside, path)
+			continue
+		}
+		size := m.dirSize(dir)
+		measured -= size
+		if size < m.confirmDeleteBytes {
+			continue
+		}
+		switch {
+		case big == nil:
+			big = &bigDeletion{paths: targets, remove: remove, path: path, size: size}
+		case size > big.size:
+			big.path, big.size = path, size
+			big.others++
+		default:
+			big.others++
+		}
+	}
+	if len(outside) > 0 && measured >= m.confirmDeleteBytes {
+		switch {
+		case big == nil:
+			big = &bigDeletion{paths: targets, remove: remove, path: commonDir(outside), size: measured}
+		case measured > big.size:
+			big.path, big.size = commonDir(outside), measured
+			big.others++
+		default:
+			big.others++
+		}
+	}
+	if big == nil {
+		return deletePaths(targets, remove)
+	}
+	m.bigDelete = big
+	return nil
+}
+
+// commonDir is the deepest directory holding all of items, or the only one.
+func commonDir(items []string) string {
+	dir := items[0]
+	if len(items) > 1 {
+		dir = filepath.Dir(dir)
+	}
+	for _, item := range items[1:] {
+		for !paths.Within(item, dir) && filepath.Dir(dir) != dir {
+			dir = filepath.Dir(dir)
+		}
+	}
+	return dir
+}
+
+func (m Model) handleBigDeleteKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if m.bigDelete.input != filepath.Base(m.bigDelete.path) {
+			m.statusMessage = m.tr.T("bigdelete.mismatch")
+			return m, nil
+		}
+		big := m.bigDelete
+		m.bigDelete = nil
+		return m, deletePaths(big.paths, big.remove)
+	case "esc":
+		m.bigDelete = nil
+		m.quitAfterCleanup = false
+		m.statusMessage = m.tr.T("bigdelete.cancelled")
+	case "backspace":
+		if len(m.bigDelete.input) > 0 {
+			runes := []rune(m.bigDelete.input)
+			m.bigDelete.input = string(runes[:len(runes)-1])
+		}
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			m.bigDelete.input += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+// bigDeleteFooter asks for the name of the directory, saying how many more
+// large directories the deletion takes.
+func (m Model) bigDeleteFooter() string {
+	big := m.bigDelete
+	controls := m.tr.T("footer.bigdelete", filepath.Base(big.path), formatSize(big.size), big.input)
+	if big.others > 0 {
+		controls = m.tr.N("bigdelete.others", big.others) + " • " + controls
+	}
+	return controls
+}
@@ -0,0 +1,50 @@
+package ui
+
+import "testing"
+
+// TestDeleteLarge holds back deletions taking a directory over the
+// threshold, whether the tree holds it or it was measured elsewhere.
+func TestDeleteLarge(t *testing.T) {
+	remove := func(string) error { return nil }
+	tests := []struct {
+		name     string
+		paths    []string
+		measured int64 // Beyond what the tree holds
+		want     string
+		others   int
+	}{
+		{"directory in the tree", []string{"/proj/build"}, 0, "/proj/build", 0},
+		{"small directory in the tree", []string{"/proj/docs"}, 0, "", 0},
+		{"cache outside the tree", []string{"/home/u/.cache/b/Cache", "/home/u/.cache/b/Code Cache"}, 30 << 20, "/home/u/.cache/b", 0},
+		{"single cache directory", []string{"/var/cache/apt"}, 30 << 20, "/var/cache/apt", 0},
+		{"small cache outside the tree", []string{"/var/cache/apt"}, 1 << 20, "", 0},
+		{"cache larger than the tree's", []string{"/proj/build", "/var/cache/apt"}, 100 << 20, "/var/cache/apt", 1},
+		{"cache smaller than the tree's", []string{"/proj/build", "/var/cache/apt"}, 20 << 20, "/proj/build", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := scanned(t)
+			m.confirmDeleteBytes = 10 << 20
+			measured := test.measured
+			if measured > 0 {
+				for _, path := range test.paths {
+					measured += m.itemSize(path)
+				}
+			}
+
+			cmd := m.deleteLarge(test.paths, measured, remove)
+			if test.want == "" {
+				if m.bigDelete != nil || cmd == nil {
+					t.Fatalf("held back for %s", m.bigDelete.path)
+				}
+				return
+			}
+			if m.bigDelete == nil || cmd != nil {
+				t.Fatal("deleted without asking")
+			}
+			if m.bigDelete.path != test.want || m.bigDelete.others != test.others {
+				t.Errorf("asked for %s with %d others, want %s with %d", m.bigDelete.path, m.bigDelete.others, test.want, test.others)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package ui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// ChooseMode turns dua into a picker: enter on an item of the wanted kind
+// quits with it chosen, and quitting any other way cancels.
+type ChooseMode int
+
+const (
+	ChooseNone ChooseMode = iota
+	ChooseDir
+	ChooseFile
+)
+
+// SetChooseMode starts picking a directory or file.
+func (m *Model) SetChooseMode(mode ChooseMode) {
+	m.chooseMode = mode
+}
+
+// Chosen returns the picked path, and false if the picker was cancelled.
+func (m Model) Chosen() (string, bool) {
+	return m.chosenPath, m.chosenPath != ""
+}
+
+// handleChooseKey handles the keys that behave differently while picking. It
+// reports whether the key was consumed.
+func (m *Model) handleChooseKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "enter":
+		path, isDir := m.getCurrentItem()
+		if path == "" {
+			return nil, true
+		}
+		if isDir == (m.chooseMode == ChooseDir) {
+			m.chosenPath = path
+			return tea.Quit, true
+		}
+		if m.chooseMode == ChooseDir {
+			m.statusMessage = m.tr.T("choose.not_dir")
+			return nil, true
+		}
+		// Picking a file, enter on a directory opens it as usual
+		return nil, false
+	case "esc":
+		// An active filter is cleared first, as usual
+		if m.searchQuery != "" {
+			return nil, false
+		}
+		return tea.Quit, true
+	case "q":
+		return tea.Quit, true
+	}
+	return nil, false
+}
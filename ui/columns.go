@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// column is one of the columns right of the names.
+type column int
+
+const (
+	columnSize column = iota
+	columnPercent
+	columnCount
+	columnDate
+	columnCost
+	columnOwner
+	columnPerms
+	columnLinks
+	columnsTotal
+)
+
+// columnNames are the columns as named in the config.
+var columnNames = [columnsTotal]string{"size", "percent", "count", "mtime", "cost", "owner", "perms", "links"}
+
+var columnWidths = [columnsTotal]int{
+	sizeColumnWidth,
+	6, // "100.0%"
+	7, // Entries in a directory
+	dateColumnWidth,
+	costColumnWidth,
+	14, // Owners are cut to fit
+	10, // "drwxr-xr-x"
+	5,  // Hard links of a file
+}
+
+// defaultColumns are the columns dua had before they could be picked. Dates
+// and costs still only show once toggled on.
+var defaultColumns = []column{columnSize, columnCost, columnDate}
+
+// parseColumns reads the configured columns in order, skipping unknown
+// names. The size column is always there, as it shows loading and partial
+// sizes, and comes first if it isn't placed.
+func parseColumns(names []string) []column {
+	if len(names) == 0 {
+		return slices.Clone(defaultColumns)
+	}
+	var columns []column
+	for _, name := range names {
+		i := slices.Index(columnNames[:], strings.ToLower(name))
+		if i >= 0 && !slices.Contains(columns, column(i)) {
+			columns = append(columns, column(i))
+		}
+	}
+	if !slices.Contains(columns, columnSize) {
+		columns = append([]column{columnSize}, columns...)
+	}
+	return columns
+}
+
+// rowCells is what a row's columns are drawn from. Views listing things
+// other than the tree only fill in the size.
+type rowCells struct {
+	size    string // Size as shown, which may be a spinner or estimate
+	bytes   int64
+	parent  int64 // Size of the directory holding the item, 0 if none
+	entries int   // Files and directories in a directory, -1 otherwise
+	modTime time.Time
+	path    string // For the owner, empty if there's none
+	mode    fs.FileMode
+	links   int // Hard links to a file with several, 0 otherwise
+}
+
+// sizeCells are the cells of a row with just a size.
+func sizeCells(bytes int64) rowCells {
+	return rowCells{size: formatSize(bytes), bytes: bytes, entries: -1}
+}
+
+// columnShown reports whether c is on. Dates and costs are toggled with m
+// and c.
+func (m Model) columnShown(c column) bool {
+	switch c {
+	case columnDate:
+		return m.showDates
+	case columnCost:
+		return m.costShown()
+	}
+	return true
+}
+
+// visibleColumns lays out the columns for the terminal's width: those shown,
+// dropping the last ones until the names get at least minNameColumnWidth.
+// It returns them along with the width left for the names.
+func (m Model) visibleColumns() ([]column, int) {
+	var columns []column
+	nameWidth := m.width
+	for _, c := range m.columns {
+		if m.columnShown(c) {
+			columns = append(columns, c)
+			nameWidth -= columnWidths[c] + 1
+		}
+	}
+	for nameWidth < minNameColumnWidth && len(columns) > 1 {
+		last := columns[len(columns)-1]
+		if last == columnSize {
+			// Size stays, drop the one before it instead
+			last = columns[len(columns)-2]
+			columns = slices.Delete(columns, len(columns)-2, len(columns)-1)
+		} else {
+			columns = columns[:len(columns)-1]
+		}
+		nameWidth += columnWidths[last] + 1
+	}
+	return columns, max(nameWidth, minNameColumnWidth)
+}
+
+// cell draws column c of a row.
+func (m Model) cell(c column, cells rowCells) string {
+	var text string
+	switch c {
+	case columnSize:
+		text = cells.size
+	case columnPercent:
+		if cells.parent > 0 {
+			text = fmt.Sprintf("%.1f%%", float64(cells.bytes)/float64(cells.parent)*100)
+		}
+	case columnCount:
+		if cells.entries >= 0 {
+			text = fmt.Sprint(cells.entries)
+		}
+	case columnDate:
+		text = m.formatDate(cells.modTime)
+	case columnCost:
+		text = m.cost.Format(cells.bytes)
+	case columnOwner:
+		if cells.path != "" {
+			text = ansi.Truncate(m.fileOwner(cells.path), columnWidths[c], "…")
+		}
+	case columnPerms:
+		if cells.mode != 0 {
+			text = cells.mode.String()
+		}
+	case columnLinks:
+		if cells.links > 0 {
+			text = fmt.Sprint(cells.links)
+		}
+	}
+	return sizeStyle.Width(columnWidths[c]).Render(text)
+}
+
+// fileOwner returns the user owning path, looked up once as rows come into
+// view since the scan doesn't keep it.
+func (m Model) fileOwner(path string) string {
+	if owner, ok := m.fileOwners[path]; ok {
+		return owner
+	}
+	var owner string
+	if info, err := m.fsys.Lstat(path); err == nil {
+		owner = scanner.Owner(info)
+	}
+	m.fileOwners[path] = owner
+	return owner
+}
+
+// addColumn puts c at the end of the columns if it isn't there, for when
+// its toggle turns it on.
+func (m *Model) addColumn(c column) {
+	if !slices.Contains(m.columns, c) {
+		m.columns = append(m.columns, c)
+	}
+}
+
+// pickerColumns lists every column for the picker: those in use in their
+// order, then the rest.
+func (m Model) pickerColumns() []column {
+	columns := slices.Clone(m.columns)
+	for c := range columnsTotal {
+		if !slices.Contains(columns, c) {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// openColumns shows the column picker. Changes last for the session, the
+// config's columns setting keeps them.
+func (m *Model) openColumns() {
+	m.columnsView = true
+	m.columnsCursor = 0
+}
+
+func (m Model) handleColumnsKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	columns := m.pickerColumns()
+	c := columns[m.columnsCursor]
+	i := slices.Index(m.columns, c)
+	switch msg.String() {
+	case "up", "k":
+		m.columnsCursor = max(m.columnsCursor-1, 0)
+	case "down", "j":
+		m.columnsCursor = min(m.columnsCursor+1, len(columns)-1)
+	case "K":
+		// Columns in use move among themselves
+		if i > 0 {
+			m.columns[i-1], m.columns[i] = m.columns[i], m.columns[i-1]
+			m.columnsCursor--
+		}
+	case "J":
+		if i >= 0 && i < len(m.columns)-1 {
+			m.columns[i], m.columns[i+1] = m.columns[i+1], m.columns[i]
+			m.columnsCursor++
+		}
+	case " ", "enter":
+		switch {
+		case c == columnSize:
+			m.statusMessage = m.tr.T("columns.size_fixed")
+		case i >= 0:
+			m.columns = slices.Delete(m.columns, i, i+1)
+			// The cursor stays on the column, now among the rest
+			m.columnsCursor = slices.Index(m.pickerColumns(), c)
+		case c == columnCost && !m.cost.Enabled():
+			m.statusMessage = m.tr.T("cost.unconfigured")
+		default:
+			m.columns = append(m.columns, c)
+			m.columnsCursor = len(m.columns) - 1
+			// Turning a column on means wanting to see it
+			if c == columnDate {
+				m.showDates = true
+			} else if c == columnCost {
+				m.showCost = true
+			}
+		}
+	case "esc", "q", "L":
+		m.columnsView = false
+	}
+	return m, nil
+}
+
+// renderColumns draws the column picker.
+func (m Model) renderColumns() string {
+	var b strings.Builder
+	b.WriteString(m.tr.T("columns.title") + "\n\n")
+	for i, c := range m.pickerColumns() {
+		mark := "[ ]"
+		if slices.Contains(m.columns, c) {
+			mark = "[x]"
+		}
+		line := fmt.Sprintf("%s %-8s %s", mark, columnNames[c], m.tr.T("columns."+columnNames[c]))
+		if slices.Contains(m.columns, c) && !m.columnShown(c) {
+			line += " " + m.tr.T("columns.hidden")
+		}
+		style := fileStyle
+		if i == m.columnsCursor {
+			style = selectedStyle
+		}
+		b.WriteString(style.Render(line) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,345 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/i18n"
+	"github.com/corpeningc/dua/internal/treefile"
+)
+
+var (
+	compareGrownStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87"))
+	compareShrunkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	compareSameStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+)
+
+// compareSide is an entry as it is on one side of a comparison.
+type compareSide struct {
+	present bool
+	dir     *treefile.Dir // Set for directories
+	size    int64
+	modTime time.Time
+}
+
+// compareRow is a name found in either directory, with both sides of it.
+type compareRow struct {
+	name        string
+	isDir       bool
+	left, right compareSide
+}
+
+// delta is how much larger the right side is.
+func (r compareRow) delta() int64 {
+	return r.right.size - r.left.size
+}
+
+// Compare shows two trees side by side, a directory at a time, with their
+// children aligned by name and the difference in size between them.
+type Compare struct {
+	left, right treefile.Document
+	live        bool     // Both sides were scanned here, so they can be synced
+	path        []string // Names from the roots down to the directory shown
+	rows        []compareRow
+	byDelta     bool // Sort by the size of the difference instead of name
+	cursor      int
+	offset      int
+	width       int
+	height      int
+	sync        syncPreview
+	status      string
+	rsync       string // Command to print once the program exits
+	tr          *i18n.Translator
+}
+
+// NewCompare creates the comparison of two scanned or loaded trees. live
+// says whether both are directories on this machine rather than exports.
+func NewCompare(left, right treefile.Document, live bool, cfg config.Config) Compare {
+	c := Compare{
+		left:  left,
+		right: right,
+		live:  live,
+		tr:    i18n.New(cfg.ResolvedLocale()),
+	}
+	c.loadRows()
+	return c
+}
+
+func (c Compare) Init() tea.Cmd {
+	return nil
+}
+
+// current returns the directory shown on each side, nil where it doesn't
+// exist.
+func (c Compare) current() (*treefile.Dir, *treefile.Dir) {
+	return subdir(c.left.Tree, c.path), subdir(c.right.Tree, c.path)
+}
+
+// subdir follows names down from dir.
+func subdir(dir *treefile.Dir, names []string) *treefile.Dir {
+	for _, name := range names {
+		if dir == nil {
+			return nil
+		}
+		var next *treefile.Dir
+		for _, sub := range dir.Dirs {
+			if sub.Name == name {
+				next = sub
+				break
+			}
+		}
+		dir = next
+	}
+	return dir
+}
+
+// compareRows pairs up the children of two directories by name. Either may
+// be nil.
+func compareRows(left, right *treefile.Dir) []compareRow {
+	var rows []compareRow
+	index := make(map[string]int)
+	add := func(name string, isDir bool, side compareSide, onLeft bool) {
+		i, ok := index[name]
+		if !ok {
+			i = len(rows)
+			index[name] = i
+			rows = append(rows, compareRow{name: name})
+		}
+		rows[i].isDir = rows[i].isDir || isDir
+		if onLeft {
+			rows[i].left = side
+		} else {
+			rows[i].right = side
+		}
+	}
+	for _, side := range []struct {
+		dir    *treefile.Dir
+		onLeft bool
+	}{{left, true}, {right, false}} {
+		if side.dir == nil {
+			continue
+		}
+		for _, dir := range side.dir.Dirs {
+			add(dir.Name, true, compareSide{present: true, dir: dir, size: dir.Size, modTime: dir.ModTime}, side.onLeft)
+		}
+		for _, file := range side.dir.Files {
+			add(file.Name, false, compareSide{present: true, size: file.Size, modTime: file.ModTime}, side.onLeft)
+		}
+	}
+	return rows
+}
+
+// loadRows lists the current directory and sorts it.
+func (c *Compare) loadRows() {
+	c.rows = compareRows(c.current())
+	c.sortRows()
+	c.cursor = 0
+	c.offset = 0
+}
+
+// sortRows puts directories first, then orders by name or by the largest
+// difference.
+func (c *Compare) sortRows() {
+	sort.SliceStable(c.rows, func(i, j int) bool {
+		a, b := c.rows[i], c.rows[j]
+		if a.isDir != b.isDir {
+			return a.isDir
+		}
+		if c.byDelta {
+			if da, db := absSize(a.delta()), absSize(b.delta()); da != db {
+				return da > db
+			}
+		}
+		return strings.ToLower(a.name) < strings.ToLower(b.name)
+	})
+}
+
+func absSize(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (c Compare) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+
+	case SyncDoneMsg:
+		return c.syncDone(msg), nil
+
+	case tea.KeyMsg:
+		if c.sync.open {
+			return c.handleSyncKey(msg)
+		}
+		c.status = ""
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return c, tea.Quit
+		case "up", "k":
+			if c.cursor > 0 {
+				c.cursor--
+			}
+		case "down", "j":
+			if c.cursor < len(c.rows)-1 {
+				c.cursor++
+			}
+		case "g":
+			c.cursor = 0
+		case "G":
+			c.cursor = max(len(c.rows)-1, 0)
+		case "right", "l", "enter":
+			if c.cursor < len(c.rows) && c.rows[c.cursor].isDir {
+				c.path = append(c.path, c.rows[c.cursor].name)
+				c.loadRows()
+			}
+		case "left", "h", "backspace", "esc":
+			if len(c.path) > 0 {
+				came := c.path[len(c.path)-1]
+				c.path = c.path[:len(c.path)-1]
+				c.loadRows()
+				for i, row := range c.rows {
+					if row.name == came {
+						c.cursor = i
+					}
+				}
+			}
+		case "s":
+			c.byDelta = !c.byDelta
+			c.sortRows()
+			c.cursor = 0
+		case "S":
+			c.openSync()
+		}
+	}
+
+	c.scrollToCursor()
+	return c, nil
+}
+
+func (c Compare) visibleRows() int {
+	return max(c.height-7, 1) // Header, totals, column titles and footer
+}
+
+func (c *Compare) scrollToCursor() {
+	if c.cursor < c.offset {
+		c.offset = c.cursor
+	}
+	if c.cursor >= c.offset+c.visibleRows() {
+		c.offset = c.cursor - c.visibleRows() + 1
+	}
+}
+
+// side renders one side's size, or a dash where the entry is missing.
+func (c Compare) side(side compareSide) string {
+	if !side.present {
+		return "—"
+	}
+	return formatSize(side.size)
+}
+
+// renderDelta shows the difference, colored by whether the right side grew
+// or shrank.
+func (c Compare) renderDelta(row compareRow) string {
+	delta := row.delta()
+	text := fmt.Sprintf("%12s", signedSize(delta))
+	switch {
+	case !row.left.present:
+		return compareGrownStyle.Render(fmt.Sprintf("%12s", c.tr.T("compare.right_only")))
+	case !row.right.present:
+		return compareShrunkStyle.Render(fmt.Sprintf("%12s", c.tr.T("compare.left_only")))
+	case delta > 0:
+		return compareGrownStyle.Render(text)
+	case delta < 0:
+		return compareShrunkStyle.Render(text)
+	}
+	return compareSameStyle.Render(text)
+}
+
+// padLeft and padRight pad by display width, which fmt gets wrong for the
+// dash and emoji.
+func padLeft(s string, width int) string {
+	return strings.Repeat(" ", max(width-lipgloss.Width(s), 0)) + s
+}
+
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
+
+// signedSize formats a size difference with its sign.
+func signedSize(n int64) string {
+	switch {
+	case n > 0:
+		return "+" + formatSize(n)
+	case n < 0:
+		return "-" + formatSize(-n)
+	}
+	return "="
+}
+
+func (c Compare) View() string {
+	if c.sync.open {
+		return c.renderSync()
+	}
+
+	var b strings.Builder
+
+	shown := filepath.Join(c.path...)
+	if shown == "" {
+		shown = "."
+	}
+	header := c.tr.T("compare.title", c.left.Root, c.right.Root, shown)
+	if c.width > 0 {
+		header = ansi.Truncate(header, c.width, "…")
+	}
+	b.WriteString(header + "\n")
+	b.WriteString(strings.Repeat("-", lipgloss.Width(header)) + "\n")
+
+	left, right := c.current()
+	total := compareRow{left: compareSide{present: left != nil}, right: compareSide{present: right != nil}}
+	if left != nil {
+		total.left.size = left.Size
+	}
+	if right != nil {
+		total.right.size = right.Size
+	}
+
+	nameWidth := max(c.width-38, 10)
+	line := func(name, leftSize, rightSize, delta string) string {
+		name = ansi.Truncate(name, nameWidth, "…")
+		return padRight(name, nameWidth) + " " + padLeft(leftSize, 10) + " │ " + padRight(rightSize, 10) + " " + delta
+	}
+	b.WriteString(line(c.tr.T("compare.total"), c.side(total.left), c.side(total.right), c.renderDelta(total)) + "\n\n")
+	b.WriteString(line(c.tr.T("compare.name"), c.tr.T("compare.left"), c.tr.T("compare.right"), fmt.Sprintf("%12s", c.tr.T("compare.delta"))) + "\n")
+
+	if len(c.rows) == 0 {
+		b.WriteString(c.tr.T("compare.empty") + "\n")
+	}
+	for i := c.offset; i < len(c.rows) && i < c.offset+c.visibleRows(); i++ {
+		row := c.rows[i]
+		name := "📄 " + row.name
+		if row.isDir {
+			name = "📁 " + row.name + "/"
+		}
+		text := line(name, c.side(row.left), c.side(row.right), c.renderDelta(row))
+		if i == c.cursor {
+			text = selectedStyle.Render(line(name, c.side(row.left), c.side(row.right), fmt.Sprintf("%12s", signedSize(row.delta()))))
+		}
+		b.WriteString(text + "\n")
+	}
+
+	if c.status != "" {
+		b.WriteString("\n" + c.status)
+	}
+	b.WriteString("\n" + c.tr.T("compare.footer"))
+	return b.String()
+}
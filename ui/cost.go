@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"path/filepath"
+
+	"github.com/corpeningc/dua/internal/cost"
+)
+
+// SetCost estimates monthly storage costs at rate, shown in a column from the
+// start if show is set.
+func (m *Model) SetCost(rate cost.Rate, show bool) {
+	m.cost = rate
+	m.showCost = show && rate.Enabled()
+}
+
+// costShown reports whether rows carry a monthly cost column.
+func (m Model) costShown() bool {
+	return m.showCost && m.cost.Enabled()
+}
+
+// toggleCost shows or hides the cost column, if a rate is configured.
+func (m *Model) toggleCost() {
+	if !m.cost.Enabled() {
+		m.statusMessage = m.tr.T("cost.unconfigured")
+		return
+	}
+	m.showCost = !m.showCost
+	if m.showCost {
+		m.addColumn(columnCost)
+	}
+}
+
+// selectionTotal is the size of the selection. Items inside a selected
+// directory are already part of its size and aren't counted twice.
+func (m Model) selectionTotal() int64 {
+	var total int64
+	for path := range m.selected {
+		if !m.hasSelectedAncestor(path) {
+			total += m.itemSize(path)
+		}
+	}
+	return total
+}
+
+func (m Model) hasSelectedAncestor(path string) bool {
+	for dir := filepath.Dir(path); dir != path; path, dir = dir, filepath.Dir(dir) {
+		if m.selected[dir] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// deltaFlashDuration is how long a size change stays next to a row after the
+// directory last grew. Growth within that window adds up.
+const deltaFlashDuration = 1500 * time.Millisecond
+
+var deltaStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#04B575")).
+	Bold(true)
+
+// sizeDelta is how much a directory has grown recently during a scan.
+type sizeDelta struct {
+	bytes   int64
+	updated time.Time
+}
+
+// recordSizeDelta notes that the directory at path changed size by bytes.
+func (m *Model) recordSizeDelta(path string, bytes int64, now time.Time) {
+	if bytes == 0 || !m.isScanning {
+		return
+	}
+
+	if delta, ok := m.sizeDeltas[path]; ok && now.Sub(delta.updated) < deltaFlashDuration {
+		delta.bytes += bytes
+		delta.updated = now
+		return
+	}
+	m.sizeDeltas[path] = &sizeDelta{bytes: bytes, updated: now}
+}
+
+// pruneSizeDeltas forgets changes that are no longer shown, keeping the map to
+// the directories that are actively growing.
+func (m *Model) pruneSizeDeltas(now time.Time) {
+	for path, delta := range m.sizeDeltas {
+		if now.Sub(delta.updated) >= deltaFlashDuration {
+			delete(m.sizeDeltas, path)
+		}
+	}
+}
+
+// renderSizeDelta returns the "+123 MB" label for a directory that grew
+// recently, or "" if it hasn't.
+func (m Model) renderSizeDelta(path string) string {
+	if !m.isScanning {
+		return ""
+	}
+	delta, ok := m.sizeDeltas[path]
+	if !ok || time.Since(delta.updated) >= deltaFlashDuration {
+		return ""
+	}
+
+	if delta.bytes < 0 {
+		return deltaStyle.Render("-" + formatSize(-delta.bytes))
+	}
+	return deltaStyle.Render("+" + formatSize(delta.bytes))
+}
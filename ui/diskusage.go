@@ -0,0 +1,48 @@
+package ui
+
+import "github.com/corpeningc/dua/internal/scanner"
+
+// dirSize is the total of dir as shown: the disk space its files take with
+// u on, the sum of their sizes otherwise.
+func (m Model) dirSize(dir *scanner.DirInfo) int64 {
+	if m.diskUsage {
+		return dir.Alloc
+	}
+	return dir.Size
+}
+
+// fileSize is the size of file as shown, see dirSize.
+func (m Model) fileSize(file scanner.FileInfo) int64 {
+	if m.diskUsage {
+		return file.Alloc
+	}
+	return file.Size
+}
+
+// countedSize is what file adds to its directory's total as shown, which is
+// nothing for a hard link counted elsewhere.
+func (m Model) countedSize(file scanner.FileInfo) int64 {
+	size, alloc := file.Counted()
+	if m.diskUsage {
+		return alloc
+	}
+	return size
+}
+
+// toggleDiskUsage switches between apparent sizes and disk usage. Trees
+// loaded from exports written before disk usage was recorded only have
+// sizes.
+func (m *Model) toggleDiskUsage() {
+	if root := m.tree.Root; !m.diskUsage && root != nil && root.Alloc == 0 && root.Size > 0 {
+		m.statusMessage = m.tr.T("diskusage.unrecorded")
+		return
+	}
+	m.diskUsage = !m.diskUsage
+	// Sorting by size may order things differently now
+	m.sortCache = make(map[string]*sortedContents)
+	if m.diskUsage {
+		m.statusMessage = m.tr.T("diskusage.on")
+	} else {
+		m.statusMessage = m.tr.T("diskusage.off")
+	}
+}
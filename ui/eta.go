@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// etaWarmup is how long a scan runs before its throughput is trusted for an
+// estimate.
+const etaWarmup = 2 * time.Second
+
+// scanHistory is the record of the last complete scan of the root, read at
+// start and replaced when a scan completes.
+type scanHistory struct {
+	file string
+	last *scanner.LastScan // nil if the root wasn't scanned before
+}
+
+// SetScanHistory estimates how long the scan will take from what the last
+// complete scan of the root, recorded in file, found, and records this scan
+// there once it completes.
+func (m *Model) SetScanHistory(file string) {
+	m.history = &scanHistory{file: file}
+	if last, err := scanner.LoadLastScan(file); err == nil {
+		m.history.last = &last
+	}
+}
+
+// scanTotal is how many files and directories the scan is expected to find:
+// the pre-count if there is one, or else what the last scan found.
+func (m Model) scanTotal() (int64, bool) {
+	switch {
+	case m.precount != nil && m.precount.done:
+		return m.precount.count.Files + m.precount.count.Dirs, true
+	case m.history != nil && m.history.last != nil:
+		return m.history.last.Files + m.history.last.Dirs, true
+	}
+	return 0, false
+}
+
+// scanETA estimates the time left in the scan from its throughput so far.
+// It's false until the scan has run long enough to tell, and once it's past
+// what was expected.
+func (m Model) scanETA() (time.Duration, bool) {
+	total, ok := m.scanTotal()
+	elapsed := time.Since(m.scanStartTime)
+	scanned := int64(m.progressFiles + m.progressDirs)
+	if !ok || elapsed < etaWarmup || scanned == 0 || scanned >= total {
+		return 0, false
+	}
+	perItem := elapsed / time.Duration(scanned)
+	return perItem * time.Duration(total-scanned), true
+}
+
+// recordScan saves what the completed scan found, for estimating the next
+// one. Resumed and quick scans didn't see the whole tree in one go, so
+// they're not recorded.
+func (m Model) recordScan() tea.Cmd {
+	if m.history == nil || m.resumed || m.quickStopped() {
+		return nil
+	}
+	file := m.history.file
+	last := scanner.LastScan{
+		Files:    int64(m.progressFiles),
+		Dirs:     int64(m.progressDirs),
+		Duration: m.stats.scanDuration,
+		Finished: time.Now(),
+	}
+	m.history.last = &last
+	return func() tea.Msg {
+		// The estimate is a nicety, not worth interrupting anything over
+		_ = scanner.SaveLastScan(file, last)
+		return nil
+	}
+}
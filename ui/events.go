@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/corpeningc/dua/internal/paths"
+	"github.com/corpeningc/dua/internal/tree"
+)
+
+// apply makes a change to the tree, dropping the cached order of every
+// directory it changed and moving what the model keeps by path along with
+// moved items. Growth while scanning is recorded for display.
+func (m *Model) apply(event tree.Event) {
+	changes := m.tree.Apply(event)
+	if m.quickStopped() {
+		m.quick.estimates = nil
+	}
+
+	switch e := event.(type) {
+	case tree.Scanned:
+		if e.Dir.Path == m.currentPath {
+			m.expanded[m.currentPath] = true
+			m.sortCache = make(map[string]*sortedContents)
+			return
+		}
+		// Above the scanned directory only sizes changed, so the order can
+		// wait, see sortDebounce
+		delete(m.sortCache, e.Dir.Path)
+		now := time.Now()
+		for _, change := range changes {
+			m.recordSizeDelta(change.Path, change.Grew, now)
+			if change.Path != e.Dir.Path {
+				m.markSortStale(change.Path)
+			}
+		}
+		return
+	case tree.Removed:
+		dropUnder(m.queue, e.Path)
+		dropUnder(m.markedForDeletion, e.Path)
+	case tree.Moved:
+		movePaths(m.expanded, e.From, e.To)
+		movePaths(m.selected, e.From, e.To)
+		movePaths(m.markedForDeletion, e.From, e.To)
+		movePaths(m.queue, e.From, e.To)
+		movePaths(m.sizeCache, e.From, e.To)
+		for path := range m.sortCache {
+			if _, ok := tree.MovedPath(path, e.From, e.To); ok {
+				delete(m.sortCache, path)
+			}
+		}
+		if moved, ok := tree.MovedPath(m.cursorPath, e.From, e.To); ok {
+			m.cursorPath = moved
+		}
+	}
+
+	for _, change := range changes {
+		delete(m.sortCache, change.Path)
+	}
+}
+
+// dropUnder deletes dir and every path below it from byPath.
+func dropUnder[V any](byPath map[string]V, dir string) {
+	for path := range byPath {
+		if paths.Within(path, dir) {
+			delete(byPath, path)
+		}
+	}
+}
+
+// movePaths rekeys the entries of byPath at or below from to where they end
+// up under to. Whatever was kept for to and below is dropped first, as the
+// move replaced it.
+func movePaths[V any](byPath map[string]V, from, to string) {
+	moved := make(map[string]V)
+	for path, value := range byPath {
+		if dest, ok := tree.MovedPath(path, from, to); ok {
+			moved[dest] = value
+			delete(byPath, path)
+		}
+	}
+	dropUnder(byPath, to)
+	for path, value := range moved {
+		byPath[path] = value
+	}
+}
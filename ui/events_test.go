@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/corpeningc/dua/internal/tree"
+)
+
+// keys returns the paths of byPath in order, for comparing.
+func keys[V any](byPath map[string]V) []string {
+	return slices.Sorted(maps.Keys(byPath))
+}
+
+// TestApplyMoved renames a directory and checks everything the model keeps
+// by path follows it, while what the rename replaced is forgotten.
+func TestApplyMoved(t *testing.T) {
+	m := scanned(t)
+	m.selected = map[string]bool{"/proj/docs": true, "/proj/src": true}
+	m.markedForDeletion = map[string]bool{"/proj/docs/guide.pdf": true, "/proj/manual/old": true}
+	m.queue = map[string]bool{"/proj/docs/images": true, "/proj/build": true}
+	m.sizeCache = map[string]cachedSize{"/proj/docs/images": {size: 1}, "/proj/manual": {size: 2}}
+
+	m.apply(tree.Moved{From: "/proj/docs", To: "/proj/manual"})
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"selected", keys(m.selected), []string{"/proj/manual", "/proj/src"}},
+		{"marked for deletion", keys(m.markedForDeletion), []string{"/proj/manual/guide.pdf"}},
+		{"queue", keys(m.queue), []string{"/proj/build", "/proj/manual/images"}},
+		{"size cache", keys(m.sizeCache), []string{"/proj/manual/images"}},
+	}
+	for _, test := range tests {
+		if !slices.Equal(test.got, test.want) {
+			t.Errorf("%s holds %q, want %q", test.name, test.got, test.want)
+		}
+	}
+	if m.sizeCache["/proj/manual/images"].size != 1 {
+		t.Error("the cached size didn't move along")
+	}
+}
+
+// TestApplyMovedSimilarName checks a sibling sharing the start of the
+// moved name stays where it is.
+func TestApplyMovedSimilarName(t *testing.T) {
+	m := scanned(t)
+	m.queue = map[string]bool{"/proj/docs": true, "/proj/docs2": true}
+	m.apply(tree.Moved{From: "/proj/docs", To: "/proj/manual"})
+	if got, want := keys(m.queue), []string{"/proj/docs2", "/proj/manual"}; !slices.Equal(got, want) {
+		t.Errorf("queue holds %q, want %q", got, want)
+	}
+}
+
+// TestApplyRemoved deletes a directory, which takes what's queued and
+// marked below it along.
+func TestApplyRemoved(t *testing.T) {
+	m := scanned(t)
+	m.markedForDeletion = map[string]bool{"/proj/docs/guide.pdf": true, "/proj/src/main.go": true}
+	m.queue = map[string]bool{"/proj/docs": true, "/proj/docs/images": true, "/proj/docs2": true, "/proj/build": true}
+
+	m.apply(tree.Removed{Path: "/proj/docs"})
+
+	if got, want := keys(m.markedForDeletion), []string{"/proj/src/main.go"}; !slices.Equal(got, want) {
+		t.Errorf("marked for deletion holds %q, want %q", got, want)
+	}
+	if got, want := keys(m.queue), []string{"/proj/build", "/proj/docs2"}; !slices.Equal(got, want) {
+		t.Errorf("queue holds %q, want %q", got, want)
+	}
+}
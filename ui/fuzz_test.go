@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/corpeningc/dua/internal/vfs"
+)
+
+// fuzzPaths seed the path fuzzers with what tends to break path handling:
+// invalid UTF-8, paths past PATH_MAX and mixed separators.
+var fuzzPaths = []string{
+	"",
+	".",
+	"/",
+	"/home/user/file.txt",
+	"relative/dir/",
+	"\xff\xfe\xfd",
+	"/tmp/\xc3(/bad",
+	"dir/caf\xe9",
+	strings.Repeat("a/", 2100),
+	"/" + strings.Repeat("x", 4096),
+	strings.Repeat("/\xff", 2048),
+	`C:\Users\me/Documents\file.txt`,
+	`\\server\share/dir\file`,
+	`\\?\C:\long/path`,
+	`/mixed\sep/path\`,
+	`//double//slashes//`,
+	`\`,
+	"C:",
+}
+
+func FuzzFuzzyMatch(f *testing.F) {
+	for _, seed := range []struct{ query, target string }{
+		{"", ""},
+		{"abc", "aXbXc"},
+		{"ÄÖ", "äö"},
+		{"\xff", "a\xffb"},
+		{"\xc3", "\xc3\xa4"},
+		{"é", "e\u0301"},
+		{strings.Repeat("a", 4096), strings.Repeat("ab", 4096)},
+		{`a\b`, `a/x\b`},
+	} {
+		f.Add(seed.query, seed.target)
+	}
+	f.Fuzz(func(t *testing.T, query, target string) {
+		matched := fuzzyMatch(query, target)
+		if !fuzzyMatch(query, query) {
+			t.Errorf("%q doesn't match itself", query)
+		}
+		if !fuzzyMatch("", target) {
+			t.Errorf("empty query doesn't match %q", target)
+		}
+		// Anything around a match keeps it matching
+		if matched && !fuzzyMatch(query, "x"+target+"x") {
+			t.Errorf("%q matches %q but not with more around it", query, target)
+		}
+	})
+}
+
+func FuzzGetBaseName(f *testing.F) {
+	for _, path := range fuzzPaths {
+		f.Add(path)
+	}
+	f.Fuzz(func(t *testing.T, path string) {
+		base := getBaseName(path)
+		if base == path {
+			// The root, only separators, or already a name
+			return
+		}
+		if base == "" {
+			t.Fatalf("getBaseName(%q) is empty", path)
+		}
+		if strings.ContainsAny(base, `/\`) {
+			t.Fatalf("getBaseName(%q) = %q still holds a separator", path, base)
+		}
+		if !strings.Contains(path, base) {
+			t.Fatalf("getBaseName(%q) = %q isn't part of the path", path, base)
+		}
+	})
+}
+
+func FuzzValidateRename(f *testing.F) {
+	for _, seed := range []struct{ oldPath, newName string }{
+		{"/dir/file", "renamed"},
+		{"/dir/file", "file"},
+		{"/dir/file", "taken"},
+		{"/dir/file", ".."},
+		{"/dir/file", "../escape"},
+		{"/dir/file", "a/b"},
+		{"/dir/file", `a\b`},
+		{"/dir/file", "nul\x00"},
+		{"/dir/file", "line\nbreak"},
+		{"/dir/file", "\xff\xfe"},
+		{"/dir/file", "  spaced  "},
+		{"/dir/file", strings.Repeat("n", 4096)},
+		{"/" + strings.Repeat("d/", 2100) + "file", "x"},
+		{`C:\dir/file`, "x"},
+		{"/", "x"},
+		{"", "x"},
+	} {
+		f.Add(seed.oldPath, seed.newName)
+	}
+	fsys := vfs.NewMem()
+	fsys.WriteFile("/dir/file", 1, time.Time{})
+	fsys.WriteFile("/dir/taken", 1, time.Time{})
+	f.Fuzz(func(t *testing.T, oldPath, newName string) {
+		newPath, err := validateRename(fsys, oldPath, newName)
+		if err != nil && !errors.Is(err, errRenameTargetExists) {
+			return
+		}
+		// Whatever is accepted stays in the directory it was in
+		if filepath.Dir(newPath) != filepath.Dir(oldPath) && newPath != filepath.Clean(oldPath) {
+			t.Fatalf("renaming %q to %q leaves its directory: %q", oldPath, newName, newPath)
+		}
+		name := filepath.Base(newPath)
+		if name == "." || name == ".." || strings.ContainsRune(name, 0) || !utf8.ValidString(name) {
+			t.Fatalf("renaming %q to %q accepted %q", oldPath, newName, newPath)
+		}
+	})
+}
+
+// FuzzParentWalk walks from a path up through its parents the way moving to
+// the parent does, which must reach a root isRoot recognizes rather than
+// loop at a fixed point of filepath.Dir.
+func FuzzParentWalk(f *testing.F) {
+	for _, path := range fuzzPaths {
+		f.Add(path)
+	}
+	f.Fuzz(func(t *testing.T, path string) {
+		current := path
+		// Every step drops at least a byte, or stops
+		for steps := 0; !isRoot(current); steps++ {
+			if steps > len(path)+1 {
+				t.Fatalf("walking up from %q hasn't reached a root after %d steps, at %q", path, steps, current)
+			}
+			parent := filepath.Dir(current)
+			if parent == current {
+				t.Fatalf("walking up from %q stuck at %q, which isRoot doesn't accept", path, current)
+			}
+			current = parent
+		}
+	})
+}
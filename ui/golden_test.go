@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/vfs"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// fixtureTime is the modification time of everything in the fixture, so
+// nothing depends on when the test runs.
+var fixtureTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// fixtureFS builds the tree the golden tests scan.
+func fixtureFS() *vfs.Mem {
+	fsys := vfs.NewMem()
+	for path, size := range map[string]int64{
+		"/proj/README.md":             2_048,
+		"/proj/go.mod":                120,
+		"/proj/src/main.go":           8_192,
+		"/proj/src/util.go":           4_096,
+		"/proj/src/vendor/lib.a":      3 << 20,
+		"/proj/build/app":             12 << 20,
+		"/proj/build/app.debug":       40 << 20,
+		"/proj/docs/guide.pdf":        1 << 20,
+		"/proj/docs/images/shot.png":  512 << 10,
+		"/proj/docs/images/shot2.png": 640 << 10,
+	} {
+		fsys.WriteFile(path, size, fixtureTime)
+	}
+	fsys.MkdirAll("/proj/empty", fixtureTime)
+	return fsys
+}
+
+// run executes cmd and feeds m every message it and the commands after it
+// produce, until none is left. A finished scan stops its scanner, which ends
+// the commands listening to it.
+func run(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	msgs := make(chan tea.Msg, 64)
+	pending := 0
+	start := func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		pending++
+		go func() { msgs <- cmd() }()
+	}
+
+	start(cmd)
+	deadline := time.After(10 * time.Second)
+	for pending > 0 {
+		select {
+		case msg := <-msgs:
+			pending--
+			switch msg := msg.(type) {
+			case nil:
+			case tea.BatchMsg:
+				for _, cmd := range msg {
+					start(cmd)
+				}
+			case spinnerTickMsg:
+				// Dropped, so the spinner neither waits nor moves
+			default:
+				next, cmd := m.Update(msg)
+				m = next.(Model)
+				start(cmd)
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for the model to settle")
+		}
+	}
+	return m
+}
+
+// send delivers msg to m and runs what it leads to.
+func send(t *testing.T, m Model, msg tea.Msg) Model {
+	t.Helper()
+	next, cmd := m.Update(msg)
+	return run(t, next.(Model), cmd)
+}
+
+// scanned returns a model that has scanned the fixture.
+func scanned(t *testing.T) Model {
+	t.Helper()
+	return scannedFS(t, fixtureFS())
+}
+
+// scannedFS returns a model that has scanned /proj in fsys.
+func scannedFS(t *testing.T, fsys *vfs.Mem) Model {
+	t.Helper()
+	// Nothing may come from the environment running the test, such as its
+	// language or a terminal that shows images
+	cfg := config.Default()
+	cfg.Locale = "en"
+	cfg.ImagePreviews = "off"
+	m := NewStreamingModel("/proj", cfg)
+	m.SetFS(fsys)
+	t.Cleanup(m.StopScan)
+	m = send(t, m, tea.WindowSizeMsg{Width: 100, Height: 24})
+	m = run(t, m, m.startConcurrentStreaming())
+	if m.isScanning {
+		t.Fatal("scan didn't finish")
+	}
+	return m
+}
+
+// keyMsg turns a key as Bubble Tea names it into the message typing it sends.
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	case "ctrl+s":
+		return tea.KeyMsg{Type: tea.KeyCtrlS}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// press replays keys on m, one after another.
+func press(t *testing.T, m Model, keys ...string) Model {
+	t.Helper()
+	for _, key := range keys {
+		m = send(t, m, keyMsg(key))
+	}
+	return m
+}
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+	}{
+		{"scanned", nil},
+		{"expanded", []string{"j", "l", "j", "j", "l"}},
+		{"sorted_by_size", []string{"s", "s"}},
+		{"reverse_sort", []string{"s", "s", "ctrl+s"}},
+		{"marked", []string{"j", "j", "d"}},
+		{"selected", []string{"j", "t", "j", "t"}},
+		{"search", []string{"/", "s", "h", "o", "t", "enter"}},
+		{"rename_prompt", []string{"j", "r", "backspace", "backspace", "x"}},
+		{"help", []string{"?"}},
+		{"disk_usage", []string{"u"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := press(t, scanned(t), test.keys...)
+			got := m.View()
+
+			golden := filepath.Join("testdata", test.name+".golden")
+			if *updateGolden {
+				if err := os.MkdirAll("testdata", 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("view differs from %s (run with -update to accept it)\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
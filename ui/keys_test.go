@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quits reports whether cmd, or any command batched in it, quits.
+func quits(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	switch msg := cmd().(type) {
+	case tea.QuitMsg:
+		return true
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			if quits(cmd) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// modes opens each screen and input of the interface, the way its key does
+// where that doesn't reach outside the fixture.
+var modes = []struct {
+	name string
+	open func(t *testing.T, m Model) Model
+}{
+	{"tree", func(t *testing.T, m Model) Model { return m }},
+	{"search", func(t *testing.T, m Model) Model { return press(t, m, "/") }},
+	{"rename", func(t *testing.T, m Model) Model { return press(t, m, "j", "r") }},
+	{"export", func(t *testing.T, m Model) Model { return press(t, m, "j", "t", "e") }},
+	{"visual", func(t *testing.T, m Model) Model { return press(t, m, "v") }},
+	{"deletion", func(t *testing.T, m Model) Model { return press(t, m, "j", "d") }},
+	{"help", func(t *testing.T, m Model) Model { return press(t, m, "?") }},
+	{"queue", func(t *testing.T, m Model) Model { return press(t, m, "j", "x", "Q") }},
+	{"note", func(t *testing.T, m Model) Model { return press(t, m, "j", "n") }},
+	{"owners", func(t *testing.T, m Model) Model { return press(t, m, "O") }},
+	{"columns", func(t *testing.T, m Model) Model { return press(t, m, "L") }},
+	{"review", func(t *testing.T, m Model) Model { return press(t, m, "j", "t", "V") }},
+	{"pager", func(t *testing.T, m Model) Model {
+		m.openPager("/nonexistent")
+		return m
+	}},
+	{"suggestions", func(t *testing.T, m Model) Model {
+		m.suggestionsView = true
+		return m
+	}},
+	{"reconcile", func(t *testing.T, m Model) Model {
+		m.reconcileView = true
+		return m
+	}},
+	{"quit confirmation", func(t *testing.T, m Model) Model {
+		m.quitConfirm = true
+		return m
+	}},
+	{"big deletion", func(t *testing.T, m Model) Model {
+		m.bigDelete = &bigDeletion{paths: []string{"/proj/build"}, path: "/proj/build"}
+		return m
+	}},
+	{"tutorial", func(t *testing.T, m Model) Model {
+		m.StartTutorial()
+		return m
+	}},
+	{"choose", func(t *testing.T, m Model) Model {
+		m.SetChooseMode(ChooseDir)
+		return m
+	}},
+}
+
+func TestCtrlCQuitsEverywhere(t *testing.T) {
+	for _, mode := range modes {
+		t.Run(mode.name, func(t *testing.T) {
+			m := mode.open(t, scanned(t))
+			if _, cmd := m.Update(keyMsg("ctrl+c")); !quits(cmd) {
+				t.Error("ctrl+c didn't quit")
+			}
+		})
+	}
+}
+
+// TestInputCapturesKeys types keys the tree acts on into each input that
+// takes over the keyboard, none of which may reach the tree.
+func TestInputCapturesKeys(t *testing.T) {
+	keys := []string{"r", "d", "/"}
+	tests := []struct {
+		mode  string
+		check func(t *testing.T, m Model)
+	}{
+		{"rename", func(t *testing.T, m Model) {
+			if !m.renameMode || m.renameInput != filepath.Base(m.renameOrigPath)+"rd/" {
+				t.Errorf("rename input %q, want the keys typed after the name", m.renameInput)
+			}
+		}},
+		{"search", func(t *testing.T, m Model) {
+			if !m.searchMode || m.searchQuery != "rd/" {
+				t.Errorf("search query %q, want the keys typed", m.searchQuery)
+			}
+		}},
+		{"queue", func(t *testing.T, m Model) {
+			if !m.queueView {
+				t.Error("queue closed")
+			}
+		}},
+		{"pager", func(t *testing.T, m Model) {
+			if !m.pagerOpen {
+				t.Error("pager closed")
+			}
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			var m Model
+			for _, mode := range modes {
+				if mode.name == test.mode {
+					m = mode.open(t, scanned(t))
+				}
+			}
+			renaming, searching := m.renameMode, m.searchMode
+			m = press(t, m, keys...)
+			test.check(t, m)
+
+			if len(m.markedForDeletion) > 0 || m.deletionMode {
+				t.Errorf("d reached the tree, marking %v", m.markedForDeletion)
+			}
+			if m.renameMode != renaming {
+				t.Error("r reached the tree, starting a rename")
+			}
+			if m.searchMode != searching {
+				t.Error("/ reached the tree, starting a search")
+			}
+		})
+	}
+}
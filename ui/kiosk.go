@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/corpeningc/dua/internal/kiosk"
+)
+
+// SetKiosk limits deleting, renaming and cleaning up to the paths policy
+// allows changing, see the kiosk package.
+func (m *Model) SetKiosk(policy *kiosk.Policy) {
+	m.kioskPolicy = policy
+}
+
+// kioskRefuses reports whether kiosk mode keeps any of paths from being
+// changed, saying which in the status line if so.
+func (m *Model) kioskRefuses(paths ...string) bool {
+	for _, path := range paths {
+		if !m.kioskPolicy.CanChange(path) {
+			if len(m.kioskPolicy.Writable) == 0 {
+				m.statusMessage = m.tr.T("kiosk.read_only", path)
+			} else {
+				m.statusMessage = m.tr.T("kiosk.refused", path, strings.Join(m.kioskPolicy.Writable, ", "))
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package ui
+
+import "strings"
+
+// virtualList draws a long list through a viewport. Only the rows on screen
+// are asked for, so drawing costs the same however long the list is.
+type virtualList[T any] struct {
+	top    int // Index of the first row on screen
+	height int // Rows that fit on screen
+	// rows returns the rows from index from up to to, fewer at the end of
+	// the list
+	rows func(from, to int) []T
+}
+
+// render writes a line per row on screen, returning how many it wrote.
+func (l virtualList[T]) render(b *strings.Builder, line func(index int, row T) string) int {
+	rows := l.rows(l.top, l.top+l.height)
+	for i, row := range rows {
+		b.WriteString(line(l.top+i, row) + "\n")
+	}
+	return len(rows)
+}
+
+// sliceRows serves the rows of a list already in memory.
+func sliceRows[T any](all []T) func(from, to int) []T {
+	return func(from, to int) []T {
+		from = min(max(from, 0), len(all))
+		return all[from:max(min(to, len(all)), from)]
+	}
+}
@@ -0,0 +1,251 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/dupes"
+	"github.com/corpeningc/dua/internal/i18n"
+)
+
+var mediaGroupStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.Color("#7D56F4"))
+
+// mediaRow is one line of the review pane, either a group header or a file.
+type mediaRow struct {
+	group int
+	file  int // -1 for the group header
+}
+
+// MediaReview is a pane for going through groups of similar images and
+// deleting the unwanted copies.
+type MediaReview struct {
+	groups  []dupes.MediaGroup
+	marked  map[string]bool
+	cursor  int
+	offset  int
+	width   int
+	height  int
+	confirm bool // d was pressed once with files marked
+	status  string
+	tr      *i18n.Translator
+}
+
+// NewMediaReview creates the review pane for groups found by
+// dupes.FindSimilarImages.
+func NewMediaReview(groups []dupes.MediaGroup, cfg config.Config) MediaReview {
+	review := MediaReview{
+		groups: groups,
+		marked: make(map[string]bool),
+		tr:     i18n.New(cfg.ResolvedLocale()),
+	}
+	review.cursor = review.firstFileRow()
+	return review
+}
+
+func (r MediaReview) Init() tea.Cmd {
+	return nil
+}
+
+func (r MediaReview) rows() []mediaRow {
+	var rows []mediaRow
+	for g, group := range r.groups {
+		rows = append(rows, mediaRow{group: g, file: -1})
+		for f := range group.Files {
+			rows = append(rows, mediaRow{group: g, file: f})
+		}
+	}
+	return rows
+}
+
+func (r MediaReview) firstFileRow() int {
+	if len(r.groups) == 0 {
+		return 0
+	}
+	return 1
+}
+
+// moveCursor steps over group headers so the cursor always rests on a file.
+func (r *MediaReview) moveCursor(delta int) {
+	rows := r.rows()
+	for next := r.cursor + delta; next >= 0 && next < len(rows); next += delta {
+		if rows[next].file >= 0 {
+			r.cursor = next
+			return
+		}
+	}
+}
+
+func (r MediaReview) currentRow() (mediaRow, bool) {
+	rows := r.rows()
+	if r.cursor < 0 || r.cursor >= len(rows) {
+		return mediaRow{}, false
+	}
+	return rows[r.cursor], true
+}
+
+func (r MediaReview) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		r.width = msg.Width
+		r.height = msg.Height
+
+	case BulkDeletionMsg:
+		r.removeDeleted(msg.DeletedPaths)
+		r.marked = make(map[string]bool)
+		r.status = r.tr.T("media.deleted", msg.SuccessCount, msg.ErrorCount)
+
+	case tea.KeyMsg:
+		if msg.String() != "d" {
+			r.confirm = false
+		}
+
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return r, tea.Quit
+		case "up", "k":
+			r.moveCursor(-1)
+		case "down", "j":
+			r.moveCursor(1)
+		case "t", " ":
+			if row, ok := r.currentRow(); ok && row.file >= 0 {
+				path := r.groups[row.group].Files[row.file].Path
+				if r.marked[path] {
+					delete(r.marked, path)
+				} else {
+					r.marked[path] = true
+				}
+			}
+		case "a":
+			// Keep the largest copy, mark the rest
+			if row, ok := r.currentRow(); ok {
+				for _, file := range r.groups[row.group].Files[1:] {
+					r.marked[file.Path] = true
+				}
+			}
+		case "esc":
+			r.marked = make(map[string]bool)
+		case "d":
+			if len(r.marked) == 0 {
+				break
+			}
+			if !r.confirm {
+				r.confirm = true
+				break
+			}
+			r.confirm = false
+			paths := make([]string, 0, len(r.marked))
+			for path := range r.marked {
+				paths = append(paths, path)
+			}
+			return r, deletePaths(paths, os.RemoveAll)
+		}
+	}
+
+	r.scrollToCursor()
+	return r, nil
+}
+
+func (r MediaReview) visibleRows() int {
+	return max(r.height-4, 1) // Header and footer
+}
+
+func (r *MediaReview) scrollToCursor() {
+	if r.cursor < r.offset {
+		r.offset = r.cursor
+	}
+	if r.cursor >= r.offset+r.visibleRows() {
+		r.offset = r.cursor - r.visibleRows() + 1
+	}
+	// Keep the group header above its first file in view
+	if rows := r.rows(); r.offset > 0 && r.offset < len(rows) && rows[r.offset].file == 0 {
+		r.offset--
+	}
+}
+
+// removeDeleted drops deleted files and any group left with a single image.
+func (r *MediaReview) removeDeleted(paths []string) {
+	deleted := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		deleted[path] = true
+	}
+
+	var groups []dupes.MediaGroup
+	for _, group := range r.groups {
+		var files []dupes.MediaFile
+		for _, file := range group.Files {
+			if !deleted[file.Path] {
+				files = append(files, file)
+			}
+		}
+		if len(files) > 1 {
+			groups = append(groups, dupes.MediaGroup{Files: files})
+		}
+	}
+	r.groups = groups
+
+	r.cursor = max(min(r.cursor, len(r.rows())-1), 0)
+	if row, ok := r.currentRow(); !ok || row.file < 0 {
+		r.moveCursor(1)
+	}
+}
+
+func (r MediaReview) View() string {
+	var b strings.Builder
+
+	header := r.tr.T("media.title", len(r.groups))
+	b.WriteString(header + "\n")
+	b.WriteString(strings.Repeat("-", lipgloss.Width(header)) + "\n")
+
+	if len(r.groups) == 0 {
+		b.WriteString(r.tr.T("media.empty") + "\n")
+	}
+
+	rows := r.rows()
+	for i := r.offset; i < len(rows) && i < r.offset+r.visibleRows(); i++ {
+		row := rows[i]
+		group := r.groups[row.group]
+
+		if row.file < 0 {
+			line := r.tr.T("media.group", row.group+1, len(group.Files), formatSize(group.Savings()))
+			b.WriteString(mediaGroupStyle.Render(line) + "\n")
+			continue
+		}
+
+		file := group.Files[row.file]
+		mark := "[ ]"
+		if r.marked[file.Path] {
+			mark = "[x]"
+		}
+		line := fmt.Sprintf("  %s %10s  %s", mark, formatSize(file.Size), file.Path)
+		if r.width > 0 {
+			line = ansi.Truncate(line, r.width, "…")
+		}
+
+		switch {
+		case i == r.cursor:
+			line = selectedItemStyle.Render(line)
+		case r.marked[file.Path]:
+			line = markedForDeletionStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n")
+	footer := r.tr.T("media.footer")
+	switch {
+	case r.confirm:
+		footer = r.tr.T("media.confirm", len(r.marked))
+	case r.status != "":
+		footer = r.status + " • " + footer
+	}
+	b.WriteString(footer)
+
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/corpeningc/dua/internal/memusage"
+	"github.com/corpeningc/dua/internal/scanner"
+	"github.com/corpeningc/dua/internal/tree"
+)
+
+// memoryCheckInterval is how often the process size is checked against the
+// memory limit while scanning.
+const memoryCheckInterval = 2 * time.Second
+
+// pruneDepth is how far below the root collapsed directories lose their
+// contents once over the memory limit. The levels above stay browsable.
+const pruneDepth = 2
+
+// guardMemory checks the process size against the memory limit, and when
+// it's over, drops the contents of collapsed directories deep in the tree,
+// keeping only their totals.
+func (m *Model) guardMemory(now time.Time) {
+	if m.memoryLimit == 0 || now.Sub(m.memoryChecked) < memoryCheckInterval {
+		return
+	}
+	m.memoryChecked = now
+	if memusage.RSS() <= m.memoryLimit {
+		return
+	}
+
+	m.pruneBelow(m.tree.Root, 0)
+	m.sortCache = make(map[string]*sortedContents)
+	// Freed memory only leaves the resident size once returned to the OS
+	debug.FreeOSMemory()
+
+	if !m.degraded {
+		m.degraded = true
+		m.statusMessage = m.tr.T("memory.degraded", formatSize(int64(m.memoryLimit)))
+	}
+}
+
+// pruneBelow prunes the collapsed directories under dir from pruneDepth
+// down.
+func (m *Model) pruneBelow(dir *scanner.DirInfo, depth int) {
+	for i := range dir.Subdirs {
+		subdir := &dir.Subdirs[i]
+		if depth+1 >= pruneDepth && !m.expanded[subdir.Path] {
+			m.tree.Apply(tree.Pruned{Path: subdir.Path})
+			continue
+		}
+		m.pruneBelow(subdir, depth+1)
+	}
+}
@@ -1,15 +1,32 @@
 package ui
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/browsers"
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/cost"
+	"github.com/corpeningc/dua/internal/i18n"
+	"github.com/corpeningc/dua/internal/kiosk"
+	"github.com/corpeningc/dua/internal/openfiles"
+	"github.com/corpeningc/dua/internal/owners"
+	"github.com/corpeningc/dua/internal/pkgcache"
 	"github.com/corpeningc/dua/internal/scanner"
+	"github.com/corpeningc/dua/internal/sessionlock"
+	"github.com/corpeningc/dua/internal/shred"
+	"github.com/corpeningc/dua/internal/termimage"
+	"github.com/corpeningc/dua/internal/trash"
+	"github.com/corpeningc/dua/internal/tree"
+	"github.com/corpeningc/dua/internal/vfs"
 )
 
 // BulkDeletionMsg reports the results of a bulk deletion operation.
@@ -18,26 +35,71 @@ type BulkDeletionMsg struct {
 	SuccessCount int
 	ErrorCount   int
 	Errors       []error
+	Took         time.Duration
 }
 
 // RenameMsg reports the result of a rename operation.
 type RenameMsg struct {
-	OldPath string
-	NewPath string
-	Success bool
-	Error   error
+	OldPath   string
+	NewPath   string
+	Overwrite bool
+	Success   bool
+	Error     error
 }
 
+// errRenameTargetExists is returned by validateRename when the new name is
+// already taken by another file or directory.
+var errRenameTargetExists = errors.New("target already exists")
+
+// renameValidationError describes why validateRename rejected a name. It keeps
+// the message key so the model can show it in the user's language.
+type renameValidationError struct {
+	key  string
+	args []any
+}
+
+func (e *renameValidationError) Error() string {
+	return i18n.New("en").T(e.key, e.args...)
+}
+
+// StreamingUpdateMsg carries every scanner update received during one frame.
 type StreamingUpdateMsg struct {
-	Update     scanner.StreamingUpdate
+	Updates    []scanner.StreamingUpdate
 	UpdateChan <-chan scanner.StreamingUpdate
 	ErrorChan  <-chan error
+	Generation int // The scan the updates belong to
 }
 
+// DirectoryLoadedMsg delivers a directory loaded on demand while no streaming
+// scan is running to pick it up.
+type DirectoryLoadedMsg struct {
+	Path       string
+	DirInfo    *scanner.DirInfo
+	Error      error
+	Generation int
+}
+
+// spinnerTickMsg advances the loading spinner shown on rows being loaded.
+type spinnerTickMsg struct{}
+
 type StreamErrorMsg struct {
-	Error error
+	Error      error
+	ErrorChan  <-chan error
+	Generation int
 }
 
+// spinnerInterval is how often the loading spinner advances.
+const spinnerInterval = 100 * time.Millisecond
+
+// streamingFrameInterval bounds how often scanner updates reach Update, and so
+// how often the tree is re-rendered while a scan is running.
+const streamingFrameInterval = 75 * time.Millisecond
+
+// readOnlyKeys are the keys that change files or read them from disk, which a
+// loaded tree refuses: queueing, deleting, renaming, the preview pane and
+// what's on this machine rather than in the tree.
+var readOnlyKeys = map[string]bool{"x": true, "Q": true, "d": true, "S": true, "r": true, "p": true, "C": true, "D": true, "R": true}
+
 // SortMode defines different ways to sort directory contents.
 type SortMode int
 
@@ -48,6 +110,33 @@ const (
 	SortByType
 )
 
+// DefaultAsc reports the direction a sort key starts in: names and types
+// ascending, sizes and dates largest and newest first.
+func (s SortMode) DefaultAsc() bool {
+	switch s {
+	case SortBySize, SortByDate:
+		return false
+	default:
+		return true
+	}
+}
+
+// parseSortMode maps a sort key name from the config to its mode, falling
+// back to sorting by name.
+func parseSortMode(name string) SortMode {
+	for _, mode := range []SortMode{SortByName, SortByDate, SortBySize, SortByType} {
+		if strings.EqualFold(name, mode.String()) {
+			return mode
+		}
+	}
+	return SortByName
+}
+
+// messageKey returns the i18n key for the sort mode's display name.
+func (s SortMode) messageKey() string {
+	return "sort." + strings.ToLower(s.String())
+}
+
 func (s SortMode) String() string {
 	switch s {
 	case SortByName:
@@ -65,75 +154,333 @@ func (s SortMode) String() string {
 
 // Model represents the application state for the directory viewer.
 type Model struct {
-	rootDir     *scanner.DirInfo
+	tree        *tree.Tree
 	currentPath string
 	displayPath string // Absolute path for display purposes only
 
+	fsys             vfs.FS // Scanned, deleted from and renamed in
 	streamingScanner *scanner.StreamingScanner
-	directoryMap     map[string]*scanner.DirInfo
 	updateChan       <-chan scanner.StreamingUpdate
 	errorChan        <-chan error
 	isScanning       bool
 	scanStartTime    time.Time
+	checkpoint       string   // Journal of the scan, for --resume
+	resumed          bool     // Continuing an interrupted scan
+	resumePending    []string // Directories the interrupted scan hadn't reached
+	memoryLimit      uint64   // Bytes, 0 for none
+	memoryChecked    time.Time
+	degraded         bool // Contents were dropped to stay under memoryLimit
+	verifySample     int  // Directories besides the root to verify, 0 to not verify
+	verifying        bool
+	verifyChecks     []scanner.Check
+	quick            *quickScan // Set for a time-limited scan
+	precount         *precount  // Set when the tree is counted to show progress
+	history          *scanHistory
+	sampleAbove      int // Files in a directory before it's sampled, 0 for never
+
+	// generation counts rescans. Results of earlier scans still in flight
+	// carry an older one and are dropped, so they can't bring back items
+	// deleted or renamed since.
+	generation int
+	// mtimeRescan is when rescans reuse unchanged listings, see
+	// config.MtimeRescan
+	mtimeRescan string
 
 	progressFiles int
 	progressDirs  int
-	progressBytes int64
+
+	// Directories being loaded on demand, animated with a spinner
+	loadingDirs   map[string]bool
+	spinnerFrame  int
+	spinnerActive bool
+
+	// One-off message shown in the footer until the next key press
+	statusMessage string
+
+	tutorialActive bool
+	tutorialStep   int
+
+	stats sessionStats
+
+	chooseMode ChooseMode
+	chosenPath string
+
+	// Recent growth per directory, flashed next to rows while scanning
+	sizeDeltas map[string]*sizeDelta
 
 	cursor            int
+	cursorPath        string // Item under the cursor, kept across tree mutations
 	selected          map[string]bool
 	expanded          map[string]bool
 	markedForDeletion map[string]bool
 	viewportTop       int
 
-	visualMode  bool
-	visualStart int
+	visualMode      bool
+	visualStart     int
+	visualStartPath string
+	visualBase      map[string]bool // Selected before the range, which it adds to
+
+	// The screen reviewing the selection before acting on it
+	reviewView    bool
+	reviewCursor  int
+	reviewConfirm bool
 
 	deletionMode bool
 
-	renameMode     bool
-	renameOrigPath string
-	renameInput    string
+	shredConfirm     bool // S was pressed once, asking to confirm a secure delete
+	shredCopyOnWrite bool // The tree is on a filesystem shredding can't reach
+
+	// Deleting a directory of at least confirmDeleteBytes waits in bigDelete
+	// for its name to be typed. 0 never asks.
+	confirmDeleteBytes int64
+	bigDelete          *bigDeletion
+
+	// Other dua sessions whose roots overlap this one, found through the
+	// lock files of sessionLock
+	sessionLock *sessionlock.Lock
+	peers       []sessionlock.Session
+
+	renameMode      bool
+	renameOrigPath  string
+	renameInput     string
+	renameError     string
+	renameOverwrite bool // Target exists and the user has been asked to confirm
+
+	exportMode      bool
+	exportInput     string
+	exportOverwrite bool // Target exists and the user has been asked to confirm
+
+	// Notes attached to paths, kept in the state database at notesFile
+	notes     map[string]string
+	notesFile string
+	noteMode  bool
+	notePath  string
+	noteInput string
+
+	pagerOpen  bool
+	pager      pagerView
+	pagerTop   int
+	pagerBytes int64 // How much of a file the pager reads
+
+	previewOpen      bool
+	preview          filePreview
+	previewRequested previewRequest
+	graphics         termimage.Protocol // How thumbnails are drawn, if at all
+
+	// Stats of backup repositories that have been previewed
+	backups map[string]backupInfo
+	// Processes holding each open file, by path
+	openFiles map[string][]openfiles.Process
+
+	// Rules attributing paths to owners, and the per-owner breakdown screen
+	owners     *owners.Rules
+	ownersView bool
+	ownersTop  int
+	ownerUsage []ownerUsage
+
+	// Cleanup suggestions and the screen listing them
+	suggestionsView    bool
+	suggestionsCursor  int
+	suggestionsConfirm bool
+	suggestionList     []suggestion
+	logMaxAgeDays      int // How old rotated logs must be to be suggested
+	// Caches outside the scanned tree, measured when suggestions are first
+	// shown
+	packageCaches  []pkgcache.Cache
+	browserCaches  []browsers.Browser
+	trashBins      []trash.Bin
+	cachesMeasured bool
+	cachesLoading  bool
+
+	// Directories that couldn't be read, and the screen comparing the
+	// scanned total with what the filesystem reports
+	unreadable    []string
+	reconcileView bool
+	reconcile     *reconcileReport // nil while it's gathered
+
+	// Items set aside for deletion, run together from the queue screen
+	queue            map[string]bool
+	queueView        bool
+	queueCursor      int
+	queueConfirm     bool // Asking whether to delete everything queued
+	queueQuitting    bool // The confirmation was triggered by quitting
+	quitAfterCleanup bool
+
+	// Asking before quitting with items marked or queued
+	quitConfirm bool
 
 	searchMode  bool
 	searchQuery string
 
-	sortMode SortMode
-	sortAsc  bool
+	sortMode  SortMode
+	sortAsc   bool
+	sortCache map[string]*sortedContents
+
+	// Totals of directories scanned earlier in the session, shown while a
+	// rescan gets to them
+	sizeCache map[string]cachedSize
+
+	// Sizes are the disk space taken rather than the apparent size
+	diskUsage bool
+
+	showDates     bool
+	relativeDates bool
+	dateLayout    string
+
+	ageHeatmap bool
+	ageColors  []config.AgeColor
+
+	cost     cost.Rate
+	showCost bool
+
+	// Columns right of the names in order, and the picker choosing them
+	columns       []column
+	columnsView   bool
+	columnsCursor int
+	fileOwners    map[string]string // Users owning files, by path, as shown
+
+	// Scans and deletions taking at least notifyAfter end with a desktop
+	// notification if the terminal has lost focus
+	notifyAfter time.Duration
+	unfocused   bool
+
+	// Overlay listing every key of the mode below it, for when the footer
+	// has no room for them all
+	helpView bool
+
+	tr *i18n.Translator
 
 	width  int
 	height int
+
+	inlineLines int // Fixed height when drawing inline instead of full screen
+
+	// The tree was loaded from an export rather than scanned, so its paths
+	// may not exist here and nothing may touch them
+	readOnly bool
+
+	// Restricts what may be changed on shared servers, nil if unrestricted
+	kioskPolicy *kiosk.Policy
+}
+
+// sortedContents caches the order of one directory's children for a given sort.
+type sortedContents struct {
+	mode    SortMode
+	asc     bool
+	files   []int // Indexes into the directory's Files
+	subdirs []int // Indexes into the directory's Subdirs
+	sorted  time.Time
+	stale   bool // Sizes changed since sorting
 }
 
 // NewModel creates a new model for the directory viewer.
 func NewModel(rootDir *scanner.DirInfo, path string) Model {
+	cfg := config.Default()
+
 	return Model{
-		rootDir:     rootDir,
-		currentPath: path,
-		cursor:      0,
-		expanded:    make(map[string]bool),
-		selected:    make(map[string]bool),
-		viewportTop: 0,
-		visualMode:  false,
-		visualStart: -1,
-		width:       80,
-		height:      24,
-		sortMode:    SortByName,
-		sortAsc:     false,
-		searchMode:  false,
-		searchQuery: "",
+		tree:              tree.New(rootDir),
+		currentPath:       path,
+		cursor:            0,
+		expanded:          make(map[string]bool),
+		selected:          make(map[string]bool),
+		loadingDirs:       make(map[string]bool),
+		markedForDeletion: make(map[string]bool),
+		queue:             make(map[string]bool),
+		notes:             make(map[string]string),
+		backups:           make(map[string]backupInfo),
+		sizeDeltas:        make(map[string]*sizeDelta),
+		viewportTop:       0,
+		visualMode:        false,
+		visualStart:       -1,
+		width:             80,
+		height:            24,
+		sortMode:          SortByName,
+		sortAsc:           SortByName.DefaultAsc(),
+		sortCache:         make(map[string]*sortedContents),
+		sizeCache:         make(map[string]cachedSize),
+		searchMode:        false,
+		searchQuery:       "",
+		dateLayout:        dateLayoutFor(cfg),
+		graphics:          termimage.Detect(),
+		pagerBytes:        int64(cfg.PagerMaxKB) << 10,
+		logMaxAgeDays:     cfg.LogMaxAgeDays,
+		tr:                i18n.New(cfg.ResolvedLocale()),
+
+		confirmDeleteBytes: int64(cfg.ConfirmDeleteGB) << 30,
+		mtimeRescan:        cfg.MtimeRescan,
+		columns:            parseColumns(cfg.Columns),
+		fileOwners:         make(map[string]string),
+		diskUsage:          cfg.DiskUsage,
 	}
 }
 
 // NewStreamingModel creates a model with fast startup and progressive loading.
-func NewStreamingModel(path string) Model {
+func NewStreamingModel(path string, cfg config.Config) Model {
+	// The scanner joins child paths with filepath.Join, which cleans them, so
+	// the root must be clean too for parent lookups to reach it. Callers
+	// should pass a root from scanner.NormalizeRoot, this is only a fallback.
+	path = filepath.Clean(path)
+
 	// Get absolute path for display
 	displayPath, err := filepath.Abs(path)
 	if err != nil {
 		displayPath = path // Fallback to original path if Abs fails
 	}
 
-	rootDir := &scanner.DirInfo{
+	return Model{
+		tree:              tree.New(rootPlaceholder(path)),
+		currentPath:       path,
+		displayPath:       displayPath,
+		fsys:              vfs.OS,
+		streamingScanner:  scanner.NewStreamingScanner(),
+		loadingDirs:       make(map[string]bool),
+		isScanning:        true,
+		spinnerActive:     true,
+		scanStartTime:     time.Now(),
+		cursor:            0,
+		expanded:          make(map[string]bool),
+		selected:          make(map[string]bool),
+		markedForDeletion: make(map[string]bool),
+		queue:             make(map[string]bool),
+		notes:             make(map[string]string),
+		backups:           make(map[string]backupInfo),
+		sizeDeltas:        make(map[string]*sizeDelta),
+		viewportTop:       0,
+		visualMode:        false,
+		visualStart:       -1,
+		width:             80,
+		height:            24,
+		sortMode:          parseSortMode(cfg.Sort),
+		sortAsc:           parseSortMode(cfg.Sort).DefaultAsc() != cfg.SortReverse,
+		sortCache:         make(map[string]*sortedContents),
+		sizeCache:         make(map[string]cachedSize),
+		renameMode:        false,
+		searchMode:        false,
+		searchQuery:       "",
+		showDates:         cfg.ShowDates,
+		relativeDates:     cfg.DateFormat == config.DateRelative,
+		dateLayout:        dateLayoutFor(cfg),
+		ageHeatmap:        cfg.AgeHeatmap,
+		ageColors:         sortAgeColors(cfg.AgeColors),
+		graphics:          termimage.Parse(cfg.ImagePreviews),
+		pagerBytes:        int64(cfg.PagerMaxKB) << 10,
+		logMaxAgeDays:     cfg.LogMaxAgeDays,
+		memoryLimit:       uint64(cfg.MemoryLimitMB) << 20,
+		tr:                i18n.New(cfg.ResolvedLocale()),
+
+		confirmDeleteBytes: int64(cfg.ConfirmDeleteGB) << 30,
+		mtimeRescan:        cfg.MtimeRescan,
+		sampleAbove:        cfg.SampleAboveFiles,
+		notifyAfter:        time.Duration(cfg.NotifyAfterSeconds) * time.Second,
+		columns:            parseColumns(cfg.Columns),
+		fileOwners:         make(map[string]string),
+		diskUsage:          cfg.DiskUsage,
+	}
+}
+
+// rootPlaceholder stands in for the root until the scanner reads it.
+func rootPlaceholder(path string) *scanner.DirInfo {
+	return &scanner.DirInfo{
 		Path:        path,
 		Size:        0,
 		Files:       make([]scanner.FileInfo, 0),
@@ -142,39 +489,196 @@ func NewStreamingModel(path string) Model {
 		IsLoading:   true,
 		FileCount:   0,
 		SubdirCount: 0,
+		PendingDirs: 1,
 	}
+}
 
-	return Model{
-		rootDir:          rootDir,
-		currentPath:      path,
-		displayPath:      displayPath,
-		streamingScanner: scanner.NewStreamingScanner(),
-		directoryMap:     make(map[string]*scanner.DirInfo),
-		isScanning:       true,
-		scanStartTime:    time.Now(),
-		cursor:           0,
-		expanded:         make(map[string]bool),
-		selected:         make(map[string]bool),
-		viewportTop:      0,
-		visualMode:       false,
-		visualStart:      -1,
-		width:            80,
-		height:           24,
-		sortMode:         SortByName,
-		sortAsc:          false,
-		renameMode:       false,
-		searchMode:       false,
-		searchQuery:      "",
-	}
-}
-
-// Init initializes the model, starting background loading if in streaming mode.
+// NewLoadedModel creates a model for a tree loaded from an export instead of
+// scanned, labelled with where it came from. It is read-only: the paths may
+// belong to other machines, so deleting, renaming, queueing and viewing files
+// are refused.
+func NewLoadedModel(root *scanner.DirInfo, label string, cfg config.Config) Model {
+	m := NewStreamingModel(root.Path, cfg)
+	m.tree = tree.New(root)
+	m.displayPath = label
+	m.streamingScanner = nil
+	m.isScanning = false
+	m.spinnerActive = false
+	m.readOnly = true
+	m.expanded[m.currentPath] = true
+	m.countProgress(root)
+	return m
+}
+
+// NewResumedModel continues an interrupted scan from the tree and pending
+// directories scanner.LoadCheckpoint recovered.
+func NewResumedModel(root *scanner.DirInfo, pending []string, cfg config.Config) Model {
+	m := NewStreamingModel(root.Path, cfg)
+	m.tree = tree.New(root)
+	m.resumed = true
+	m.resumePending = pending
+	m.expanded[m.currentPath] = true
+	m.countProgress(root)
+	return m
+}
+
+// countProgress adds a tree that didn't arrive through the scanner to the
+// file and directory counts.
+func (m *Model) countProgress(dir *scanner.DirInfo) {
+	m.progressFiles += dir.FileCount
+	m.progressDirs += dir.SubdirCount
+	for i := range dir.Subdirs {
+		m.countProgress(&dir.Subdirs[i])
+	}
+}
+
+// SetFS scans, deletes from and renames in fsys instead of the operating
+// system's filesystem. It must be called before Init.
+func (m *Model) SetFS(fsys vfs.FS) {
+	m.fsys = fsys
+	if m.streamingScanner != nil {
+		m.streamingScanner.SetFS(fsys)
+	}
+}
+
+// SetCheckpoint journals the scan to file, so it can be resumed with
+// --resume if interrupted.
+func (m *Model) SetCheckpoint(file string) {
+	if m.streamingScanner == nil {
+		return
+	}
+	m.checkpoint = file
+	m.streamingScanner.SetCheckpoint(file)
+}
+
+// StopScan stops a scan still running when the program exits, writing out
+// its checkpoint.
+func (m Model) StopScan() {
+	if m.isScanning && m.streamingScanner != nil {
+		m.streamingScanner.Stop()
+	}
+}
+
+// finishScan stops the scanner once the scan is complete or cut short, and
+// starts what waits for the scan.
+func (m *Model) finishScan() tea.Cmd {
+	m.isScanning = false
+	m.sizeDeltas = make(map[string]*sizeDelta)
+	m.stats.scanDuration = time.Since(m.scanStartTime)
+	if m.streamingScanner != nil {
+		m.streamingScanner.Stop()
+	}
+	m.stopPrecount()
+	// Anything still waiting on the scanner failed to read
+	for path := range m.loadingDirs {
+		if dir := m.tree.Find(path); dir != nil {
+			dir.IsLoading = false
+		}
+		delete(m.loadingDirs, path)
+	}
+	verify := m.verifyTree()
+	notify := m.notifyDone(m.stats.scanDuration, m.tr.T("notify.scanned", m.displayPath, formatSize(m.dirSize(m.tree.Root))))
+	return tea.Batch(m.loadOpenFiles(), verify, m.recordScan(), notify)
+}
+
+// rescan throws the tree away and scans the root again, for when it changed
+// outside dua. Expanded directories and the cursor are kept, and pick up
+// where they were as the scan reaches them.
+func (m *Model) rescan() tea.Cmd {
+	if m.streamingScanner != nil {
+		m.streamingScanner.Stop()
+	}
+	m.generation++
+	m.streamingScanner = scanner.NewStreamingScanner()
+	m.streamingScanner.SetFS(m.fsys)
+	if m.checkpoint != "" {
+		m.streamingScanner.SetCheckpoint(m.checkpoint)
+	}
+	status := m.tr.T("rescan.started")
+	if m.reuseListings() {
+		m.streamingScanner.SetBaseline(m.tree.Root)
+		status = m.tr.T("rescan.mtime")
+	}
+
+	// A cached tree is labelled with its age, which no longer applies
+	if displayPath, err := filepath.Abs(m.currentPath); err == nil {
+		m.displayPath = displayPath
+	}
+	m.rememberSizes(m.tree.Root)
+	m.tree = tree.New(rootPlaceholder(m.currentPath))
+	m.resumed = false
+	m.resumePending = nil
+	m.isScanning = true
+	m.scanStartTime = time.Now()
+	m.progressFiles, m.progressDirs = 0, 0
+	m.loadingDirs = make(map[string]bool)
+	m.sizeDeltas = make(map[string]*sizeDelta)
+	m.sortCache = make(map[string]*sortedContents)
+	m.unreadable = nil
+	m.degraded = false
+	m.verifying = false
+	m.verifyChecks = nil
+	if m.quick != nil {
+		m.quick.stopped = false
+	}
+	m.statusMessage = status
+	m.stopPrecount()
+	cmd := tea.Batch(m.startConcurrentStreaming(), m.startPrecount())
+	if !m.spinnerActive {
+		m.spinnerActive = true
+		cmd = tea.Batch(cmd, spinnerTick())
+	}
+	return cmd
+}
+
+// reuseListings reports whether a rescan should take the listings of
+// directories whose modification time hasn't changed from the current tree.
+func (m Model) reuseListings() bool {
+	switch m.mtimeRescan {
+	case config.MtimeRescanAlways:
+		return true
+	case config.MtimeRescanNever:
+		return false
+	}
+	// Mounts only describe the real filesystem
+	return m.fsys == vfs.OS && scanner.NetworkFS(m.currentPath)
+}
+
+// NewCachedModel creates a model for this machine's tree as cached by the
+// daemon when it was scanned. The paths are local, so unlike other loaded
+// trees everything works, though items may have changed since.
+func NewCachedModel(root *scanner.DirInfo, scanned time.Time, cfg config.Config) Model {
+	m := NewLoadedModel(root, root.Path, cfg)
+	m.readOnly = false
+	m.displayPath = m.tr.T("header.cached", root.Path, m.formatDate(scanned))
+	return m
+}
+
+// SetInline draws the interface in the given number of lines of the normal
+// screen rather than taking over the terminal.
+func (m *Model) SetInline(lines int) {
+	m.inlineLines = lines
+	m.height = lines
+}
+
+// Init initializes the model, starting background loading if in streaming
+// mode. An already scanned tree only needs its open files looked up.
 func (m Model) Init() tea.Cmd {
-	return m.startConcurrentStreaming()
+	if m.streamingScanner == nil {
+		return tea.Batch(m.loadOpenFiles(), m.findPeers())
+	}
+	return tea.Batch(m.startConcurrentStreaming(), m.findPeers(), m.quickDeadline(), m.startPrecount(), spinnerTick())
 }
 
 func (m Model) startConcurrentStreaming() tea.Cmd {
-	updateChan, errorChan := m.streamingScanner.StartStreaming(m.currentPath)
+	var updateChan <-chan scanner.StreamingUpdate
+	var errorChan <-chan error
+	m.streamingScanner.SetSampling(m.sampleAbove)
+	if m.resumed {
+		updateChan, errorChan = m.streamingScanner.ResumeStreaming(m.tree.Root, m.resumePending)
+	} else {
+		updateChan, errorChan = m.streamingScanner.StartStreaming(m.currentPath)
+	}
 
 	return tea.Batch(
 		m.listenForUpdates(updateChan, errorChan),
@@ -182,83 +686,287 @@ func (m Model) startConcurrentStreaming() tea.Cmd {
 	)
 }
 
+// listenForUpdates waits for the next scanner update, then keeps collecting
+// for the rest of the frame so a busy scan re-renders at a bounded rate.
 func (m Model) listenForUpdates(updateChan <-chan scanner.StreamingUpdate, errorChan <-chan error) tea.Cmd {
+	generation := m.generation
 	return func() tea.Msg {
-		update := <-updateChan
+		update, ok := <-updateChan
+		if !ok {
+			return nil
+		}
+
+		updates := []scanner.StreamingUpdate{update}
+		frame := time.After(streamingFrameInterval)
+
+	collect:
+		for !update.IsComplete {
+			select {
+			case update, ok = <-updateChan:
+				if !ok {
+					break collect
+				}
+				updates = append(updates, update)
+			case <-frame:
+				break collect
+			}
+		}
+
 		return StreamingUpdateMsg{
-			Update:     update,
+			Updates:    updates,
 			UpdateChan: updateChan,
 			ErrorChan:  errorChan,
+			Generation: generation,
 		}
 	}
 }
 
 func (m Model) listenForErrors(errorChan <-chan error) tea.Cmd {
+	generation := m.generation
 	return func() tea.Msg {
-		err := <-errorChan
-		return StreamErrorMsg{Error: err}
+		err, ok := <-errorChan
+		if !ok {
+			return nil
+		}
+		return StreamErrorMsg{Error: err, ErrorChan: errorChan, Generation: generation}
 	}
 }
 
 // Update handles all messages and user input for the directory viewer.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.handleMsg(msg)
+
+	// Streaming updates, deletions, renames and re-sorts all shift rows around,
+	// so keep the cursor on the same item rather than the same index
+	m.restoreCursor()
+
+	if load := m.refreshPreview(); load != nil {
+		cmd = tea.Batch(cmd, load)
+	}
+
+	return m, cmd
+}
+
+func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.inlineLines > 0 {
+			m.height = min(m.inlineLines, msg.Height)
+		}
 
 	case StreamingUpdateMsg:
-		update := msg.Update
-		if update.IsComplete {
-			m.isScanning = false
-			if m.streamingScanner != nil {
-				m.streamingScanner.Stop()
+		if msg.Generation != m.generation {
+			// A scan replaced by a rescan, stop listening to it
+			return m, nil
+		}
+		m.pruneSizeDeltas(time.Now())
+		for _, update := range msg.Updates {
+			if update.IsComplete {
+				if reused := m.streamingScanner.Reused(); reused > 0 {
+					m.statusMessage = m.tr.N("rescan.reused", reused)
+				}
+				// The scanner closes its channels once stopped, so stop listening
+				return m, m.finishScan()
 			}
-		} else {
+
 			// Process incremental update
 			m.progressFiles += update.FileCount
 			m.progressDirs += update.DirCount
-			m.progressBytes += update.TotalSize
 
 			if update.DirInfo != nil {
-				m.directoryMap[update.DirInfo.Path] = update.DirInfo
+				delete(m.loadingDirs, update.DirInfo.Path)
+				m.apply(tree.Scanned{Dir: update.DirInfo})
+			}
+		}
+		m.guardMemory(time.Now())
+		return m, m.listenForUpdates(msg.UpdateChan, msg.ErrorChan)
 
-				if update.Path == m.currentPath {
-					m.rootDir = update.DirInfo
-					m.expanded[update.Path] = true
-				} else {
-					// Integrate this directory into the tree structure
-					m.integrateDirectoryIntoTree(update.DirInfo)
-				}
+	case StreamErrorMsg:
+		if msg.Generation != m.generation {
+			return m, nil
+		}
+		if msg.Error != nil {
+			m.stats.errors++
+			var scanErr *scanner.ScanError
+			if errors.As(msg.Error, &scanErr) {
+				m.unreadable = append(m.unreadable, scanErr.Path)
+			}
+		}
+		return m, m.listenForErrors(msg.ErrorChan)
+
+	case DirectoryLoadedMsg:
+		if msg.Generation != m.generation {
+			return m, nil
+		}
+		delete(m.loadingDirs, msg.Path)
+		if msg.Error != nil {
+			m.stats.errors++
+			m.unreadable = append(m.unreadable, msg.Path)
+			if dir := m.tree.Find(msg.Path); dir != nil {
+				dir.IsLoading = false
 			}
+			m.statusMessage = m.tr.T("error.load", getBaseName(msg.Path), msg.Error)
+			return m, nil
 		}
-		return m, tea.Batch(
-			m.listenForUpdates(msg.UpdateChan, msg.ErrorChan),
-			m.listenForErrors(msg.ErrorChan),
-		)
+
+		m.progressFiles += msg.DirInfo.FileCount
+		m.progressDirs += msg.DirInfo.SubdirCount
+		m.apply(tree.Scanned{Dir: msg.DirInfo})
+
+	case PrecountMsg:
+		m.precounted(msg)
+
+	case tea.FocusMsg:
+		m.unfocused = false
+
+	case tea.BlurMsg:
+		m.unfocused = true
+
+	case spinnerTickMsg:
+		// Rows the scan hasn't reached spin too
+		if len(m.loadingDirs) == 0 && !m.isScanning {
+			m.spinnerActive = false
+			return m, nil
+		}
+		m.spinnerFrame++
+		return m, spinnerTick()
 
 	case BulkDeletionMsg:
+		var freed int64
 		for _, path := range msg.DeletedPaths {
-			m.removeItemFromTree(path)
+			freed += m.itemSize(path)
+			m.apply(tree.Removed{Path: path})
 		}
+		m.stats.reclaimedBytes += freed
+		m.stats.deletedItems += msg.SuccessCount
+		m.stats.errors += msg.ErrorCount
 
 		m.visualMode = false
 		m.visualStart = -1
+		m.visualStartPath = ""
 		m.selected = make(map[string]bool)
 
 		m.deletionMode = false
 		m.markedForDeletion = make(map[string]bool)
 
+		m.refreshSuggestions()
+		if msg.ErrorCount > 0 {
+			m.statusMessage = m.tr.N("delete.failed", msg.ErrorCount, msg.Errors[0])
+		}
+		if m.quitAfterCleanup {
+			if msg.ErrorCount == 0 {
+				return m, tea.Quit
+			}
+			// Stay with what couldn't be deleted left in the queue
+			m.quitAfterCleanup = false
+			m.queueQuitting = false
+		}
+		notify := m.notifyDone(msg.Took, m.tr.N("notify.deleted", msg.SuccessCount, formatSize(freed)))
+		if m.cachesDeleted(msg.DeletedPaths) {
+			return m, tea.Batch(notify, m.measureCaches())
+		}
+		return m, notify
+
+	case VerifyMsg:
+		m.verifyDone(msg)
+
+	case PagerMsg:
+		if msg.view.path == m.pager.path {
+			m.pager = msg.view
+		}
+
+	case PreviewMsg:
+		// A slow load may finish after the cursor has moved on
+		if msg.preview.request == m.previewRequested {
+			m.preview = msg.preview
+		}
+
+	case CachesMsg:
+		m.packageCaches = msg.Packages
+		m.browserCaches = msg.Browsers
+		m.trashBins = msg.Trash
+		m.cachesMeasured = true
+		m.cachesLoading = false
+		m.refreshSuggestions()
+
+	case CleanCommandMsg:
+		if msg.Error != nil {
+			m.statusMessage = m.tr.T("suggest.command_failed", msg.Title, msg.Error)
+		} else {
+			m.statusMessage = m.tr.T("suggest.command_done", msg.Title)
+		}
+		return m, m.measureCaches()
+
+	case ReconcileMsg:
+		if m.reconcileView {
+			m.reconcile = &msg.report
+		}
+
+	case QuickDeadlineMsg:
+		cmd := m.quickDeadlineReached(msg)
+		return m, cmd
+
+	case PeersMsg:
+		m.peers = msg.Sessions
+
+	case OpenFilesMsg:
+		m.setOpenFiles(msg.Files)
+
+	case BackupStatsMsg:
+		m.backups[msg.Path] = backupInfo{kind: m.backups[msg.Path].kind, stats: msg.Stats, err: msg.Error}
+
+	case ExportMsg:
+		if msg.Error != nil {
+			m.statusMessage = m.tr.T("export.failed", msg.Error)
+		} else {
+			m.statusMessage = m.tr.N("export.done", msg.Count, msg.Path)
+		}
+
+	case NoteMsg:
+		switch {
+		case msg.Error != nil:
+			m.statusMessage = m.tr.T("note.failed", msg.Error)
+		case msg.Note == "":
+			delete(m.notes, msg.Path)
+			m.statusMessage = m.tr.T("note.removed")
+		default:
+			m.notes[msg.Path] = msg.Note
+			m.statusMessage = m.tr.T("note.saved")
+		}
+
 	case RenameMsg:
-		if msg.Success {
-			m.renameItemInTree(msg.OldPath, msg.NewPath)
+		if !msg.Success {
+			// Stay in rename mode so the name can be corrected
+			m.renameError = msg.Error.Error()
+			m.renameOverwrite = false
+			return m, nil
 		}
-		// Reset rename mode
-		m.renameMode = false
-		m.renameInput = ""
-		m.renameOrigPath = ""
+
+		m.apply(tree.Moved{From: msg.OldPath, To: msg.NewPath})
+		var cmd tea.Cmd
+		if note, ok := m.notes[msg.OldPath]; ok {
+			m.notes[msg.NewPath] = note
+			delete(m.notes, msg.OldPath)
+			cmd = moveNote(m.notesFile, msg.OldPath, msg.NewPath)
+		}
+
+		m.resetRename()
+		return m, cmd
 
 	case tea.KeyMsg:
+		// Force quit works in every mode, before any text input sees the key
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+		m.statusMessage = ""
+
+		if m.tutorialActive {
+			return m.handleTutorialKey(msg)
+		}
+
 		// Handle search mode input first
 		if m.searchMode {
 			switch msg.String() {
@@ -269,19 +977,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Exit search mode and clear search
 				m.searchMode = false
 				m.searchQuery = ""
-				m.cursor = 0
+				m.setCursor(0)
 				m.viewportTop = 0
 			case "backspace":
 				if len(m.searchQuery) > 0 {
 					m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
-					m.cursor = 0
+					m.setCursor(0)
 					m.viewportTop = 0
 				}
 			default:
 				// Append typed characters (only single printable characters)
 				if len(msg.String()) == 1 && msg.String()[0] >= 32 && msg.String()[0] <= 126 {
 					m.searchQuery += msg.String()
-					m.cursor = 0
+					m.setCursor(0)
 					m.viewportTop = 0
 				}
 			}
@@ -293,31 +1001,119 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch msg.String() {
 			case "enter":
 				// Confirm rename
-				return m, m.performRename()
+				newPath, err := validateRename(m.fsys, m.renameOrigPath, m.renameInput)
+				switch {
+				case errors.Is(err, errRenameTargetExists):
+					if m.renameOverwrite {
+						return m, m.performRename(newPath, true)
+					}
+					// Ask before clobbering, a second enter confirms
+					m.renameOverwrite = true
+					m.renameError = m.tr.T("rename.exists", filepath.Base(newPath))
+				case err != nil:
+					var invalid *renameValidationError
+					if errors.As(err, &invalid) {
+						m.renameError = m.tr.T(invalid.key, invalid.args...)
+					} else {
+						m.renameError = err.Error()
+					}
+				case newPath == m.renameOrigPath:
+					// Name unchanged, nothing to do
+					m.resetRename()
+				default:
+					return m, m.performRename(newPath, false)
+				}
 			case "esc":
 				// Cancel rename
-				m.renameMode = false
-				m.renameInput = ""
-				m.renameOrigPath = ""
+				m.resetRename()
 			case "backspace":
 				if len(m.renameInput) > 0 {
-					m.renameInput = m.renameInput[:len(m.renameInput)-1]
+					runes := []rune(m.renameInput)
+					m.renameInput = string(runes[:len(runes)-1])
 				}
+				m.renameError = ""
+				m.renameOverwrite = false
 			default:
 				// Append typed characters (only single printable characters)
 				if len(msg.String()) == 1 {
 					m.renameInput += msg.String()
 				}
+				m.renameError = ""
+				m.renameOverwrite = false
 			}
 			return m, nil
 		}
 
+		if m.exportMode {
+			return m.handleExportKey(msg)
+		}
+
+		if m.quitConfirm {
+			return m.handleQuitKey(msg)
+		}
+
+		if m.bigDelete != nil {
+			return m.handleBigDeleteKey(msg)
+		}
+
+		if m.noteMode {
+			return m.handleNoteKey(msg)
+		}
+
+		if m.queueView {
+			return m.handleQueueKey(msg)
+		}
+
+		if m.pagerOpen {
+			return m.handlePagerKey(msg)
+		}
+
+		if m.ownersView {
+			return m.handleOwnersKey(msg)
+		}
+
+		if m.columnsView {
+			return m.handleColumnsKey(msg)
+		}
+
+		if m.suggestionsView {
+			return m.handleSuggestionsKey(msg)
+		}
+
+		if m.reconcileView {
+			return m.handleReconcileKey(msg)
+		}
+
+		if m.reviewView {
+			return m.handleReviewKey(msg)
+		}
+
+		if m.helpView {
+			return m.handleHelpKey(msg)
+		}
+
+		if m.chooseMode != ChooseNone {
+			if cmd, handled := m.handleChooseKey(msg); handled {
+				return m, cmd
+			}
+		}
+
+		if m.readOnly && readOnlyKeys[msg.String()] {
+			m.statusMessage = m.tr.T("readonly.refused")
+			return m, nil
+		}
+
 		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
+		case "q":
+			cmd := m.requestQuit()
+			return m, cmd
+		case "x":
+			m.toggleQueued()
+		case "Q":
+			m.openQueue(false)
 		case "up", "k":
 			if m.cursor > 0 {
-				m.cursor--
+				m.setCursor(m.cursor - 1)
 				if m.visualMode {
 					m.updateVisualSelection()
 				}
@@ -326,15 +1122,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "down", "j":
 			maxItems := m.countVisibleItems()
 			if m.cursor < maxItems-1 {
-				m.cursor++
+				m.setCursor(m.cursor + 1)
 				if m.visualMode {
 					m.updateVisualSelection()
 				}
 				m.adjustViewport()
 			}
 		case "right", "l", "enter":
-			if path, isDir := m.getCurrentItem(); isDir && path != "" {
+			path, isDir := m.getCurrentItem()
+			if isDir && path != "" {
 				m.expanded[path] = true
+				if dir := m.tree.Find(path); dir != nil && dir.Pruned {
+					m.statusMessage = m.tr.T("memory.pruned")
+				}
+				return m, m.requestDirectoryLoad(path)
+			}
+			if path != "" && msg.String() == "enter" {
+				if m.readOnly {
+					m.statusMessage = m.tr.T("readonly.refused")
+					return m, nil
+				}
+				return m, m.openPager(path)
 			}
 		case "left", "h":
 			if path, isDir := m.getCurrentItem(); isDir && path != "" {
@@ -342,51 +1150,82 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "ctrl+s":
 			m.sortAsc = !m.sortAsc
+		case "m":
+			m.showDates = !m.showDates
+			if m.showDates {
+				m.addColumn(columnDate)
+			}
+		case "M":
+			// Switching format implies wanting to see it
+			m.showDates = true
+			m.addColumn(columnDate)
+			m.relativeDates = !m.relativeDates
+		case "a":
+			m.ageHeatmap = !m.ageHeatmap
+		case "c":
+			m.toggleCost()
+		case "u":
+			m.toggleDiskUsage()
+		case "p":
+			m.previewOpen = !m.previewOpen
+			m.previewRequested = previewRequest{}
+			m.adjustViewport()
 		case "s":
 			m.sortMode = (m.sortMode + 1) % 4
+			m.sortAsc = m.sortMode.DefaultAsc()
 		case "esc":
 			m.visualMode = false
 			m.visualStart = -1
+			m.visualStartPath = ""
 			m.selected = make(map[string]bool)
 			m.deletionMode = false
 			m.markedForDeletion = make(map[string]bool)
+			m.shredConfirm = false
 			// Clear search query
 			if m.searchQuery != "" {
 				m.searchQuery = ""
-				m.cursor = 0
+				m.setCursor(0)
 				m.viewportTop = 0
 			}
-		case "t":
+		case "t", " ":
 			if path, _ := m.getCurrentItem(); path != "" {
-				m.selected[path] = true
+				if m.selected[path] {
+					delete(m.selected, path)
+				} else {
+					m.selected[path] = true
+				}
 			}
 		case "d":
 			if m.deletionMode {
 				if len(m.markedForDeletion) > 0 {
-					return m, m.performBulkDeletion()
+					cmd := m.performBulkDeletion(false)
+					return m, cmd
 				}
-			} else {
+			} else if m.markForDeletion() {
 				m.deletionMode = true
-				m.markedForDeletion = make(map[string]bool)
-
-				if m.visualMode && len(m.selected) > 0 {
-					for path := range m.selected {
-						m.markedForDeletion[path] = true
-					}
-				} else {
-					if path, _ := m.getCurrentItem(); path != "" {
-						m.markedForDeletion[path] = true
-					}
-				}
+				return m, tea.Batch(m.loadOpenFiles(), m.findPeers())
+			}
+		case "S":
+			// Secure delete needs marked items and a second press once the
+			// limitations have been shown
+			if !m.deletionMode || len(m.markedForDeletion) == 0 {
+				break
 			}
+			if m.shredConfirm {
+				m.shredConfirm = false
+				cmd := m.performBulkDeletion(true)
+				return m, cmd
+			}
+			m.shredConfirm = true
+			m.shredCopyOnWrite = shred.CopyOnWrite(m.currentPath)
 		case "g":
-			m.cursor = 0
+			m.setCursor(0)
 			if m.visualMode {
 				m.updateVisualSelection()
 			}
 			m.adjustViewport()
 		case "G":
-			m.cursor = m.countVisibleItems() - 1
+			m.setCursor(m.countVisibleItems() - 1)
 			if m.visualMode {
 				m.updateVisualSelection()
 			}
@@ -397,7 +1236,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Already in rename mode, ignore
 			} else {
 				// Enter rename mode
-				if path, _ := m.getCurrentItem(); path != "" {
+				if path, _ := m.getCurrentItem(); path != "" && !m.kioskRefuses(path) {
 					m.renameMode = true
 					m.renameOrigPath = path
 					m.renameInput = filepath.Base(path) // Pre-fill with current name
@@ -405,36 +1244,135 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "v":
 			if m.visualMode {
+				// The range stays selected, so more can be added elsewhere
 				m.visualMode = false
 				m.visualStart = -1
-				m.selected = make(map[string]bool)
+				m.visualStartPath = ""
+				m.visualBase = nil
 			} else {
 				m.visualMode = true
 				m.visualStart = m.cursor
+				m.visualStartPath = m.cursorPath
+				m.visualBase = make(map[string]bool, len(m.selected))
+				for path := range m.selected {
+					m.visualBase[path] = true
+				}
 
 				if path, _ := m.getCurrentItem(); path != "" {
 					m.selected[path] = true
 				}
 			}
+		case "n":
+			m.startNote()
+		case "C":
+			return m, m.openSuggestions()
+		case "D":
+			return m, m.openReconcile()
+		case "R":
+			return m, m.rescan()
+		case "O":
+			m.openOwners()
+		case "L":
+			m.openColumns()
+		case "?":
+			m.helpView = true
+		case "V":
+			m.openReview()
 		case "/":
 			// Enter search mode
 			m.searchMode = true
 			m.searchQuery = ""
+		case "e":
+			if len(m.SelectedPaths()) == 0 {
+				m.statusMessage = m.tr.T("export.empty")
+			} else {
+				m.exportMode = true
+				m.exportInput = defaultExportFile
+				m.exportOverwrite = false
+			}
 		}
 	}
 	return m, nil
 }
 
+// markForDeletion marks what 'd' applies to: the selection if there is one,
+// otherwise the item under the cursor. Everything is resolved by path rather
+// than row index, and while a search filter is active only selected items that
+// are actually on screen are marked, so nothing hidden by the filter is deleted.
+// A selection the filter hides entirely marks nothing rather than falling back
+// to the cursor, which the user didn't pick. It reports whether anything was
+// marked.
+func (m *Model) markForDeletion() bool {
+	m.markedForDeletion = make(map[string]bool)
+
+	for path := range m.selected {
+		if m.searchQuery == "" || m.findIndexOfPath(path) >= 0 {
+			m.markedForDeletion[path] = true
+		}
+	}
+
+	if len(m.markedForDeletion) == 0 {
+		if len(m.selected) > 0 {
+			m.statusMessage = m.tr.T("delete.hidden")
+			return false
+		}
+		if m.cursorPath == "" {
+			return false
+		}
+		m.markedForDeletion[m.cursorPath] = true
+	}
+	return true
+}
+
+// setCursor moves the cursor to index and remembers the item under it.
+func (m *Model) setCursor(index int) {
+	m.cursor = index
+	m.cursorPath, _ = m.getCurrentItem()
+}
+
+// restoreCursor re-derives the cursor index from the remembered path after the
+// tree has changed, shifting the viewport by the same amount so the row stays
+// put on screen. If the item is gone the cursor stays on the same row.
+func (m *Model) restoreCursor() {
+	oldCursor, oldVisualStart := m.cursor, m.visualStart
+
+	if index := m.findIndexOfPath(m.cursorPath); index >= 0 {
+		m.viewportTop += index - m.cursor
+		m.cursor = index
+	} else {
+		maxItems := m.countVisibleItems()
+		if m.cursor >= maxItems {
+			m.cursor = maxItems - 1
+		}
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
+		m.cursorPath, _ = m.getCurrentItem()
+	}
+
+	if m.visualMode {
+		if index := m.findIndexOfPath(m.visualStartPath); index >= 0 {
+			m.visualStart = index
+		}
+		if m.cursor != oldCursor || m.visualStart != oldVisualStart {
+			m.updateVisualSelection()
+		}
+	}
+
+	m.adjustViewport()
+}
+
 // adjustViewport ensures the cursor stays visible within terminal bounds.
 func (m *Model) adjustViewport() {
-	visibleLines := m.height - 4
-	if visibleLines < 1 {
-		visibleLines = 10
-	}
+	visibleLines := m.treeLines()
 
 	if m.cursor >= m.viewportTop+visibleLines {
 		m.viewportTop = m.cursor - visibleLines + 1
 	}
+	// Directories pinned above the rows take lines from the bottom
+	for m.cursor >= m.viewportTop+visibleLines-len(m.stickyRows(m.viewportTop, visibleLines)) {
+		m.viewportTop++
+	}
 
 	if m.cursor < m.viewportTop {
 		m.viewportTop = m.cursor
@@ -445,75 +1383,142 @@ func (m *Model) adjustViewport() {
 	}
 }
 
-// sortDirectoryContents returns sorted copies of files and subdirectories.
-func (m Model) sortDirectoryContents(dir *scanner.DirInfo) ([]scanner.FileInfo, []scanner.DirInfo) {
-	files := make([]scanner.FileInfo, len(dir.Files))
-	copy(files, dir.Files)
-
-	subdirs := make([]scanner.DirInfo, len(dir.Subdirs))
-	copy(subdirs, dir.Subdirs)
+// treeLines is how many rows the tree gets between the header, the footer and
+// the preview pane.
+func (m Model) treeLines() int {
+	visibleLines := m.height - 4
+	if visibleLines < 1 {
+		visibleLines = 10
+	}
+	return visibleLines - m.previewLines(visibleLines)
+}
 
-	m.sortFiles(files)
-	m.sortDirs(subdirs)
+// sortDebounce is how long a directory keeps an outdated order while
+// scanning. Sizes land many times a second, and re-sorting a directory of
+// tens of thousands of entries on each would eat the scan's CPU.
+const sortDebounce = 500 * time.Millisecond
+
+// sortDirectoryContents returns the order to show the files and
+// subdirectories of dir in, as indexes into dir.Files and dir.Subdirs. It's
+// cached per directory until the sort changes or entries are added or
+// removed. When only sizes changed the cached order is marked stale, and
+// while scanning a stale order is kept for up to sortDebounce.
+func (m Model) sortDirectoryContents(dir *scanner.DirInfo) (files, subdirs []int) {
+	cached, ok := m.sortCache[dir.Path]
+	if ok && cached.mode == m.sortMode && cached.asc == m.sortAsc &&
+		len(cached.files) == len(dir.Files) && len(cached.subdirs) == len(dir.Subdirs) &&
+		(!cached.stale || m.isScanning && time.Since(cached.sorted) < sortDebounce) {
+		return cached.files, cached.subdirs
+	}
 
+	fileKeys := make([]sortKey, len(dir.Files))
+	for i, file := range dir.Files {
+		fileKeys[i] = newSortKey(file.Name, m.fileSize(file), file.ModTime, true)
+	}
+	dirKeys := make([]sortKey, len(dir.Subdirs))
+	for i := range dir.Subdirs {
+		subdir := &dir.Subdirs[i]
+		size := m.dirSize(subdir)
+		if cached, ok := m.cachedTotal(subdir); ok {
+			// Rows keep their place while a rescan gets to them
+			size = cached
+		}
+		dirKeys[i] = newSortKey(getBaseName(subdir.Path), size, subdir.ModTime, false)
+	}
+	files, subdirs = m.sortedOrder(fileKeys), m.sortedOrder(dirKeys)
+
+	if m.sortCache != nil {
+		m.sortCache[dir.Path] = &sortedContents{
+			mode:    m.sortMode,
+			asc:     m.sortAsc,
+			files:   files,
+			subdirs: subdirs,
+			sorted:  time.Now(),
+		}
+	}
 	return files, subdirs
 }
 
-func (m Model) sortFiles(files []scanner.FileInfo) {
-	sort.Slice(files, func(i, j int) bool {
-		var result bool
-		switch m.sortMode {
-		case SortByName:
-			result = strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
-		case SortBySize:
-			result = files[i].Size < files[j].Size
-		case SortByDate:
-			result = strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
-		case SortByType:
-			extI := getFileExtension(files[i].Name)
-			extJ := getFileExtension(files[j].Name)
-			if extI == extJ {
-				result = strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
-			} else {
-				result = strings.ToLower(extI) < strings.ToLower(extJ)
-			}
-		}
+// markSortStale notes that the sizes in the directory at path changed,
+// leaving its entries where they are.
+func (m *Model) markSortStale(path string) {
+	if cached, ok := m.sortCache[path]; ok {
+		cached.stale = true
+	}
+}
 
-		if !m.sortAsc {
-			result = !result
-		}
+// sortKey is what ordering compares of an entry, worked out once per sort
+// rather than on every comparison.
+type sortKey struct {
+	name    string
+	folded  string // Lowercased, for case-insensitive order
+	ext     string
+	extFold string
+	size    int64
+	modTime time.Time
+}
 
-		return result
+// newSortKey makes the key of an entry. Directories have no type, so their
+// extension is left empty and sorting by type orders them by name.
+func newSortKey(name string, size int64, modTime time.Time, isFile bool) sortKey {
+	key := sortKey{name: name, folded: strings.ToLower(name), size: size, modTime: modTime}
+	if isFile {
+		key.ext = getFileExtension(name)
+		key.extFold = strings.ToLower(key.ext)
+	}
+	return key
+}
+
+// sortedOrder returns the indexes of keys in the current sort order.
+func (m Model) sortedOrder(keys []sortKey) []int {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return m.compareKeys(&keys[a], &keys[b])
 	})
+	return order
 }
 
-func (m Model) sortDirs(subdirs []scanner.DirInfo) {
-	sort.Slice(subdirs, func(i, j int) bool {
-		var result bool
+// compareKeys orders two entries by the current sort key in the current
+// direction. Ties always fall back to ascending name, so reversing the
+// direction never reshuffles entries with equal keys. A directory's size is
+// its best-known recursive total.
+func (m Model) compareKeys(a, b *sortKey) int {
+	var c int
+	switch m.sortMode {
+	case SortByName:
+		return m.directed(compareFolded(a.folded, b.folded, a.name, b.name))
+	case SortBySize:
+		c = cmp.Compare(a.size, b.size)
+	case SortByDate:
+		c = a.modTime.Compare(b.modTime)
+	case SortByType:
+		c = compareFolded(a.extFold, b.extFold, a.ext, b.ext)
+	}
 
-		switch m.sortMode {
-		case SortByName:
-			nameI := getBaseName(subdirs[i].Path)
-			nameJ := getBaseName(subdirs[j].Path)
-			result = strings.ToLower(nameI) < strings.ToLower(nameJ)
-		case SortBySize:
-			result = subdirs[i].Size < subdirs[j].Size
-		case SortByDate:
-			nameI := getBaseName(subdirs[i].Path)
-			nameJ := getBaseName(subdirs[j].Path)
-			result = strings.ToLower(nameI) < strings.ToLower(nameJ)
-		case SortByType:
-			nameI := getBaseName(subdirs[i].Path)
-			nameJ := getBaseName(subdirs[j].Path)
-			result = strings.ToLower(nameI) < strings.ToLower(nameJ)
-		}
+	if c != 0 {
+		return m.directed(c)
+	}
+	return compareFolded(a.folded, b.folded, a.name, b.name)
+}
 
-		if !m.sortAsc {
-			result = !result
-		}
+// directed applies the current sort direction to an ascending comparison.
+func (m Model) directed(c int) int {
+	if m.sortAsc {
+		return c
+	}
+	return -c
+}
 
-		return result
-	})
+// compareFolded compares names case-insensitively by their lowercased
+// forms, using case only to break ties.
+func compareFolded(foldedA, foldedB, a, b string) int {
+	if c := strings.Compare(foldedA, foldedB); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
 }
 
 func getFileExtension(filename string) string {
@@ -524,28 +1529,17 @@ func getFileExtension(filename string) string {
 	return ""
 }
 
-func (m *Model) findDirectoryInTree(dir *scanner.DirInfo, targetPath string) *scanner.DirInfo {
-	if dir.Path == targetPath {
-		return dir
-	}
-
-	// Search in subdirectories
-	for i := range dir.Subdirs {
-		if found := m.findDirectoryInTree(&dir.Subdirs[i], targetPath); found != nil {
-			return found
-		}
-	}
-	return nil
-}
-
 func (m *Model) updateVisualSelection() {
-	// Clear selected and recalculate range
-	m.selected = make(map[string]bool)
+	// Recalculate the range on top of what was selected before it
+	m.selected = make(map[string]bool, len(m.visualBase))
+	for path := range m.visualBase {
+		m.selected[path] = true
+	}
 	start := min(m.visualStart, m.cursor)
 	end := max(m.visualStart, m.cursor)
 
 	for i := start; i <= end; i++ {
-		if path, _ := m.findItemAtIndex(m.rootDir, 0, 0, i); path != "" {
+		if path, _ := m.findItemAtIndex(m.tree.Root, 0, 0, i); path != "" {
 			m.selected[path] = true
 		}
 	}
@@ -573,17 +1567,21 @@ func fuzzyMatch(query, target string) bool {
 		return true
 	}
 
-	query = strings.ToLower(query)
-	target = strings.ToLower(target)
-
+	// Compared by rune, so a multi-byte character can't be matched by bytes
+	// from different characters. Invalid UTF-8 decodes as utf8.RuneError on
+	// both sides.
+	queryRunes := []rune(strings.ToLower(query))
 	queryIdx := 0
-	for i := 0; i < len(target) && queryIdx < len(query); i++ {
-		if target[i] == query[queryIdx] {
+	for _, r := range strings.ToLower(target) {
+		if r == queryRunes[queryIdx] {
 			queryIdx++
+			if queryIdx == len(queryRunes) {
+				return true
+			}
 		}
 	}
 
-	return queryIdx == len(query)
+	return false
 }
 
 // matchesSearch returns true if the file matches the search query.
@@ -622,19 +1620,31 @@ func (m Model) dirMatchesSearch(dir *scanner.DirInfo) bool {
 	return false
 }
 
-func (m Model) performBulkDeletion() tea.Cmd {
+// performBulkDeletion deletes everything marked, overwriting files first if
+// secure is set.
+func (m *Model) performBulkDeletion(secure bool) tea.Cmd {
 	pathsToDelete := make([]string, 0, len(m.markedForDeletion))
 
 	for path := range m.markedForDeletion {
 		pathsToDelete = append(pathsToDelete, path)
 	}
 
+	if secure {
+		return m.deleteLarge(pathsToDelete, 0, shred.RemoveAll)
+	}
+	return m.deleteLarge(pathsToDelete, 0, m.fsys.RemoveAll)
+}
+
+// deletePaths removes each path with remove and reports the outcome as a
+// BulkDeletionMsg.
+func deletePaths(pathsToDelete []string, remove func(string) error) tea.Cmd {
 	return func() tea.Msg {
+		start := time.Now()
 		var errors []error
 		var deletedPaths []string
 
 		for _, path := range pathsToDelete {
-			if err := os.RemoveAll(path); err != nil {
+			if err := remove(path); err != nil {
 				errors = append(errors, fmt.Errorf("%s: %w", path, err))
 			} else {
 				deletedPaths = append(deletedPaths, path)
@@ -646,134 +1656,132 @@ func (m Model) performBulkDeletion() tea.Cmd {
 			SuccessCount: len(deletedPaths),
 			ErrorCount:   len(errors),
 			Errors:       errors,
+			Took:         time.Since(start),
 		}
 	}
 }
 
-func (m Model) performRename() tea.Cmd {
+func (m Model) performRename(newPath string, overwrite bool) tea.Cmd {
 	oldPath := m.renameOrigPath
-	parentDir := filepath.Dir(oldPath)
-	newName := strings.TrimSpace(m.renameInput)
-	newPath := filepath.Join(parentDir, newName)
+	fsys := m.fsys
 
 	return func() tea.Msg {
-		err := os.Rename(oldPath, newPath)
+		err := fsys.Rename(oldPath, newPath)
 		return RenameMsg{
-			OldPath: oldPath,
-			NewPath: newPath,
-			Success: err == nil,
-			Error:   err,
+			OldPath:   oldPath,
+			NewPath:   newPath,
+			Overwrite: overwrite,
+			Success:   err == nil,
+			Error:     err,
 		}
 	}
 }
 
-func (m *Model) removeItemFromTree(targetPath string) {
-	parentPath := filepath.Dir(targetPath)
+// resetRename leaves rename mode and clears its state.
+func (m *Model) resetRename() {
+	m.renameMode = false
+	m.renameInput = ""
+	m.renameOrigPath = ""
+	m.renameError = ""
+	m.renameOverwrite = false
+}
 
-	if parent := m.findDirectoryInTree(m.rootDir, parentPath); parent != nil {
-		for i, file := range parent.Files {
-			if filepath.Join(parent.Path, file.Name) == targetPath {
-				parent.Files = append(parent.Files[:i], parent.Files[i+1:]...)
-				parent.Size -= file.Size
-				break
-			}
-		}
+// validateRename checks a proposed new name for oldPath and returns the path it
+// would be renamed to. The name must be non-empty and a single path element;
+// errRenameTargetExists is returned alongside the path if it is already taken.
+func validateRename(fsys vfs.FS, oldPath, newName string) (string, error) {
+	newName = strings.TrimSpace(newName)
+
+	switch {
+	case newName == "":
+		return "", &renameValidationError{key: "rename.empty"}
+	case newName == "." || newName == "..":
+		return "", &renameValidationError{key: "rename.invalid", args: []any{newName}}
+	case strings.ContainsRune(newName, '/') || strings.ContainsRune(newName, filepath.Separator):
+		return "", &renameValidationError{key: "rename.separator"}
+	case strings.ContainsRune(newName, 0):
+		return "", &renameValidationError{key: "rename.nul"}
+	case !utf8.ValidString(newName):
+		return "", &renameValidationError{key: "rename.utf8"}
+	case strings.ContainsFunc(newName, unicode.IsControl):
+		// Newlines and escape sequences in names break listings and scripts
+		return "", &renameValidationError{key: "rename.control"}
+	}
 
-		for i, subdir := range parent.Subdirs {
-			if subdir.Path == targetPath {
-				parent.Subdirs = append(parent.Subdirs[:i], parent.Subdirs[i+1:]...)
-				parent.Size -= subdir.Size
-				parent.SubdirCount--
-				break
-			}
-		}
+	newPath := filepath.Join(filepath.Dir(oldPath), newName)
+	if newPath == oldPath {
+		return newPath, nil
+	}
 
-		m.updateParentSizes(parentPath)
+	if targetInfo, err := fsys.Lstat(newPath); err == nil {
+		// A case-only rename on a case-insensitive filesystem finds the
+		// original file, which isn't a conflict
+		if origInfo, err := fsys.Lstat(oldPath); err == nil && fsys.SameFile(origInfo, targetInfo) {
+			return newPath, nil
+		}
+		return newPath, errRenameTargetExists
 	}
-}
 
-func (m *Model) renameItemInTree(oldPath, newPath string) {
-	parentPath := filepath.Dir(oldPath)
-	oldName := filepath.Base(oldPath)
-	newName := filepath.Base(newPath)
+	return newPath, nil
+}
 
-	if parent := m.findDirectoryInTree(m.rootDir, parentPath); parent != nil {
-		// Update file
-		for i := range parent.Files {
-			if parent.Files[i].Name == oldName {
-				parent.Files[i].Name = newName
-				return
-			}
-		}
+// requestDirectoryLoad loads an unloaded placeholder the user has expanded.
+// While a scan is running the scanner is asked to take it ahead of the
+// background walk; otherwise it is read directly. Directories already loading
+// are left alone.
+func (m *Model) requestDirectoryLoad(path string) tea.Cmd {
+	dir := m.tree.Find(path)
+	if dir == nil || dir.IsLoaded || dir.IsLoading {
+		return nil
+	}
 
-		// Update subdirectory
-		for i := range parent.Subdirs {
-			if filepath.Base(parent.Subdirs[i].Path) == oldName {
-				parent.Subdirs[i].Path = newPath
-				// Update directoryMap
-				if dirInfo, exists := m.directoryMap[oldPath]; exists {
-					delete(m.directoryMap, oldPath)
-					m.directoryMap[newPath] = dirInfo
-					dirInfo.Path = newPath
-				}
-				// Update expanded map
-				if m.expanded[oldPath] {
-					delete(m.expanded, oldPath)
-					m.expanded[newPath] = true
-				}
-				return
-			}
+	var cmd tea.Cmd
+	if m.isScanning && m.streamingScanner != nil {
+		// Already claimed paths are in flight and arrive with the stream
+		if !m.streamingScanner.RequestScan(path) {
+			return nil
 		}
+	} else {
+		cmd = loadDirectory(m.fsys, path, m.generation)
 	}
-}
 
-func (m *Model) updateParentSizes(path string) {
-	for path != "/" && path != "." {
-		if dir := m.findDirectoryInTree(m.rootDir, path); dir != nil {
-			var newSize int64
-			for _, file := range dir.Files {
-				newSize += file.Size
-			}
+	dir.IsLoading = true
+	m.loadingDirs[path] = true
 
-			for _, subdir := range dir.Subdirs {
-				newSize += subdir.Size
-			}
-
-			dir.Size = newSize
-		}
-		path = filepath.Dir(path)
+	if !m.spinnerActive {
+		m.spinnerActive = true
+		cmd = tea.Batch(cmd, spinnerTick())
 	}
+	return cmd
 }
 
-func (m *Model) integrateDirectoryIntoTree(dirInfo *scanner.DirInfo) {
-	parentPath := filepath.Dir(dirInfo.Path)
-
-	// Find the parent directory in the tree
-	parentDir := m.findDirectoryInTree(m.rootDir, parentPath)
-	if parentDir != nil {
-		// Find the corresponding subdir entry and replace it with the loaded data
-		for i, subdir := range parentDir.Subdirs {
-			if subdir.Path == dirInfo.Path {
-				parentDir.Subdirs[i] = *dirInfo
-				// Update parent size to include this child's size
-				m.updateParentSizesFromChild(parentPath, dirInfo.Size)
-				break
-			}
-		}
+// loadDirectory reads a single directory in the background.
+func loadDirectory(fsys vfs.FS, path string, generation int) tea.Cmd {
+	return func() tea.Msg {
+		dirInfo, err := scanner.ScanDirectoryFS(fsys, path)
+		return DirectoryLoadedMsg{Path: path, DirInfo: dirInfo, Error: err, Generation: generation}
 	}
 }
 
-func (m *Model) updateParentSizesFromChild(parentPath string, childSize int64) {
-	for parentPath != "/" && parentPath != "." {
-		if dir := m.findDirectoryInTree(m.rootDir, parentPath); dir != nil {
-			dir.Size += childSize
-		}
-		parentPath = filepath.Dir(parentPath)
+func spinnerTick() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	})
+}
+
+// isRoot reports whether path is the top of its filesystem: "/" on Unix, or a
+// drive or UNC share root such as C:\ or \\server\share\ on Windows. Relative
+// paths bottom out at ".".
+func isRoot(path string) bool {
+	if path == "" || path == "." {
+		return true
 	}
+
+	rest := strings.TrimPrefix(path, filepath.VolumeName(path))
+	return rest == "" || rest == string(filepath.Separator) || filepath.Dir(path) == path
 }
 
 // View renders the current state
 func (m Model) View() string {
 	return m.ViewTree()
 }
-
@@ -0,0 +1,259 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/scanner"
+	"github.com/corpeningc/dua/internal/vfs"
+)
+
+func TestIsRoot(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"", true},
+		{".", true},
+		{"dir", false},
+		{"dir/sub", false},
+	}
+	if runtime.GOOS == "windows" {
+		tests = append(tests, []struct {
+			path string
+			want bool
+		}{
+			{`C:\`, true},
+			{`C:`, true},
+			{`c:\`, true},
+			{`C:\Users`, false},
+			{`C:\Users\`, false},
+			{`\\server\share\`, true},
+			{`\\server\share`, true},
+			{`\\server\share\dir`, false},
+			{`\\?\C:\`, true},
+			{`\\?\C:\Users`, false},
+			{`\`, true},
+		}...)
+	} else {
+		tests = append(tests, []struct {
+			path string
+			want bool
+		}{
+			{"/", true},
+			{"/home", false},
+			{"/home/", false},
+			{"//", false},
+			// Only names elsewhere
+			{`C:\`, false},
+			{`C:`, false},
+			{`\\server\share\`, false},
+			{`\\?\C:\`, false},
+		}...)
+	}
+
+	for _, test := range tests {
+		if got := isRoot(test.path); got != test.want {
+			t.Errorf("isRoot(%q) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+// TestMarkForDeletionFiltered marks under a search filter, which has to mark
+// what's highlighted or selected on screen and never what the filter hides.
+func TestMarkForDeletionFiltered(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []string
+		marked []string
+	}{
+		{"highlighted row", []string{"/", "b", "u", "enter", "j", "d"}, []string{"/proj/build"}},
+		{"visible part of the selection", []string{"j", "t", "j", "j", "t", "/", "b", "u", "enter", "d"}, []string{"/proj/build"}},
+		{"selection all hidden", []string{"j", "t", "/", "b", "u", "enter", "j", "d"}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := press(t, scanned(t), test.keys...)
+			var marked []string
+			for path := range m.markedForDeletion {
+				marked = append(marked, path)
+			}
+			if !slices.Equal(marked, test.marked) {
+				t.Fatalf("marked %v, want %v", marked, test.marked)
+			}
+			if test.marked == nil {
+				if m.deletionMode || m.statusMessage != m.tr.T("delete.hidden") {
+					t.Errorf("deleting with nothing marked, status %q", m.statusMessage)
+				}
+				return
+			}
+			if highlighted, _ := m.getCurrentItem(); len(m.selected) == 0 && highlighted != marked[0] {
+				t.Errorf("marked %s, the highlighted row is %s", marked[0], highlighted)
+			}
+		})
+	}
+}
+
+// BenchmarkStreamingSort streams 30,000 subdirectories of the root, sorted
+// by size, rendering after every update as a busy scan does. Each arriving
+// size can reorder the root, which is what re-sorting debounces. Every
+// render counts the root's rows for the scrollbar, so an iteration takes
+// a while: run it with -benchtime 1x.
+func BenchmarkStreamingSort(b *testing.B) {
+	const subdirs = 30_000
+	root := &scanner.DirInfo{Path: "/bench", IsLoaded: true, SubdirCount: subdirs, PendingDirs: subdirs}
+	for i := range subdirs {
+		root.Subdirs = append(root.Subdirs, scanner.DirInfo{Path: fmt.Sprintf("/bench/d%05d", i), PendingDirs: 1})
+	}
+
+	for b.Loop() {
+		b.StopTimer()
+		m := NewStreamingModel("/bench", config.Default())
+		m.sortMode = SortBySize
+		m.sortAsc = SortBySize.DefaultAsc()
+		next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m = next.(Model)
+		scanned := *root
+		scanned.Subdirs = slices.Clone(root.Subdirs)
+		m = update(m, &scanned)
+		b.StartTimer()
+
+		for i := range subdirs {
+			// Sizes that land all over the order
+			size := int64(i*7919) % 1_000_003
+			m = update(m, &scanner.DirInfo{
+				Path:      fmt.Sprintf("/bench/d%05d", i),
+				Files:     []scanner.FileInfo{{Name: "f", Size: size}},
+				IsLoaded:  true,
+				Size:      size,
+				FileCount: 1,
+			})
+			_ = m.View()
+		}
+	}
+}
+
+// update delivers dir as the scanner streams it.
+func update(m Model, dir *scanner.DirInfo) Model {
+	next, _ := m.Update(StreamingUpdateMsg{
+		Updates:    []scanner.StreamingUpdate{{Path: dir.Path, DirInfo: dir, FileCount: dir.FileCount, DirCount: dir.SubdirCount}},
+		Generation: m.generation,
+	})
+	return next.(Model)
+}
+
+// TestQuitAfterCleanup deletes the queue on the way out, which may only
+// quit once everything went.
+func TestQuitAfterCleanup(t *testing.T) {
+	failed := BulkDeletionMsg{
+		DeletedPaths: []string{"/proj/docs"},
+		SuccessCount: 1,
+		ErrorCount:   1,
+		Errors:       []error{errors.New("/proj/build: permission denied")},
+	}
+	tests := []struct {
+		name  string
+		msg   BulkDeletionMsg
+		quits bool
+	}{
+		{"all deleted", BulkDeletionMsg{DeletedPaths: []string{"/proj/build", "/proj/docs"}, SuccessCount: 2}, true},
+		{"some failed", failed, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := scanned(t)
+			m.queue = map[string]bool{"/proj/build": true, "/proj/docs": true}
+			m.openQueue(true)
+			m.queueConfirm = false
+			m.quitAfterCleanup = true
+
+			next, cmd := m.Update(test.msg)
+			m = next.(Model)
+			if quits(cmd) != test.quits {
+				t.Fatalf("quit %v, want %v", quits(cmd), test.quits)
+			}
+			if test.quits {
+				return
+			}
+			if m.quitAfterCleanup || !m.queueView || !m.queue["/proj/build"] {
+				t.Error("left the queue of what couldn't be deleted")
+			}
+			if !strings.Contains(m.statusMessage, "permission denied") {
+				t.Errorf("status %q doesn't show the error", m.statusMessage)
+			}
+		})
+	}
+}
+
+// TestFailedChanges has the filesystem refuse a deletion and a rename,
+// which must be reported and leave the tree as it was.
+func TestFailedChanges(t *testing.T) {
+	fsys := fixtureFS()
+	// Deep inside, so nothing of /proj/build may go
+	fsys.Fail(vfs.OpRemove, "/proj/build/app.debug", fs.ErrPermission)
+	fsys.Fail(vfs.OpRename, "/proj/docs", fs.ErrPermission)
+	m := scannedFS(t, fsys)
+
+	deleted, ok := deletePaths([]string{"/proj/build", "/proj/src"}, fsys.RemoveAll)().(BulkDeletionMsg)
+	if !ok || deleted.ErrorCount != 1 || !errors.Is(deleted.Errors[0], fs.ErrPermission) || !slices.Equal(deleted.DeletedPaths, []string{"/proj/src"}) {
+		t.Fatalf("deletion reported %+v, want /proj/src deleted and permission denied on /proj/build", deleted)
+	}
+	next, _ := m.Update(deleted)
+	m = next.(Model)
+	if m.tree.Find("/proj/build") == nil || !fsys.Exists("/proj/build/app") {
+		t.Error("/proj/build is gone after failing to delete it")
+	}
+	if m.tree.Find("/proj/src") != nil {
+		t.Error("/proj/src is still in the tree after deleting it")
+	}
+	if !strings.Contains(m.statusMessage, "permission denied") {
+		t.Errorf("status %q doesn't show the error", m.statusMessage)
+	}
+
+	m.renameMode = true
+	m.renameOrigPath = "/proj/docs"
+	renamed, ok := m.performRename("/proj/manual", false)().(RenameMsg)
+	if !ok || renamed.Success || !errors.Is(renamed.Error, fs.ErrPermission) {
+		t.Fatalf("rename reported %+v, want permission denied", renamed)
+	}
+	next, _ = m.Update(renamed)
+	m = next.(Model)
+	if m.tree.Find("/proj/docs") == nil || m.tree.Find("/proj/manual") != nil {
+		t.Error("the tree changed after failing to rename /proj/docs")
+	}
+	if !m.renameMode || !strings.Contains(m.renameError, "permission denied") {
+		t.Errorf("left rename mode %v with error %q, want to stay and show the error", !m.renameMode, m.renameError)
+	}
+}
+
+// TestRenameInput edits a name holding multibyte characters, which
+// backspace must take off whole, and rejects names that aren't UTF-8.
+func TestRenameInput(t *testing.T) {
+	m := scanned(t)
+	m.renameMode = true
+	m.renameOrigPath = "/proj/docs"
+	m.renameInput = "naïve—ü"
+
+	m = press(t, m, "backspace")
+	if m.renameInput != "naïve—" || !utf8.ValidString(m.renameInput) {
+		t.Errorf("after one backspace the input is %q, want %q", m.renameInput, "naïve—")
+	}
+	m = press(t, m, "backspace", "backspace", "backspace")
+	if m.renameInput != "naï" {
+		t.Errorf("after four backspaces the input is %q, want %q", m.renameInput, "naï")
+	}
+
+	_, err := validateRename(vfs.NewMem(), "/proj/docs", "bad\xffname")
+	var invalid *renameValidationError
+	if !errors.As(err, &invalid) || invalid.key != "rename.utf8" {
+		t.Errorf("renaming to invalid UTF-8 gave %v, want it rejected", err)
+	}
+}
@@ -0,0 +1,15 @@
+package ui
+
+// duplicateBadge marks mount points showing data counted elsewhere in the
+// tree, such as bind mounts.
+const duplicateBadge = "🔗"
+
+// duplicateLine explains a duplicate mount in the preview pane, or returns
+// "" for anything else.
+func (m Model) duplicateLine(path string) string {
+	dir := m.tree.Find(path)
+	if dir == nil || dir.DuplicateOf == "" {
+		return ""
+	}
+	return m.tr.T("preview.duplicate", duplicateBadge, dir.DuplicateOf)
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/notes"
+)
+
+// noteBadge marks rows that have a note attached.
+const noteBadge = "📝"
+
+// NoteMsg reports the result of saving a note.
+type NoteMsg struct {
+	Path  string
+	Note  string
+	Error error
+}
+
+// SetNotes shows the given notes by path and saves edits to the state
+// database at file.
+func (m *Model) SetNotes(file string, loaded map[string]string) {
+	m.notesFile = file
+	m.notes = loaded
+}
+
+// startNote opens the note editor for the item under the cursor, starting
+// from its current note.
+func (m *Model) startNote() {
+	path, _ := m.getCurrentItem()
+	if path == "" {
+		return
+	}
+	if m.notesFile == "" {
+		m.statusMessage = m.tr.T("note.unavailable")
+		return
+	}
+	m.noteMode = true
+	m.notePath = path
+	m.noteInput = m.notes[path]
+}
+
+func (m Model) handleNoteKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.noteMode = false
+		if m.noteInput == m.notes[m.notePath] {
+			return m, nil
+		}
+		return m, saveNote(m.notesFile, m.notePath, m.noteInput)
+	case "esc":
+		m.noteMode = false
+	case "backspace":
+		if len(m.noteInput) > 0 {
+			runes := []rune(m.noteInput)
+			m.noteInput = string(runes[:len(runes)-1])
+		}
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			m.noteInput += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+// moveNote carries a note over to a renamed item in the background.
+func moveNote(file, oldPath, newPath string) tea.Cmd {
+	return func() tea.Msg {
+		if err := notes.Move(file, oldPath, newPath); err != nil {
+			return NoteMsg{Path: newPath, Note: "", Error: err}
+		}
+		return nil
+	}
+}
+
+// saveNote writes a note in the background. An empty note removes it.
+func saveNote(file, path, note string) tea.Cmd {
+	return func() tea.Msg {
+		err := notes.Set(file, path, note)
+		return NoteMsg{Path: path, Note: note, Error: err}
+	}
+}
@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/notify"
+)
+
+// notifyDone announces the end of a scan or deletion that took took with a
+// desktop notification, if it took long enough for the user to have
+// switched away and the terminal says they have. Terminals that don't
+// report focus never get one.
+func (m Model) notifyDone(took time.Duration, body string) tea.Cmd {
+	if m.notifyAfter <= 0 || took < m.notifyAfter || !m.unfocused {
+		return nil
+	}
+	title := m.tr.T("notify.title")
+	return func() tea.Msg {
+		// Without a notification tool there's just no notification
+		_ = notify.Send(title, body)
+		return nil
+	}
+}
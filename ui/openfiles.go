@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/openfiles"
+)
+
+// openBadge marks files some process has open.
+const openBadge = "🔒"
+
+// openWarningProcesses is how many holders the deletion warning names.
+const openWarningProcesses = 3
+
+// OpenFilesMsg carries the files processes have open, read in the
+// background.
+type OpenFilesMsg struct {
+	Files []openfiles.File
+}
+
+// loadOpenFiles lists the open files once the scan is done and again before
+// deleting, as they change all the time. Trees loaded from a file may come
+// from another machine, so they're left alone.
+func (m Model) loadOpenFiles() tea.Cmd {
+	if m.readOnly {
+		return nil
+	}
+	return func() tea.Msg {
+		files, err := openfiles.Scan()
+		if err != nil {
+			return nil
+		}
+		return OpenFilesMsg{Files: files}
+	}
+}
+
+// setOpenFiles keeps the holders of each open file that still has a name.
+func (m *Model) setOpenFiles(files []openfiles.File) {
+	m.openFiles = make(map[string][]openfiles.Process)
+	for _, file := range files {
+		if !file.Deleted {
+			m.openFiles[file.Path] = file.Holders
+		}
+	}
+}
+
+// openHolders lists the processes holding open files at or below the marked
+// paths, and how many such files there are.
+func (m Model) openHolders(paths map[string]bool) (int, []openfiles.Process) {
+	var count int
+	var holders []openfiles.Process
+	for path, processes := range m.openFiles {
+		if !m.underAny(path, paths) {
+			continue
+		}
+		count++
+		for _, process := range processes {
+			if !slices.Contains(holders, process) {
+				holders = append(holders, process)
+			}
+		}
+	}
+	slices.SortFunc(holders, func(a, b openfiles.Process) int {
+		return a.PID - b.PID
+	})
+	return count, holders
+}
+
+// underAny reports whether path is one of paths or inside one of them.
+func (m Model) underAny(path string, paths map[string]bool) bool {
+	for ; ; path = filepath.Dir(path) {
+		if paths[path] {
+			return true
+		}
+		if isRoot(path) {
+			return false
+		}
+	}
+}
+
+// openWarning says which processes hold marked files open, as deleting them
+// won't free their space until those exit. It's empty if none do.
+func (m Model) openWarning() string {
+	count, holders := m.openHolders(m.markedForDeletion)
+	if count == 0 {
+		return ""
+	}
+	var names []string
+	for _, process := range holders[:min(len(holders), openWarningProcesses)] {
+		names = append(names, process.String())
+	}
+	list := strings.Join(names, ", ")
+	if len(holders) > openWarningProcesses {
+		list = m.tr.T("open.more", list, len(holders)-openWarningProcesses)
+	}
+	return m.tr.N("open.warning", count, list)
+}
+
+// openLine names the processes holding the file at path open in the preview
+// pane, or is empty if none do.
+func (m Model) openLine(path string) string {
+	holders := m.openFiles[path]
+	if len(holders) == 0 {
+		return ""
+	}
+	var names []string
+	for _, process := range holders {
+		names = append(names, process.String())
+	}
+	return m.tr.T("preview.open", openBadge, strings.Join(names, ", "))
+}
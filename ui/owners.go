@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/owners"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// ownerUsage is one line of the owner breakdown: everything attributed to
+// the same owners.
+type ownerUsage struct {
+	owners string
+	bytes  int64
+	files  int
+}
+
+// SetOwners labels directories with their owners from rules and enables the
+// owner breakdown.
+func (m *Model) SetOwners(rules *owners.Rules) {
+	m.owners = rules
+}
+
+// ownersOf returns who owns path, joined by spaces, or "" if nobody does.
+func (m Model) ownersOf(path string, isDir bool) string {
+	rel, err := filepath.Rel(m.currentPath, path)
+	if err != nil {
+		return ""
+	}
+	if rel == "." {
+		rel = ""
+	}
+	return strings.Join(m.owners.Match(filepath.ToSlash(rel), isDir), " ")
+}
+
+// ownerLabel is shown after a directory's name when its owners differ from
+// its parent's, so a subtree handed to another team stands out without
+// repeating the label on every row.
+func (m Model) ownerLabel(path string) string {
+	if m.owners == nil {
+		return ""
+	}
+	label := m.ownersOf(path, true)
+	if path != m.currentPath && label == m.ownersOf(filepath.Dir(path), true) {
+		return ""
+	}
+	return label
+}
+
+// ownerBreakdown totals file sizes by owner, largest first. Files matched
+// by a rule with several owners count towards that group as a whole, so the
+// totals add up to the scanned size.
+func (m Model) ownerBreakdown() []ownerUsage {
+	usage := make(map[string]*ownerUsage)
+	var walk func(dir *scanner.DirInfo)
+	walk = func(dir *scanner.DirInfo) {
+		for _, file := range dir.Files {
+			key := m.ownersOf(filepath.Join(dir.Path, file.Name), false)
+			entry := usage[key]
+			if entry == nil {
+				entry = &ownerUsage{owners: key}
+				usage[key] = entry
+			}
+			entry.bytes += m.countedSize(file)
+			entry.files++
+		}
+		for i := range dir.Subdirs {
+			walk(&dir.Subdirs[i])
+		}
+	}
+	walk(m.tree.Root)
+
+	breakdown := make([]ownerUsage, 0, len(usage))
+	for _, entry := range usage {
+		breakdown = append(breakdown, *entry)
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].bytes != breakdown[j].bytes {
+			return breakdown[i].bytes > breakdown[j].bytes
+		}
+		return breakdown[i].owners < breakdown[j].owners
+	})
+	return breakdown
+}
+
+// openOwners shows the owner breakdown, if there are rules to build it from.
+func (m *Model) openOwners() {
+	if m.owners == nil {
+		m.statusMessage = m.tr.T("owners.none_loaded")
+		return
+	}
+	m.ownersView = true
+	m.ownersTop = 0
+	m.ownerUsage = m.ownerBreakdown()
+}
+
+func (m Model) handleOwnersKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.ownersTop > 0 {
+			m.ownersTop--
+		}
+	case "down", "j":
+		if m.ownersTop < len(m.ownerUsage)-1 {
+			m.ownersTop++
+		}
+	case "esc", "q", "O":
+		m.ownersView = false
+		m.ownerUsage = nil
+	}
+	return m, nil
+}
+
+// renderOwners draws the owner breakdown in at most height lines.
+func (m Model) renderOwners(height int) string {
+	var b strings.Builder
+
+	var total int64
+	for _, entry := range m.ownerUsage {
+		total += entry.bytes
+	}
+	b.WriteString(m.tr.N("owners.title", len(m.ownerUsage), formatSize(total)) + "\n\n")
+
+	visible := max(height-2, 1)
+	for i := m.ownersTop; i < len(m.ownerUsage) && i < m.ownersTop+visible; i++ {
+		entry := m.ownerUsage[i]
+		name := entry.owners
+		if name == "" {
+			name = m.tr.T("owners.unowned")
+		}
+		share := 0.0
+		if total > 0 {
+			share = float64(entry.bytes) / float64(total) * 100
+		}
+		line := fmt.Sprintf("%-30s %5.1f%%  %s", name, share, m.tr.N("owners.files", entry.files))
+		b.WriteString(m.renderRow(line, fileStyle, sizeCells(entry.bytes)) + "\n")
+	}
+	return b.String()
+}
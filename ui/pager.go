@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/corpeningc/dua/internal/highlight"
+	"github.com/muesli/termenv"
+)
+
+// pagerTabWidth is how many spaces a tab expands to in the pager.
+const pagerTabWidth = 4
+
+// pagerView is a text file loaded into the pager.
+type pagerView struct {
+	path      string
+	loaded    bool
+	lines     []string
+	size      int64
+	truncated bool // Only the start of the file was read
+	binary    bool
+	err       error
+}
+
+// PagerMsg carries a file loaded for the pager.
+type PagerMsg struct {
+	view pagerView
+}
+
+// openPager shows the file at path in the pager.
+func (m *Model) openPager(path string) tea.Cmd {
+	m.pagerOpen = true
+	m.pager = pagerView{path: path}
+	m.pagerTop = 0
+	return loadPager(path, m.pagerBytes)
+}
+
+// loadPager reads up to limit bytes of the file and highlights them, unless
+// they look binary.
+func loadPager(path string, limit int64) tea.Cmd {
+	return func() tea.Msg {
+		view := pagerView{path: path, loaded: true}
+
+		f, err := os.Open(path)
+		if err != nil {
+			view.err = err
+			return PagerMsg{view: view}
+		}
+		defer f.Close()
+
+		if info, err := f.Stat(); err == nil {
+			view.size = info.Size()
+		}
+
+		data, err := io.ReadAll(io.LimitReader(f, limit))
+		if err != nil {
+			view.err = err
+			return PagerMsg{view: view}
+		}
+		view.truncated = int64(len(data)) < view.size
+
+		if isBinary(data, view.truncated) {
+			view.binary = true
+			return PagerMsg{view: view}
+		}
+
+		view.lines = highlight.Lines(filepath.Base(path), sanitizeText(data), pagerFormatter())
+		return PagerMsg{view: view}
+	}
+}
+
+// isBinary guesses whether data is anything but text: it holds a NUL byte or
+// isn't valid UTF-8. A rune cut off at the end of a truncated read is allowed.
+func isBinary(data []byte, truncated bool) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	if truncated {
+		for i := 0; i < utf8.UTFMax-1 && len(data) > 0; i++ {
+			if r, _ := utf8.DecodeLastRune(data); r != utf8.RuneError {
+				break
+			}
+			data = data[:len(data)-1]
+		}
+	}
+	return !utf8.Valid(data)
+}
+
+// sanitizeText expands tabs and replaces other control characters, which
+// would otherwise reach the terminal as escape sequences or throw off the
+// layout.
+func sanitizeText(data []byte) string {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\t", strings.Repeat(" ", pagerTabWidth))
+	return strings.Map(func(r rune) rune {
+		if r != '\n' && unicode.IsControl(r) {
+			return '·'
+		}
+		return r
+	}, text)
+}
+
+// pagerFormatter picks the chroma formatter matching the terminal's colors.
+func pagerFormatter() string {
+	switch lipgloss.ColorProfile() {
+	case termenv.TrueColor:
+		return "terminal16m"
+	case termenv.ANSI256:
+		return "terminal256"
+	case termenv.ANSI:
+		return "terminal16"
+	}
+	return "noop"
+}
+
+// pagerPage is how many file lines fit under the pager's title.
+func (m Model) pagerPage() int {
+	return max(m.height-5, 1)
+}
+
+func (m Model) handlePagerKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	last := max(len(m.pager.lines)-m.pagerPage(), 0)
+
+	switch msg.String() {
+	case "up", "k":
+		m.pagerTop--
+	case "down", "j":
+		m.pagerTop++
+	case "pgup", "b", "ctrl+u":
+		m.pagerTop -= m.pagerPage()
+	case "pgdown", "f", " ", "ctrl+d":
+		m.pagerTop += m.pagerPage()
+	case "g", "home":
+		m.pagerTop = 0
+	case "G", "end":
+		m.pagerTop = last
+	case "esc", "q", "enter":
+		m.pagerOpen = false
+	}
+	m.pagerTop = max(min(m.pagerTop, last), 0)
+	return m, nil
+}
+
+// renderPager shows the visible part of the paged file in the given height.
+func (m Model) renderPager(height int) string {
+	var b strings.Builder
+
+	view := m.pager
+	title := previewTitleStyle.Render(filepath.Base(view.path)) + " • " + formatSize(view.size)
+	if view.truncated {
+		title += " • " + m.tr.T("pager.truncated", formatSize(m.pagerBytes))
+	}
+	b.WriteString(ansi.Truncate(title, m.width, "…") + "\n")
+
+	switch {
+	case !view.loaded:
+		b.WriteString(m.tr.T("row.loading") + "…\n")
+	case view.err != nil:
+		b.WriteString(m.tr.T("preview.error", view.err) + "\n")
+	case view.binary:
+		b.WriteString(m.tr.T("pager.binary") + "\n")
+	default:
+		end := min(m.pagerTop+max(height-1, 1), len(view.lines))
+		for _, line := range view.lines[m.pagerTop:end] {
+			// Cutting a line can drop the reset at the end of its last token
+			b.WriteString(ansi.Truncate(line, m.width, "") + ansi.ResetStyle + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// progressBarWidth is how many cells the header's progress bar spans.
+const progressBarWidth = 20
+
+// precount is a count of the tree run alongside the scan, which the scan's
+// progress is measured against.
+type precount struct {
+	count  scanner.Count
+	done   bool
+	cancel context.CancelFunc
+}
+
+// PrecountMsg delivers the count of the tree.
+type PrecountMsg struct {
+	Count      scanner.Count
+	Err        error
+	Generation int
+}
+
+// SetPrecount counts the tree alongside the scan, so the header can show a
+// progress bar. It reads every directory a second time, without statting
+// the files in them.
+func (m *Model) SetPrecount() {
+	m.precount = &precount{}
+}
+
+// startPrecount counts the tree for the scan about to start.
+func (m Model) startPrecount() tea.Cmd {
+	if m.precount == nil || m.streamingScanner == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	*m.precount = precount{cancel: cancel}
+	fsys, root, generation := m.fsys, m.currentPath, m.generation
+	return func() tea.Msg {
+		count, err := scanner.Precount(ctx, fsys, root)
+		return PrecountMsg{Count: count, Err: err, Generation: generation}
+	}
+}
+
+// stopPrecount gives up on a count the scan no longer needs.
+func (m Model) stopPrecount() {
+	if m.precount != nil && m.precount.cancel != nil {
+		m.precount.cancel()
+	}
+}
+
+func (m *Model) precounted(msg PrecountMsg) {
+	if msg.Generation != m.generation || msg.Err != nil {
+		return
+	}
+	m.precount.count = msg.Count
+	m.precount.done = true
+}
+
+// scanProgress is the share of the tree scanned so far, from 0 to 1, and
+// false if the tree hasn't been counted.
+func (m Model) scanProgress() (float64, bool) {
+	if m.precount == nil || !m.precount.done {
+		return 0, false
+	}
+	total := m.precount.count.Files + m.precount.count.Dirs
+	if total == 0 {
+		return 1, true
+	}
+	progress := float64(int64(m.progressFiles)+int64(m.progressDirs)) / float64(total)
+	// Files created since the count can take the scan past it
+	if progress > 1 {
+		progress = 1
+	}
+	return progress, true
+}
+
+// progressBar draws progress, from 0 to 1, as a bar width cells wide.
+func progressBar(progress float64, width int) string {
+	filled := int(progress * float64(width))
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
@@ -0,0 +1,239 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/corpeningc/dua/internal/media"
+	"github.com/corpeningc/dua/internal/termimage"
+)
+
+const (
+	// maxPreviewLines caps the preview pane so the tree keeps most of the
+	// screen on tall terminals.
+	maxPreviewLines = 16
+	// previewInfoLines is the rule, the metadata and the note above the
+	// thumbnail.
+	previewInfoLines = 5
+	// maxThumbnailBytes skips decoding images too large to shrink quickly.
+	maxThumbnailBytes = 50 << 20
+)
+
+var previewTitleStyle = lipgloss.NewStyle().Bold(true)
+
+// previewRequest identifies a loaded preview: the item and the cells its
+// thumbnail may use.
+type previewRequest struct {
+	path       string
+	cols, rows int
+}
+
+// filePreview is what the preview pane shows for one item.
+type filePreview struct {
+	request previewRequest
+	info    fs.FileInfo
+	err     error
+
+	// Set for audio, video and images that could be probed
+	media media.Info
+
+	// thumbnail draws the image over thumbRows rows when the terminal
+	// supports a graphics protocol
+	thumbnail string
+	thumbRows int
+}
+
+// PreviewMsg carries a preview loaded in the background.
+type PreviewMsg struct {
+	preview filePreview
+}
+
+// previewLines is how many of the content area's total lines the preview
+// pane takes, none while it's closed or there's no room for it.
+func (m Model) previewLines(total int) int {
+	if !m.previewOpen || total < 2*previewInfoLines {
+		return 0
+	}
+	return min(total/2, maxPreviewLines)
+}
+
+// refreshPreview starts loading the preview for the item under the cursor if
+// the pane shows something else, or was resized.
+func (m *Model) refreshPreview() tea.Cmd {
+	if !m.previewOpen || m.cursorPath == "" {
+		return nil
+	}
+
+	total := max(m.height-4, 1)
+	req := previewRequest{
+		path: m.cursorPath,
+		cols: m.width,
+		rows: max(m.previewLines(total)-previewInfoLines, 0),
+	}
+	if req == m.previewRequested {
+		return nil
+	}
+	m.previewRequested = req
+	return tea.Batch(loadPreview(req, m.graphics), m.loadBackupStats())
+}
+
+// loadPreview reads an item's metadata and media details and, for images
+// when a graphics protocol is available, renders a thumbnail.
+func loadPreview(req previewRequest, protocol termimage.Protocol) tea.Cmd {
+	return func() tea.Msg {
+		preview := filePreview{request: req}
+		preview.info, preview.err = os.Stat(req.path)
+		if preview.err != nil || preview.info.IsDir() {
+			return PreviewMsg{preview: preview}
+		}
+
+		// Files that aren't media, or don't parse, only get their metadata
+		var err error
+		if preview.media, err = media.Probe(req.path); err != nil || preview.media.Kind != media.Image {
+			return PreviewMsg{preview: preview}
+		}
+
+		cols, rows := termimage.Fit(preview.media.Width, preview.media.Height, req.cols, req.rows)
+		if protocol == termimage.None || rows < 1 || preview.info.Size() > maxThumbnailBytes {
+			return PreviewMsg{preview: preview}
+		}
+
+		f, err := os.Open(req.path)
+		if err != nil {
+			return PreviewMsg{preview: preview}
+		}
+		defer f.Close()
+
+		img, _, err := image.Decode(f)
+		if err != nil {
+			return PreviewMsg{preview: preview}
+		}
+		if preview.thumbnail, err = termimage.Encode(img, cols, rows, protocol); err == nil {
+			preview.thumbRows = rows
+		}
+		return PreviewMsg{preview: preview}
+	}
+}
+
+// describeMedia summarizes what was read from a media file on one line, e.g.
+// "MP4 video • 1:32:05 • 1920×1080 • H.264, AAC".
+func (m Model) describeMedia(info media.Info) string {
+	var parts []string
+	switch info.Kind {
+	case media.Image:
+		parts = append(parts, m.tr.T("preview.image", info.Format, info.Width, info.Height))
+	case media.Video:
+		parts = append(parts, m.tr.T("preview.video", info.Format))
+	case media.Audio:
+		parts = append(parts, m.tr.T("preview.audio", info.Format))
+	}
+
+	if info.Duration > 0 {
+		parts = append(parts, formatDuration(info.Duration))
+	}
+	if info.Kind == media.Video && info.Width > 0 {
+		parts = append(parts, fmt.Sprintf("%d×%d", info.Width, info.Height))
+	}
+	// MP3 and FLAC files name their only codec already
+	if len(info.Codecs) > 0 && !(len(info.Codecs) == 1 && info.Codecs[0] == info.Format) {
+		parts = append(parts, strings.Join(info.Codecs, ", "))
+	}
+	if !info.Taken.IsZero() {
+		parts = append(parts, m.tr.T("preview.taken", m.formatDate(info.Taken)))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// formatDuration writes a duration as a player would, e.g. "3:07" or
+// "1:32:05".
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds < 3600 {
+		return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// showingThumbnail reports whether the preview pane is on screen with an
+// image to draw.
+func (m Model) showingThumbnail() bool {
+	if m.tutorialActive || m.queueView || m.pagerOpen || m.ownersView || m.columnsView || m.helpView || m.suggestionsView || m.reconcileView || m.reviewView {
+		return false
+	}
+	return m.previewOpen && m.preview.request == m.previewRequested && m.preview.thumbRows > 0
+}
+
+// renderPreview draws the preview pane in exactly height lines.
+//
+// The thumbnail is emitted on the pane's last line and drawn upwards from
+// there with the cursor saved and restored. The renderer only rewrites lines
+// that changed, top to bottom, so this keeps the blank lines under the
+// image from being repainted after it, and moving through the tree above
+// never touches it.
+func (m Model) renderPreview(height int) string {
+	lines := []string{strings.Repeat("─", m.width)}
+
+	preview := m.preview
+	path := m.previewRequested.path
+	switch {
+	case preview.request != m.previewRequested:
+		lines = append(lines, previewTitleStyle.Render(filepath.Base(path)), m.tr.T("row.loading")+"…")
+	case preview.err != nil:
+		lines = append(lines, previewTitleStyle.Render(filepath.Base(path)), m.tr.T("preview.error", preview.err))
+	default:
+		info := preview.info
+		lines = append(lines, previewTitleStyle.Render(info.Name()))
+
+		size := info.Size()
+		kind := m.tr.T("preview.file")
+		if info.IsDir() {
+			size = m.itemSize(path)
+			kind = m.tr.T("preview.directory")
+		} else if preview.media.Kind != 0 {
+			kind = m.describeMedia(preview.media)
+		}
+		lines = append(lines,
+			m.tr.T("preview.details", formatSize(size), m.formatDate(info.ModTime())),
+			kind)
+		// Directories have no thumbnail to make room for
+		if backup := m.backupLine(path); backup != "" {
+			lines = append(lines, backup)
+		}
+	}
+	if note, ok := m.notes[path]; ok {
+		lines = append(lines, m.tr.T("preview.note", noteBadge, note))
+	}
+	if open := m.openLine(path); open != "" {
+		lines = append(lines, open)
+	}
+	if duplicate := m.duplicateLine(path); duplicate != "" {
+		lines = append(lines, duplicate)
+	}
+
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, m.width, "…")
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	lines = lines[:height]
+
+	if m.showingThumbnail() && preview.thumbRows < height {
+		// A full-width line isn't followed by an erase to the end of the line,
+		// which would wipe the image's bottom row in cell-based protocols
+		up := "\r"
+		if preview.thumbRows > 1 {
+			up = fmt.Sprintf("\x1b[%dF", preview.thumbRows-1)
+		}
+		lines[height-1] = strings.Repeat(" ", m.width) + "\x1b7" + up + preview.thumbnail + "\x1b8"
+	}
+	return strings.Join(lines, "\n")
+}
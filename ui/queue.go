@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var queuedStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#E6C229")).
+	Strikethrough(true)
+
+// toggleQueued adds the selection, or the item under the cursor, to the
+// cleanup queue, or takes it off again if it's all queued already.
+func (m *Model) toggleQueued() {
+	var paths []string
+	for path := range m.selected {
+		if m.searchQuery == "" || m.findIndexOfPath(path) >= 0 {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 && m.cursorPath != "" {
+		paths = append(paths, m.cursorPath)
+	}
+
+	allQueued := true
+	for _, path := range paths {
+		allQueued = allQueued && m.queue[path]
+	}
+	for _, path := range paths {
+		if allQueued {
+			delete(m.queue, path)
+		} else {
+			m.queue[path] = true
+		}
+	}
+
+	m.selected = make(map[string]bool)
+	m.visualMode = false
+	m.visualStart = -1
+	m.visualStartPath = ""
+}
+
+// queuedPaths lists the cleanup queue in path order.
+func (m Model) queuedPaths() []string {
+	paths := make([]string, 0, len(m.queue))
+	for path := range m.queue {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// queueTotal is the space running the queue would free. Items inside a
+// queued directory are already part of its size and aren't counted twice.
+func (m Model) queueTotal() int64 {
+	var total int64
+	for path := range m.queue {
+		if !m.hasQueuedAncestor(path) {
+			total += m.itemSize(path)
+		}
+	}
+	return total
+}
+
+func (m Model) hasQueuedAncestor(path string) bool {
+	for dir := filepath.Dir(path); dir != path; path, dir = dir, filepath.Dir(dir) {
+		if m.queue[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// openQueue shows the queue screen. With quitting set, it asks whether to run
+// the queue before leaving.
+func (m *Model) openQueue(quitting bool) {
+	m.queueView = true
+	m.queueCursor = 0
+	m.queueConfirm = quitting
+	m.queueQuitting = quitting
+}
+
+func (m Model) handleQueueKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.queueConfirm {
+		switch msg.String() {
+		case "y":
+			m.queueConfirm = false
+			m.quitAfterCleanup = m.queueQuitting
+			cmd := m.deleteLarge(m.queuedPaths(), 0, m.fsys.RemoveAll)
+			return m, cmd
+		case "n":
+			if m.queueQuitting {
+				return m, tea.Quit
+			}
+			m.queueConfirm = false
+		case "esc":
+			m.queueConfirm = false
+			m.queueQuitting = false
+		}
+		return m, nil
+	}
+
+	paths := m.queuedPaths()
+	switch msg.String() {
+	case "up", "k":
+		if m.queueCursor > 0 {
+			m.queueCursor--
+		}
+	case "down", "j":
+		if m.queueCursor < len(paths)-1 {
+			m.queueCursor++
+		}
+	case "x", "backspace", "delete":
+		if m.queueCursor < len(paths) {
+			delete(m.queue, paths[m.queueCursor])
+			m.queueCursor = max(min(m.queueCursor, len(paths)-2), 0)
+		}
+	case "enter":
+		if len(paths) > 0 {
+			m.queueConfirm = true
+		}
+	case "esc", "Q":
+		m.queueView = false
+	case "q":
+		cmd := m.requestQuit()
+		return m, cmd
+	}
+	return m, nil
+}
+
+// renderQueue lists the queued items with their sizes in an area of the
+// given height, keeping the cursor in view.
+func (m Model) renderQueue(height int) string {
+	var b strings.Builder
+
+	paths := m.queuedPaths()
+	b.WriteString(m.tr.N("queue.title", len(paths), formatSize(m.queueTotal())) + "\n\n")
+	if len(paths) == 0 {
+		b.WriteString(m.tr.T("queue.empty") + "\n")
+		return b.String()
+	}
+
+	visible := max(height-2, 1)
+	top := max(m.queueCursor-visible+1, 0)
+	list := virtualList[string]{top: top, height: visible, rows: sliceRows(paths)}
+	list.render(&b, func(i int, path string) string {
+		style := fileStyle
+		if i == m.queueCursor {
+			style = selectedStyle
+		}
+		name := path
+		if rel, err := filepath.Rel(m.currentPath, path); err == nil {
+			name = rel
+		}
+		size := m.itemSize(path)
+		return m.renderRow(name, style, sizeCells(size))
+	})
+	return b.String()
+}
@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// quickRootWait is how often a quick scan past its time checks whether the
+// root has been read, so it can stop.
+const quickRootWait = 100 * time.Millisecond
+
+// quickScan is a scan stopped after a time limit, leaving the branches it
+// hadn't reached unscanned.
+type quickScan struct {
+	limit   time.Duration
+	stopped bool
+	// estimates holds the bytes estimated to be in the unscanned parts of
+	// each directory with any, worked out again whenever the tree changes
+	estimates map[string]int64
+}
+
+// QuickDeadlineMsg ends a quick scan once its time is up.
+type QuickDeadlineMsg struct {
+	Generation int
+}
+
+// SetQuick stops the scan after limit, presenting what it reached. The scan
+// goes breadth-first, so that's the top of the tree, with what's unscanned
+// below marked along with an estimate of its size. Rescans go all the way.
+func (m *Model) SetQuick(limit time.Duration) {
+	m.quick = &quickScan{limit: limit}
+}
+
+func (m Model) quickDeadline() tea.Cmd {
+	if m.quick == nil || m.streamingScanner == nil {
+		return nil
+	}
+	generation := m.generation
+	return tea.Tick(m.quick.limit, func(time.Time) tea.Msg {
+		return QuickDeadlineMsg{Generation: generation}
+	})
+}
+
+func (m *Model) quickDeadlineReached(msg QuickDeadlineMsg) tea.Cmd {
+	if msg.Generation != m.generation || !m.isScanning {
+		return nil
+	}
+	// Without the root there's nothing to show, so wait for it
+	if !m.tree.Root.IsLoaded {
+		generation := m.generation
+		return tea.Tick(quickRootWait, func(time.Time) tea.Msg {
+			return QuickDeadlineMsg{Generation: generation}
+		})
+	}
+	m.quick.stopped = true
+	m.quick.estimates = nil
+	cmd := m.finishScan()
+	m.statusMessage = m.tr.T("quick.stopped", m.quick.limit, m.unscannedShare(m.tree.Root))
+	return cmd
+}
+
+// quickStopped reports whether the tree is from a quick scan that was cut
+// short.
+func (m Model) quickStopped() bool {
+	return m.quick != nil && m.quick.stopped
+}
+
+// unscanned returns the bytes estimated to be in the unscanned parts of dir,
+// 0 if it was scanned all the way.
+func (m Model) unscanned(dir *scanner.DirInfo) int64 {
+	if !m.quickStopped() || dir.PendingDirs == 0 {
+		return 0
+	}
+	if m.quick.estimates == nil {
+		m.quick.estimates = estimateUnscanned(m.tree.Root)
+	}
+	return m.quick.estimates[dir.Path]
+}
+
+// unscannedShare is the estimated percentage of the whole tree in the
+// unscanned parts of dir.
+func (m Model) unscannedShare(dir *scanner.DirInfo) int {
+	unscanned := m.unscanned(dir)
+	if unscanned == 0 {
+		return 0
+	}
+	total := m.tree.Root.Size + m.unscanned(m.tree.Root)
+	// Round up, a sliver unscanned is still unscanned
+	return int((unscanned*100 + total - 1) / total)
+}
+
+// estimateUnscanned guesses the size of each unscanned directory as that of
+// its fully scanned siblings on average, which tend to be alike, or failing
+// that the bytes per directory scanned so far. Directories above add up the
+// guesses below them.
+func estimateUnscanned(root *scanner.DirInfo) map[string]int64 {
+	var scannedBytes, scannedDirs int64
+	var count func(dir *scanner.DirInfo)
+	count = func(dir *scanner.DirInfo) {
+		if !dir.IsLoaded {
+			return
+		}
+		scannedDirs++
+		for _, file := range dir.Files {
+			size, _ := file.Counted()
+			scannedBytes += size
+		}
+		for i := range dir.Subdirs {
+			count(&dir.Subdirs[i])
+		}
+	}
+	count(root)
+	var perDir int64
+	if scannedDirs > 0 {
+		perDir = scannedBytes / scannedDirs
+	}
+
+	estimates := make(map[string]int64)
+	var estimate func(dir *scanner.DirInfo) int64
+	estimate = func(dir *scanner.DirInfo) int64 {
+		if dir.PendingDirs == 0 {
+			return 0
+		}
+		var complete, completeBytes int64
+		for _, subdir := range dir.Subdirs {
+			if subdir.PendingDirs == 0 {
+				complete++
+				completeBytes += subdir.Size
+			}
+		}
+		sibling := perDir
+		if complete > 0 {
+			sibling = completeBytes / complete
+		}
+
+		var total int64
+		for i := range dir.Subdirs {
+			subdir := &dir.Subdirs[i]
+			if subdir.IsLoaded {
+				total += estimate(subdir)
+			} else if subdir.PendingDirs > 0 {
+				estimates[subdir.Path] = sibling
+				total += sibling
+			}
+		}
+		estimates[dir.Path] = total
+		return total
+	}
+	if root.IsLoaded {
+		estimate(root)
+	}
+	return estimates
+}
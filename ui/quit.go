@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// requestQuit quits at once unless there's pending work: items marked for
+// deletion or waiting in the queue. Then it asks first, so a stray q doesn't
+// throw the work away.
+func (m *Model) requestQuit() tea.Cmd {
+	if len(m.markedForDeletion) == 0 && len(m.queue) == 0 {
+		return tea.Quit
+	}
+	m.quitConfirm = true
+	return nil
+}
+
+func (m Model) handleQuitKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "q":
+		return m, tea.Quit
+	case "r":
+		// Running the queue first is the queue screen's own quit prompt
+		if len(m.queue) > 0 {
+			m.quitConfirm = false
+			m.openQueue(true)
+		}
+	case "n", "esc":
+		m.quitConfirm = false
+	}
+	return m, nil
+}
+
+// quitPrompt describes the pending work in the footer, e.g. "3 items
+// marked, 2 operations queued".
+func (m Model) quitPrompt() string {
+	var pending []string
+	if n := len(m.markedForDeletion); n > 0 {
+		pending = append(pending, m.tr.N("quit.marked", n))
+	}
+	if n := len(m.queue); n > 0 {
+		pending = append(pending, m.tr.N("quit.queued", n))
+		return m.tr.T("footer.quit_queue", strings.Join(pending, ", "))
+	}
+	return m.tr.T("footer.quit", strings.Join(pending, ", "))
+}
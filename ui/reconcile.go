@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/fsusage"
+	"github.com/corpeningc/dua/internal/openfiles"
+	"github.com/corpeningc/dua/internal/paths"
+)
+
+// reconcileUnreadableShown is how many unreadable directories are named.
+const reconcileUnreadableShown = 3
+
+// reconcileReservedTarget is the percentage of reserved blocks suggested
+// instead of ext4's default of 5, which on a large disk holding only data is
+// far more than root needs to keep a full system running.
+const reconcileReservedTarget = 1
+
+// reconcileReport is what the filesystem says about itself, gathered in the
+// background, to set against the scanned tree.
+type reconcileReport struct {
+	usage    fsusage.Usage
+	usageErr error
+	// The filesystem the scan started on, and others mounted inside the scan
+	mount   fsusage.Mount
+	mounted bool
+	nested  []fsusage.Mount
+	// Deleted files on the filesystem still held open
+	deletedFiles int
+	deletedBytes int64
+	// Space held by ZFS snapshots, if it could be read
+	snapshotBytes int64
+	snapshotsRead bool
+}
+
+// ReconcileMsg carries the report for the reconciliation screen.
+type ReconcileMsg struct {
+	report reconcileReport
+}
+
+// openReconcile shows how the scanned total compares with what the
+// filesystem reports as used, gathering the filesystem's side first.
+func (m *Model) openReconcile() tea.Cmd {
+	m.reconcileView = true
+	m.reconcile = nil
+	root := m.currentPath
+	return func() tea.Msg {
+		return ReconcileMsg{report: gatherReconcile(root)}
+	}
+}
+
+func gatherReconcile(root string) reconcileReport {
+	var report reconcileReport
+	report.usage, report.usageErr = fsusage.Stat(root)
+
+	mounts, _ := fsusage.Mounts()
+	report.mount, report.mounted = fsusage.MountOf(mounts, root)
+	for _, mount := range mounts {
+		if mount.Point != root && paths.Within(mount.Point, root) {
+			report.nested = append(report.nested, mount)
+		}
+	}
+
+	if files, err := openfiles.Scan(); err == nil {
+		for _, file := range files {
+			if !file.Deleted || !report.mounted {
+				continue
+			}
+			if mount, ok := fsusage.MountOf(mounts, file.Path); ok && mount.Point == report.mount.Point {
+				report.deletedFiles++
+				report.deletedBytes += file.Size
+			}
+		}
+	}
+
+	if report.mounted && report.mount.Type == "zfs" {
+		out, err := exec.Command("zfs", "get", "-Hp", "-o", "value", "usedbysnapshots", report.mount.Source).Output()
+		if err == nil {
+			report.snapshotBytes, err = strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+			report.snapshotsRead = err == nil
+		}
+	}
+	return report
+}
+
+// tuneReserved returns the command lowering the filesystem's reserved
+// blocks to reconcileReservedTarget percent, or "" if there's none.
+func tuneReserved(report *reconcileReport) string {
+	switch {
+	case !report.mounted || !strings.HasPrefix(report.mount.Source, "/dev/"):
+		return ""
+	case report.mount.Type == "ext2" || report.mount.Type == "ext3" || report.mount.Type == "ext4":
+		return fmt.Sprintf("sudo tune2fs -m %d %s", reconcileReservedTarget, report.mount.Source)
+	}
+	return ""
+}
+
+func (m Model) handleReconcileKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "D":
+		m.reconcileView = false
+		m.reconcile = nil
+	}
+	return m, nil
+}
+
+// nestedInTree returns the mounts inside the scan that the tree counts, and
+// how much they add up to. Mounts inside another nested mount are already
+// counted in it, and duplicate mounts aren't counted at all.
+func (m Model) nestedInTree(nested []fsusage.Mount) ([]string, int64) {
+	var points []string
+	var total int64
+	for _, mount := range nested {
+		covered := false
+		for _, outer := range nested {
+			if outer.Point != mount.Point && outer.Contains(mount.Point) {
+				covered = true
+				break
+			}
+		}
+		dir := m.tree.Find(mount.Point)
+		if covered || dir == nil || dir.DuplicateOf != "" {
+			continue
+		}
+		points = append(points, mount.Point)
+		total += m.itemSize(mount.Point)
+	}
+	return points, total
+}
+
+// renderReconcile lists the filesystem's used space against the scanned
+// total and the likely reasons they differ, in at most height lines.
+func (m Model) renderReconcile(height int) string {
+	var b strings.Builder
+	b.WriteString(m.tr.T("reconcile.title") + "\n\n")
+	report := m.reconcile
+	if report == nil {
+		b.WriteString(m.tr.T("reconcile.loading") + "\n")
+		return b.String()
+	}
+
+	var lines []string
+	row := func(label string, bytes int64) {
+		lines = append(lines, m.renderRow(label, fileStyle, sizeCells(bytes)))
+	}
+	note := func(text string) {
+		lines = append(lines, "  "+text)
+	}
+
+	var scanned int64
+	if m.tree.Root != nil {
+		scanned = m.dirSize(m.tree.Root)
+	}
+	if report.usageErr != nil {
+		note(m.tr.T("reconcile.usage_error", report.usageErr))
+		row(m.tr.T("reconcile.scanned", m.currentPath), scanned)
+	} else {
+		used := int64(report.usage.Used())
+		if report.mounted {
+			note(m.tr.T("reconcile.filesystem", report.mount.Source, report.mount.Type, report.mount.Point))
+		}
+		row(m.tr.T("reconcile.used"), used)
+		row(m.tr.T("reconcile.scanned", m.currentPath), scanned)
+		if diff := used - scanned; diff >= 0 {
+			row(m.tr.T("reconcile.more_used"), diff)
+		} else {
+			row(m.tr.T("reconcile.more_scanned"), -diff)
+		}
+	}
+	lines = append(lines, "", m.tr.T("reconcile.reasons"))
+
+	reasons := len(lines)
+	if m.isScanning {
+		note(m.tr.T("reconcile.scanning"))
+	}
+	if report.mounted && report.mount.Point != m.currentPath {
+		note(m.tr.T("reconcile.outside", report.mount.Point))
+	}
+	if points, total := m.nestedInTree(report.nested); len(points) > 0 {
+		row(m.tr.N("reconcile.nested", len(points), strings.Join(points, ", ")), total)
+	}
+	if len(m.unreadable) > 0 {
+		shown := strings.Join(m.unreadable[:min(len(m.unreadable), reconcileUnreadableShown)], ", ")
+		note(m.tr.N("reconcile.unreadable", len(m.unreadable), shown))
+	}
+	if report.deletedFiles > 0 {
+		row(m.tr.N("reconcile.deleted_open", report.deletedFiles), report.deletedBytes)
+	}
+	if reserved := int64(report.usage.Free - report.usage.Available); report.usageErr == nil && reserved > 0 {
+		share := float64(reserved) / float64(report.usage.Total) * 100
+		row(m.tr.T("reconcile.reserved", share, reserved), reserved)
+		if command := tuneReserved(report); command != "" && share > reconcileReservedTarget {
+			note(m.tr.T("reconcile.reserved_tune", command))
+		}
+	}
+	switch {
+	case report.snapshotsRead && report.snapshotBytes > 0:
+		row(m.tr.T("reconcile.snapshots_zfs"), report.snapshotBytes)
+	case report.mounted && (report.mount.Type == "btrfs" || report.mount.Type == "zfs" && !report.snapshotsRead):
+		note(m.tr.T("reconcile.snapshots", report.mount.Type))
+	}
+	if len(lines) == reasons {
+		note(m.tr.T("reconcile.none"))
+	}
+	if !m.diskUsage {
+		// Disk usage counts blocks as the filesystem does
+		note(m.tr.T("reconcile.apparent"))
+	}
+
+	for _, line := range lines[:min(len(lines), max(height-2, 1))] {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// selectedList is the selection in path order, wherever in the tree each
+// item is.
+func (m Model) selectedList() []string {
+	paths := make([]string, 0, len(m.selected))
+	for path := range m.selected {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// openReview shows the selection, across all directories, before acting on
+// it.
+func (m *Model) openReview() {
+	if len(m.selected) == 0 {
+		m.statusMessage = m.tr.T("review.none")
+		return
+	}
+	m.reviewView = true
+	m.reviewCursor = 0
+	m.reviewConfirm = false
+}
+
+func (m Model) handleReviewKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	paths := m.selectedList()
+	if m.reviewConfirm {
+		switch msg.String() {
+		case "y":
+			m.reviewConfirm = false
+			m.reviewView = false
+			cmd := m.deleteLarge(paths, 0, m.fsys.RemoveAll)
+			return m, cmd
+		case "n", "esc":
+			m.reviewConfirm = false
+		}
+		return m, nil
+	}
+
+	if m.readOnly && (msg.String() == "x" || msg.String() == "d") {
+		m.statusMessage = m.tr.T("readonly.refused")
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.reviewCursor > 0 {
+			m.reviewCursor--
+		}
+	case "down", "j":
+		if m.reviewCursor < len(paths)-1 {
+			m.reviewCursor++
+		}
+	case "t", " ", "backspace", "delete":
+		if m.reviewCursor < len(paths) {
+			delete(m.selected, paths[m.reviewCursor])
+			m.reviewCursor = max(min(m.reviewCursor, len(paths)-2), 0)
+		}
+		if len(m.selected) == 0 {
+			m.reviewView = false
+		}
+	case "x":
+		for _, path := range paths {
+			m.queue[path] = true
+		}
+		m.selected = make(map[string]bool)
+		m.visualMode = false
+		m.reviewView = false
+	case "d":
+		if len(paths) > 0 {
+			m.reviewConfirm = true
+		}
+	case "esc", "q", "V":
+		m.reviewView = false
+	}
+	return m, nil
+}
+
+// renderReview lists the selected items with their sizes in at most height
+// lines, keeping the cursor in view.
+func (m Model) renderReview(height int) string {
+	var b strings.Builder
+
+	paths := m.selectedList()
+	b.WriteString(m.tr.N("review.title", len(paths), formatSize(m.selectionTotal())) + "\n\n")
+
+	visible := max(height-2, 1)
+	top := max(m.reviewCursor-visible+1, 0)
+	list := virtualList[string]{top: top, height: visible, rows: sliceRows(paths)}
+	list.render(&b, func(i int, path string) string {
+		style := fileStyle
+		if i == m.reviewCursor {
+			style = selectedStyle
+		}
+		name := path
+		if rel, err := filepath.Rel(m.currentPath, path); err == nil {
+			name = rel
+		}
+		size := m.itemSize(path)
+		return m.renderRow(name, style, sizeCells(size))
+	})
+	return b.String()
+}
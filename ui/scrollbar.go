@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+var (
+	scrollTrackStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#3A3A3A"))
+	scrollThumbStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#8A8A8A"))
+	scrollMatchStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD700"))
+	scrollMarkedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#CC0000"))
+)
+
+// scrollbar draws the column at the right edge of the tree, a cell per
+// line, or returns nil when every row fits in listLines. The thumb spans
+// the rows on screen, and rows elsewhere that are marked for deletion or
+// match the search are marked where they fall, so they can be found in long
+// listings.
+func (m Model) scrollbar(lines, listLines int) []string {
+	total := m.countVisibleItems()
+	if total <= listLines || lines < 1 {
+		return nil
+	}
+	thumb := max(lines*listLines/total, 1)
+	start := m.viewportTop * lines / total
+	if start+thumb > lines || m.viewportTop+listLines >= total {
+		// Reaching the end shows as the thumb reaching the bottom
+		start = lines - thumb
+	}
+
+	matches := make([]bool, lines)
+	marked := make([]bool, lines)
+	if m.searchQuery != "" || len(m.markedForDeletion) > 0 {
+		m.walkRows(m.tree.Root, 0, 0, func(index int, path, name string) {
+			cell := index * lines / total
+			if m.markedForDeletion[path] {
+				marked[cell] = true
+			} else if m.searchQuery != "" && fuzzyMatch(m.searchQuery, name) {
+				matches[cell] = true
+			}
+		})
+	}
+
+	cells := make([]string, lines)
+	for i := range cells {
+		// Marks under the thumb are on screen, where they show anyway
+		switch {
+		case i >= start && i < start+thumb:
+			cells[i] = scrollThumbStyle.Render("┃")
+		case marked[i]:
+			cells[i] = scrollMarkedStyle.Render("■")
+		case matches[i]:
+			cells[i] = scrollMatchStyle.Render("■")
+		default:
+			cells[i] = scrollTrackStyle.Render("│")
+		}
+	}
+	return cells
+}
+
+// walkRows calls visit with every row of the tree from dir down, in the
+// order they're shown, returning the index after the last.
+func (m Model) walkRows(dir *scanner.DirInfo, depth, index int, visit func(index int, path, name string)) int {
+	if m.searchQuery != "" && !m.dirMatchesSearch(dir) {
+		return index
+	}
+	visit(index, dir.Path, getBaseName(dir.Path))
+	index++
+	if depth > 0 && !m.expanded[dir.Path] {
+		return index
+	}
+
+	fileOrder, subdirOrder := m.sortDirectoryContents(dir)
+	for _, i := range fileOrder {
+		name := dir.Files[i].Name
+		if m.searchQuery != "" && !m.matchesSearch(name) {
+			continue
+		}
+		visit(index, filepath.Join(dir.Path, name), name)
+		index++
+	}
+	for _, i := range subdirOrder {
+		index = m.walkRows(&dir.Subdirs[i], depth+1, index, visit)
+	}
+	return index
+}
+
+// withScrollbar puts a cell of bar at the end of each line of rows, which
+// are width wide, padding them with empty lines to the bar's length.
+func withScrollbar(rows string, bar []string, width int) string {
+	if bar == nil {
+		return rows
+	}
+	lines := strings.Split(strings.TrimSuffix(rows, "\n"), "\n")
+	var b strings.Builder
+	for i, cell := range bar {
+		line := strings.Repeat(" ", width)
+		if i < len(lines) && lines[i] != "" {
+			line = lines[i]
+		}
+		b.WriteString(line + cell + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultExportFile is offered as the target when exporting a selection.
+const defaultExportFile = "dua-selection.txt"
+
+// nulExportSuffix has an export end each path with NUL rather than a
+// newline, for xargs -0 and rsync --from0, which take any name.
+const nulExportSuffix = ".nul"
+
+// ExportMsg reports the result of writing the selection to a file.
+type ExportMsg struct {
+	Path  string
+	Count int
+	Error error
+}
+
+// SelectedPaths returns what the user picked: the items marked for deletion
+// if any are, otherwise the selection, in path order.
+func (m Model) SelectedPaths() []string {
+	source := m.selected
+	if m.deletionMode && len(m.markedForDeletion) > 0 {
+		source = m.markedForDeletion
+	}
+
+	paths := make([]string, 0, len(source))
+	for path := range source {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// CursorDir returns the directory under the cursor, or the one containing
+// the file under it.
+func (m Model) CursorDir() string {
+	path, isDir := m.getCurrentItem()
+	switch {
+	case path == "":
+		return m.currentPath
+	case isDir:
+		return path
+	default:
+		return filepath.Dir(path)
+	}
+}
+
+// exportSelection writes paths to file, one per line, ready for tools like
+// rsync --files-from or xargs, or NUL-separated if it ends in
+// nulExportSuffix.
+func exportSelection(file string, paths []string) tea.Cmd {
+	return func() tea.Msg {
+		end := "\n"
+		if strings.HasSuffix(file, nulExportSuffix) {
+			end = "\x00"
+		}
+		content := strings.Join(paths, end) + end
+		err := os.WriteFile(file, []byte(content), 0o644)
+		return ExportMsg{Path: file, Count: len(paths), Error: err}
+	}
+}
+
+func (m Model) handleExportKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if m.exportInput == "" {
+			m.exportMode = false
+			return m, nil
+		}
+		// Ask before clobbering, a second enter confirms
+		if _, err := os.Lstat(m.exportInput); err == nil && !m.exportOverwrite {
+			m.exportOverwrite = true
+			return m, nil
+		}
+		m.exportMode = false
+		m.exportOverwrite = false
+		return m, exportSelection(m.exportInput, m.SelectedPaths())
+	case "esc":
+		m.exportMode = false
+		m.exportOverwrite = false
+	case "backspace":
+		if len(m.exportInput) > 0 {
+			m.exportInput = m.exportInput[:len(m.exportInput)-1]
+		}
+		m.exportOverwrite = false
+	default:
+		if len(msg.String()) == 1 {
+			m.exportInput += msg.String()
+			m.exportOverwrite = false
+		}
+	}
+	return m, nil
+}
+
+// ReadPathList parses a newline-separated list of paths, skipping blank
+// lines.
+func ReadPathList(r io.Reader) ([]string, error) {
+	var paths []string
+
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		if line := strings.TrimRight(lines.Text(), "\r"); strings.TrimSpace(line) != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, lines.Err()
+}
+
+// ImportSelection selects paths, or marks them for deletion when mark is
+// set, and expands their parents so they can be reviewed. Paths that don't
+// exist or lie outside the scanned root are skipped. It returns how many
+// were imported.
+func (m *Model) ImportSelection(paths []string, mark bool) int {
+	target := m.selected
+	if mark {
+		m.deletionMode = true
+		target = m.markedForDeletion
+	}
+
+	imported := 0
+	for _, path := range paths {
+		resolved, ok := m.resolveImportedPath(path)
+		if !ok {
+			continue
+		}
+
+		target[resolved] = true
+		for dir := filepath.Dir(resolved); dir != m.currentPath; dir = filepath.Dir(dir) {
+			m.expanded[dir] = true
+		}
+		imported++
+	}
+
+	m.statusMessage = m.tr.T("import.done", imported, len(paths)-imported)
+	return imported
+}
+
+// resolveImportedPath puts path in the same form as paths in the tree:
+// absolute, with symlinks in its parent directories resolved the way the
+// root was. The last element is left alone so a symlink is selected rather
+// than its target.
+func (m *Model) resolveImportedPath(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err != nil {
+		return "", false
+	}
+	resolved := filepath.Join(parent, filepath.Base(abs))
+
+	if _, err := os.Lstat(resolved); err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(m.currentPath, resolved)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return resolved, true
+}
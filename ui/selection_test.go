@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestExportSelection exports the selection over an existing file, which
+// takes a second enter, as NUL-separated paths.
+func TestExportSelection(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "selection.nul")
+	if err := os.WriteFile(file, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := press(t, scanned(t), "j", "t", "j", "j", "t", "e")
+	m.exportInput = file
+	m = press(t, m, "enter")
+	if !m.exportMode || !m.exportOverwrite {
+		t.Fatal("didn't ask before overwriting")
+	}
+	if data, _ := os.ReadFile(file); string(data) != "keep" {
+		t.Fatalf("overwritten before confirming, holds %q", data)
+	}
+
+	m = press(t, m, "enter")
+	if m.exportMode {
+		t.Error("still asking after confirming")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/proj/build\x00/proj/go.mod\x00"; string(data) != want {
+		t.Errorf("exported %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/sessionlock"
+)
+
+// PeersMsg carries the other dua sessions working in this tree.
+type PeersMsg struct {
+	Sessions []sessionlock.Session
+}
+
+// SetSessionLock has the model look for other sessions in its tree through
+// lock, on startup and whenever items are marked for deletion.
+func (m *Model) SetSessionLock(lock *sessionlock.Lock) {
+	m.sessionLock = lock
+}
+
+func (m Model) findPeers() tea.Cmd {
+	if m.sessionLock == nil {
+		return nil
+	}
+	lock := m.sessionLock
+	return func() tea.Msg {
+		sessions, err := lock.Others()
+		if err != nil {
+			return nil
+		}
+		return PeersMsg{Sessions: sessions}
+	}
+}
+
+// peerWarning names another session in this tree, as deleting while it's
+// open leaves it showing items that are gone and lets both delete the same
+// ones.
+func (m Model) peerWarning() string {
+	if len(m.peers) == 0 {
+		return ""
+	}
+	peer := m.peers[0]
+	return m.tr.N("peers.warning", len(m.peers), peer.User, peer.PID, peer.Root)
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// cachedSize is the total of a directory from earlier in the session, which
+// holds while its modification time is unchanged.
+type cachedSize struct {
+	modTime time.Time
+	size    int64
+	alloc   int64
+}
+
+// rememberSizes keeps the totals of the fully scanned directories under dir
+// for the rest of the session, before a rescan throws the tree away.
+// Directories not reached by the tree keep what was known of them.
+func (m *Model) rememberSizes(dir *scanner.DirInfo) {
+	if dir.IsLoaded && dir.PendingDirs == 0 && dir.Sample == nil && dir.DuplicateOf == "" && !dir.ModTime.IsZero() {
+		m.sizeCache[dir.Path] = cachedSize{modTime: dir.ModTime, size: dir.Size, alloc: dir.Alloc}
+	}
+	for i := range dir.Subdirs {
+		m.rememberSizes(&dir.Subdirs[i])
+	}
+}
+
+// cachedTotal returns the total of a directory that hasn't been scanned yet
+// from earlier in the session, if its modification time shows it's likely
+// the same. A directory's time only changes with its own entries, so
+// changes deeper down still wait for the scan to reach them.
+func (m Model) cachedTotal(dir *scanner.DirInfo) (int64, bool) {
+	if dir.IsLoaded {
+		return 0, false
+	}
+	cached, ok := m.sizeCache[dir.Path]
+	if !ok || !cached.modTime.Equal(dir.ModTime) {
+		return 0, false
+	}
+	if m.diskUsage {
+		return cached.alloc, true
+	}
+	return cached.size, true
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"path/filepath"
+	"slices"
+)
+
+// stickyRows returns the directories holding the row at top, outermost
+// first, to pin above the tree once their own rows have scrolled out of
+// view, so it's clear where rows deep in a subtree belong. They take at
+// most half of lines, keeping the innermost.
+func (m Model) stickyRows(top, lines int) []treeRow {
+	if top == 0 {
+		return nil
+	}
+	rows := m.treeRows(top, top+1)
+	if len(rows) == 0 {
+		return nil
+	}
+	row := rows[0]
+
+	// A file's row is inside its directory, a directory's inside its parent
+	dir, depth := row.dir, row.depth-1
+	if row.file == nil {
+		dir = m.tree.Find(filepath.Dir(row.dir.Path))
+	}
+	var sticky []treeRow
+	for dir != nil && depth >= 0 && len(sticky) < lines/2 {
+		sticky = append(sticky, treeRow{dir: dir, depth: depth})
+		if dir.Path == m.tree.Root.Path {
+			break
+		}
+		dir, depth = m.tree.Find(filepath.Dir(dir.Path)), depth-1
+	}
+	slices.Reverse(sticky)
+	return sticky
+}
@@ -0,0 +1,305 @@
+package ui
+
+import (
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/browsers"
+	"github.com/corpeningc/dua/internal/logfiles"
+	"github.com/corpeningc/dua/internal/pkgcache"
+	"github.com/corpeningc/dua/internal/scanner"
+	"github.com/corpeningc/dua/internal/trash"
+	"github.com/corpeningc/dua/internal/vfs"
+)
+
+// suggestion is something that can be cleaned up in one go, with how much it
+// should free.
+type suggestion struct {
+	title string
+	bytes int64
+	// paths are the files or directories the cleanup deletes
+	paths []string
+	// command cleans up instead of deleting paths, with the terminal handed
+	// over so it can ask for a password or confirmation
+	command []string
+	// blocked says why it can't be cleaned up right now
+	blocked string
+}
+
+// suggestionProviders each look for one kind of cleanup, in the scanned tree
+// or elsewhere on the machine.
+var suggestionProviders = []func(Model) []suggestion{
+	Model.logSuggestions,
+	Model.packageCacheSuggestions,
+	Model.browserSuggestions,
+	Model.trashSuggestions,
+}
+
+// CachesMsg carries the package and browser caches and the trash measured
+// in the background.
+type CachesMsg struct {
+	Packages []pkgcache.Cache
+	Browsers []browsers.Browser
+	Trash    []trash.Bin
+}
+
+// CleanCommandMsg reports how a suggestion's clean command went.
+type CleanCommandMsg struct {
+	Title string
+	Error error
+}
+
+// suggestions gathers every provider's suggestions, largest first.
+func (m Model) suggestions() []suggestion {
+	var all []suggestion
+	for _, provider := range suggestionProviders {
+		for _, s := range provider(m) {
+			if s.bytes > 0 || len(s.paths) > 0 {
+				all = append(all, s)
+			}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].bytes > all[j].bytes
+	})
+	return all
+}
+
+// logSuggestions offers to delete rotated logs and archived journal files
+// older than the configured age. Live logs never match.
+func (m Model) logSuggestions() []suggestion {
+	cutoff := time.Now().AddDate(0, 0, -m.logMaxAgeDays)
+	logs := suggestion{}
+	var walk func(dir *scanner.DirInfo)
+	walk = func(dir *scanner.DirInfo) {
+		for _, file := range dir.Files {
+			path := filepath.Join(dir.Path, file.Name)
+			if file.ModTime.Before(cutoff) && logfiles.Classify(path) != logfiles.None {
+				logs.paths = append(logs.paths, path)
+				logs.bytes += file.Size
+			}
+		}
+		for i := range dir.Subdirs {
+			walk(&dir.Subdirs[i])
+		}
+	}
+	walk(m.tree.Root)
+
+	logs.title = m.tr.N("suggest.logs", len(logs.paths), m.logMaxAgeDays)
+	return []suggestion{logs}
+}
+
+// packageCacheSuggestions offers to clean each package manager's cache with
+// its own command, once the caches have been measured.
+func (m Model) packageCacheSuggestions() []suggestion {
+	var caches []suggestion
+	for _, cache := range m.packageCaches {
+		s := suggestion{title: m.tr.T("suggest.package", cache.Name), bytes: cache.Size, command: cache.Clean}
+		if len(cache.Clean) == 0 {
+			s.paths = cache.Dirs
+		}
+		caches = append(caches, s)
+	}
+	return caches
+}
+
+// browserSuggestions offers to delete each browser's caches, leaving its
+// bookmarks, history and other profile data alone. A running browser's
+// caches are left until it's closed.
+func (m Model) browserSuggestions() []suggestion {
+	var caches []suggestion
+	for _, browser := range m.browserCaches {
+		s := suggestion{
+			title: m.tr.T("suggest.browser", browser.Name, formatSize(browser.DataSize)),
+			bytes: browser.CacheSize,
+			paths: browser.CacheDirs,
+		}
+		if browser.Running {
+			s.blocked = m.tr.T("suggest.browser_running", browser.Name)
+		}
+		caches = append(caches, s)
+	}
+	return caches
+}
+
+// trashSuggestions offers to empty the trash of the scanned filesystem.
+// What's in there was deleted but still takes up space, which the scan
+// doesn't show unless it covers the trash directory.
+func (m Model) trashSuggestions() []suggestion {
+	var bins []suggestion
+	for _, bin := range m.trashBins {
+		bins = append(bins, suggestion{
+			title:   m.tr.N("suggest.trash", bin.Items, bin.Dir),
+			bytes:   bin.Size,
+			paths:   bin.Entries,
+			command: bin.Empty,
+		})
+	}
+	return bins
+}
+
+// measureCaches starts measuring the package and browser caches and the
+// trash, which live outside the scanned tree and can take a moment to
+// total.
+func (m *Model) measureCaches() tea.Cmd {
+	if m.cachesLoading {
+		return nil
+	}
+	m.cachesLoading = true
+	root := m.currentPath
+	// Another filesystem's trash isn't on this machine
+	local := m.fsys == vfs.OS
+	return func() tea.Msg {
+		msg := CachesMsg{Packages: pkgcache.Find(), Browsers: browsers.Find()}
+		if local {
+			if abs, err := filepath.Abs(root); err == nil {
+				msg.Trash = trash.Find(abs)
+			}
+		}
+		return msg
+	}
+}
+
+// openSuggestions shows the cleanup suggestions for what's been scanned so
+// far, measuring the caches elsewhere the first time.
+func (m *Model) openSuggestions() tea.Cmd {
+	m.suggestionsView = true
+	m.suggestionsCursor = 0
+	m.suggestionsConfirm = false
+	m.suggestionList = m.suggestions()
+	if !m.cachesMeasured {
+		return m.measureCaches()
+	}
+	return nil
+}
+
+// refreshSuggestions rebuilds the open list after something was cleaned up
+// or measured. A pending confirmation is dropped, as its row may have moved.
+func (m *Model) refreshSuggestions() {
+	if m.suggestionsView {
+		m.suggestionsConfirm = false
+		m.suggestionList = m.suggestions()
+		m.suggestionsCursor = max(min(m.suggestionsCursor, len(m.suggestionList)-1), 0)
+	}
+}
+
+// cachesDeleted tells whether any of paths was a package or browser cache
+// or in the trash, which then need measuring again.
+func (m Model) cachesDeleted(paths []string) bool {
+	for _, cache := range m.packageCaches {
+		for _, dir := range cache.Dirs {
+			if slices.Contains(paths, dir) {
+				return true
+			}
+		}
+	}
+	for _, browser := range m.browserCaches {
+		for _, dir := range browser.CacheDirs {
+			if slices.Contains(paths, dir) {
+				return true
+			}
+		}
+	}
+	for _, bin := range m.trashBins {
+		if len(bin.Entries) > 0 && slices.Contains(paths, bin.Entries[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// runCleanCommand runs a suggestion's command in the foreground.
+func runCleanCommand(s suggestion) tea.Cmd {
+	cmd := exec.Command(s.command[0], s.command[1:]...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return CleanCommandMsg{Title: s.title, Error: err}
+	})
+}
+
+func (m Model) handleSuggestionsKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.suggestionsConfirm {
+		switch msg.String() {
+		case "y":
+			m.suggestionsConfirm = false
+			s := m.suggestionList[m.suggestionsCursor]
+			if len(s.command) > 0 {
+				if m.kioskPolicy != nil {
+					// Commands clean up wherever they like
+					m.statusMessage = m.tr.T("kiosk.commands")
+					return m, nil
+				}
+				return m, runCleanCommand(s)
+			}
+			cmd := m.deleteLarge(s.paths, s.bytes, m.fsys.RemoveAll)
+			return m, cmd
+		case "n", "esc":
+			m.suggestionsConfirm = false
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.suggestionsCursor > 0 {
+			m.suggestionsCursor--
+		}
+	case "down", "j":
+		if m.suggestionsCursor < len(m.suggestionList)-1 {
+			m.suggestionsCursor++
+		}
+	case "enter":
+		if m.suggestionsCursor >= len(m.suggestionList) {
+			break
+		}
+		if blocked := m.suggestionList[m.suggestionsCursor].blocked; blocked != "" {
+			m.statusMessage = blocked
+		} else {
+			m.suggestionsConfirm = true
+		}
+	case "esc", "q", "C":
+		m.suggestionsView = false
+		m.suggestionList = nil
+	}
+	return m, nil
+}
+
+// renderSuggestions lists the suggestions in at most height lines.
+func (m Model) renderSuggestions(height int) string {
+	var b strings.Builder
+
+	var total int64
+	for _, s := range m.suggestionList {
+		total += s.bytes
+	}
+	b.WriteString(m.tr.N("suggest.title", len(m.suggestionList), formatSize(total)) + "\n\n")
+	if len(m.suggestionList) == 0 {
+		b.WriteString(m.tr.T("suggest.none") + "\n")
+		if m.cachesLoading {
+			b.WriteString(m.tr.T("suggest.measuring") + "\n")
+		}
+		return b.String()
+	}
+
+	visible := max(height-2, 1)
+	if m.cachesLoading {
+		visible = max(visible-1, 1)
+	}
+	top := max(m.suggestionsCursor-visible+1, 0)
+	for i := top; i < len(m.suggestionList) && i < top+visible; i++ {
+		s := m.suggestionList[i]
+		style := fileStyle
+		if i == m.suggestionsCursor {
+			style = selectedStyle
+		}
+		b.WriteString(m.renderRow(s.title, style, sizeCells(s.bytes)) + "\n")
+	}
+	if m.cachesLoading {
+		b.WriteString(m.tr.T("suggest.measuring") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/corpeningc/dua/internal/scanner"
+)
+
+// sessionStats accumulates what happened during a session for the summary
+// printed on exit.
+type sessionStats struct {
+	scanDuration   time.Duration
+	errors         int
+	deletedItems   int
+	reclaimedBytes int64
+}
+
+// SessionSummary renders the end-of-session report: scan totals, errors,
+// deletions and the largest directory found.
+func (m Model) SessionSummary() string {
+	var b strings.Builder
+
+	duration := m.stats.scanDuration
+	if m.isScanning {
+		duration = time.Since(m.scanStartTime)
+	}
+
+	var totalBytes int64
+	if m.tree.Root != nil {
+		totalBytes = m.dirSize(m.tree.Root)
+	}
+
+	b.WriteString(m.tr.T("summary.title") + "\n")
+	scanned := m.tr.T("summary.scanned", m.progressFiles, m.progressDirs, formatSize(totalBytes), duration.Round(time.Millisecond))
+	if m.isScanning {
+		scanned += " " + m.tr.T("summary.interrupted")
+	}
+	b.WriteString(scanned + "\n")
+	if m.isScanning && m.checkpoint != "" {
+		b.WriteString(m.tr.T("summary.resume", m.currentPath) + "\n")
+	}
+	b.WriteString(m.tr.T("summary.errors", m.stats.errors) + "\n")
+	b.WriteString(m.verifySummary())
+	b.WriteString(m.tr.T("summary.deleted", m.stats.deletedItems, formatSize(m.stats.reclaimedBytes)) + "\n")
+
+	if largest := m.largestDirectory(); largest != nil {
+		b.WriteString(m.tr.T("summary.largest", largest.Path, formatSize(directFileBytes(largest))) + "\n")
+	}
+
+	return b.String()
+}
+
+// largestDirectory finds the directory holding the most bytes in its own
+// files. Recursive totals always favour the root's children, so this points
+// at where data actually sits instead.
+func (m Model) largestDirectory() *scanner.DirInfo {
+	if m.tree.Root == nil {
+		return nil
+	}
+
+	var largest *scanner.DirInfo
+	var largestBytes int64
+	var walk func(dir *scanner.DirInfo)
+	walk = func(dir *scanner.DirInfo) {
+		if bytes := directFileBytes(dir); bytes > largestBytes {
+			largest, largestBytes = dir, bytes
+		}
+		for i := range dir.Subdirs {
+			walk(&dir.Subdirs[i])
+		}
+	}
+	walk(m.tree.Root)
+
+	return largest
+}
+
+func directFileBytes(dir *scanner.DirInfo) int64 {
+	var total int64
+	for _, file := range dir.Files {
+		size, _ := file.Counted()
+		total += size
+	}
+	return total
+}
+
+// itemSize returns the size of the file or directory at path in the tree.
+func (m *Model) itemSize(path string) int64 {
+	if dir := m.tree.Find(path); dir != nil {
+		return m.dirSize(dir)
+	}
+
+	if parent := m.tree.Find(filepath.Dir(path)); parent != nil {
+		name := filepath.Base(path)
+		for _, file := range parent.Files {
+			if file.Name == name {
+				return m.fileSize(file)
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/corpeningc/dua/internal/treefile"
+	"github.com/corpeningc/dua/internal/treesync"
+)
+
+// syncPreview is the plan for making the right side of a comparison a copy
+// of the left, for the directory shown when it was opened.
+type syncPreview struct {
+	open    bool
+	deletes bool // Also delete what only the right side has
+	changes []treesync.Change
+	cursor  int
+	offset  int
+	confirm bool
+	running bool
+	errs    []error
+}
+
+// SyncDoneMsg reports a sync, with both sides scanned again afterwards.
+type SyncDoneMsg struct {
+	Done        int
+	Errors      []error
+	Left, Right treefile.Document
+	Err         error // Set if rescanning failed
+}
+
+// RsyncCommand returns the command chosen to be printed on exit, if any.
+func (c Compare) RsyncCommand() string {
+	return c.rsync
+}
+
+// syncRoots are the directories on each side the preview covers.
+func (c Compare) syncRoots() (string, string) {
+	return filepath.Join(append([]string{c.left.Root}, c.path...)...),
+		filepath.Join(append([]string{c.right.Root}, c.path...)...)
+}
+
+func (c *Compare) openSync() {
+	c.sync = syncPreview{open: true, deletes: c.sync.deletes}
+	c.planSync()
+}
+
+func (c *Compare) planSync() {
+	left, right := c.current()
+	c.sync.changes = treesync.Plan(left, right, c.sync.deletes)
+	c.sync.cursor = 0
+	c.sync.offset = 0
+}
+
+func (c Compare) handleSyncKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return c, tea.Quit
+	}
+	if c.sync.running {
+		return c, nil
+	}
+	c.status = ""
+
+	if c.sync.confirm {
+		c.sync.confirm = false
+		if key == "y" {
+			c.sync.running = true
+			return c, c.runSync()
+		}
+		return c, nil
+	}
+
+	switch key {
+	case "esc", "q":
+		c.sync.open = false
+	case "up", "k":
+		if c.sync.cursor > 0 {
+			c.sync.cursor--
+		}
+	case "down", "j":
+		if c.sync.cursor < len(c.sync.changes)-1 {
+			c.sync.cursor++
+		}
+	case "d":
+		c.sync.deletes = !c.sync.deletes
+		c.planSync()
+	case "r":
+		left, right := c.syncRoots()
+		c.rsync = treesync.RsyncCommand(left, right, c.sync.deletes)
+		return c, tea.Quit
+	case "enter":
+		switch {
+		case !c.live:
+			c.status = c.tr.T("sync.unavailable")
+		case len(c.sync.changes) > 0:
+			c.sync.confirm = true
+		}
+	}
+
+	visible := c.visibleRows()
+	if c.sync.cursor < c.sync.offset {
+		c.sync.offset = c.sync.cursor
+	}
+	if c.sync.cursor >= c.sync.offset+visible {
+		c.sync.offset = c.sync.cursor - visible + 1
+	}
+	return c, nil
+}
+
+// runSync carries out the plan, then scans both sides again so the
+// comparison shows the result.
+func (c Compare) runSync() tea.Cmd {
+	changes := c.sync.changes
+	left, right := c.syncRoots()
+	leftRoot, rightRoot := c.left.Root, c.right.Root
+	return func() tea.Msg {
+		done, errs := treesync.Apply(left, right, changes)
+		msg := SyncDoneMsg{Done: done, Errors: errs}
+		if msg.Left, msg.Err = treefile.Scan(leftRoot); msg.Err != nil {
+			return msg
+		}
+		msg.Right, msg.Err = treefile.Scan(rightRoot)
+		return msg
+	}
+}
+
+func (c Compare) syncDone(msg SyncDoneMsg) Compare {
+	c.sync.running = false
+	if msg.Err == nil {
+		c.left, c.right = msg.Left, msg.Right
+		c.loadRows()
+	}
+	if len(msg.Errors) > 0 || msg.Err != nil {
+		// Stay on the preview so the failures can be read
+		c.sync.errs = msg.Errors
+		if msg.Err != nil {
+			c.sync.errs = append(c.sync.errs, msg.Err)
+		}
+		c.planSync()
+		return c
+	}
+	c.sync.open = false
+	c.status = c.tr.N("sync.done", msg.Done)
+	return c
+}
+
+func (c Compare) renderSync() string {
+	var b strings.Builder
+
+	left, right := c.syncRoots()
+	copied, deleted := treesync.Totals(c.sync.changes)
+	header := c.tr.N("sync.title", len(c.sync.changes), left, right, formatSize(copied), formatSize(deleted))
+	if c.width > 0 {
+		header = ansi.Truncate(header, c.width, "…")
+	}
+	b.WriteString(header + "\n")
+	b.WriteString(strings.Repeat("-", lipgloss.Width(header)) + "\n")
+
+	if len(c.sync.changes) == 0 {
+		b.WriteString(c.tr.T("sync.none") + "\n")
+	}
+	nameWidth := max(c.width-22, 10)
+	for i := c.sync.offset; i < len(c.sync.changes) && i < c.sync.offset+c.visibleRows(); i++ {
+		change := c.sync.changes[i]
+		name := change.Path
+		if change.Dir {
+			name += "/"
+		}
+		action := padRight(c.tr.T("sync."+change.Action.String()), 8)
+		line := fmt.Sprintf("%s %s %s", action, padLeft(formatSize(change.Size), 10), ansi.Truncate(name, nameWidth, "…"))
+		switch {
+		case i == c.sync.cursor:
+			line = selectedStyle.Render(line)
+		case change.Action == treesync.Delete:
+			line = compareGrownStyle.Render(line)
+		case change.Action == treesync.Copy:
+			line = compareShrunkStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	for i, err := range c.sync.errs {
+		if i == 3 {
+			b.WriteString(c.tr.T("sync.more_errors", len(c.sync.errs)-i) + "\n")
+			break
+		}
+		b.WriteString(compareGrownStyle.Render(err.Error()) + "\n")
+	}
+
+	b.WriteString("\n")
+	switch {
+	case c.sync.running:
+		b.WriteString(c.tr.T("sync.running"))
+	case c.sync.confirm:
+		b.WriteString(c.tr.N("sync.confirm", len(c.sync.changes), right))
+	default:
+		if c.status != "" {
+			b.WriteString(c.status + "\n")
+		}
+		deletes := c.tr.T("sync.deletes_off")
+		if c.sync.deletes {
+			deletes = c.tr.T("sync.deletes_on")
+		}
+		b.WriteString(c.tr.T("footer.sync", deletes))
+	}
+	return b.String()
+}
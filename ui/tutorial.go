@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/corpeningc/dua/internal/paths"
+)
+
+// tutorialStateFile marks the tutorial as completed once it has been shown.
+const tutorialStateFile = "tutorial-completed"
+
+// tutorialHint pairs a key with what it does.
+type tutorialHint struct {
+	keys string
+	desc string // i18n key
+}
+
+// tutorialStep is one page of the first-run tutorial.
+type tutorialStep struct {
+	title string // i18n key
+	body  string // i18n key
+	hints []tutorialHint
+}
+
+var tutorialSteps = []tutorialStep{
+	{
+		title: "tutorial.welcome.title",
+		body:  "tutorial.welcome.body",
+	},
+	{
+		title: "tutorial.navigate.title",
+		body:  "tutorial.navigate.body",
+		hints: []tutorialHint{
+			{"↑↓ / j k", "tutorial.navigate.move"},
+			{"→ / l", "tutorial.navigate.expand"},
+			{"← / h", "tutorial.navigate.collapse"},
+			{"g / G", "tutorial.navigate.jump"},
+		},
+	},
+	{
+		title: "tutorial.search.title",
+		body:  "tutorial.search.body",
+		hints: []tutorialHint{
+			{"/", "tutorial.search.start"},
+			{"enter", "tutorial.search.keep"},
+			{"esc", "tutorial.search.clear"},
+		},
+	},
+	{
+		title: "tutorial.mark.title",
+		body:  "tutorial.mark.body",
+		hints: []tutorialHint{
+			{"t / space", "tutorial.mark.toggle"},
+			{"v", "tutorial.mark.visual"},
+			{"V", "tutorial.mark.review"},
+		},
+	},
+	{
+		title: "tutorial.delete.title",
+		body:  "tutorial.delete.body",
+		hints: []tutorialHint{
+			{"d", "tutorial.delete.mark"},
+			{"d d", "tutorial.delete.confirm"},
+			{"esc", "tutorial.delete.cancel"},
+		},
+	},
+	{
+		title: "tutorial.done.title",
+		body:  "tutorial.done.body",
+		hints: []tutorialHint{
+			{"s", "tutorial.done.sort"},
+			{"q", "tutorial.done.quit"},
+		},
+	},
+}
+
+var (
+	tutorialBoxStyle = lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(lipgloss.Color("#7D56F4")).
+				Padding(1, 2)
+
+	tutorialTitleStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("#7D56F4"))
+
+	tutorialKeyStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Background(lipgloss.Color("#7D56F4")).
+				Padding(0, 1)
+)
+
+// TutorialCompleted reports whether the first-run tutorial has already been
+// finished or dismissed.
+func TutorialCompleted() bool {
+	path, err := paths.StateFile(tutorialStateFile)
+	if err != nil {
+		// Nowhere to remember it, so don't nag on every launch
+		return true
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// StartTutorial shows the tutorial overlay from its first step.
+func (m *Model) StartTutorial() {
+	m.tutorialActive = true
+	m.tutorialStep = 0
+}
+
+// handleTutorialKey steps through the tutorial. Finishing or dismissing it
+// records it as completed so it is never shown again.
+func (m Model) handleTutorialKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "right", "l", " ":
+		if m.tutorialStep < len(tutorialSteps)-1 {
+			m.tutorialStep++
+			return m, nil
+		}
+		m.tutorialActive = false
+		return m, markTutorialCompleted()
+	case "left", "h":
+		if m.tutorialStep > 0 {
+			m.tutorialStep--
+		}
+	case "esc", "q":
+		m.tutorialActive = false
+		return m, markTutorialCompleted()
+	}
+	return m, nil
+}
+
+func markTutorialCompleted() tea.Cmd {
+	return func() tea.Msg {
+		path, err := paths.StateFile(tutorialStateFile)
+		if err != nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil
+		}
+		os.WriteFile(path, nil, 0o644)
+		return nil
+	}
+}
+
+// renderTutorial draws the current tutorial step as a box centred in an area
+// of the given size.
+func (m Model) renderTutorial(width, height int) string {
+	step := tutorialSteps[m.tutorialStep]
+
+	var b strings.Builder
+	b.WriteString(tutorialTitleStyle.Render(m.tr.T(step.title)) + "\n\n")
+	b.WriteString(m.tr.T(step.body) + "\n")
+
+	if len(step.hints) > 0 {
+		b.WriteString("\n")
+		keyWidth := 0
+		for _, hint := range step.hints {
+			keyWidth = max(keyWidth, lipgloss.Width(tutorialKeyStyle.Render(hint.keys)))
+		}
+		for _, hint := range step.hints {
+			key := tutorialKeyStyle.Render(hint.keys)
+			key += strings.Repeat(" ", keyWidth-lipgloss.Width(key))
+			b.WriteString(key + "  " + m.tr.T(hint.desc) + "\n")
+		}
+	}
+
+	b.WriteString("\n" + sizeStyle.Render(m.tr.T("tutorial.progress", m.tutorialStep+1, len(tutorialSteps))))
+
+	box := tutorialBoxStyle.Render(b.String())
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
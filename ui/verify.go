@@ -0,0 +1,80 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/corpeningc/dua/internal/scanner"
+	"github.com/corpeningc/dua/internal/vfs"
+)
+
+// VerifyMsg carries the directories checked against the filesystem once the
+// scan finished.
+type VerifyMsg struct {
+	Checks []scanner.Check
+}
+
+// SetVerify checks the root and sample other directories against a plain
+// walk of the filesystem once the scan finishes, reporting any difference.
+func (m *Model) SetVerify(sample int) {
+	m.verifySample = sample
+}
+
+// verifyTree starts checking a sample of the finished tree. The totals are
+// taken now, since the tree changes under a background walk. Only the
+// operating system's filesystem can be walked.
+func (m *Model) verifyTree() tea.Cmd {
+	if m.verifySample == 0 || m.tree.Root == nil || m.fsys != vfs.OS {
+		return nil
+	}
+	checks := scanner.SampleChecks(m.tree.Root, m.verifySample)
+	if len(checks) == 0 {
+		m.statusMessage = m.tr.T("verify.none")
+		return nil
+	}
+	m.verifying = true
+	m.statusMessage = m.tr.T("verify.running", len(checks))
+	return func() tea.Msg {
+		return VerifyMsg{Checks: scanner.Verify(checks)}
+	}
+}
+
+func (m *Model) verifyDone(msg VerifyMsg) {
+	m.verifying = false
+	m.verifyChecks = msg.Checks
+	if failed := len(m.verifyFailures()); failed > 0 {
+		m.statusMessage = m.tr.T("verify.failed", failed, len(msg.Checks))
+	} else {
+		m.statusMessage = m.tr.T("verify.ok", len(msg.Checks))
+	}
+}
+
+func (m Model) verifyFailures() []scanner.Check {
+	var failed []scanner.Check
+	for _, check := range m.verifyChecks {
+		if !check.OK() {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// verifySummary is the verification's part of the session summary.
+func (m Model) verifySummary() string {
+	if m.verifyChecks == nil {
+		if m.verifying {
+			return m.tr.T("summary.verify_unfinished") + "\n"
+		}
+		return ""
+	}
+	failed := m.verifyFailures()
+	s := m.tr.T("summary.verify", len(m.verifyChecks)-len(failed), len(m.verifyChecks)) + "\n"
+	for _, check := range failed {
+		if check.Err != nil {
+			s += m.tr.T("summary.verify_error", check.Path, check.Err) + "\n"
+			continue
+		}
+		s += m.tr.T("summary.verify_diff", check.Path,
+			check.Scanned.Size, check.Scanned.Files, check.Scanned.Dirs,
+			check.Walked.Size, check.Walked.Files, check.Walked.Dirs) + "\n"
+	}
+	return s
+}
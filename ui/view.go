@@ -3,13 +3,22 @@ package ui
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/corpeningc/dua/internal/config"
+	"github.com/corpeningc/dua/internal/humanize"
+	"github.com/corpeningc/dua/internal/i18n"
 	"github.com/corpeningc/dua/internal/scanner"
+	"github.com/corpeningc/dua/internal/termimage"
 )
 
+// spinnerFrames animate rows whose contents are being loaded.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
 var ( 
 	selectedItemStyle = lipgloss.NewStyle().
 	Background(lipgloss.Color("#7D56F4")).  // Purple background      
@@ -46,52 +55,338 @@ func (m Model) ViewTree() string {
 		direction = "↑"
 	}
 
-	header := fmt.Sprintf("DUA - Disk Usage Analyzer | Path: %s | Sort: %s%s", m.displayPath, m.sortMode.String(), direction)
+	header := m.tr.T("header.title", m.displayPath, m.tr.T(m.sortMode.messageKey()), direction)
+
+	var totalBytes int64
+	if m.tree.Root != nil {
+		totalBytes = m.dirSize(m.tree.Root)
+	}
 
 	// Add scanning progress
 	if m.isScanning {
 		elapsed := time.Since(m.scanStartTime)
-		progress := fmt.Sprintf(" | SCANNING: %d files, %d dirs, %s in %v",
-			m.progressFiles, m.progressDirs, formatSize(m.progressBytes), elapsed.Truncate(time.Second))
+		progress := m.tr.T("header.scanning",
+			m.progressFiles, m.progressDirs, formatSize(totalBytes), elapsed.Truncate(time.Second))
 		header += progress
+		if progress, ok := m.scanProgress(); ok {
+			header += m.tr.T("header.progress", progressBar(progress, progressBarWidth), int(progress*100))
+		} else if m.precount != nil {
+			header += m.tr.T("header.counting")
+		}
+		if eta, ok := m.scanETA(); ok {
+			header += m.tr.T("header.eta", eta.Round(time.Second))
+		}
 	} else {
 		// Show final stats
-		finalStats := fmt.Sprintf(" | SCANNED: %d files, %d dirs, %s",
-			m.progressFiles, m.progressDirs, formatSize(m.progressBytes))
+		finalStats := m.tr.T("header.scanned",
+			m.progressFiles, m.progressDirs, formatSize(totalBytes))
 		header += finalStats
 	}
+	if m.diskUsage {
+		header += m.tr.T("header.disk_usage")
+	}
+	if m.kioskPolicy != nil {
+		header += m.tr.T("header.kiosk")
+	}
+	if m.degraded {
+		header += m.tr.T("header.degraded")
+	}
+	if m.quickStopped() {
+		header += m.tr.T("header.quick", m.unscannedShare(m.tree.Root))
+	}
+	if len(m.peers) > 0 {
+		header += m.tr.N("header.peers", len(m.peers))
+	}
+
+	// Kitty draws images above the text, so a thumbnail stays until it's
+	// deleted. Sending the delete with the header makes it go out whenever
+	// the thumbnail disappears, as the header changes along with it.
+	if m.graphics == termimage.Kitty && !m.showingThumbnail() {
+		header = termimage.KittyClear + header
+	}
 
 	b.WriteString(header + "\n")
-	b.WriteString(strings.Repeat("-", len(header)) + "\n")
+	b.WriteString(strings.Repeat("-", lipgloss.Width(header)) + "\n")
 
 	var contentBuilder strings.Builder
-	if m.rootDir != nil {
-		visibleLines := m.height - 4 // Reserve space for header and footer
+	if m.tutorialActive {
+		visibleLines := m.height - 4
 		if visibleLines < 1 {
 			visibleLines = 10
 		}
-		m.renderDirectoryWithViewport(&contentBuilder, m.rootDir, 0, 0, m.viewportTop, visibleLines)
+		contentBuilder.WriteString(m.renderTutorial(m.width, visibleLines) + "\n")
+	} else if m.queueView {
+		contentBuilder.WriteString(m.renderQueue(max(m.height-4, 1)))
+	} else if m.pagerOpen {
+		contentBuilder.WriteString(m.renderPager(max(m.height-4, 1)))
+	} else if m.ownersView {
+		contentBuilder.WriteString(m.renderOwners(max(m.height-4, 1)))
+	} else if m.columnsView {
+		contentBuilder.WriteString(m.renderColumns())
+	} else if m.helpView {
+		contentBuilder.WriteString(m.renderHelp(max(m.height-4, 1)))
+	} else if m.suggestionsView {
+		contentBuilder.WriteString(m.renderSuggestions(max(m.height-4, 1)))
+	} else if m.reconcileView {
+		contentBuilder.WriteString(m.renderReconcile(max(m.height-4, 1)))
+	} else if m.reviewView {
+		contentBuilder.WriteString(m.renderReview(max(m.height-4, 1)))
+	} else if m.tree.Root != nil {
+		visibleLines := m.treeLines() // Reserve space for header, footer and preview
+		sticky := m.stickyRows(m.viewportTop, visibleLines)
+		listLines := visibleLines - len(sticky)
+		bar := m.scrollbar(visibleLines, listLines)
+		rows := m
+		if bar != nil {
+			rows.width-- // The scrollbar takes the last column
+		}
+		var treeBuilder strings.Builder
+		for _, row := range sticky {
+			treeBuilder.WriteString(rows.renderTreeRow(-1, row) + "\n")
+		}
+		list := virtualList[treeRow]{top: m.viewportTop, height: listLines, rows: m.treeRows}
+		linesUsed := len(sticky) + list.render(&treeBuilder, rows.renderTreeRow)
+		contentBuilder.WriteString(withScrollbar(treeBuilder.String(), bar, rows.width))
+		if bar != nil {
+			linesUsed = visibleLines
+		}
+
+		if previewLines := m.previewLines(m.height - 4); previewLines > 0 {
+			// Pad a short tree so the pane stays at the bottom
+			contentBuilder.WriteString(strings.Repeat("\n", max(visibleLines-linesUsed, 0)))
+			contentBuilder.WriteString(m.renderPreview(previewLines) + "\n")
+		}
 	}
 
 	b.WriteString(contentBuilder.String())
 
 	// Footer with controls
 	b.WriteString("\n")
+	controls, help := m.footerControls()
+	segments := strings.Split(controls, " • ")
+	if m.statusMessage != "" {
+		segments = append([]string{m.statusMessage}, segments...)
+	}
+	b.WriteString(m.actionBar(segments, help) + "\n")
+
+	return b.String()
+}
+
+// footerControls returns the footer of the current mode as segments joined
+// by " • ", and whether the help overlay can be opened from it.
+func (m Model) footerControls() (string, bool) {
 	var controls string
-	if m.searchMode {
-		controls = fmt.Sprintf("Search: %s_ • enter: confirm • esc: cancel", m.searchQuery)
+	help := false
+	if m.helpView {
+		controls = m.tr.T("footer.help")
+	} else if m.tutorialActive {
+		controls = m.tr.T("footer.tutorial")
+	} else if m.quitConfirm {
+		controls = m.quitPrompt()
+	} else if m.bigDelete != nil {
+		controls = m.bigDeleteFooter()
+	} else if m.queueView && m.queueConfirm && m.queueQuitting {
+		controls = m.tr.T("footer.queue_quit", len(m.queue), formatSize(m.queueTotal()))
+	} else if m.queueView && m.queueConfirm {
+		controls = m.tr.T("footer.queue_confirm", len(m.queue), formatSize(m.queueTotal()))
+	} else if m.queueView {
+		controls = m.tr.T("footer.queue")
+	} else if m.pagerOpen {
+		controls = m.tr.T("footer.pager")
+	} else if m.ownersView {
+		controls = m.tr.T("footer.owners")
+	} else if m.columnsView {
+		controls = m.tr.T("footer.columns")
+	} else if m.suggestionsView && m.suggestionsConfirm {
+		s := m.suggestionList[m.suggestionsCursor]
+		if len(s.command) > 0 {
+			controls = m.tr.T("footer.suggest_run", s.title, strings.Join(s.command, " "), formatSize(s.bytes))
+		} else {
+			controls = m.tr.T("footer.suggest_confirm", s.title, formatSize(s.bytes))
+		}
+	} else if m.suggestionsView {
+		controls = m.tr.T("footer.suggest")
+	} else if m.reconcileView {
+		controls = m.tr.T("footer.reconcile")
+	} else if m.reviewView && m.reviewConfirm {
+		controls = m.tr.T("footer.review_confirm", len(m.selected), formatSize(m.selectionTotal()))
+	} else if m.reviewView {
+		controls = m.tr.T("footer.review")
+	} else if m.searchMode {
+		controls = m.tr.T("footer.search", m.searchQuery)
+	} else if m.noteMode {
+		controls = m.tr.T("footer.note", m.noteInput)
+	} else if m.exportMode && m.exportOverwrite {
+		controls = m.tr.T("footer.export_overwrite", m.exportInput)
+	} else if m.exportMode {
+		controls = m.tr.T("footer.export", m.exportInput)
+	} else if m.renameMode && m.renameOverwrite {
+		controls = m.tr.T("footer.rename_overwrite", m.renameInput, m.renameError)
+	} else if m.renameMode && m.renameError != "" {
+		controls = m.tr.T("footer.rename_error", m.renameInput, m.renameError)
 	} else if m.renameMode {
-		controls = fmt.Sprintf("Rename: %s_ • enter: confirm • esc: cancel", m.renameInput)
+		controls = m.tr.T("footer.rename", m.renameInput)
+	} else if m.deletionMode && m.shredConfirm && m.shredCopyOnWrite {
+		controls = m.tr.T("footer.shred_cow", len(m.markedForDeletion))
+	} else if m.deletionMode && m.shredConfirm {
+		controls = m.tr.T("footer.shred", len(m.markedForDeletion))
 	} else if m.deletionMode {
-		controls = fmt.Sprintf("%d marked for deletion • d: DELETE • esc: cancel", len(m.markedForDeletion))
+		controls = m.tr.T("footer.marked", len(m.markedForDeletion))
+		help = true
+		if warning := m.openWarning(); warning != "" {
+			controls = warning + " • " + controls
+		}
+		if warning := m.peerWarning(); warning != "" {
+			controls = warning + " • " + controls
+		}
+	} else if m.chooseMode == ChooseDir {
+		controls = m.tr.T("footer.choose_dir")
+		help = true
+	} else if m.chooseMode == ChooseFile {
+		controls = m.tr.T("footer.choose_file")
+		help = true
 	} else if m.searchQuery != "" {
-		controls = fmt.Sprintf("Filtered: '%s' • /: search • esc: clear • ↑↓/jk: navigate • →l: expand • ←h: collapse • q: quit", m.searchQuery)
+		controls = m.tr.T("footer.filtered", m.searchQuery)
+		help = true
+	} else if m.readOnly {
+		controls = m.contextual(m.tr.T("footer.readonly"))
+		help = true
 	} else {
-		controls = "/: search • ↑↓/jk: navigate • →l: expand • ←h: collapse • r: rename • d: delete • s: sort • ctrl+s: reverse sort • q: quit"
+		controls = m.contextual(m.tr.T("footer.default"))
+		help = true
+	}
+	if len(m.selected) > 0 && !m.searchMode && !m.renameMode && !m.exportMode && !m.noteMode && !m.queueView && !m.pagerOpen && !m.helpView && !m.ownersView && !m.columnsView && !m.suggestionsView && !m.reconcileView && !m.reviewView && !m.deletionMode {
+		if m.costShown() {
+			controls = m.tr.T("footer.selected_cost", len(m.selected), m.cost.Format(m.selectionTotal())) + controls
+		} else {
+			controls = m.tr.T("footer.selected", len(m.selected)) + controls
+		}
 	}
-	b.WriteString(controls + "\n")
+	return controls, help
+}
 
-	return b.String()
+const (
+	// sizeColumnWidth fits the widest size label, e.g. "≥ 1023.9 MB".
+	sizeColumnWidth = 12
+	// dateColumnWidth fits relative dates like "11 months ago" and the
+	// longest locale layouts.
+	dateColumnWidth = 14
+	// costColumnWidth fits monthly costs up to "$9999999".
+	costColumnWidth = 10
+	// minNameColumnWidth keeps names readable on very narrow terminals.
+	minNameColumnWidth = 20
+)
+
+// renderRow lays out a tree row as a fixed-width name column followed by
+// the right-aligned columns that fit, see visibleColumns. The name is
+// measured and truncated before it is styled, so ANSI codes and wide emoji
+// never throw off the alignment.
+func (m Model) renderRow(name string, style lipgloss.Style, cells rowCells) string {
+	columns, nameWidth := m.visibleColumns()
+
+	name = ansi.Truncate(name, nameWidth, "…")
+	name += strings.Repeat(" ", nameWidth-lipgloss.Width(name))
+
+	row := style.Render(name)
+	for _, c := range columns {
+		row += " " + m.cell(c, cells)
+	}
+	return row
+}
+
+// dateLayouts maps locales, or just their language, to absolute date layouts.
+var dateLayouts = map[string]string{
+	"en-US": "Jan 2, 2006",
+	"en":    "2 Jan 2006",
+	"de":    "02.01.2006",
+	"fr":    "02/01/2006",
+	"es":    "02/01/2006",
+	"it":    "02/01/2006",
+	"nl":    "02-01-2006",
+	"ja":    "2006/01/02",
+	"zh":    "2006/01/02",
+}
+
+// dateLayoutFor picks the absolute date layout: an explicit layout from the
+// config, then the locale's, then ISO 8601.
+func dateLayoutFor(cfg config.Config) string {
+	if cfg.DateLayout != "" {
+		return cfg.DateLayout
+	}
+
+	locale := cfg.ResolvedLocale()
+	if layout, ok := dateLayouts[locale]; ok {
+		return layout
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	if layout, ok := dateLayouts[language]; ok {
+		return layout
+	}
+	return "2006-01-02"
+}
+
+func (m Model) formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if m.relativeDates {
+		return formatRelativeTime(m.tr, t, time.Now())
+	}
+	return t.Format(m.dateLayout)
+}
+
+// formatRelativeTime describes t relative to now, e.g. "3 months ago".
+func formatRelativeTime(tr *i18n.Translator, t, now time.Time) string {
+	d := now.Sub(t)
+
+	switch {
+	case d < time.Minute:
+		return tr.T("time.just_now")
+	case d < time.Hour:
+		return tr.N("time.minutes", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return tr.N("time.hours", int(d/time.Hour))
+	case d < 30*24*time.Hour:
+		return tr.N("time.days", int(d/(24*time.Hour)))
+	case d < 365*24*time.Hour:
+		return tr.N("time.months", int(d/(30*24*time.Hour)))
+	default:
+		return tr.N("time.years", int(d/(365*24*time.Hour)))
+	}
+}
+
+// sortAgeColors orders heatmap colors from youngest to oldest, with the
+// catch-all entry last.
+func sortAgeColors(colors []config.AgeColor) []config.AgeColor {
+	sorted := slices.Clone(colors)
+	slices.SortStableFunc(sorted, func(a, b config.AgeColor) int {
+		switch {
+		case a.MaxDays == 0 && b.MaxDays == 0:
+			return 0
+		case a.MaxDays == 0:
+			return 1
+		case b.MaxDays == 0:
+			return -1
+		}
+		return a.MaxDays - b.MaxDays
+	})
+	return sorted
+}
+
+// ageStyle colors base by how long ago modTime was when the age heatmap is
+// on. Entries older than every bucket and without a catch-all keep base.
+func (m Model) ageStyle(base lipgloss.Style, modTime time.Time) lipgloss.Style {
+	if !m.ageHeatmap || modTime.IsZero() {
+		return base
+	}
+
+	days := int(time.Since(modTime) / (24 * time.Hour))
+	for _, bucket := range m.ageColors {
+		if bucket.MaxDays == 0 || days <= bucket.MaxDays {
+			return base.Foreground(lipgloss.Color(bucket.Color))
+		}
+	}
+	return base
 }
 
 // Helper funcs
@@ -102,43 +397,33 @@ func getBaseName(path string) string {
 		return parts[len(parts)-1]
 	}
 
-	if len(parts) > 1 {
+	if len(parts) > 1 && parts[len(parts)-2] != "" {
 		return parts[len(parts)-2]
 	}
 
+	// The root, or only separators
 	return path
 }
 
 func formatSize(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-
-	div, exp := int64(unit), 0
-	for n:= bytes / div; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+	return humanize.Bytes(bytes)
 }
 
 func (m Model) countVisibleItems() int {
-	if m.rootDir == nil{
+	if m.tree.Root == nil{
 		return 0
 	}
 
-	return m.countDirectoryItems(m.rootDir, 0)
+	return m.countDirectoryItems(m.tree.Root, 0)
 }
 
 
 func (m Model) getCurrentItem() (string, bool) {
-	if m.rootDir == nil {
+	if m.tree.Root == nil {
 		return "", false
 	}
 
-	return m.findItemAtIndex(m.rootDir, 0, 0, m.cursor)
+	return m.findItemAtIndex(m.tree.Root, 0, 0, m.cursor)
 }
 
 func (m Model) findItemAtIndex(dir *scanner.DirInfo, depth int, currentIndex int, targetIndex int) (string, bool) {
@@ -155,8 +440,9 @@ func (m Model) findItemAtIndex(dir *scanner.DirInfo, depth int, currentIndex int
 
 	// If expanded, check contents
 	if depth == 0 || m.expanded[dir.Path] {
-		sortedFiles, sortedSubdirs := m.sortDirectoryContents(dir)
-		for _, file := range sortedFiles {
+		fileOrder, subdirOrder := m.sortDirectoryContents(dir)
+		for _, i := range fileOrder {
+			file := &dir.Files[i]
 			// Skip files that don't match search
 			if m.searchQuery != "" && !m.matchesSearch(file.Name) {
 				continue
@@ -168,18 +454,71 @@ func (m Model) findItemAtIndex(dir *scanner.DirInfo, depth int, currentIndex int
 			currentIndex++
 		}
 
-		for _, subdir := range sortedSubdirs {
-			if path, isDir := m.findItemAtIndex(&subdir, depth + 1, currentIndex, targetIndex); path != "" {
+		for _, i := range subdirOrder {
+			subdir := &dir.Subdirs[i]
+			if path, isDir := m.findItemAtIndex(subdir, depth + 1, currentIndex, targetIndex); path != "" {
 				return path, isDir
 			}
 
-			currentIndex += m.countDirectoryItems(&subdir, depth + 1)
+			currentIndex += m.countDirectoryItems(subdir, depth + 1)
 		}
 	}
 
 	return "", false
 }
 
+// findIndexOfPath returns the visible row index of path, or -1 if it is not
+// currently visible.
+func (m Model) findIndexOfPath(path string) int {
+	if m.tree.Root == nil || path == "" {
+		return -1
+	}
+
+	if index, found := m.findIndexInDirectory(m.tree.Root, 0, 0, path); found {
+		return index
+	}
+	return -1
+}
+
+func (m Model) findIndexInDirectory(dir *scanner.DirInfo, depth int, currentIndex int, targetPath string) (int, bool) {
+	// Skip if directory doesn't match search
+	if m.searchQuery != "" && !m.dirMatchesSearch(dir) {
+		return currentIndex, false
+	}
+
+	if dir.Path == targetPath {
+		return currentIndex, true
+	}
+
+	currentIndex++
+
+	if depth == 0 || m.expanded[dir.Path] {
+		fileOrder, subdirOrder := m.sortDirectoryContents(dir)
+		for _, i := range fileOrder {
+			file := &dir.Files[i]
+			// Skip files that don't match search
+			if m.searchQuery != "" && !m.matchesSearch(file.Name) {
+				continue
+			}
+
+			if filepath.Join(dir.Path, file.Name) == targetPath {
+				return currentIndex, true
+			}
+			currentIndex++
+		}
+
+		for _, i := range subdirOrder {
+			index, found := m.findIndexInDirectory(&dir.Subdirs[i], depth+1, currentIndex, targetPath)
+			if found {
+				return index, true
+			}
+			currentIndex = index
+		}
+	}
+
+	return currentIndex, false
+}
+
 func (m Model) countDirectoryItems(dir *scanner.DirInfo, depth int) int {
 	// Skip if directory doesn't match search
 	if m.searchQuery != "" && !m.dirMatchesSearch(dir) {
@@ -202,8 +541,8 @@ func (m Model) countDirectoryItems(dir *scanner.DirInfo, depth int) int {
 		}
 
 		// Count subdirectories that match search
-		for _, subdir := range dir.Subdirs {
-			count += m.countDirectoryItems(&subdir, depth+1)
+		for i := range dir.Subdirs {
+			count += m.countDirectoryItems(&dir.Subdirs[i], depth+1)
 		}
 	}
 
@@ -211,93 +550,190 @@ func (m Model) countDirectoryItems(dir *scanner.DirInfo, depth int) int {
 }
 
 
-func (m Model) renderDirectoryWithViewport(b *strings.Builder, dir *scanner.DirInfo, depth int, currentIndex int, viewportTop int, maxLines int) int {
-	// Skip if directory doesn't match search
+// treeRow is one visible row of the tree: a directory, or a file in dir.
+type treeRow struct {
+	dir   *scanner.DirInfo
+	file  *scanner.FileInfo // nil for a directory's own row
+	depth int
+}
+
+// treeRows flattens the visible rows of the tree from index from up to to.
+// Subtrees entirely before from are skipped by count, and the walk stops
+// once to is reached.
+func (m Model) treeRows(from, to int) []treeRow {
+	var rows []treeRow
+	if m.tree.Root != nil && to > from {
+		m.collectTreeRows(m.tree.Root, 0, 0, from, to, &rows)
+	}
+	return rows
+}
+
+func (m Model) collectTreeRows(dir *scanner.DirInfo, depth, index, from, to int, rows *[]treeRow) int {
 	if m.searchQuery != "" && !m.dirMatchesSearch(dir) {
-		return currentIndex
+		return index
 	}
 
-	// Check if we should render this directory
-	linesUsed := strings.Count(b.String(), "\n")
-	if linesUsed >= maxLines {
-		return currentIndex
+	if index >= from {
+		*rows = append(*rows, treeRow{dir: dir, depth: depth})
+	}
+	index++
+	if depth > 0 && !m.expanded[dir.Path] {
+		return index
 	}
 
-	if currentIndex >= viewportTop {
-		indent := strings.Repeat("  ", depth)
-		dirName := fmt.Sprintf("📁 %s/", getBaseName(dir.Path))
-		var size string
-		if dir.IsLoading {
-			size = "Loading..."
-		} else {
-			size = formatSize(dir.Size)
+	fileOrder, subdirOrder := m.sortDirectoryContents(dir)
+	for _, i := range fileOrder {
+		if index >= to {
+			return index
 		}
-
-		line := fmt.Sprintf("%s%s", indent, dirName)
-
-		if currentIndex == m.cursor {
-			line = selectedStyle.Render(line)
-		} else if m.markedForDeletion[dir.Path] {
-			line = markedForDeletionStyle.Render(line)
-		} else if m.selected[dir.Path] {
-			line = selectedItemStyle.Render(line)
-		} else {
-			line = directoryStyle.Render(line)
+		if m.searchQuery != "" && !m.matchesSearch(dir.Files[i].Name) {
+			continue
 		}
-
-		line = fmt.Sprintf("%-50s %s", line, sizeStyle.Render(size))
-		b.WriteString(line + "\n")
+		if index >= from {
+			*rows = append(*rows, treeRow{dir: dir, file: &dir.Files[i], depth: depth + 1})
+		}
+		index++
 	}
-	currentIndex++
 
-	// Render contents if expanded
-	if (depth == 0 || m.expanded[dir.Path]) && linesUsed < maxLines{
-		// Files
-		sortedFiles, sortedSubdirs := m.sortDirectoryContents(dir)
-		for _, file := range sortedFiles {
-			// Skip files that don't match search
-			if m.searchQuery != "" && !m.matchesSearch(file.Name) {
+	for _, i := range subdirOrder {
+		if index >= to {
+			return index
+		}
+		subdir := &dir.Subdirs[i]
+		// Whole subtree is above the viewport, so just count past it
+		if index < from {
+			if count := m.countDirectoryItems(subdir, depth+1); index+count <= from {
+				index += count
 				continue
 			}
+		}
+		index = m.collectTreeRows(subdir, depth+1, index, from, to, rows)
+	}
+	return index
+}
 
-			linesUsed = strings.Count(b.String(), "\n")
-			if linesUsed >= maxLines {
-				break
-			}
-
-			if currentIndex >= viewportTop {
-				fileIndent := strings.Repeat("  ", depth + 1)
-				fileName := fmt.Sprintf("📄 %s", file.Name)
-				fileSize := formatSize(file.Size)
-
-				filePath := filepath.Join(dir.Path, file.Name)
-				fileLine := fmt.Sprintf("%s%s", fileIndent, fileName)
-
-				if currentIndex == m.cursor {
-					fileLine = selectedStyle.Render(fileLine)
-				} else if m.markedForDeletion[filePath] {
-					fileLine = markedForDeletionStyle.Render(fileLine)
-				} else if m.selected[filePath] {
-					fileLine = selectedItemStyle.Render(fileLine)
-				} else {
-					fileLine = fileStyle.Render(fileLine)
-				}
+// renderTreeRow draws the row at index of the tree.
+func (m Model) renderTreeRow(index int, row treeRow) string {
+	if row.file != nil {
+		return m.renderFileRow(index, row)
+	}
+	dir := row.dir
+
+	indent := strings.Repeat("  ", row.depth)
+	dirName := fmt.Sprintf("📁 %s/", getBaseName(dir.Path))
+	var size string
+	cached, isCached := m.cachedTotal(dir)
+	if (dir.IsLoading || !dir.IsLoaded && m.isScanning) && isCached {
+		// Known from earlier in the session, until the scan confirms it
+		size = spinnerFrames[m.spinnerFrame%len(spinnerFrames)] + " " + formatSize(cached)
+	} else if dir.IsLoading || !dir.IsLoaded && m.isScanning {
+		// Being loaded, or waiting for the scan to get to it
+		size = spinnerFrames[m.spinnerFrame%len(spinnerFrames)] + " " + m.tr.T("row.loading")
+	} else if dir.Sample != nil && dir.PendingDirs == 0 {
+		// Estimated from a sample of the files, see the badge for how closely
+		size = "≈ " + formatSize(m.dirSize(dir))
+	} else if !dir.IsLoaded && m.quickStopped() && isCached {
+		size = "~ " + formatSize(cached)
+	} else if !dir.IsLoaded && m.quickStopped() {
+		// Left unscanned by a quick scan, all there is is the estimate
+		size = "~ " + formatSize(m.unscanned(dir))
+	} else if (m.isScanning || m.quickStopped()) && dir.PendingDirs > 0 {
+		// Parts of the subtree are still unscanned, so this is a lower bound
+		size = "≥ " + formatSize(m.dirSize(dir))
+	} else {
+		size = formatSize(m.dirSize(dir))
+	}
 
-				fileLine = fmt.Sprintf("%-50s %s", fileLine, sizeStyle.Render(fileSize))
-				b.WriteString(fileLine + "\n")
-			}
-			currentIndex++
+	line := fmt.Sprintf("%s%s", indent, dirName)
+	if label := m.ownerLabel(dir.Path); label != "" {
+		line += " " + label
+	}
+	if label := m.backupLabel(dir); label != "" {
+		line += " " + label
+	}
+	if _, ok := m.notes[dir.Path]; ok {
+		line += " " + noteBadge
+	}
+	if dir.DuplicateOf != "" {
+		line += " " + duplicateBadge
+	}
+	if dir.Sample != nil {
+		line += " " + m.tr.T("sample.badge", formatSize(dir.Sample.Margin), len(dir.Files), dir.Sample.Files)
+	}
+	if m.quickStopped() && dir.PendingDirs > 0 && !dir.IsLoading {
+		if dir.IsLoaded {
+			line += " " + m.tr.T("quick.partial", m.unscannedShare(dir))
+		} else {
+			line += " " + m.tr.T("quick.unscanned", m.unscannedShare(dir))
 		}
+	}
+	if delta := m.renderSizeDelta(dir.Path); delta != "" {
+		line += " " + delta
+	}
 
-		// Subdirectories
-		for _, subdir := range sortedSubdirs {
-			linesUsed = strings.Count(b.String(), "\n")
-			if linesUsed >= maxLines {
-				break
-			}
-			currentIndex = m.renderDirectoryWithViewport(b, &subdir, depth+1, currentIndex, viewportTop, maxLines)
+	style := m.ageStyle(directoryStyle, dir.ModTime)
+	if index == m.cursor {
+		style = selectedStyle
+	} else if m.markedForDeletion[dir.Path] {
+		style = markedForDeletionStyle
+	} else if m.queue[dir.Path] {
+		style = queuedStyle
+	} else if m.selected[dir.Path] {
+		style = selectedItemStyle
+	}
+
+	cells := rowCells{
+		size:    size,
+		bytes:   m.dirSize(dir),
+		entries: dir.FileCount + dir.SubdirCount,
+		modTime: dir.ModTime,
+		path:    dir.Path,
+		mode:    dir.Mode,
+	}
+	if parent := m.tree.Find(filepath.Dir(dir.Path)); parent != nil && dir.Path != m.tree.Root.Path {
+		cells.parent = m.dirSize(parent)
+	}
+	return m.renderRow(line, style, cells)
+}
+
+func (m Model) renderFileRow(index int, row treeRow) string {
+	file := row.file
+	filePath := filepath.Join(row.dir.Path, file.Name)
+	fileLine := fmt.Sprintf("%s📄 %s", strings.Repeat("  ", row.depth), file.Name)
+	if _, ok := m.notes[filePath]; ok {
+		fileLine += " " + noteBadge
+	}
+	if len(m.openFiles[filePath]) > 0 {
+		fileLine += " " + openBadge
+	}
+	if file.LinkOf != "" {
+		// Its size is counted at the other link, not in this directory
+		counted := file.LinkOf
+		if rel, err := filepath.Rel(m.currentPath, counted); err == nil {
+			counted = rel
 		}
+		fileLine += " " + m.tr.T("links.counted_at", counted)
+	}
+
+	style := m.ageStyle(fileStyle, file.ModTime)
+	if index == m.cursor {
+		style = selectedStyle
+	} else if m.markedForDeletion[filePath] {
+		style = markedForDeletionStyle
+	} else if m.queue[filePath] {
+		style = queuedStyle
+	} else if m.selected[filePath] {
+		style = selectedItemStyle
 	}
 
-	return currentIndex
-}
\ No newline at end of file
+	return m.renderRow(fileLine, style, rowCells{
+		size:    formatSize(m.fileSize(*file)),
+		bytes:   m.fileSize(*file),
+		parent:  m.dirSize(row.dir),
+		entries: -1,
+		modTime: file.ModTime,
+		path:    filePath,
+		mode:    file.Mode,
+		links:   file.Links,
+	})
+}